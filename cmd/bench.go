@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"signet/config"
+	"signet/core"
+	"signet/crypto"
+	"signet/node"
+	"signet/server"
+	"signet/storage"
+	"time"
+)
+
+const (
+	benchFromNode = "bench-from"
+	benchToNode   = "bench-to"
+)
+
+// benchResult はベンチマーク結果を表す
+type benchResult struct {
+	Transactions int
+	Elapsed      time.Duration
+	BlocksPerSec float64
+	VerifyTotal  time.Duration
+	VerifyPerTx  time.Duration
+}
+
+// RunBench は `signet bench` コマンドを実行する
+// 一時ディレクトリ上のノードで propose→approve→commit を N 回繰り返し、スループットを計測する
+func RunBench(args []string) {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	count := fs.Int("n", 100, "生成するトランザクション数")
+
+	if err := fs.Parse(args); err != nil {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	if *count <= 0 {
+		fmt.Fprintln(os.Stderr, "Error: -n must be positive")
+		os.Exit(1)
+	}
+
+	if _, err := runBench(*count, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runBench はベンチマーク本体。結果を out に出力して返す
+func runBench(count int, out io.Writer) (*benchResult, error) {
+	rootDir, err := os.MkdirTemp("", "signet-bench-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(rootDir)
+
+	n, fromPriv, err := setupBenchNode(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// propose → approve → commit
+	start := time.Now()
+	for i := 0; i < count; i++ {
+		tx := &core.TransactionData{
+			From:   benchFromNode,
+			To:     benchToNode,
+			Amount: int64(i + 1),
+			Title:  fmt.Sprintf("bench-%d", i),
+		}
+		sig, err := crypto.SignTransaction(fromPriv, tx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign transaction: %w", err)
+		}
+
+		pending, err := n.ProposeTransactionDetailed(&server.TransactionData{
+			From:   tx.From,
+			To:     tx.To,
+			Amount: tx.Amount,
+			Title:  tx.Title,
+		}, sig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to propose transaction: %w", err)
+		}
+
+		if _, err := n.ApproveTransaction(pending.ID); err != nil {
+			return nil, fmt.Errorf("failed to approve transaction: %w", err)
+		}
+	}
+	elapsed := time.Since(start)
+
+	// 署名検証コスト（From/To 両方）
+	verifyStart := time.Now()
+	if err := verifyBenchChain(n); err != nil {
+		return nil, err
+	}
+	verifyTotal := time.Since(verifyStart)
+
+	result := &benchResult{
+		Transactions: count,
+		Elapsed:      elapsed,
+		BlocksPerSec: float64(count) / elapsed.Seconds(),
+		VerifyTotal:  verifyTotal,
+		VerifyPerTx:  verifyTotal / time.Duration(count),
+	}
+
+	fmt.Fprintf(out, "Transactions: %d\n", result.Transactions)
+	fmt.Fprintf(out, "Elapsed: %v\n", result.Elapsed)
+	fmt.Fprintf(out, "Throughput: %.2f blocks/sec\n", result.BlocksPerSec)
+	fmt.Fprintf(out, "Verification: %v total, %v per block\n", result.VerifyTotal, result.VerifyPerTx)
+
+	return result, nil
+}
+
+// setupBenchNode はベンチマーク用のノードを rootDir に初期化する
+// To 側がローカルノード、From 側は鍵だけ持つ疑似ピアとして登録する
+func setupBenchNode(rootDir string) (*node.Node, ed25519.PrivateKey, error) {
+	cfg := &config.Config{
+		RootDir:  rootDir,
+		Address:  "127.0.0.1",
+		NickName: benchToNode,
+		NodeName: benchToNode,
+		Port:     config.DefaultPort,
+		// 疑似ピアは残高を持たないため、残高超過の検査はしない
+		AllowNegativeBalance: true,
+	}
+
+	toPub, toPriv, err := crypto.GenerateKeyPair()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate key pair: %w", err)
+	}
+	fromPub, fromPriv, err := crypto.GenerateKeyPair()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate key pair: %w", err)
+	}
+
+	if err := crypto.SavePrivateKey(cfg.PrivKeyPath(), toPriv); err != nil {
+		return nil, nil, fmt.Errorf("failed to save private key: %w", err)
+	}
+	if err := storage.NewBlockStore(cfg.BlockFilePath()).Append(core.NewGenesisBlock()); err != nil {
+		return nil, nil, fmt.Errorf("failed to write genesis block: %w", err)
+	}
+
+	nodeStore := storage.NewNodeStore(cfg.NodesDir())
+	for name, pub := range map[string]ed25519.PublicKey{benchToNode: toPub, benchFromNode: fromPub} {
+		info := &storage.NodeInfo{
+			Name:      name,
+			NickName:  name,
+			Address:   config.NormalizeAddress(cfg.Address),
+			PublicKey: hex.EncodeToString(pub),
+		}
+		if err := nodeStore.Save(name, info); err != nil {
+			return nil, nil, fmt.Errorf("failed to save node info: %w", err)
+		}
+	}
+
+	n, err := node.NewNode(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize node: %w", err)
+	}
+	return n, fromPriv, nil
+}
+
+// verifyBenchChain はチェーン上の全トランザクションブロックの署名を検証する
+func verifyBenchChain(n *node.Node) error {
+	peers, err := n.NodeStore.LoadAll()
+	if err != nil {
+		return fmt.Errorf("failed to load peers: %w", err)
+	}
+
+	return n.Chain.ForEach(func(b *core.Block) error {
+		if b.Payload.Type != "transaction" {
+			return nil
+		}
+		tx, err := b.GetTransactionData()
+		if err != nil {
+			return err
+		}
+		for nodeName, sig := range map[string]string{tx.From: b.Payload.FromSignature, tx.To: b.Payload.ToSignature} {
+			pub, err := crypto.HexToPublicKey(peers[nodeName].PublicKey)
+			if err != nil {
+				return fmt.Errorf("failed to decode public key of %s: %w", nodeName, err)
+			}
+			if !crypto.VerifyTransactionSignature(pub, tx, sig) {
+				return fmt.Errorf("invalid signature in block %d", b.Header.Index)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRunBench(t *testing.T) {
+	var out bytes.Buffer
+
+	result, err := runBench(5, &out)
+	if err != nil {
+		t.Fatalf("runBench() error = %v", err)
+	}
+
+	if result.Transactions != 5 {
+		t.Errorf("Transactions = %d, want 5", result.Transactions)
+	}
+	if result.BlocksPerSec <= 0 {
+		t.Errorf("BlocksPerSec = %v, want > 0", result.BlocksPerSec)
+	}
+	if !strings.Contains(out.String(), "blocks/sec") {
+		t.Errorf("output does not contain throughput: %q", out.String())
+	}
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"signet/config"
+	"signet/p2p"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// cliClient はCLIからローカルノードにリクエストするためのHTTPクライアント
+var cliClient = &http.Client{
+	Timeout: 10 * time.Second,
+}
+
+// localNodeURL はローカルノードのベースURLを返す（start と同じ規則でポートを決定）
+// TLS が有効なら https とする（useLocalNodeClient で cliClient にも設定すること）
+func localNodeURL(cfg *config.Config) string {
+	host, port := config.ParseAddress(cfg.Address)
+	if cfg.Port != "" && cfg.Port != config.DefaultPort {
+		port = cfg.Port
+	}
+	scheme := "http"
+	if cfg.TLSEnabled() {
+		scheme = "https"
+	}
+	return scheme + "://" + net.JoinHostPort(host, port)
+}
+
+// useLocalNodeClient は cliClient をローカルノードの更新系エンドポイントを呼べるよう設定する
+// TLS が有効ならノード自身の証明書を提示して TLSCAFile で検証し（相互 TLS のノードはクライアント証明書を要求するため）、
+// APIKey が設定されていれば Authorization: Bearer で付与する
+func useLocalNodeClient(cfg *config.Config) error {
+	var base http.RoundTripper
+	if cfg.TLSEnabled() {
+		configs, err := p2p.LoadTLS(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSCAFile, cfg.TLSVersion())
+		if err != nil {
+			return err
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = configs.Client
+		base = transport
+	}
+	if cfg.APIKey != "" {
+		base = &p2p.APIKeyTransport{Base: base, Key: cfg.APIKey}
+	}
+	cliClient.Transport = base
+	return nil
+}
+
+// postJSON は body をJSONでPOSTし、成功時はレスポンスを out にデコードする
+// 200以外の場合はサーバーのエラーメッセージを含むエラーを返す
+func postJSON(url string, body any, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := cliClient.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return decodeResponse(resp, out)
+}
+
+// getJSON は url に GET し、成功時はレスポンスを out にデコードする
+func getJSON(url string, out any) error {
+	resp, err := cliClient.Get(url)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return decodeResponse(resp, out)
+}
+
+// decodeResponse はレスポンスのステータスを確認し、out にデコードする
+func decodeResponse(resp *http.Response, out any) error {
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		var errResp struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(respBody, &errResp) == nil && errResp.Error != "" {
+			return fmt.Errorf("server error (%d): %s", resp.StatusCode, errResp.Error)
+		}
+		return fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(respBody))
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// checkNodeRunning は PID ファイルからローカルノードが起動中かを確認する
+// PID ファイルがない、またはそのプロセスが存在しなければエラーを返す
+func checkNodeRunning(cfg *config.Config) error {
+	data, err := os.ReadFile(cfg.PIDFilePath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("PID file not found. Is the node running? (start it with `signet start`)")
+		}
+		return fmt.Errorf("failed to read PID file: %w", err)
+	}
+
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return fmt.Errorf("invalid PID file: %s", cfg.PIDFilePath())
+	}
+
+	if !processAlive(pid) {
+		return fmt.Errorf("node is not running (stale PID %d in %s)", pid, cfg.PIDFilePath())
+	}
+	return nil
+}
+
+// processAlive は pid のプロセスが存在するかを返す
+func processAlive(pid int) bool {
+	// シグナル 0 はプロセスの存在確認のみ行う
+	process, err := os.FindProcess(pid)
+	if err == nil {
+		err = process.Signal(syscall.Signal(0))
+	}
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
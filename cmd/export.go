@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"signet/config"
+	"signet/storage"
+)
+
+// RunExport は `signet export` コマンドを実行する
+// ローカルの block.jsonl の全ブロックを1つの JSON 配列としてファイルに書き出す（バックアップ・移行用）
+func RunExport(args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	out := fs.String("out", "", "書き出す JSON ファイルのパス")
+	force := fs.Bool("force", false, "既存のファイルを上書きする")
+
+	if err := fs.Parse(args); err != nil {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	if *out == "" {
+		fmt.Fprintln(os.Stderr, "Error: --out is required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	count, err := exportChain(cfg, *out, *force)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Exported %d blocks to %s\n", count, *out)
+}
+
+// exportChain は cfg の block.jsonl の全ブロックを整形した JSON 配列として path に書き出し、ブロック数を返す
+// 書き込みは writeOutputFile で行い、既存のファイルは force が true の場合のみ上書きする
+func exportChain(cfg *config.Config, path string, force bool) (int, error) {
+	blocks, err := storage.NewBlockStore(cfg.BlockFilePath()).LoadAll()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load blocks: %w", err)
+	}
+	if len(blocks) == 0 {
+		return 0, fmt.Errorf("no blocks in %s", cfg.BlockFilePath())
+	}
+
+	data, err := json.MarshalIndent(blocks, "", "  ")
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal blocks: %w", err)
+	}
+	if err := writeOutputFile(path, append(data, '\n'), force); err != nil {
+		return 0, err
+	}
+	return len(blocks), nil
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"signet/config"
+	"signet/storage"
+	"strings"
+	"testing"
+)
+
+func TestExportImportChain(t *testing.T) {
+	src := &config.Config{RootDir: t.TempDir()}
+	blocks, _ := writeVerifyTestChain(t, src.BlockFilePath())
+
+	path := filepath.Join(t.TempDir(), "chain.json")
+	count, err := exportChain(src, path, false)
+	if err != nil {
+		t.Fatalf("exportChain() error = %v", err)
+	}
+	if count != len(blocks) {
+		t.Errorf("exportChain() = %d, want %d", count, len(blocks))
+	}
+
+	dst := &config.Config{RootDir: t.TempDir()}
+	count, err = importChain(dst, path, false)
+	if err != nil {
+		t.Fatalf("importChain() error = %v", err)
+	}
+	if count != len(blocks) {
+		t.Errorf("importChain() = %d, want %d", count, len(blocks))
+	}
+
+	imported, err := storage.NewBlockStore(dst.BlockFilePath()).LoadAll()
+	if err != nil {
+		t.Fatalf("LoadAll() error = %v", err)
+	}
+	if len(imported) != len(blocks) {
+		t.Fatalf("imported %d blocks, want %d", len(imported), len(blocks))
+	}
+	for i := range blocks {
+		if imported[i].Header.Hash != blocks[i].Header.Hash {
+			t.Errorf("block %d hash = %s, want %s", i, imported[i].Header.Hash, blocks[i].Header.Hash)
+		}
+	}
+
+	if _, err := exportChain(&config.Config{RootDir: t.TempDir()}, filepath.Join(t.TempDir(), "empty.json"), false); err == nil {
+		t.Error("exportChain() should fail without blocks")
+	}
+}
+
+func TestExportChain_Overwrite(t *testing.T) {
+	src := &config.Config{RootDir: t.TempDir()}
+	blocks, _ := writeVerifyTestChain(t, src.BlockFilePath())
+
+	// 親ディレクトリがなければ作成する
+	path := filepath.Join(t.TempDir(), "backup", "chain.json")
+	old := []byte("old backup\n")
+	if _, err := exportChain(src, path, false); err != nil {
+		t.Fatalf("exportChain() error = %v", err)
+	}
+	if err := os.WriteFile(path, old, 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	// 既存のバックアップは --force なしでは上書きしない
+	if _, err := exportChain(src, path, false); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("exportChain() error = %v, want already exists", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != string(old) {
+		t.Errorf("existing file was modified: %q", data)
+	}
+
+	count, err := exportChain(src, path, true)
+	if err != nil {
+		t.Fatalf("exportChain(force) error = %v", err)
+	}
+	if count != len(blocks) {
+		t.Errorf("exportChain(force) = %d, want %d", count, len(blocks))
+	}
+	if data, _ := os.ReadFile(path); string(data) == string(old) {
+		t.Error("exportChain(force) did not overwrite the existing file")
+	}
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"signet/config"
+	"signet/core"
+	"signet/storage"
+)
+
+// RunImport は `signet import` コマンドを実行する
+// signet export で書き出した JSON 配列を検証し、ローカルの block.jsonl を置き換える
+func RunImport(args []string) {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	in := fs.String("in", "", "読み込む JSON ファイルのパス")
+	force := fs.Bool("force", false, "ローカルのチェーンより短くても置き換える")
+
+	if err := fs.Parse(args); err != nil {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	if *in == "" {
+		fmt.Fprintln(os.Stderr, "Error: --in is required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	// 起動中のノードはメモリ上のチェーンで block.jsonl を上書きするため、停止中のみ置き換える
+	if checkNodeRunning(cfg) == nil {
+		fmt.Fprintln(os.Stderr, "Error: node is running. Stop it with `signet stop` before importing")
+		os.Exit(1)
+	}
+
+	count, err := importChain(cfg, *in, *force)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Imported %d blocks from %s\n", count, *in)
+}
+
+// importChain は path の JSON 配列のブロックを検証し、cfg の block.jsonl を置き換えてブロック数を返す
+// 読み込んだチェーンがローカルのチェーンより短い場合は force が指定されない限り置き換えない（nodes/ のノードファイルは変更しない）
+func importChain(cfg *config.Config, path string, force bool) (int, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	var blocks []*core.Block
+	if err := json.Unmarshal(data, &blocks); err != nil {
+		return 0, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	// 整形で字下げされたペイロードを block.jsonl と同じ空白なしの形に戻す（ハッシュの対象のため）
+	for i, b := range blocks {
+		if b == nil {
+			return 0, fmt.Errorf("invalid chain: block %d is null", i)
+		}
+		if len(b.Payload.Data) == 0 {
+			continue
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, b.Payload.Data); err != nil {
+			return 0, fmt.Errorf("invalid payload data in block %d: %w", i, err)
+		}
+		b.Payload.Data = compact.Bytes()
+	}
+
+	chain, err := core.NewChainFromBlocks(blocks)
+	if err != nil {
+		return 0, fmt.Errorf("invalid chain: %w", err)
+	}
+	if err := chain.ValidateChain(); err != nil {
+		return 0, fmt.Errorf("invalid chain: %w", err)
+	}
+
+	store := storage.NewBlockStore(cfg.BlockFilePath())
+	// --force 指定時は破損して読めないローカルのチェーンも置き換えられる
+	local, err := store.LoadAll()
+	if err != nil && !force {
+		return 0, fmt.Errorf("failed to load local blocks (use --force to replace it): %w", err)
+	}
+	if len(blocks) < len(local) && !force {
+		return 0, fmt.Errorf("imported chain has %d blocks but the local chain has %d (use --force to replace it)", len(blocks), len(local))
+	}
+
+	if err := store.ReplaceAll(blocks); err != nil {
+		return 0, fmt.Errorf("failed to replace block file: %w", err)
+	}
+	return len(blocks), nil
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"signet/config"
+	"signet/core"
+	"signet/storage"
+	"testing"
+)
+
+func TestImportChain_RefuseShorter(t *testing.T) {
+	cfg := &config.Config{RootDir: t.TempDir()}
+	writeVerifyTestChain(t, cfg.BlockFilePath())
+
+	// ジェネシスのみのチェーンを書き出す
+	short := &config.Config{RootDir: t.TempDir()}
+	if err := storage.NewBlockStore(short.BlockFilePath()).Append(core.NewGenesisBlock()); err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "chain.json")
+	if _, err := exportChain(short, path, false); err != nil {
+		t.Fatalf("exportChain() error = %v", err)
+	}
+
+	if _, err := importChain(cfg, path, false); err == nil {
+		t.Fatal("importChain() should refuse a chain shorter than the local one")
+	}
+	if blocks, _ := storage.NewBlockStore(cfg.BlockFilePath()).LoadAll(); len(blocks) != 4 {
+		t.Errorf("local chain has %d blocks after refused import, want 4", len(blocks))
+	}
+
+	if _, err := importChain(cfg, path, true); err != nil {
+		t.Fatalf("importChain(force) error = %v", err)
+	}
+	if blocks, _ := storage.NewBlockStore(cfg.BlockFilePath()).LoadAll(); len(blocks) != 1 {
+		t.Errorf("local chain has %d blocks after forced import, want 1", len(blocks))
+	}
+}
+
+func TestImportChain_Invalid(t *testing.T) {
+	cfg := &config.Config{RootDir: t.TempDir()}
+	path := filepath.Join(t.TempDir(), "chain.json")
+
+	tampered := `[{"header":{"index":0,"created_at":"2024-01-01T00:00:00Z","prev_hash":"","hash":"bad"},"payload":{"type":"add_node","data":{}}}]`
+	if err := os.WriteFile(path, []byte(tampered), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := importChain(cfg, path, true); err == nil {
+		t.Error("importChain() should reject an invalid chain even with force")
+	}
+	if _, err := os.Stat(cfg.BlockFilePath()); !os.IsNotExist(err) {
+		t.Error("block file should not be written for an invalid chain")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"path/filepath"
 	"signet/config"
 	"signet/core"
 	"signet/crypto"
@@ -18,6 +19,7 @@ func RunInit(args []string) {
 	addr := fs.String("address", "", "ノードのアドレス (例: 192.168.120.137)")
 	nickname := fs.String("nickname", "", "ニックネーム")
 	nodename := fs.String("nodename", "", "ノード名")
+	rootDir := fs.String("rootdir", config.DefaultRootDir(), "ファイル類のルートディレクトリ (デフォルト: $SIGNET_ROOT または /etc/signet)")
 
 	if err := fs.Parse(args); err != nil {
 		fs.Usage()
@@ -31,38 +33,70 @@ func RunInit(args []string) {
 		os.Exit(1)
 	}
 
+	// 設定ファイルに書き込むため、他のディレクトリから実行しても同じ場所を指すよう絶対パスにする
+	root, err := filepath.Abs(*rootDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: invalid root directory: %v\n", err)
+		os.Exit(1)
+	}
+
 	// 設定読み込み（デフォルト値でOK）
 	cfg := &config.Config{
-		RootDir:  "/etc/signet",
+		RootDir:  root,
 		Address:  *addr,
 		NickName: *nickname,
 		NodeName: *nodename,
 		Port:     "8080",
 	}
 
+	// 設定ファイルは SIGNET_CONFIG がなければ RootDir の下に保存する
+	confPath := os.Getenv(config.EnvConfigPath)
+	if confPath == "" {
+		confPath = filepath.Join(cfg.RootDir, "signet.conf")
+	}
+
+	pubKeyHex, err := initNode(cfg, confPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println("Signet node initialized successfully!")
+	fmt.Printf("  Node Name: %s\n", *nodename)
+	fmt.Printf("  Nick Name: %s\n", *nickname)
+	fmt.Printf("  Address: %s\n", *addr)
+	fmt.Printf("  Public Key: %s\n", pubKeyHex)
+	fmt.Printf("  Root Dir: %s\n", cfg.RootDir)
+	fmt.Printf("  Config: %s\n", confPath)
+	// 他のコマンドは ConfigPath の設定ファイルを読むため、既定の場所でなければ環境変数で指定してもらう
+	if confPath != config.ConfigPath() {
+		fmt.Printf("  Set %s=%s (or %s=%s) to use this node with other commands\n",
+			config.EnvRootDir, cfg.RootDir, config.EnvConfigPath, confPath)
+	}
+}
+
+// initNode は cfg.RootDir に鍵ペア・ジェネシスブロック・自ノードのノードファイルを作成し、設定を confPath に保存する
+// 生成した公開鍵(hex)を返す
+func initNode(cfg *config.Config, confPath string) (string, error) {
 	// RootDir 作成
 	if err := os.MkdirAll(cfg.RootDir, 0755); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to create root directory: %v\n", err)
-		os.Exit(1)
+		return "", fmt.Errorf("failed to create root directory: %w", err)
 	}
 
 	// nodes ディレクトリ作成
 	if err := os.MkdirAll(cfg.NodesDir(), 0755); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to create nodes directory: %v\n", err)
-		os.Exit(1)
+		return "", fmt.Errorf("failed to create nodes directory: %w", err)
 	}
 
 	// Ed25519鍵ペア生成
 	pubKey, privKey, err := crypto.GenerateKeyPair()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to generate key pair: %v\n", err)
-		os.Exit(1)
+		return "", fmt.Errorf("failed to generate key pair: %w", err)
 	}
 
 	// 秘密鍵を保存
 	if err := crypto.SavePrivateKey(cfg.PrivKeyPath(), privKey); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to save private key: %v\n", err)
-		os.Exit(1)
+		return "", fmt.Errorf("failed to save private key: %w", err)
 	}
 
 	// ジェネシスブロック生成（全ノード共通の固定データ）
@@ -73,40 +107,31 @@ func RunInit(args []string) {
 	// block.jsonl に書き込み
 	blockStore := storage.NewBlockStore(cfg.BlockFilePath())
 	if err := blockStore.Append(genesis); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to write genesis block: %v\n", err)
-		os.Exit(1)
+		return "", fmt.Errorf("failed to write genesis block: %w", err)
 	}
 
 	// 自ノード情報をnodesディレクトリに保存
 	nodeStore := storage.NewNodeStore(cfg.NodesDir())
 	nodeInfo := &storage.NodeInfo{
-		Name:      *nodename,
-		NickName:  *nickname,
-		Address:   config.NormalizeAddress(*addr),
+		Name:      cfg.NodeName,
+		NickName:  cfg.NickName,
+		Address:   config.NormalizeAddress(cfg.Address),
 		PublicKey: pubKeyHex,
 	}
-	if err := nodeStore.Save(*nodename, nodeInfo); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to save node info: %v\n", err)
-		os.Exit(1)
+	if err := nodeStore.Save(cfg.NodeName, nodeInfo); err != nil {
+		return "", fmt.Errorf("failed to save node info: %w", err)
 	}
 
 	// 設定ファイル保存
-	if err := saveConfig(cfg); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to save config: %v\n", err)
-		os.Exit(1)
+	if err := saveConfig(cfg, confPath); err != nil {
+		return "", fmt.Errorf("failed to save config: %w", err)
 	}
 
-	fmt.Println("Signet node initialized successfully!")
-	fmt.Printf("  Node Name: %s\n", *nodename)
-	fmt.Printf("  Nick Name: %s\n", *nickname)
-	fmt.Printf("  Address: %s\n", *addr)
-	fmt.Printf("  Public Key: %s\n", pubKeyHex)
-	fmt.Printf("  Config: %s\n", defaultConfigPath())
+	return pubKeyHex, nil
 }
 
-// saveConfig は設定をファイルに保存する
-func saveConfig(cfg *config.Config) error {
-	path := defaultConfigPath()
+// saveConfig は設定を path に保存する
+func saveConfig(cfg *config.Config, path string) error {
 	content := fmt.Sprintf("RootDir = %s\n", cfg.RootDir)
 	content += fmt.Sprintf("Address = %s\n", cfg.Address)
 	content += fmt.Sprintf("NickName = %s\n", cfg.NickName)
@@ -114,11 +139,3 @@ func saveConfig(cfg *config.Config) error {
 	content += fmt.Sprintf("Port = %s\n", cfg.Port)
 	return os.WriteFile(path, []byte(content), 0644)
 }
-
-// defaultConfigPath はデフォルトの設定ファイルパスを返す
-func defaultConfigPath() string {
-	if path := os.Getenv("SIGNET_CONFIG"); path != "" {
-		return path
-	}
-	return "/etc/signet/signet.conf"
-}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"signet/config"
+	"signet/storage"
+	"testing"
+)
+
+func TestInitNode_CustomRootDir(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "signet")
+	cfg := &config.Config{RootDir: root, Address: "10.0.0.1", NickName: "alice", NodeName: "alice", Port: "8080"}
+	confPath := filepath.Join(root, "signet.conf")
+
+	pubKeyHex, err := initNode(cfg, confPath)
+	if err != nil {
+		t.Fatalf("initNode() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "ed25519.priv")); err != nil {
+		t.Errorf("private key not created under root dir: %v", err)
+	}
+	blocks, err := storage.NewBlockStore(filepath.Join(root, "block.jsonl")).LoadAll()
+	if err != nil || len(blocks) != 1 || !blocks[0].IsGenesisBlock() {
+		t.Errorf("block file = %d blocks, %v; want the genesis block", len(blocks), err)
+	}
+	self, err := storage.NewNodeStore(filepath.Join(root, "nodes")).Load("alice")
+	if err != nil {
+		t.Fatalf("Load(alice) error = %v", err)
+	}
+	if self.PublicKey != pubKeyHex {
+		t.Errorf("node file public key = %s, want %s", self.PublicKey, pubKeyHex)
+	}
+
+	// 保存した設定ファイルを start / stop と同じ方法で読む
+	t.Setenv(config.EnvConfigPath, "")
+	t.Setenv(config.EnvRootDir, root)
+	loaded, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if loaded.RootDir != root || loaded.NodeName != "alice" {
+		t.Errorf("loaded config RootDir = %s, NodeName = %s; want %s, alice", loaded.RootDir, loaded.NodeName, root)
+	}
+}
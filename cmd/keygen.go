@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"signet/crypto"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// RunKeygen は `signet keygen` コマンドを実行する
+// ノードを初期化せずに鍵ペアを生成し、秘密鍵をファイルに保存して公開鍵(hex)を標準出力に表示する
+func RunKeygen(args []string) {
+	fs := flag.NewFlagSet("keygen", flag.ExitOnError)
+	out := fs.String("out", "", "秘密鍵を保存するパス")
+	raw := fs.Bool("raw", false, "PEM ではなく生の Base64 形式で保存する")
+	encrypt := fs.Bool("encrypt", false, "パスフレーズを入力して暗号化した PEM で保存する（オフライン保管用。signet start / send では読み込めない）")
+
+	if err := fs.Parse(args); err != nil {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	if *out == "" {
+		fmt.Fprintln(os.Stderr, "Error: --out is required")
+		fs.Usage()
+		os.Exit(1)
+	}
+	if *raw && *encrypt {
+		fmt.Fprintln(os.Stderr, "Error: --raw and --encrypt cannot be used together")
+		os.Exit(1)
+	}
+
+	var passphrase []byte
+	if *encrypt {
+		var p []byte
+		var err error
+		if fd := int(os.Stdin.Fd()); term.IsTerminal(fd) {
+			p, err = readPassphraseTerminal(fd, os.Stderr)
+		} else {
+			p, err = readPassphrase(bufio.NewReader(os.Stdin), os.Stderr)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		passphrase = p
+	}
+
+	pubKey, err := generateKeyFile(*out, *raw, passphrase)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Println(hex.EncodeToString(pubKey))
+}
+
+// generateKeyFile は鍵ペアを生成して秘密鍵を path に保存し、公開鍵を返す
+// passphrase が空でなければ暗号化した PEM、raw なら生の Base64、それ以外は PEM で保存する
+// 既存の鍵ファイルは上書きしない
+func generateKeyFile(path string, raw bool, passphrase []byte) (ed25519.PublicKey, error) {
+	if _, err := os.Stat(path); err == nil {
+		return nil, fmt.Errorf("%s already exists", path)
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to check %s: %w", path, err)
+	}
+
+	pubKey, privKey, err := crypto.GenerateKeyPair()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate key pair: %w", err)
+	}
+
+	switch {
+	case len(passphrase) > 0:
+		err = crypto.SavePrivateKeyEncrypted(path, privKey, passphrase)
+	case raw:
+		err = crypto.SavePrivateKeyRaw(path, privKey)
+	default:
+		err = crypto.SavePrivateKey(path, privKey)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save private key: %w", err)
+	}
+
+	return pubKey, nil
+}
+
+// readPassphrase は端末でない入力（パイプ・リダイレクト）からパスフレーズを1行ずつ読み込む
+// プロンプトは w（標準エラー出力）に書き、公開鍵を出力する標準出力には混ぜない
+func readPassphrase(r *bufio.Reader, w io.Writer) ([]byte, error) {
+	return confirmPassphrase(func(prompt string) ([]byte, error) {
+		fmt.Fprint(w, prompt)
+		line, err := r.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			return nil, fmt.Errorf("failed to read passphrase: %w", err)
+		}
+		return []byte(strings.TrimRight(line, "\r\n")), nil
+	})
+}
+
+// readPassphraseTerminal は端末 fd からエコーを止めてパスフレーズを読み込む（画面やスクロールバックに残さない）
+func readPassphraseTerminal(fd int, w io.Writer) ([]byte, error) {
+	return confirmPassphrase(func(prompt string) ([]byte, error) {
+		fmt.Fprint(w, prompt)
+		line, err := term.ReadPassword(fd)
+		// 入力した改行もエコーされないため、次のプロンプトの前に改行する
+		fmt.Fprintln(w)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read passphrase: %w", err)
+		}
+		return line, nil
+	})
+}
+
+// confirmPassphrase は readLine でパスフレーズを確認のため2回読み込み、一致すれば返す
+func confirmPassphrase(readLine func(prompt string) ([]byte, error)) ([]byte, error) {
+	passphrase, err := readLine("Passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase must not be empty")
+	}
+	confirm, err := readLine("Confirm passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(passphrase, confirm) {
+		return nil, fmt.Errorf("passphrases do not match")
+	}
+	return passphrase, nil
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"signet/crypto"
+	"strings"
+	"testing"
+)
+
+func TestGenerateKeyFile(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name       string
+		raw        bool
+		passphrase []byte
+		format     string
+	}{
+		{name: "pem", format: crypto.KeyFormatPEM},
+		{name: "raw", raw: true, format: crypto.KeyFormatRaw},
+		{name: "encrypted", passphrase: []byte("secret")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, tt.name+".priv")
+			pub, err := generateKeyFile(path, tt.raw, tt.passphrase)
+			if err != nil {
+				t.Fatalf("generateKeyFile() error = %v", err)
+			}
+
+			if tt.passphrase != nil {
+				priv, err := crypto.LoadPrivateKeyEncrypted(path, tt.passphrase)
+				if err != nil {
+					t.Fatalf("LoadPrivateKeyEncrypted() error = %v", err)
+				}
+				if !pub.Equal(crypto.GetPublicKeyFromPrivateKey(priv)) {
+					t.Error("public key does not match the saved private key")
+				}
+				return
+			}
+			format, got, err := crypto.InspectKeyFile(path)
+			if err != nil {
+				t.Fatalf("InspectKeyFile() error = %v", err)
+			}
+			if format != tt.format || !pub.Equal(got) {
+				t.Errorf("format/public key = %s/%x, want %s/%x", format, got, tt.format, pub)
+			}
+		})
+	}
+
+	// 既存の鍵ファイルは上書きしない
+	if _, err := generateKeyFile(filepath.Join(dir, "pem.priv"), false, nil); err == nil {
+		t.Error("generateKeyFile() should refuse to overwrite an existing file")
+	}
+}
+
+func TestReadPassphrase(t *testing.T) {
+	var prompts bytes.Buffer
+	got, err := readPassphrase(bufio.NewReader(strings.NewReader("secret\nsecret\n")), &prompts)
+	if err != nil {
+		t.Fatalf("readPassphrase() error = %v", err)
+	}
+	if string(got) != "secret" {
+		t.Errorf("passphrase = %q, want secret", got)
+	}
+	if !strings.Contains(prompts.String(), "Confirm") {
+		t.Errorf("prompts = %q, want confirmation prompt", prompts.String())
+	}
+
+	for _, input := range []string{"secret\nother\n", "\n\n", ""} {
+		if _, err := readPassphrase(bufio.NewReader(strings.NewReader(input)), &prompts); err == nil {
+			t.Errorf("readPassphrase(%q) should fail", input)
+		}
+	}
+}
+
+func TestReadPassphraseTerminal_NotTerminal(t *testing.T) {
+	// 端末でない fd ではエコーを止められないため読み込まない（RunKeygen は readPassphrase を使う）
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Pipe() error = %v", err)
+	}
+	defer r.Close()
+	defer w.Close()
+	w.WriteString("secret\nsecret\n")
+
+	if _, err := readPassphraseTerminal(int(r.Fd()), io.Discard); err == nil {
+		t.Error("readPassphraseTerminal() should fail for a non-terminal fd")
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"signet/config"
+	"signet/crypto"
+	"signet/storage"
+)
+
+// RunKeyInfo は `signet key-info` コマンドを実行する
+// 起動中のノードを介さず、秘密鍵ファイルの形式と公開鍵を表示する（秘密鍵は表示しない）
+func RunKeyInfo(args []string) {
+	fs := flag.NewFlagSet("key-info", flag.ExitOnError)
+	file := fs.String("file", "", "秘密鍵ファイルのパス (デフォルト: RootDir/ed25519.priv)")
+
+	if err := fs.Parse(args); err != nil {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	path := *file
+	if path == "" {
+		path = cfg.PrivKeyPath()
+	}
+
+	if err := writeKeyInfo(os.Stdout, cfg, path); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// writeKeyInfo は鍵ファイルの形式と公開鍵(hex)を w に書き出す
+// 自ノードのノードファイルがあれば、登録されている公開鍵と一致するかも書き出す
+func writeKeyInfo(w io.Writer, cfg *config.Config, path string) error {
+	format, pubKey, err := crypto.InspectKeyFile(path)
+	if err != nil {
+		return err
+	}
+	pubHex := hex.EncodeToString(pubKey)
+
+	fmt.Fprintf(w, "File: %s\n", path)
+	fmt.Fprintf(w, "Format: %s\n", format)
+	fmt.Fprintf(w, "Public key: %s\n", pubHex)
+
+	if cfg.NodeName == "" {
+		return nil
+	}
+	self, err := storage.NewNodeStore(cfg.NodesDir()).Load(cfg.NodeName)
+	if err != nil {
+		return nil
+	}
+	if self.PublicKey == pubHex {
+		fmt.Fprintf(w, "Node file (%s): matches\n", cfg.NodeName)
+	} else {
+		fmt.Fprintf(w, "Node file (%s): MISMATCH (registered %s)\n", cfg.NodeName, self.PublicKey)
+	}
+	return nil
+}
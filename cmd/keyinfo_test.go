@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/hex"
+	"signet/config"
+	"signet/crypto"
+	"signet/storage"
+	"strings"
+	"testing"
+)
+
+func TestWriteKeyInfo(t *testing.T) {
+	cfg := &config.Config{RootDir: t.TempDir(), NodeName: "self"}
+
+	pub, priv, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+	if err := crypto.SavePrivateKey(cfg.PrivKeyPath(), priv); err != nil {
+		t.Fatalf("SavePrivateKey() error = %v", err)
+	}
+	pubHex := hex.EncodeToString(pub)
+
+	var out bytes.Buffer
+	if err := writeKeyInfo(&out, cfg, cfg.PrivKeyPath()); err != nil {
+		t.Fatalf("writeKeyInfo() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "Format: pem") || !strings.Contains(out.String(), pubHex) {
+		t.Errorf("output = %q, want format and public key", out.String())
+	}
+	if strings.Contains(out.String(), hex.EncodeToString(priv)) {
+		t.Error("output must not contain the private key")
+	}
+
+	// ノードファイルの公開鍵と照合する
+	store := storage.NewNodeStore(cfg.NodesDir())
+	if err := store.Save("self", &storage.NodeInfo{Name: "self", NickName: "self", Address: "10.0.0.1", PublicKey: strings.Repeat("ab", 32)}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	out.Reset()
+	if err := writeKeyInfo(&out, cfg, cfg.PrivKeyPath()); err != nil {
+		t.Fatalf("writeKeyInfo() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "MISMATCH") {
+		t.Errorf("output = %q, want MISMATCH", out.String())
+	}
+
+	if err := writeKeyInfo(&out, cfg, cfg.NodeFilePath("missing")); err == nil {
+		t.Error("writeKeyInfo() should fail for a missing key file")
+	}
+}
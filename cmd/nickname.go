@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"signet/config"
+)
+
+// RunNickname は `signet nickname` コマンドを実行する
+// 起動中のローカルノードにニックネーム変更を依頼する
+func RunNickname(args []string) {
+	fs := flag.NewFlagSet("nickname", flag.ExitOnError)
+	nickname := fs.String("nickname", "", "新しいニックネーム")
+
+	if err := fs.Parse(args); err != nil {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	if *nickname == "" {
+		fmt.Fprintln(os.Stderr, "Error: --nickname is required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+	if err := useLocalNodeClient(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load TLS configuration: %v\n", err)
+		os.Exit(1)
+	}
+
+	var resp struct {
+		Status string `json:"status"`
+	}
+	url := localNodeURL(cfg) + "/node/nickname"
+	if err := postJSON(url, map[string]string{"nick_name": *nickname}, &resp); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Nickname updated: %s\n", *nickname)
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteOutputFile(t *testing.T) {
+	t.Run("creates parent directories", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "backup", "2026", "chain.json")
+
+		if err := writeOutputFile(path, []byte("first"), false); err != nil {
+			t.Fatalf("writeOutputFile() error = %v", err)
+		}
+
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("ReadFile() error = %v", err)
+		}
+		if string(got) != "first" {
+			t.Errorf("content = %q, want %q", got, "first")
+		}
+	})
+
+	t.Run("refuses to overwrite without force", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "chain.json")
+		if err := os.WriteFile(path, []byte("existing"), 0644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+
+		if err := writeOutputFile(path, []byte("new"), false); err == nil {
+			t.Fatal("writeOutputFile() should fail when file exists")
+		}
+
+		got, _ := os.ReadFile(path)
+		if string(got) != "existing" {
+			t.Errorf("content = %q, want existing file untouched", got)
+		}
+	})
+
+	t.Run("overwrites with force", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "chain.json")
+		if err := os.WriteFile(path, []byte("existing"), 0644); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+
+		if err := writeOutputFile(path, []byte("new"), true); err != nil {
+			t.Fatalf("writeOutputFile() error = %v", err)
+		}
+
+		got, _ := os.ReadFile(path)
+		if string(got) != "new" {
+			t.Errorf("content = %q, want %q", got, "new")
+		}
+
+		// 一時ファイルが残っていないこと
+		entries, _ := os.ReadDir(dir)
+		if len(entries) != 1 {
+			t.Errorf("directory has %d entries, want 1", len(entries))
+		}
+	})
+}
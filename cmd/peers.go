@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"signet/config"
+	"signet/storage"
+)
+
+// peerNamePattern は登録APIと同じノード名の規則（パストラバーサル防止）
+var peerNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// RunPeers は `signet peers add|remove` コマンドを実行する
+// 起動中のノードを介さず、ローカルの RootDir のノードファイルを直接操作する
+func RunPeers(args []string) {
+	if len(args) < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: signet peers <add|remove> [options]")
+		os.Exit(1)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	switch args[0] {
+	case "add":
+		fs := flag.NewFlagSet("peers add", flag.ExitOnError)
+		name := fs.String("name", "", "ノード名")
+		address := fs.String("address", "", "ノードのアドレス (例: 192.168.1.10:8080)")
+		pubkey := fs.String("pubkey", "", "Ed25519公開鍵 (hex)")
+		if err := fs.Parse(args[1:]); err != nil {
+			fs.Usage()
+			os.Exit(1)
+		}
+		if *name == "" || *address == "" || *pubkey == "" {
+			fmt.Fprintln(os.Stderr, "Error: --name, --address, --pubkey are required")
+			fs.Usage()
+			os.Exit(1)
+		}
+		if err := addPeer(cfg, *name, *address, *pubkey); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Peer added: %s\n", *name)
+	case "remove":
+		fs := flag.NewFlagSet("peers remove", flag.ExitOnError)
+		name := fs.String("name", "", "ノード名")
+		if err := fs.Parse(args[1:]); err != nil {
+			fs.Usage()
+			os.Exit(1)
+		}
+		if *name == "" {
+			fmt.Fprintln(os.Stderr, "Error: --name is required")
+			fs.Usage()
+			os.Exit(1)
+		}
+		if err := removePeer(cfg, *name); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Peer removed: %s\n", *name)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown peers command: %s\n", args[0])
+		os.Exit(1)
+	}
+}
+
+// addPeer はピアのノードファイルを作成する（公開鍵の形式などは NodeStore.Save で検証）
+func addPeer(cfg *config.Config, name, address, pubkey string) error {
+	if !peerNamePattern.MatchString(name) {
+		return fmt.Errorf("name must contain only alphanumeric characters, hyphens, and underscores: %s", name)
+	}
+
+	store := storage.NewNodeStore(cfg.NodesDir())
+	if store.Exists(name) {
+		return fmt.Errorf("peer already exists: %s", name)
+	}
+
+	info := &storage.NodeInfo{
+		Name:      name,
+		NickName:  name,
+		Address:   config.NormalizeAddress(address),
+		PublicKey: pubkey,
+	}
+	if err := store.Save(name, info); err != nil {
+		return fmt.Errorf("failed to save peer: %w", err)
+	}
+	return nil
+}
+
+// removePeer はピアのノードファイルを削除する。自ノードは削除できない
+func removePeer(cfg *config.Config, name string) error {
+	if !peerNamePattern.MatchString(name) {
+		return fmt.Errorf("name must contain only alphanumeric characters, hyphens, and underscores: %s", name)
+	}
+	if name == cfg.NodeName {
+		return fmt.Errorf("cannot remove own node: %s", name)
+	}
+
+	store := storage.NewNodeStore(cfg.NodesDir())
+	if !store.Exists(name) {
+		return fmt.Errorf("peer not found: %s", name)
+	}
+	if err := store.Delete(name); err != nil {
+		return fmt.Errorf("failed to remove peer: %w", err)
+	}
+	return nil
+}
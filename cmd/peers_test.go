@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"os"
+	"signet/config"
+	"strings"
+	"testing"
+)
+
+func TestAddRemovePeer(t *testing.T) {
+	cfg := &config.Config{RootDir: t.TempDir(), NodeName: "self"}
+	pubkey := strings.Repeat("ab", 32)
+
+	if err := addPeer(cfg, "alice", "10.0.0.1", pubkey); err != nil {
+		t.Fatalf("addPeer() error = %v", err)
+	}
+	if _, err := os.Stat(cfg.NodeFilePath("alice")); err != nil {
+		t.Fatalf("node file not created: %v", err)
+	}
+
+	if err := addPeer(cfg, "alice", "10.0.0.1", pubkey); err == nil {
+		t.Error("addPeer() should fail for an existing peer")
+	}
+
+	if err := removePeer(cfg, "alice"); err != nil {
+		t.Fatalf("removePeer() error = %v", err)
+	}
+	if _, err := os.Stat(cfg.NodeFilePath("alice")); !os.IsNotExist(err) {
+		t.Errorf("node file still exists after remove: %v", err)
+	}
+
+	if err := removePeer(cfg, "alice"); err == nil {
+		t.Error("removePeer() should fail for an unknown peer")
+	}
+}
+
+func TestAddPeer_Invalid(t *testing.T) {
+	cfg := &config.Config{RootDir: t.TempDir(), NodeName: "self"}
+	pubkey := strings.Repeat("ab", 32)
+
+	tests := []struct {
+		name, node, pubkey string
+	}{
+		{"path traversal", "../etc", pubkey},
+		{"slash", "a/b", pubkey},
+		{"invalid public key", "bob", "not-a-key"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := addPeer(cfg, tt.node, "10.0.0.1", tt.pubkey); err == nil {
+				t.Errorf("addPeer(%q) should fail", tt.node)
+			}
+		})
+	}
+
+	if err := removePeer(cfg, "self"); err == nil {
+		t.Error("removePeer() should refuse to remove own node")
+	}
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"signet/config"
+	"signet/server"
+	"text/tabwriter"
+)
+
+// RunPending は `signet pending` コマンドを実行する
+// 起動中のローカルノードから自ノード宛の承認待ちトランザクションを取得して一覧表示する
+func RunPending(args []string) {
+	cfg := loadRunningNodeConfig()
+
+	if err := listPending(localNodeURL(cfg), os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// RunApprove は `signet approve <id>` コマンドを実行する
+// 起動中のローカルノードで承認待ちトランザクションを承認し、確定したブロックのハッシュを表示する
+func RunApprove(args []string) {
+	if len(args) != 1 || args[0] == "" {
+		fmt.Fprintln(os.Stderr, "Usage: signet approve <id>")
+		os.Exit(1)
+	}
+	cfg := loadRunningNodeConfig()
+
+	if err := approvePending(localNodeURL(cfg), args[0], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// RunReject は `signet reject <id>` コマンドを実行する
+// 起動中のローカルノードで承認待ちトランザクションを拒否する
+func RunReject(args []string) {
+	if len(args) != 1 || args[0] == "" {
+		fmt.Fprintln(os.Stderr, "Usage: signet reject <id>")
+		os.Exit(1)
+	}
+	cfg := loadRunningNodeConfig()
+
+	if err := rejectPending(localNodeURL(cfg), args[0], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// loadRunningNodeConfig は設定を読み込み、ローカルノードが起動中であることを確認する（失敗したら終了する）
+func loadRunningNodeConfig() *config.Config {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+	if err := useLocalNodeClient(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load TLS configuration: %v\n", err)
+		os.Exit(1)
+	}
+	if err := checkNodeRunning(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return cfg
+}
+
+// listPending は baseURL のノードの承認待ちトランザクションを取得して out に表で出力する
+func listPending(baseURL string, out io.Writer) error {
+	var pending []*server.PendingTransaction
+	if err := getJSON(baseURL+"/transaction/pending", &pending); err != nil {
+		return err
+	}
+	return writePendingTable(out, pending)
+}
+
+// writePendingTable は承認待ちトランザクションを ID / FROM / TO / AMOUNT / TITLE の表で出力する
+// 1件もなければその旨のメッセージを出力する
+func writePendingTable(out io.Writer, pending []*server.PendingTransaction) error {
+	if len(pending) == 0 {
+		_, err := fmt.Fprintln(out, "No pending transactions")
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tFROM\tTO\tAMOUNT\tTITLE")
+	for _, p := range pending {
+		tx := p.Transaction
+		if tx == nil {
+			tx = &server.TransactionData{}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", p.ID, tx.From, tx.To, tx.Amount, tx.Title)
+	}
+	return w.Flush()
+}
+
+// approvePending は baseURL のノードで id の承認待ちトランザクションを承認し、確定したブロックのハッシュを出力する
+func approvePending(baseURL, id string, out io.Writer) error {
+	var resp struct {
+		Block *server.Block `json:"block"`
+	}
+	if err := postJSON(baseURL+"/transaction/approve", map[string]string{"id": id}, &resp); err != nil {
+		return err
+	}
+	if resp.Block == nil {
+		return fmt.Errorf("approve response has no block")
+	}
+	fmt.Fprintf(out, "Approved: block %s\n", resp.Block.Header.Hash)
+	return nil
+}
+
+// rejectPending は baseURL のノードで id の承認待ちトランザクションを拒否する
+func rejectPending(baseURL, id string, out io.Writer) error {
+	if err := postJSON(baseURL+"/transaction/reject", map[string]string{"id": id}, nil); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Rejected: %s\n", id)
+	return nil
+}
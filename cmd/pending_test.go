@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"signet/server"
+	"strings"
+	"testing"
+)
+
+// newPendingStub は承認待ち一覧・承認・拒否に応答するスタブサーバー（承認・拒否できるのは tx-1 のみ）
+func newPendingStub(t *testing.T, pending []*server.PendingTransaction) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /transaction/pending", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(pending)
+	})
+	handle := func(w http.ResponseWriter, r *http.Request, resp any) {
+		var req struct {
+			ID string `json:"id"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.ID != "tx-1" {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string]string{"error": "pending transaction not found"})
+			return
+		}
+		json.NewEncoder(w).Encode(resp)
+	}
+	mux.HandleFunc("POST /transaction/approve", func(w http.ResponseWriter, r *http.Request) {
+		block := &server.Block{Header: server.BlockHeader{Index: 3, Hash: "block-hash"}}
+		handle(w, r, map[string]any{"status": "approved", "block": block})
+	})
+	mux.HandleFunc("POST /transaction/reject", func(w http.ResponseWriter, r *http.Request) {
+		handle(w, r, map[string]string{"status": "rejected", "message": "Transaction rejected"})
+	})
+
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+func TestWritePendingTable(t *testing.T) {
+	pending := []*server.PendingTransaction{
+		{ID: "tx-1", Transaction: &server.TransactionData{From: "alice", To: "bob", Amount: 500, Title: "ランチ"}},
+		{ID: "tx-22", Transaction: &server.TransactionData{From: "carol", To: "bob", Amount: 12000, Title: "飲み会"}},
+	}
+
+	var out bytes.Buffer
+	if err := writePendingTable(&out, pending); err != nil {
+		t.Fatalf("writePendingTable() error = %v", err)
+	}
+
+	want := "ID     FROM   TO   AMOUNT  TITLE\n" +
+		"tx-1   alice  bob  500     ランチ\n" +
+		"tx-22  carol  bob  12000   飲み会\n"
+	if out.String() != want {
+		t.Errorf("output =\n%s\nwant\n%s", out.String(), want)
+	}
+
+	out.Reset()
+	if err := writePendingTable(&out, nil); err != nil {
+		t.Fatalf("writePendingTable(nil) error = %v", err)
+	}
+	if out.String() != "No pending transactions\n" {
+		t.Errorf("empty output = %q", out.String())
+	}
+}
+
+func TestListPending(t *testing.T) {
+	ts := newPendingStub(t, []*server.PendingTransaction{
+		{ID: "tx-1", Transaction: &server.TransactionData{From: "alice", To: "bob", Amount: 500, Title: "ランチ"}},
+	})
+
+	var out bytes.Buffer
+	if err := listPending(ts.URL, &out); err != nil {
+		t.Fatalf("listPending() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "tx-1") || !strings.Contains(out.String(), "ランチ") {
+		t.Errorf("output does not contain the pending transaction: %q", out.String())
+	}
+}
+
+func TestApproveRejectPending(t *testing.T) {
+	ts := newPendingStub(t, nil)
+
+	var out bytes.Buffer
+	if err := approvePending(ts.URL, "tx-1", &out); err != nil {
+		t.Fatalf("approvePending() error = %v", err)
+	}
+	if out.String() != "Approved: block block-hash\n" {
+		t.Errorf("approve output = %q", out.String())
+	}
+
+	out.Reset()
+	if err := rejectPending(ts.URL, "tx-1", &out); err != nil {
+		t.Fatalf("rejectPending() error = %v", err)
+	}
+	if out.String() != "Rejected: tx-1\n" {
+		t.Errorf("reject output = %q", out.String())
+	}
+
+	if err := approvePending(ts.URL, "unknown", &out); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("approvePending(unknown) error = %v, want not found", err)
+	}
+	if err := rejectPending(ts.URL, "unknown", &out); err == nil {
+		t.Error("rejectPending(unknown) should fail")
+	}
+}
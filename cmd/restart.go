@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"signet/config"
+	"time"
+)
+
+// defaultRestartTimeout は restart で停止中のノードの終了を待つ時間のデフォルト
+// ノードのシャットダウン（最大10秒）より長くする
+const defaultRestartTimeout = 15 * time.Second
+
+// restartPollInterval は restart でノードの終了を確認する間隔
+const restartPollInterval = 100 * time.Millisecond
+
+// RunRestart は `signet restart` コマンドを実行する
+// 起動中のノードに SIGTERM を送って終了を待ち、同じ設定で新しいノードをフォアグラウンドで起動する
+func RunRestart(args []string) {
+	fs := flag.NewFlagSet("restart", flag.ExitOnError)
+	timeout := fs.Duration("timeout", defaultRestartTimeout, "起動中のノードの終了を待つ時間")
+
+	if err := fs.Parse(args); err != nil {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+
+	pid, err := signalStop(cfg)
+	switch {
+	case errors.Is(err, errNodeNotRunning):
+		fmt.Printf("%v; starting a new node\n", err)
+	case err != nil:
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	default:
+		fmt.Printf("Sent SIGTERM to process %d\n", pid)
+		// 終了前に起動すると待ち受けポートを奪い合うため、終了を確認できなければ起動しない
+		if err := waitForExit(pid, cfg.PIDFilePath(), *timeout); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Process %d exited\n", pid)
+	}
+
+	runNode(cfg)
+}
+
+// waitForExit は pid のプロセスが終了して PID ファイル pidPath がなくなるまで待つ
+// プロセスの終了後に PID ファイルが残っていれば（異常終了など）削除する。timeout までに終了しなければエラーを返す
+func waitForExit(pid int, pidPath string, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for processAlive(pid) {
+		if time.Now().After(deadline) {
+			return fmt.Errorf("process %d did not exit within %v; not starting a new node", pid, timeout)
+		}
+		time.Sleep(restartPollInterval)
+	}
+
+	if err := os.Remove(pidPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove stale PID file: %w", err)
+	}
+	return nil
+}
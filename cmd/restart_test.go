@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"signet/config"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWaitForExit(t *testing.T) {
+	cfg := &config.Config{RootDir: t.TempDir()}
+
+	proc := exec.Command("sleep", "30")
+	if err := proc.Start(); err != nil {
+		t.Skipf("failed to start sleep: %v", err)
+	}
+	exited := make(chan struct{})
+	go func() {
+		proc.Wait() // 終了したプロセスを回収しないとゾンビとして残り、存在確認が成功し続ける
+		close(exited)
+	}()
+	t.Cleanup(func() {
+		proc.Process.Kill()
+		<-exited
+	})
+
+	pid := proc.Process.Pid
+	if err := os.WriteFile(cfg.PIDFilePath(), []byte(fmt.Sprintf("%d\n", pid)), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	// 起動中のプロセスが終了しなければ時間切れで失敗する
+	if err := waitForExit(pid, cfg.PIDFilePath(), 200*time.Millisecond); err == nil || !strings.Contains(err.Error(), "did not exit") {
+		t.Fatalf("waitForExit() error = %v, want timeout", err)
+	}
+
+	if got, err := signalStop(cfg); err != nil || got != pid {
+		t.Fatalf("signalStop() = %d, %v; want %d", got, err, pid)
+	}
+	if err := waitForExit(pid, cfg.PIDFilePath(), 5*time.Second); err != nil {
+		t.Fatalf("waitForExit() error = %v", err)
+	}
+	if _, err := os.Stat(cfg.PIDFilePath()); !os.IsNotExist(err) {
+		t.Error("PID file should be removed after the process exited")
+	}
+
+	if _, err := signalStop(cfg); !errors.Is(err, errNodeNotRunning) {
+		t.Errorf("signalStop() without PID file error = %v, want errNodeNotRunning", err)
+	}
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"crypto/ed25519"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"signet/config"
+	"signet/core"
+	"signet/crypto"
+	"time"
+)
+
+// sendPollInterval は承認待ちで /transaction/status/{id} を問い合わせる間隔
+var sendPollInterval = time.Second
+
+// sendRequest は `signet send` で提案する取引
+type sendRequest struct {
+	From   string
+	To     string
+	Amount int64
+	Title  string
+}
+
+// RunSend は `signet send` コマンドを実行する
+// 自ノードの秘密鍵で署名して起動中のローカルノードに提案し、--wait 指定時は To の承認を待つ
+func RunSend(args []string) {
+	fs := flag.NewFlagSet("send", flag.ExitOnError)
+	to := fs.String("to", "", "送り先のノード名")
+	amount := fs.Int64("amount", 0, "金額")
+	title := fs.String("title", "", "タイトル")
+	wait := fs.Duration("wait", 0, "承認を待つ時間 (例: 60s、0 = 待たない)")
+
+	if err := fs.Parse(args); err != nil {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	if *to == "" || *amount <= 0 || *title == "" {
+		fmt.Fprintln(os.Stderr, "Error: --to, --amount (positive), --title are required")
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load config: %v\n", err)
+		os.Exit(1)
+	}
+	if err := useLocalNodeClient(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load TLS configuration: %v\n", err)
+		os.Exit(1)
+	}
+	if err := checkNodeRunning(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	privKey, err := crypto.LoadPrivateKey(cfg.PrivKeyPath())
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: failed to load private key: %v\n", err)
+		os.Exit(1)
+	}
+
+	req := &sendRequest{From: cfg.NodeName, To: *to, Amount: *amount, Title: *title}
+	if err := sendTransaction(localNodeURL(cfg), privKey, req, *wait, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// sendTransaction は取引に署名して baseURL のノードに提案する
+// wait > 0 なら承認・拒否・期限切れになるまで状態を問い合わせ、承認されたらブロックのハッシュを出力する
+// wait 内に決着しなければ承認待ちの ID を含むエラーを返す
+func sendTransaction(baseURL string, privKey ed25519.PrivateKey, req *sendRequest, wait time.Duration, out io.Writer) error {
+	fromSignature, err := crypto.SignTransaction(privKey, &core.TransactionData{
+		From:   req.From,
+		To:     req.To,
+		Amount: req.Amount,
+		Title:  req.Title,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to sign transaction: %w", err)
+	}
+
+	var proposed struct {
+		ID string `json:"id"`
+	}
+	body := map[string]any{
+		"from":           req.From,
+		"to":             req.To,
+		"amount":         req.Amount,
+		"title":          req.Title,
+		"from_signature": fromSignature,
+	}
+	if err := postJSON(baseURL+"/transaction/propose", body, &proposed); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "Proposed: %s\n", proposed.ID)
+
+	if wait <= 0 {
+		return nil
+	}
+
+	deadline := time.Now().Add(wait)
+	for {
+		var status struct {
+			Status    string `json:"status"`
+			BlockHash string `json:"block_hash"`
+		}
+		if err := getJSON(baseURL+"/transaction/status/"+proposed.ID, &status); err != nil {
+			return err
+		}
+
+		switch status.Status {
+		case "approved":
+			fmt.Fprintf(out, "Approved: block %s\n", status.BlockHash)
+			return nil
+		case core.ArchiveReasonExpired, core.ArchiveReasonRejected:
+			return fmt.Errorf("transaction %s: %s", status.Status, proposed.ID)
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for approval (pending ID: %s)", proposed.ID)
+		}
+		time.Sleep(sendPollInterval)
+	}
+}
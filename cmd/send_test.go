@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"signet/config"
+	"signet/core"
+	"signet/crypto"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newSendStub は propose を受け付け、status を approvedAfter 回目の問い合わせで approved にするスタブサーバー
+// approvedAfter が 0 なら approved にならない
+func newSendStub(t *testing.T, approvedAfter int32) (*httptest.Server, *map[string]any) {
+	t.Helper()
+
+	proposed := &map[string]any{}
+	var polls atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /transaction/propose", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(proposed)
+		json.NewEncoder(w).Encode(map[string]string{"status": "proposed", "id": "tx-1"})
+	})
+	mux.HandleFunc("GET /transaction/status/{id}", func(w http.ResponseWriter, r *http.Request) {
+		status := map[string]string{"id": r.PathValue("id"), "status": "pending"}
+		if n := polls.Add(1); approvedAfter > 0 && n >= approvedAfter {
+			status["status"] = "approved"
+			status["block_hash"] = "block-hash"
+		}
+		json.NewEncoder(w).Encode(status)
+	})
+
+	ts := httptest.NewServer(mux)
+	t.Cleanup(ts.Close)
+	return ts, proposed
+}
+
+func TestSendTransaction(t *testing.T) {
+	orig := sendPollInterval
+	sendPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { sendPollInterval = orig })
+
+	pub, priv, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+	req := &sendRequest{From: "alice", To: "bob", Amount: 500, Title: "ランチ"}
+
+	t.Run("proposed without waiting", func(t *testing.T) {
+		ts, proposed := newSendStub(t, 1)
+		var out bytes.Buffer
+		if err := sendTransaction(ts.URL, priv, req, 0, &out); err != nil {
+			t.Fatalf("sendTransaction() error = %v", err)
+		}
+		if !strings.Contains(out.String(), "Proposed: tx-1") || strings.Contains(out.String(), "Approved") {
+			t.Errorf("output = %q, want only the proposed ID", out.String())
+		}
+
+		// ローカルで署名した From 署名が送られていること
+		sig, _ := (*proposed)["from_signature"].(string)
+		tx := &core.TransactionData{From: "alice", To: "bob", Amount: 500, Title: "ランチ"}
+		if !crypto.VerifyTransactionSignature(pub, tx, sig) {
+			t.Errorf("from_signature %q does not verify", sig)
+		}
+	})
+
+	t.Run("eventually approved", func(t *testing.T) {
+		ts, _ := newSendStub(t, 3)
+		var out bytes.Buffer
+		if err := sendTransaction(ts.URL, priv, req, 5*time.Second, &out); err != nil {
+			t.Fatalf("sendTransaction() error = %v", err)
+		}
+		if !strings.Contains(out.String(), "Approved: block block-hash") {
+			t.Errorf("output = %q, want approved block hash", out.String())
+		}
+	})
+
+	t.Run("timeout reports pending ID", func(t *testing.T) {
+		ts, _ := newSendStub(t, 0)
+		var out bytes.Buffer
+		err := sendTransaction(ts.URL, priv, req, 50*time.Millisecond, &out)
+		if err == nil || !strings.Contains(err.Error(), "tx-1") {
+			t.Errorf("sendTransaction() error = %v, want timeout with pending ID", err)
+		}
+	})
+}
+
+func TestCheckNodeRunning(t *testing.T) {
+	cfg := &config.Config{RootDir: t.TempDir()}
+
+	if err := checkNodeRunning(cfg); err == nil || !strings.Contains(err.Error(), "PID file not found") {
+		t.Errorf("checkNodeRunning() error = %v, want PID file not found", err)
+	}
+
+	tests := []struct {
+		name    string
+		pid     string
+		wantErr bool
+	}{
+		{name: "running", pid: fmt.Sprintf("%d\n", os.Getpid())},
+		{name: "stale", pid: "2147483646\n", wantErr: true},
+		{name: "invalid", pid: "abc\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(cfg.PIDFilePath(), []byte(tt.pid), 0644); err != nil {
+				t.Fatalf("WriteFile() error = %v", err)
+			}
+			if err := checkNodeRunning(cfg); (err != nil) != tt.wantErr {
+				t.Errorf("checkNodeRunning() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
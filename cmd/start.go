@@ -8,6 +8,7 @@ import (
 	"os/signal"
 	"signet/config"
 	"signet/node"
+	"signet/p2p"
 	"signet/server"
 	"syscall"
 	"time"
@@ -21,6 +22,24 @@ func RunStart(args []string) {
 		log.Fatalf("Error: failed to load config: %v", err)
 	}
 
+	runNode(cfg)
+}
+
+// runNode は cfg のノードを起動し、SIGINT / SIGTERM を受け取るまでフォアグラウンドで動かす（start / restart 共通）
+func runNode(cfg *config.Config) {
+	var err error
+
+	// TLS（TLSCertFile / TLSKeyFile 設定時）。ピアとの通信は NewNode がクライアントを作成する前に切り替える
+	var tlsConfigs *p2p.TLSConfigs
+	if cfg.TLSEnabled() {
+		tlsConfigs, err = p2p.LoadTLS(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSCAFile, cfg.TLSVersion())
+		if err != nil {
+			log.Fatalf("Error: failed to load TLS configuration: %v", err)
+		}
+		p2p.UseTLS(tlsConfigs.Client)
+	}
+	p2p.UseAPIKey(cfg.APIKey)
+
 	// Node 初期化
 	n, err := node.NewNode(cfg)
 	if err != nil {
@@ -29,17 +48,50 @@ func RunStart(args []string) {
 
 	// ピアからチェーン同期
 	log.Println("Syncing chain with peers...")
+	tipBeforeSync := n.Chain.GetLastHash()
 	if err := n.SyncChain(); err != nil {
 		log.Printf("Warning: chain sync failed: %v", err)
 	}
+	syncChanged := n.Chain.GetLastHash() != tipBeforeSync
 
-	// HTTPサーバー起動
-	host, port := config.ParseAddress(cfg.Address)
-	if cfg.Port != "" && cfg.Port != config.DefaultPort {
-		port = cfg.Port
+	// 承認待ちトランザクションの期限切れ処理
+	if ttl := cfg.PendingTTL(); ttl > 0 {
+		n.Go(func(ctx context.Context) { n.StartExpirySweeper(ctx, time.Minute) })
+		log.Printf("Pending transaction TTL: %v", ttl)
+	}
+
+	// ピアとの定期的なチェーン同期（Shutdown でバックグラウンド処理とともに停止する）
+	if interval := cfg.ChainSyncInterval(); interval > 0 {
+		n.Go(func(ctx context.Context) { n.StartSyncLoop(ctx, interval) })
+		log.Printf("Periodic chain sync interval: %v", interval)
 	}
-	addr := fmt.Sprintf("%s:%s", host, port)
+
+	// HTTPサーバー起動
+	addr := cfg.ListenAddress()
 	srv := server.NewServer(addr, n)
+	srv.SetOpenAPI(cfg.ServeOpenAPI)
+	srv.SetUnixSocket(cfg.ListenSocket, cfg.ListenSocketOnly)
+	srv.SetValidateChain(cfg.ValidateChainOnServe)
+	srv.SetConcurrencyLimit("GET /chain/verify", cfg.VerifyConcurrency)
+	srv.SetConcurrencyLimit("GET /chain", cfg.ChainConcurrency)
+	srv.SetMaxChainBlocks(cfg.MaxChainBlocks)
+	srv.SetMaxBodyBytes(cfg.MaxRequestBodyBytes)
+	if cfg.BlockRateLimit > 0 {
+		srv.SetBlockRateLimiter(server.NewTokenBucketLimiter(float64(cfg.BlockRateLimit), cfg.BlockRateBurst))
+	}
+	srv.SetAdminToken(cfg.AdminToken)
+	srv.SetAPIKey(cfg.APIKey)
+	if cfg.APIKey != "" {
+		log.Println("API key authentication enabled for mutating endpoints")
+	}
+	if tlsConfigs != nil {
+		srv.SetTLS(tlsConfigs.Server)
+		if cfg.TLSCAFile != "" {
+			log.Printf("Mutual TLS enabled (CA: %s, min version: TLS %s)", cfg.TLSCAFile, cfg.TLSMinVersion)
+		} else {
+			log.Printf("TLS enabled (min version: TLS %s)", cfg.TLSMinVersion)
+		}
+	}
 
 	// サーバーをgoroutineで起動
 	serverErr := make(chan error, 1)
@@ -54,8 +106,28 @@ func RunStart(args []string) {
 		log.Printf("Warning: failed to write PID file: %v", err)
 	}
 
+	// 停止処理は登録と逆順に実行される（サーバー停止 → PIDファイル削除）
+	n.OnShutdown(func(ctx context.Context) error {
+		if err := os.Remove(pidPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove PID file: %w", err)
+		}
+		return nil
+	})
+	n.OnShutdown(func(ctx context.Context) error {
+		if err := srv.Stop(ctx); err != nil {
+			return fmt.Errorf("server shutdown error: %w", err)
+		}
+		return nil
+	})
+
 	log.Printf("Signet node started (PID: %d)", pid)
-	log.Printf("Listening on %s", addr)
+	if !cfg.ListenSocketOnly || cfg.ListenSocket == "" {
+		log.Printf("Listening on %s", addr)
+	}
+	if cfg.ListenSocket != "" {
+		log.Printf("Listening on unix socket %s", cfg.ListenSocket)
+	}
+	log.Print(n.StartupReport(addr, syncChanged))
 
 	// シグナルハンドリング
 	sigCh := make(chan os.Signal, 1)
@@ -73,13 +145,8 @@ func RunStart(args []string) {
 		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 
-		if err := srv.Stop(ctx); err != nil {
-			log.Printf("Warning: server shutdown error: %v", err)
-		}
-
-		// PIDファイル削除
-		if err := os.Remove(pidPath); err != nil && !os.IsNotExist(err) {
-			log.Printf("Warning: failed to remove PID file: %v", err)
+		if err := n.Shutdown(ctx); err != nil {
+			log.Printf("Warning: shutdown error: %v", err)
 		}
 
 		log.Println("Signet node stopped")
@@ -1,12 +1,16 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"signet/config"
 	"syscall"
 )
 
+// errNodeNotRunning は PID ファイルがない・記録されたプロセスが既に終了していることを表す
+var errNodeNotRunning = errors.New("node is not running")
+
 // RunStop は `signet stop` コマンドを実行する
 func RunStop(args []string) {
 	// 設定読み込み
@@ -16,44 +20,49 @@ func RunStop(args []string) {
 		os.Exit(1)
 	}
 
-	// PIDファイルパス
-	pidPath := cfg.PIDFilePath()
+	pid, err := signalStop(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("Sent SIGTERM to process %d\n", pid)
+
+	// PIDファイル削除
+	if err := os.Remove(cfg.PIDFilePath()); err != nil && !os.IsNotExist(err) {
+		fmt.Fprintf(os.Stderr, "Warning: failed to remove PID file: %v\n", err)
+	}
+}
 
+// signalStop は PID ファイルに記録されたノードのプロセスに SIGTERM を送り、その PID を返す（stop / restart 共通）
+// PID ファイルがない・プロセスが既に終了している場合は errNodeNotRunning をラップしたエラーを返す
+func signalStop(cfg *config.Config) (int, error) {
 	// PIDファイル読み込み
-	pidData, err := os.ReadFile(pidPath)
+	pidData, err := os.ReadFile(cfg.PIDFilePath())
 	if err != nil {
 		if os.IsNotExist(err) {
-			fmt.Fprintln(os.Stderr, "Error: PID file not found. Is the node running?")
-			os.Exit(1)
+			return 0, fmt.Errorf("%w: PID file not found", errNodeNotRunning)
 		}
-		fmt.Fprintf(os.Stderr, "Error: failed to read PID file: %v\n", err)
-		os.Exit(1)
+		return 0, fmt.Errorf("failed to read PID file: %w", err)
 	}
 
 	var pid int
-	_, err = fmt.Sscanf(string(pidData), "%d", &pid)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: invalid PID format: %v\n", err)
-		os.Exit(1)
+	if _, err := fmt.Sscanf(string(pidData), "%d", &pid); err != nil {
+		return 0, fmt.Errorf("invalid PID format: %w", err)
 	}
 
 	// プロセスが存在するか確認
 	process, err := os.FindProcess(pid)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to find process: %v\n", err)
-		os.Exit(1)
+		return 0, fmt.Errorf("failed to find process: %w", err)
 	}
 
 	// SIGTERM送信
 	if err := process.Signal(syscall.SIGTERM); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: failed to send SIGTERM: %v\n", err)
-		os.Exit(1)
-	}
-
-	fmt.Printf("Sent SIGTERM to process %d\n", pid)
-
-	// PIDファイル削除
-	if err := os.Remove(pidPath); err != nil && !os.IsNotExist(err) {
-		fmt.Fprintf(os.Stderr, "Warning: failed to remove PID file: %v\n", err)
+		if errors.Is(err, os.ErrProcessDone) {
+			return 0, fmt.Errorf("%w: process %d has already exited", errNodeNotRunning, pid)
+		}
+		return 0, fmt.Errorf("failed to send SIGTERM: %w", err)
 	}
+	return pid, nil
 }
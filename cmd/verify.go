@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"signet/config"
+	"signet/core"
+	"signet/crypto"
+	"signet/storage"
+	"strings"
+)
+
+// RunVerify は `signet verify` コマンドを実行する
+// 起動中のノードを介さず、ローカルの block.jsonl の構造と取引の署名を検証する
+func RunVerify(args []string) {
+	fs := flag.NewFlagSet("verify", flag.ExitOnError)
+	file := fs.String("file", "", "ブロックファイルのパス (デフォルト: RootDir/block.jsonl)")
+
+	if err := fs.Parse(args); err != nil {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	path := *file
+	if path == "" {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to load config: %v\n", err)
+			os.Exit(1)
+		}
+		path = cfg.BlockFilePath()
+	}
+
+	if err := writeVerifyResult(os.Stdout, path); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// writeVerifyResult は path のチェーンを検証し、成功すればブロック数を w に書き出す
+// 失敗した場合は最初に不正だったブロックのインデックス（特定できる場合）を w に書き出してエラーを返す
+func writeVerifyResult(w io.Writer, path string) error {
+	count, index, err := verifyChainFile(path)
+	if err != nil {
+		if index >= 0 {
+			fmt.Fprintf(w, "FAILED: first invalid block index %d\n", index)
+		}
+		return err
+	}
+	fmt.Fprintf(w, "OK: %d blocks verified (%s)\n", count, path)
+	return nil
+}
+
+// verifyChainFile は path のブロックを読み込み、ValidateChain による構造検証と取引の署名検証を行う
+// 署名はチェーン自身の add_node ブロックに含まれる公開鍵（そのブロックより前に登録されたもの）で検証する
+// 登録済みのノード名の add_node ブロックはノードと同じく鍵の差し替えを認めず、署名があれば登録済みの鍵で検証する
+// 戻り値はブロック数・最初に不正だったブロックのインデックス（特定できない場合は -1）・エラー
+func verifyChainFile(path string) (int, int, error) {
+	blocks, err := storage.NewBlockStore(path).LoadAll()
+	if err != nil {
+		return 0, -1, fmt.Errorf("failed to load blocks: %w", err)
+	}
+	if len(blocks) == 0 {
+		return 0, -1, fmt.Errorf("no blocks in %s", path)
+	}
+
+	chain, err := core.NewChainFromBlocks(blocks)
+	if err != nil {
+		return 0, validationIndex(err), err
+	}
+	if err := chain.ValidateChain(); err != nil {
+		return 0, validationIndex(err), err
+	}
+
+	publicKeys := map[string]string{}
+	err = chain.ForEach(func(b *core.Block) error {
+		switch b.Payload.Type {
+		case "add_node":
+			data, err := b.GetAddNodeData()
+			if err != nil {
+				return core.NewValidationError(b.Header.Index, core.ValidationKindPayload, err)
+			}
+			if known, ok := publicKeys[data.NodeName]; ok {
+				if err := verifyNodeUpdate(b, data, known); err != nil {
+					return core.NewValidationError(b.Header.Index, core.ValidationKindSignature, err)
+				}
+				return nil
+			}
+			publicKeys[data.NodeName] = data.PublicKey
+		case "transaction":
+			if err := verifyTransactionBlock(b, publicKeys); err != nil {
+				return core.NewValidationError(b.Header.Index, core.ValidationKindSignature, err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, validationIndex(err), err
+	}
+
+	return len(blocks), -1, nil
+}
+
+// verifyNodeUpdate は登録済みのノード名の add_node ブロックを検証する
+// 公開鍵は登録済みの knownKey と同じでなければならず、From 署名があれば knownKey で検証する
+// （署名のない同じ鍵での再登録はアドレス・ニックネームの更新としてノードも受け付ける）
+func verifyNodeUpdate(b *core.Block, data *core.AddNodeData, knownKey string) error {
+	if !strings.EqualFold(data.PublicKey, knownKey) {
+		return fmt.Errorf("public key changed for registered node: %s", data.NodeName)
+	}
+	if b.Payload.FromSignature == "" {
+		return nil
+	}
+	pub, err := crypto.HexToPublicKey(knownKey)
+	if err != nil {
+		return fmt.Errorf("failed to decode node's public key: %w", err)
+	}
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal add_node data: %w", err)
+	}
+	if !crypto.Verify(pub, dataBytes, b.Payload.FromSignature) {
+		return fmt.Errorf("invalid node update signature: %s", data.NodeName)
+	}
+	return nil
+}
+
+// verifyTransactionBlock は取引ブロックの From/To 署名を publicKeys の公開鍵で検証する
+func verifyTransactionBlock(b *core.Block, publicKeys map[string]string) error {
+	tx, err := b.GetTransactionData()
+	if err != nil {
+		return err
+	}
+	signers := []struct {
+		role, node, signature string
+	}{
+		{"from", tx.From, b.Payload.FromSignature},
+		{"to", tx.To, b.Payload.ToSignature},
+	}
+	for _, signer := range signers {
+		pubHex, ok := publicKeys[signer.node]
+		if !ok {
+			return fmt.Errorf("unknown %s node: %s", signer.role, signer.node)
+		}
+		pub, err := crypto.HexToPublicKey(pubHex)
+		if err != nil {
+			return fmt.Errorf("failed to decode %s node's public key: %w", signer.role, err)
+		}
+		if !crypto.VerifyTransactionSignature(pub, tx, signer.signature) {
+			return fmt.Errorf("invalid %s signature", signer.role)
+		}
+	}
+	return nil
+}
+
+// validationIndex は err が *core.ValidationError ならそのインデックスを、それ以外は -1 を返す
+func validationIndex(err error) int {
+	var verr *core.ValidationError
+	if errors.As(err, &verr) {
+		return verr.Index
+	}
+	return -1
+}
@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"signet/core"
+	"signet/crypto"
+	"signet/storage"
+	"strings"
+	"testing"
+)
+
+// writeVerifyTestChain は alice と bob の登録と、両者が署名した取引1件からなるチェーンを path に書き出す
+// 書き出したブロックと、ノード名ごとの秘密鍵を返す
+func writeVerifyTestChain(t *testing.T, path string) ([]*core.Block, map[string]ed25519.PrivateKey) {
+	t.Helper()
+
+	blocks := []*core.Block{core.NewGenesisBlock()}
+	keys := map[string]ed25519.PrivateKey{}
+	for _, name := range []string{"alice", "bob"} {
+		pub, priv, err := crypto.GenerateKeyPair()
+		if err != nil {
+			t.Fatalf("GenerateKeyPair() error = %v", err)
+		}
+		keys[name] = priv
+		data, _ := json.Marshal(&core.AddNodeData{PublicKey: hex.EncodeToString(pub), NodeName: name, NickName: name, Address: "10.0.0.1"})
+		prev := blocks[len(blocks)-1]
+		blocks = append(blocks, core.NewBlock(prev.Header.Index+1, prev.Header.Hash, core.BlockPayload{Type: "add_node", Data: data}))
+	}
+
+	tx := &core.TransactionData{From: "alice", To: "bob", Amount: 100, Title: "lunch"}
+	fromSig, err := crypto.SignTransaction(keys["alice"], tx)
+	if err != nil {
+		t.Fatalf("SignTransaction() error = %v", err)
+	}
+	toSig, err := crypto.SignTransaction(keys["bob"], tx)
+	if err != nil {
+		t.Fatalf("SignTransaction() error = %v", err)
+	}
+	data, _ := json.Marshal(tx)
+	prev := blocks[len(blocks)-1]
+	blocks = append(blocks, core.NewBlock(prev.Header.Index+1, prev.Header.Hash,
+		core.BlockPayload{Type: "transaction", Data: data, FromSignature: fromSig, ToSignature: toSig}))
+
+	store := storage.NewBlockStore(path)
+	for _, b := range blocks {
+		if err := store.Append(b); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	return blocks, keys
+}
+
+func TestWriteVerifyResult(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "block.jsonl")
+	writeVerifyTestChain(t, path)
+
+	var out bytes.Buffer
+	if err := writeVerifyResult(&out, path); err != nil {
+		t.Fatalf("writeVerifyResult() error = %v", err)
+	}
+	if !strings.Contains(out.String(), "OK: 4 blocks verified") {
+		t.Errorf("output = %q, want OK with block count", out.String())
+	}
+}
+
+func TestVerifyChainFile_Tampered(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(blocks []*core.Block)
+		want   core.ValidationErrorKind
+	}{
+		{
+			name: "amount changed",
+			tamper: func(blocks []*core.Block) {
+				blocks[3].Payload.Data = json.RawMessage(`{"from":"alice","to":"bob","amount":999,"title":"lunch"}`)
+			},
+			want: core.ValidationKindHash,
+		},
+		{
+			// ハッシュを付け直しても署名が一致しなければ検出する
+			name: "amount changed and rehashed",
+			tamper: func(blocks []*core.Block) {
+				blocks[3].Payload.Data = json.RawMessage(`{"from":"alice","to":"bob","amount":999,"title":"lunch"}`)
+				blocks[3].Header.TxRoot = core.CalcTxRoot(blocks[3])
+				blocks[3].Header.Hash = core.CalcBlockHash(blocks[3])
+			},
+			want: core.ValidationKindSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			blocks, _ := writeVerifyTestChain(t, filepath.Join(dir, "good.jsonl"))
+			tt.tamper(blocks)
+
+			path := filepath.Join(dir, "block.jsonl")
+			store := storage.NewBlockStore(path)
+			for _, b := range blocks {
+				if err := store.Append(b); err != nil {
+					t.Fatalf("Append() error = %v", err)
+				}
+			}
+
+			_, index, err := verifyChainFile(path)
+			if err == nil {
+				t.Fatal("verifyChainFile() should fail for a tampered chain")
+			}
+			if index != 3 {
+				t.Errorf("index = %d, want 3", index)
+			}
+			var verr *core.ValidationError
+			if !errors.As(err, &verr) || verr.Kind != tt.want {
+				t.Errorf("error = %v, want %s validation error", err, tt.want)
+			}
+
+			var out bytes.Buffer
+			if err := writeVerifyResult(&out, path); err == nil {
+				t.Error("writeVerifyResult() should fail for a tampered chain")
+			}
+			if !strings.Contains(out.String(), "first invalid block index 3") {
+				t.Errorf("output = %q, want the failing index", out.String())
+			}
+		})
+	}
+
+	if _, _, err := verifyChainFile(filepath.Join(t.TempDir(), "missing.jsonl")); err == nil {
+		t.Error("verifyChainFile() should fail for a missing file")
+	}
+}
+
+func TestVerifyChainFile_NodeUpdate(t *testing.T) {
+	_, attacker, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+
+	// addNode は alice の add_node ブロック（公開鍵は pub、signer があればその鍵で署名）を作る
+	addNode := func(prev *core.Block, pub ed25519.PublicKey, signer ed25519.PrivateKey) *core.Block {
+		data := &core.AddNodeData{PublicKey: hex.EncodeToString(pub), NodeName: "alice", NickName: "mallory", Address: "10.0.0.9"}
+		dataBytes, _ := json.Marshal(data)
+		payload := core.BlockPayload{Type: "add_node", Data: dataBytes}
+		if signer != nil {
+			payload.FromSignature = crypto.Sign(signer, dataBytes)
+		}
+		return core.NewBlock(prev.Header.Index+1, prev.Header.Hash, payload)
+	}
+	alicePub := func(keys map[string]ed25519.PrivateKey) ed25519.PublicKey {
+		return keys["alice"].Public().(ed25519.PublicKey)
+	}
+	attackerPub := attacker.Public().(ed25519.PublicKey)
+
+	tests := []struct {
+		name    string
+		extend  func(blocks []*core.Block, keys map[string]ed25519.PrivateKey) []*core.Block
+		wantErr bool
+	}{
+		{
+			// alice を別の鍵で登録し直し、その鍵で署名した alice の取引を続ける
+			name: "re-registered with another key",
+			extend: func(blocks []*core.Block, keys map[string]ed25519.PrivateKey) []*core.Block {
+				blocks = append(blocks, addNode(blocks[len(blocks)-1], attackerPub, nil))
+				tx := &core.TransactionData{From: "alice", To: "bob", Amount: 1000, Title: "forged"}
+				fromSig, _ := crypto.SignTransaction(attacker, tx)
+				toSig, _ := crypto.SignTransaction(keys["bob"], tx)
+				data, _ := json.Marshal(tx)
+				prev := blocks[len(blocks)-1]
+				return append(blocks, core.NewBlock(prev.Header.Index+1, prev.Header.Hash,
+					core.BlockPayload{Type: "transaction", Data: data, FromSignature: fromSig, ToSignature: toSig}))
+			},
+			wantErr: true,
+		},
+		{
+			name: "re-registered with another key and signed by it",
+			extend: func(blocks []*core.Block, keys map[string]ed25519.PrivateKey) []*core.Block {
+				return append(blocks, addNode(blocks[len(blocks)-1], attackerPub, attacker))
+			},
+			wantErr: true,
+		},
+		{
+			name: "update signed by another key",
+			extend: func(blocks []*core.Block, keys map[string]ed25519.PrivateKey) []*core.Block {
+				return append(blocks, addNode(blocks[len(blocks)-1], alicePub(keys), keys["bob"]))
+			},
+			wantErr: true,
+		},
+		{
+			name: "update signed by the registered key",
+			extend: func(blocks []*core.Block, keys map[string]ed25519.PrivateKey) []*core.Block {
+				return append(blocks, addNode(blocks[len(blocks)-1], alicePub(keys), keys["alice"]))
+			},
+		},
+		{
+			// 署名のない同じ鍵での再登録はアドレス・ニックネームの更新
+			name: "unsigned re-registration with the same key",
+			extend: func(blocks []*core.Block, keys map[string]ed25519.PrivateKey) []*core.Block {
+				return append(blocks, addNode(blocks[len(blocks)-1], alicePub(keys), nil))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			blocks, keys := writeVerifyTestChain(t, filepath.Join(dir, "good.jsonl"))
+			blocks = tt.extend(blocks, keys)
+
+			path := filepath.Join(dir, "block.jsonl")
+			store := storage.NewBlockStore(path)
+			for _, b := range blocks {
+				if err := store.Append(b); err != nil {
+					t.Fatalf("Append() error = %v", err)
+				}
+			}
+
+			_, index, err := verifyChainFile(path)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("verifyChainFile() error = %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("verifyChainFile() should fail for a re-registration with another key")
+			}
+			if index != 4 {
+				t.Errorf("index = %d, want 4", index)
+			}
+			var verr *core.ValidationError
+			if !errors.As(err, &verr) || verr.Kind != core.ValidationKindSignature {
+				t.Errorf("error = %v, want signature validation error", err)
+			}
+		})
+	}
+}
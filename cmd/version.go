@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"signet/version"
+)
+
+// RunVersion は `signet version` コマンドを実行する
+func RunVersion(args []string) {
+	writeVersion(os.Stdout, version.Get())
+}
+
+// writeVersion はバージョン情報を w に書き出す
+func writeVersion(w io.Writer, info version.Info) {
+	fmt.Fprintf(w, "signet %s\n", info.Version)
+	fmt.Fprintf(w, "Go version: %s\n", info.GoVersion)
+	fmt.Fprintf(w, "Build date: %s\n", info.BuildDate)
+}
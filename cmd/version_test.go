@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"bytes"
+	"signet/version"
+	"strings"
+	"testing"
+)
+
+func TestWriteVersion(t *testing.T) {
+	var out bytes.Buffer
+	writeVersion(&out, version.Info{Version: "v1.2.3", GoVersion: "go1.25.0", BuildDate: "2024-01-02T03:04:05Z"})
+
+	for _, want := range []string{"signet v1.2.3", "Go version: go1.25.0", "Build date: 2024-01-02T03:04:05Z"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output does not contain %q: %q", want, out.String())
+		}
+	}
+}
@@ -1,15 +1,41 @@
 package config
 
 import (
+	"crypto/tls"
+	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 )
 
 const (
-	defaultRootDir  = "/etc/signet"
-	DefaultPort     = "8080"
-	defaultConfPath = "/etc/signet/signet.conf"
+	defaultRootDir = "/etc/signet"
+	DefaultPort    = "8080"
+	configFileName = "signet.conf"
+
+	defaultRebroadcastBlocks        = 10
+	defaultVerifyConcurrency        = 2
+	defaultChainConcurrency         = 8
+	defaultMinAmount                = 1
+	defaultSyncPolicy               = "sync_always"
+	defaultSyncIntervalMs           = 1000
+	defaultDiskFullPolicy           = "read_only"
+	defaultExpiredLogSize           = 1000
+	defaultTrustedPeerPolicy        = "authoritative"
+	defaultMaxClockSkewSeconds      = 300
+	defaultChainSyncIntervalSeconds = 30
+	defaultMaxBlockSizeBytes        = 1 << 20
+	defaultMaxChainBlocks           = 10000
+	defaultMaxTitleLength           = 256
+	defaultDeadPeerSkipSeconds      = 60
+	defaultPeerTimeoutSeconds       = 10
+	defaultMaxRequestBodyBytes      = 1 << 20
+	defaultTLSMinVersion            = "1.2"
+	defaultBlockRateLimit           = 50
+	defaultBlockRateBurst           = 100
 )
 
 // Config はアプリケーションの設定を表す
@@ -19,18 +45,161 @@ type Config struct {
 	NickName string
 	NodeName string
 	Port     string
+
+	// PendingTTLSeconds は承認待ちトランザクションの有効期限（秒）。0 以下なら期限切れ処理を行わない
+	PendingTTLSeconds int
+
+	// MaxClockSkewSeconds は受信ブロックの作成時刻がローカル時刻より先行してよい上限（秒）。0 以下なら検査しない
+	MaxClockSkewSeconds int
+
+	// ChainSyncIntervalSeconds は起動後にピアとチェーンを定期的に同期する間隔（秒）。0 以下なら起動時のみ同期する
+	ChainSyncIntervalSeconds int
+
+	// ExpiredLogSize は期限切れ・拒否された承認待ちトランザクションの記録を保持する件数（古いものから削除）。0 以下なら記録しない
+	ExpiredLogSize int
+
+	// RebroadcastBlocks は到達不能だったピアが復帰した際に再送する直近ブロック数。0 以下なら再送しない
+	RebroadcastBlocks int
+
+	// DeadPeerSkipSeconds は到達不能なピアへのブロードキャストを見送る期間（秒）。期間が過ぎたら再試行する。0 以下なら見送らない
+	DeadPeerSkipSeconds int
+
+	// PeerTimeoutSeconds はピアへの1回の HTTP リクエスト（応答本体の読み込みを含む）のタイムアウト（秒）。0 以下ならタイムアウトしない
+	PeerTimeoutSeconds int
+
+	// AutoApproveSelf が true なら To が自ノードの提案を ProposeTransaction の中で即座に承認してブロックを確定する
+	AutoApproveSelf bool
+
+	// ServeOpenAPI が true なら GET /openapi.json で API 仕様を配信する
+	ServeOpenAPI bool
+
+	// AdminToken が空でなければ管理用エンドポイント（POST /admin/rollback）を有効にし、Authorization: Bearer で要求する
+	AdminToken string
+
+	// APIKey が空でなければ更新系エンドポイント（/admin/ 以下を除く POST）に Authorization: Bearer で要求する共有鍵とする
+	// ピアへの送信と CLI からのリクエストにも付与するため、全ノードで同じ値を設定すること
+	APIKey string
+
+	// ListenSocket が空でなければ、そのパスの Unix ドメインソケットでも待ち受ける
+	// ListenSocketOnly が true なら TCP では待ち受けない
+	ListenSocket     string
+	ListenSocketOnly bool
+
+	// TLSCertFile / TLSKeyFile が両方設定されていれば、HTTP API（TCP）とピアとの通信を TLS で行う
+	// TLSCAFile を設定すると相互 TLS とし、その CA で署名された証明書を持つノードだけが更新系エンドポイントを呼べる
+	// TLSMinVersion は許可する TLS の最小バージョン（1.2 / 1.3）
+	TLSCertFile   string
+	TLSKeyFile    string
+	TLSCAFile     string
+	TLSMinVersion string
+
+	// ValidateChainOnServe が true なら GET /chain の応答前にチェーンの構造を検証し、不正なら 500 を返す
+	ValidateChainOnServe bool
+
+	// VerifyConcurrency / ChainConcurrency は GET /chain/verify・GET /chain の同時実行数の上限。0 以下なら無制限
+	VerifyConcurrency int
+	ChainConcurrency  int
+
+	// BlockRateLimit は POST /block を送信元アドレスごとに毎秒受け付ける件数、BlockRateBurst は連続して受け付ける件数の上限。超えたリクエストには 429 を返す
+	// BlockRateLimit が 0 以下なら制限しない
+	BlockRateLimit int
+	BlockRateBurst int
+
+	// MaxChainBlocks は GET /chain が JSON 配列で一度に返すブロック数の上限。超える範囲は 413 でページ分割を求める（NDJSON の応答は対象外）。0 以下なら無制限
+	MaxChainBlocks int
+
+	// MaxRequestBodyBytes は HTTP API の POST リクエストのボディの上限（バイト）。超えるリクエストは 413 で拒否する。0 以下なら無制限
+	MaxRequestBodyBytes int64
+
+	// MinAmount / MaxAmount は取引金額の許容範囲。MaxAmount が 0 以下なら上限なし
+	MinAmount int64
+	MaxAmount int64
+
+	// MaxTitleLength は取引のタイトルの文字数の上限。提案とブロック受信時に検証する。0 以下なら上限なし（制御文字は常に拒否する）
+	MaxTitleLength int
+
+	// AllowNegativeBalance が false なら、From の現在の残高（チェーン上の取引の合計）を超える金額の提案を拒否する
+	// 貸し借りの記録は残高 0 から始まり借りた側が負になるため、デフォルトは true（検査しない）
+	AllowNegativeBalance bool
+
+	// SyncPolicy はブロック追記の永続化方針（sync_always: 追記ごとに fsync / sync_interval: SyncIntervalMs ごとにまとめて fsync）
+	SyncPolicy     string
+	SyncIntervalMs int
+
+	// MaxBlockSizeBytes は block.jsonl の1行（1ブロック）のサイズの上限（バイト）。超える行があれば起動時の読み込みを失敗させ、超えるブロックは保存しない
+	MaxBlockSizeBytes int
+
+	// BlockIndex が true なら block.jsonl の各ブロックの位置を block.jsonl.idx に記録し、起動時は全ブロックを読まずにチェーンを構築する
+	// ブロック本体は必要になった時点で読み込む。インデックスがない・block.jsonl と一致しない場合は作り直す
+	BlockIndex bool
+
+	// TrustedPeers は同期時に優先して問い合わせるピア（ノード名）。空なら全ピアを同等に扱う
+	// TrustedPeerPolicy は信頼ピアの扱い
+	// authoritative: 信頼ピアのいずれかからチェーンを取得できれば、それ以外のピアのチェーンは（長くても）採用しない
+	// prefer: 全ピアのチェーンを比較し、同じ長さなら信頼ピアのチェーンを優先する
+	TrustedPeers      []string
+	TrustedPeerPolicy string
+
+	// DiskFullPolicy はディスク容量不足（ENOSPC）時の方針
+	// read_only: 以降の書き込みを受け付けない（再起動で解除） / retry: 書き込み要求ごとに再試行する
+	DiskFullPolicy string
 }
 
-// LoadConfig はデフォルトパスから設定を読み込む
+// 設定ファイル・ルートディレクトリの場所を指定する環境変数
+const (
+	EnvConfigPath = "SIGNET_CONFIG"
+	EnvRootDir    = "SIGNET_ROOT"
+)
+
+// LoadConfig は ConfigPath の設定ファイルから設定を読み込む
 func LoadConfig() (*Config, error) {
-	return LoadConfigFrom(defaultConfPath)
+	return LoadConfigFrom(ConfigPath())
+}
+
+// ConfigPath は設定ファイルのパスを返す
+// SIGNET_CONFIG があればそのパス、なければ DefaultRootDir の下の signet.conf
+func ConfigPath() string {
+	if path := os.Getenv(EnvConfigPath); path != "" {
+		return path
+	}
+	return filepath.Join(DefaultRootDir(), configFileName)
+}
+
+// DefaultRootDir は設定ファイルで RootDir を指定しない場合のルートディレクトリを返す
+// SIGNET_ROOT があればそのディレクトリ、なければ /etc/signet
+func DefaultRootDir() string {
+	if dir := os.Getenv(EnvRootDir); dir != "" {
+		return dir
+	}
+	return defaultRootDir
 }
 
 // LoadConfigFrom は指定パスから設定を読み込む
 func LoadConfigFrom(path string) (*Config, error) {
 	cfg := &Config{
-		RootDir: defaultRootDir,
-		Port:    DefaultPort,
+		RootDir:                  DefaultRootDir(),
+		Port:                     DefaultPort,
+		RebroadcastBlocks:        defaultRebroadcastBlocks,
+		VerifyConcurrency:        defaultVerifyConcurrency,
+		ChainConcurrency:         defaultChainConcurrency,
+		MinAmount:                defaultMinAmount,
+		MaxTitleLength:           defaultMaxTitleLength,
+		AllowNegativeBalance:     true,
+		SyncPolicy:               defaultSyncPolicy,
+		SyncIntervalMs:           defaultSyncIntervalMs,
+		DiskFullPolicy:           defaultDiskFullPolicy,
+		ExpiredLogSize:           defaultExpiredLogSize,
+		TrustedPeerPolicy:        defaultTrustedPeerPolicy,
+		MaxClockSkewSeconds:      defaultMaxClockSkewSeconds,
+		ChainSyncIntervalSeconds: defaultChainSyncIntervalSeconds,
+		MaxBlockSizeBytes:        defaultMaxBlockSizeBytes,
+		MaxChainBlocks:           defaultMaxChainBlocks,
+		MaxRequestBodyBytes:      defaultMaxRequestBodyBytes,
+		DeadPeerSkipSeconds:      defaultDeadPeerSkipSeconds,
+		PeerTimeoutSeconds:       defaultPeerTimeoutSeconds,
+		TLSMinVersion:            defaultTLSMinVersion,
+		BlockRateLimit:           defaultBlockRateLimit,
+		BlockRateBurst:           defaultBlockRateBurst,
 	}
 
 	// 設定ファイルが存在しない場合はデフォルト値を返す
@@ -58,10 +227,295 @@ func LoadConfigFrom(path string) (*Config, error) {
 	if v, ok := values["Port"]; ok {
 		cfg.Port = v
 	}
+	if v, ok := values["PendingTTLSeconds"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PendingTTLSeconds: %w", err)
+		}
+		cfg.PendingTTLSeconds = n
+	}
+	if v, ok := values["RebroadcastBlocks"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RebroadcastBlocks: %w", err)
+		}
+		cfg.RebroadcastBlocks = n
+	}
+	if v, ok := values["DeadPeerSkipSeconds"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DeadPeerSkipSeconds: %w", err)
+		}
+		cfg.DeadPeerSkipSeconds = n
+	}
+	if v, ok := values["PeerTimeoutSeconds"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PeerTimeoutSeconds: %w", err)
+		}
+		cfg.PeerTimeoutSeconds = n
+	}
+	if v, ok := values["VerifyConcurrency"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid VerifyConcurrency: %w", err)
+		}
+		cfg.VerifyConcurrency = n
+	}
+	if v, ok := values["ChainConcurrency"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ChainConcurrency: %w", err)
+		}
+		cfg.ChainConcurrency = n
+	}
+	if v, ok := values["BlockRateLimit"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid BlockRateLimit: %w", err)
+		}
+		cfg.BlockRateLimit = n
+	}
+	if v, ok := values["BlockRateBurst"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid BlockRateBurst: %w", err)
+		}
+		cfg.BlockRateBurst = n
+	}
+	if v, ok := values["MaxChainBlocks"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MaxChainBlocks: %w", err)
+		}
+		cfg.MaxChainBlocks = n
+	}
+	if v, ok := values["MaxRequestBodyBytes"]; ok {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MaxRequestBodyBytes: %w", err)
+		}
+		cfg.MaxRequestBodyBytes = n
+	}
+	if v, ok := values["MinAmount"]; ok {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MinAmount: %w", err)
+		}
+		cfg.MinAmount = n
+	}
+	if v, ok := values["MaxAmount"]; ok {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MaxAmount: %w", err)
+		}
+		cfg.MaxAmount = n
+	}
+	if v, ok := values["MaxTitleLength"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MaxTitleLength: %w", err)
+		}
+		cfg.MaxTitleLength = n
+	}
+	if v, ok := values["AllowNegativeBalance"]; ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AllowNegativeBalance: %w", err)
+		}
+		cfg.AllowNegativeBalance = b
+	}
+	if v, ok := values["SyncPolicy"]; ok {
+		cfg.SyncPolicy = v
+	}
+	if v, ok := values["BlockIndex"]; ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid BlockIndex: %w", err)
+		}
+		cfg.BlockIndex = b
+	}
+	if v, ok := values["SyncIntervalMs"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SyncIntervalMs: %w", err)
+		}
+		cfg.SyncIntervalMs = n
+	}
+	if v, ok := values["MaxClockSkewSeconds"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MaxClockSkewSeconds: %w", err)
+		}
+		cfg.MaxClockSkewSeconds = n
+	}
+	if v, ok := values["MaxBlockSizeBytes"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MaxBlockSizeBytes: %w", err)
+		}
+		cfg.MaxBlockSizeBytes = n
+	}
+	if v, ok := values["ChainSyncIntervalSeconds"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ChainSyncIntervalSeconds: %w", err)
+		}
+		cfg.ChainSyncIntervalSeconds = n
+	}
+	if v, ok := values["ExpiredLogSize"]; ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ExpiredLogSize: %w", err)
+		}
+		cfg.ExpiredLogSize = n
+	}
+	if v, ok := values["DiskFullPolicy"]; ok {
+		if v != "read_only" && v != "retry" {
+			return nil, fmt.Errorf("invalid DiskFullPolicy: %s", v)
+		}
+		cfg.DiskFullPolicy = v
+	}
+	if v, ok := values["TrustedPeers"]; ok {
+		cfg.TrustedPeers = nil
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				cfg.TrustedPeers = append(cfg.TrustedPeers, name)
+			}
+		}
+	}
+	if v, ok := values["TrustedPeerPolicy"]; ok {
+		if v != "authoritative" && v != "prefer" {
+			return nil, fmt.Errorf("invalid TrustedPeerPolicy: %s", v)
+		}
+		cfg.TrustedPeerPolicy = v
+	}
+	if v, ok := values["TLSCertFile"]; ok {
+		cfg.TLSCertFile = v
+	}
+	if v, ok := values["TLSKeyFile"]; ok {
+		cfg.TLSKeyFile = v
+	}
+	if v, ok := values["TLSCAFile"]; ok {
+		cfg.TLSCAFile = v
+	}
+	if v, ok := values["TLSMinVersion"]; ok {
+		if _, ok := tlsVersions[v]; !ok {
+			return nil, fmt.Errorf("invalid TLSMinVersion: %s", v)
+		}
+		cfg.TLSMinVersion = v
+	}
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") {
+		return nil, fmt.Errorf("TLSCertFile and TLSKeyFile must be set together")
+	}
+	if cfg.TLSCAFile != "" && cfg.TLSCertFile == "" {
+		return nil, fmt.Errorf("TLSCAFile requires TLSCertFile and TLSKeyFile")
+	}
+	if v, ok := values["AdminToken"]; ok {
+		cfg.AdminToken = v
+	}
+	if v, ok := values["APIKey"]; ok {
+		cfg.APIKey = v
+	}
+	if v, ok := values["AutoApproveSelf"]; ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AutoApproveSelf: %w", err)
+		}
+		cfg.AutoApproveSelf = b
+	}
+	if v, ok := values["ServeOpenAPI"]; ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ServeOpenAPI: %w", err)
+		}
+		cfg.ServeOpenAPI = b
+	}
+	if v, ok := values["ListenSocket"]; ok {
+		cfg.ListenSocket = v
+	}
+	if v, ok := values["ListenSocketOnly"]; ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ListenSocketOnly: %w", err)
+		}
+		cfg.ListenSocketOnly = b
+	}
+	if v, ok := values["ValidateChainOnServe"]; ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ValidateChainOnServe: %w", err)
+		}
+		cfg.ValidateChainOnServe = b
+	}
 
 	return cfg, nil
 }
 
+// tlsVersions は TLSMinVersion に指定できる値
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLSEnabled は HTTP API とピアとの通信を TLS で行うかを返す
+func (c *Config) TLSEnabled() bool {
+	return c.TLSCertFile != "" && c.TLSKeyFile != ""
+}
+
+// TLSVersion は TLSMinVersion を crypto/tls の定数で返す（未設定なら TLS 1.2）
+func (c *Config) TLSVersion() uint16 {
+	if v, ok := tlsVersions[c.TLSMinVersion]; ok {
+		return v
+	}
+	return tls.VersionTLS12
+}
+
+// PendingTTL は承認待ちトランザクションの有効期限を返す（0 なら無期限）
+func (c *Config) PendingTTL() time.Duration {
+	if c.PendingTTLSeconds <= 0 {
+		return 0
+	}
+	return time.Duration(c.PendingTTLSeconds) * time.Second
+}
+
+// MaxClockSkew は受信ブロックの作成時刻がローカル時刻より先行してよい上限を返す（0 なら検査しない）
+func (c *Config) MaxClockSkew() time.Duration {
+	if c.MaxClockSkewSeconds <= 0 {
+		return 0
+	}
+	return time.Duration(c.MaxClockSkewSeconds) * time.Second
+}
+
+// DeadPeerSkip は到達不能なピアへのブロードキャストを見送る期間を返す（0 なら見送らない）
+func (c *Config) DeadPeerSkip() time.Duration {
+	if c.DeadPeerSkipSeconds <= 0 {
+		return 0
+	}
+	return time.Duration(c.DeadPeerSkipSeconds) * time.Second
+}
+
+// PeerTimeout はピアへの HTTP リクエストのタイムアウトを返す（0 ならタイムアウトしない）
+func (c *Config) PeerTimeout() time.Duration {
+	if c.PeerTimeoutSeconds <= 0 {
+		return 0
+	}
+	return time.Duration(c.PeerTimeoutSeconds) * time.Second
+}
+
+// ChainSyncInterval は起動後にピアとチェーンを定期的に同期する間隔を返す（0 なら定期同期しない）
+func (c *Config) ChainSyncInterval() time.Duration {
+	if c.ChainSyncIntervalSeconds <= 0 {
+		return 0
+	}
+	return time.Duration(c.ChainSyncIntervalSeconds) * time.Second
+}
+
+// SyncInterval は sync_interval モードでの fsync 間隔を返す
+func (c *Config) SyncInterval() time.Duration {
+	return time.Duration(c.SyncIntervalMs) * time.Millisecond
+}
+
 // PrivKeyPath は秘密鍵ファイルのパスを返す
 func (c *Config) PrivKeyPath() string {
 	return filepath.Join(c.RootDir, "ed25519.priv")
@@ -77,6 +531,16 @@ func (c *Config) PendingFilePath() string {
 	return filepath.Join(c.RootDir, "pending_transaction.json")
 }
 
+// ExpiredFilePath は期限切れ・拒否された承認待ちトランザクションの記録ファイルのパスを返す
+func (c *Config) ExpiredFilePath() string {
+	return filepath.Join(c.RootDir, "expired_transaction.json")
+}
+
+// SeenBlocksFilePath は転送済みブロックのハッシュを保存するファイルのパスを返す
+func (c *Config) SeenBlocksFilePath() string {
+	return filepath.Join(c.RootDir, "seen_blocks.json")
+}
+
 // NodesDir はノード設定ディレクトリのパスを返す
 func (c *Config) NodesDir() string {
 	return filepath.Join(c.RootDir, "nodes")
@@ -92,19 +556,31 @@ func (c *Config) NodeFilePath(nodeName string) string {
 	return filepath.Join(c.RootDir, "nodes", nodeName)
 }
 
+// ListenAddress は HTTP サーバーが待ち受けるアドレス（host:port）を返す
+// Address のポートより Port（デフォルト以外を指定した場合）を優先する
+func (c *Config) ListenAddress() string {
+	host, port := ParseAddress(c.Address)
+	if c.Port != "" && c.Port != DefaultPort {
+		port = c.Port
+	}
+	return net.JoinHostPort(host, port)
+}
+
 // ParseAddress はアドレス文字列からホストとポートをパースする
-// 形式: "host:port" または "host" (デフォルトポート使用)
+// 形式: "host:port" / "[ipv6]:port" または "host" / "ipv6" / "[ipv6]" (デフォルトポート使用)
+// IPv6 のホストは角括弧を外して返す
 func ParseAddress(addr string) (host string, port string) {
-	parts := strings.Split(addr, ":")
-	if len(parts) == 2 {
-		return parts[0], parts[1]
+	if h, p, err := net.SplitHostPort(addr); err == nil {
+		if p == "" {
+			p = DefaultPort
+		}
+		return h, p
 	}
-	return addr, DefaultPort
+	return strings.TrimSuffix(strings.TrimPrefix(addr, "["), "]"), DefaultPort
 }
 
 // NormalizeAddress はアドレスにポートが含まれていなければデフォルトポートを付与する
-// "192.168.1.1" → "192.168.1.1:8080", "192.168.1.1:9090" → "192.168.1.1:9090"
+// "192.168.1.1" → "192.168.1.1:8080", "192.168.1.1:9090" → "192.168.1.1:9090", "fe80::1" → "[fe80::1]:8080"
 func NormalizeAddress(addr string) string {
-	host, port := ParseAddress(addr)
-	return host + ":" + port
+	return net.JoinHostPort(ParseAddress(addr))
 }
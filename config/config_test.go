@@ -1,8 +1,11 @@
 package config
 
 import (
+	"crypto/tls"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestLoadConfigFrom(t *testing.T) {
@@ -18,6 +21,75 @@ func TestLoadConfigFrom(t *testing.T) {
 		if cfg.Port != DefaultPort {
 			t.Errorf("Port = %v, want %v", cfg.Port, DefaultPort)
 		}
+		if cfg.RebroadcastBlocks != defaultRebroadcastBlocks {
+			t.Errorf("RebroadcastBlocks = %v, want %v", cfg.RebroadcastBlocks, defaultRebroadcastBlocks)
+		}
+		if cfg.VerifyConcurrency != defaultVerifyConcurrency {
+			t.Errorf("VerifyConcurrency = %v, want %v", cfg.VerifyConcurrency, defaultVerifyConcurrency)
+		}
+		if cfg.ChainConcurrency != defaultChainConcurrency {
+			t.Errorf("ChainConcurrency = %v, want %v", cfg.ChainConcurrency, defaultChainConcurrency)
+		}
+		if cfg.MaxBlockSizeBytes != defaultMaxBlockSizeBytes {
+			t.Errorf("MaxBlockSizeBytes = %v, want %v", cfg.MaxBlockSizeBytes, defaultMaxBlockSizeBytes)
+		}
+		if cfg.AdminToken != "" {
+			t.Errorf("AdminToken = %q, want empty", cfg.AdminToken)
+		}
+		if cfg.APIKey != "" {
+			t.Errorf("APIKey = %q, want empty", cfg.APIKey)
+		}
+		if cfg.AutoApproveSelf {
+			t.Error("AutoApproveSelf = true, want false")
+		}
+		if cfg.BlockIndex {
+			t.Error("BlockIndex = true, want false")
+		}
+		if cfg.BlockRateLimit != defaultBlockRateLimit || cfg.BlockRateBurst != defaultBlockRateBurst {
+			t.Errorf("BlockRateLimit/Burst = %v/%v, want %v/%v", cfg.BlockRateLimit, cfg.BlockRateBurst, defaultBlockRateLimit, defaultBlockRateBurst)
+		}
+		if cfg.MaxChainBlocks != defaultMaxChainBlocks {
+			t.Errorf("MaxChainBlocks = %v, want %v", cfg.MaxChainBlocks, defaultMaxChainBlocks)
+		}
+		if cfg.MaxRequestBodyBytes != defaultMaxRequestBodyBytes {
+			t.Errorf("MaxRequestBodyBytes = %v, want %v", cfg.MaxRequestBodyBytes, defaultMaxRequestBodyBytes)
+		}
+		if cfg.SyncPolicy != defaultSyncPolicy || cfg.SyncIntervalMs != defaultSyncIntervalMs {
+			t.Errorf("SyncPolicy/SyncIntervalMs = %v/%v, want %v/%v", cfg.SyncPolicy, cfg.SyncIntervalMs, defaultSyncPolicy, defaultSyncIntervalMs)
+		}
+		if cfg.MinAmount != defaultMinAmount || cfg.MaxAmount != 0 {
+			t.Errorf("MinAmount/MaxAmount = %v/%v, want %v/0", cfg.MinAmount, cfg.MaxAmount, defaultMinAmount)
+		}
+		if cfg.MaxTitleLength != defaultMaxTitleLength {
+			t.Errorf("MaxTitleLength = %v, want %v", cfg.MaxTitleLength, defaultMaxTitleLength)
+		}
+		if !cfg.AllowNegativeBalance {
+			t.Error("AllowNegativeBalance = false, want true")
+		}
+		if cfg.DiskFullPolicy != defaultDiskFullPolicy {
+			t.Errorf("DiskFullPolicy = %v, want %v", cfg.DiskFullPolicy, defaultDiskFullPolicy)
+		}
+		if cfg.MaxClockSkew() != defaultMaxClockSkewSeconds*time.Second {
+			t.Errorf("MaxClockSkew() = %v, want %v", cfg.MaxClockSkew(), defaultMaxClockSkewSeconds*time.Second)
+		}
+		if cfg.DeadPeerSkip() != defaultDeadPeerSkipSeconds*time.Second {
+			t.Errorf("DeadPeerSkip() = %v, want %v", cfg.DeadPeerSkip(), defaultDeadPeerSkipSeconds*time.Second)
+		}
+		if cfg.PeerTimeout() != defaultPeerTimeoutSeconds*time.Second {
+			t.Errorf("PeerTimeout() = %v, want %v", cfg.PeerTimeout(), defaultPeerTimeoutSeconds*time.Second)
+		}
+		if cfg.ChainSyncInterval() != defaultChainSyncIntervalSeconds*time.Second {
+			t.Errorf("ChainSyncInterval() = %v, want %v", cfg.ChainSyncInterval(), defaultChainSyncIntervalSeconds*time.Second)
+		}
+		if cfg.ExpiredLogSize != defaultExpiredLogSize {
+			t.Errorf("ExpiredLogSize = %v, want %v", cfg.ExpiredLogSize, defaultExpiredLogSize)
+		}
+		if len(cfg.TrustedPeers) != 0 || cfg.TrustedPeerPolicy != defaultTrustedPeerPolicy {
+			t.Errorf("TrustedPeers/TrustedPeerPolicy = %v/%v, want []/%v", cfg.TrustedPeers, cfg.TrustedPeerPolicy, defaultTrustedPeerPolicy)
+		}
+		if cfg.TLSEnabled() || cfg.TLSVersion() != tls.VersionTLS12 {
+			t.Errorf("TLSEnabled()/TLSVersion() = %v/%x, want false/TLS 1.2", cfg.TLSEnabled(), cfg.TLSVersion())
+		}
 	})
 
 	t.Run("existing file with values", func(t *testing.T) {
@@ -29,6 +101,40 @@ Address = 10.0.0.1
 NickName = TestUser
 NodeName = testnode
 Port = 9090
+RebroadcastBlocks = 3
+DeadPeerSkipSeconds = 0
+PeerTimeoutSeconds = 120
+ServeOpenAPI = true
+AutoApproveSelf = true
+BlockIndex = true
+AdminToken = s3cret
+APIKey = shared-key
+VerifyConcurrency = 1
+ChainConcurrency = 0
+MaxChainBlocks = 200
+MaxRequestBodyBytes = 0
+BlockRateLimit = 5
+BlockRateBurst = 10
+MinAmount = 100
+MaxAmount = 50000
+MaxTitleLength = 64
+AllowNegativeBalance = false
+SyncPolicy = sync_interval
+SyncIntervalMs = 200
+MaxBlockSizeBytes = 4096
+DiskFullPolicy = retry
+ExpiredLogSize = 50
+MaxClockSkewSeconds = 0
+ChainSyncIntervalSeconds = 5
+TrustedPeers = "alice, bob,"
+TrustedPeerPolicy = prefer
+ValidateChainOnServe = true
+ListenSocket = /run/signet.sock
+ListenSocketOnly = true
+TLSCertFile = /etc/signet/node.crt
+TLSKeyFile = /etc/signet/node.key
+TLSCAFile = /etc/signet/ca.crt
+TLSMinVersion = 1.3
 `
 		if err := writeFile(confPath, content); err != nil {
 			t.Fatalf("failed to write config: %v", err)
@@ -54,6 +160,84 @@ Port = 9090
 		if cfg.Port != "9090" {
 			t.Errorf("Port = %v, want 9090", cfg.Port)
 		}
+		if cfg.RebroadcastBlocks != 3 {
+			t.Errorf("RebroadcastBlocks = %v, want 3", cfg.RebroadcastBlocks)
+		}
+		if !cfg.ServeOpenAPI {
+			t.Error("ServeOpenAPI = false, want true")
+		}
+		if !cfg.AutoApproveSelf {
+			t.Error("AutoApproveSelf = false, want true")
+		}
+		if !cfg.BlockIndex {
+			t.Error("BlockIndex = false, want true")
+		}
+		if cfg.MaxBlockSizeBytes != 4096 {
+			t.Errorf("MaxBlockSizeBytes = %v, want 4096", cfg.MaxBlockSizeBytes)
+		}
+		if cfg.AdminToken != "s3cret" {
+			t.Errorf("AdminToken = %q, want s3cret", cfg.AdminToken)
+		}
+		if cfg.APIKey != "shared-key" {
+			t.Errorf("APIKey = %q, want shared-key", cfg.APIKey)
+		}
+		if cfg.VerifyConcurrency != 1 {
+			t.Errorf("VerifyConcurrency = %v, want 1", cfg.VerifyConcurrency)
+		}
+		if cfg.ChainConcurrency != 0 {
+			t.Errorf("ChainConcurrency = %v, want 0", cfg.ChainConcurrency)
+		}
+		if cfg.BlockRateLimit != 5 || cfg.BlockRateBurst != 10 {
+			t.Errorf("BlockRateLimit/Burst = %v/%v, want 5/10", cfg.BlockRateLimit, cfg.BlockRateBurst)
+		}
+		if cfg.MaxChainBlocks != 200 {
+			t.Errorf("MaxChainBlocks = %v, want 200", cfg.MaxChainBlocks)
+		}
+		if cfg.MaxRequestBodyBytes != 0 {
+			t.Errorf("MaxRequestBodyBytes = %v, want 0", cfg.MaxRequestBodyBytes)
+		}
+		if cfg.SyncPolicy != "sync_interval" || cfg.SyncInterval() != 200*time.Millisecond {
+			t.Errorf("SyncPolicy/SyncInterval = %v/%v, want sync_interval/200ms", cfg.SyncPolicy, cfg.SyncInterval())
+		}
+		if cfg.MinAmount != 100 || cfg.MaxAmount != 50000 {
+			t.Errorf("MinAmount/MaxAmount = %v/%v, want 100/50000", cfg.MinAmount, cfg.MaxAmount)
+		}
+		if cfg.MaxTitleLength != 64 {
+			t.Errorf("MaxTitleLength = %v, want 64", cfg.MaxTitleLength)
+		}
+		if cfg.AllowNegativeBalance {
+			t.Error("AllowNegativeBalance = true, want false")
+		}
+		if cfg.DiskFullPolicy != "retry" {
+			t.Errorf("DiskFullPolicy = %v, want retry", cfg.DiskFullPolicy)
+		}
+		if cfg.MaxClockSkew() != 0 {
+			t.Errorf("MaxClockSkew() = %v, want 0", cfg.MaxClockSkew())
+		}
+		if cfg.DeadPeerSkip() != 0 {
+			t.Errorf("DeadPeerSkip() = %v, want 0", cfg.DeadPeerSkip())
+		}
+		if cfg.PeerTimeout() != 2*time.Minute {
+			t.Errorf("PeerTimeout() = %v, want 2m", cfg.PeerTimeout())
+		}
+		if cfg.ChainSyncInterval() != 5*time.Second {
+			t.Errorf("ChainSyncInterval() = %v, want 5s", cfg.ChainSyncInterval())
+		}
+		if cfg.ExpiredLogSize != 50 {
+			t.Errorf("ExpiredLogSize = %v, want 50", cfg.ExpiredLogSize)
+		}
+		if strings.Join(cfg.TrustedPeers, ",") != "alice,bob" || cfg.TrustedPeerPolicy != "prefer" {
+			t.Errorf("TrustedPeers/TrustedPeerPolicy = %v/%v, want [alice bob]/prefer", cfg.TrustedPeers, cfg.TrustedPeerPolicy)
+		}
+		if !cfg.ValidateChainOnServe {
+			t.Error("ValidateChainOnServe = false, want true")
+		}
+		if cfg.ListenSocket != "/run/signet.sock" || !cfg.ListenSocketOnly {
+			t.Errorf("ListenSocket/ListenSocketOnly = %v/%v, want /run/signet.sock/true", cfg.ListenSocket, cfg.ListenSocketOnly)
+		}
+		if !cfg.TLSEnabled() || cfg.TLSCAFile != "/etc/signet/ca.crt" || cfg.TLSVersion() != tls.VersionTLS13 {
+			t.Errorf("TLSEnabled()/TLSCAFile/TLSVersion() = %v/%v/%x, want true//etc/signet/ca.crt/TLS 1.3", cfg.TLSEnabled(), cfg.TLSCAFile, cfg.TLSVersion())
+		}
 	})
 
 	t.Run("partial config uses defaults for missing values", func(t *testing.T) {
@@ -87,6 +271,41 @@ NickName = TestUser
 	})
 }
 
+func TestLoadConfigFrom_PendingTTL(t *testing.T) {
+	t.Run("valid value", func(t *testing.T) {
+		confPath := filepath.Join(t.TempDir(), "signet.conf")
+		if err := writeFile(confPath, "PendingTTLSeconds = 3600\n"); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+
+		cfg, err := LoadConfigFrom(confPath)
+		if err != nil {
+			t.Fatalf("LoadConfigFrom() error = %v", err)
+		}
+		if cfg.PendingTTL() != time.Hour {
+			t.Errorf("PendingTTL() = %v, want %v", cfg.PendingTTL(), time.Hour)
+		}
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		confPath := filepath.Join(t.TempDir(), "signet.conf")
+		if err := writeFile(confPath, "PendingTTLSeconds = abc\n"); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+
+		if _, err := LoadConfigFrom(confPath); err == nil {
+			t.Error("LoadConfigFrom() should return error for invalid PendingTTLSeconds")
+		}
+	})
+
+	t.Run("default disables expiry", func(t *testing.T) {
+		cfg := &Config{}
+		if cfg.PendingTTL() != 0 {
+			t.Errorf("PendingTTL() = %v, want 0", cfg.PendingTTL())
+		}
+	})
+}
+
 func TestConfigPathHelpers(t *testing.T) {
 	cfg := &Config{
 		RootDir: "/test/signet",
@@ -112,6 +331,11 @@ func TestConfigPathHelpers(t *testing.T) {
 			method:   cfg.PendingFilePath,
 			expected: "/test/signet/pending_transaction.json",
 		},
+		{
+			name:     "ExpiredFilePath",
+			method:   cfg.ExpiredFilePath,
+			expected: "/test/signet/expired_transaction.json",
+		},
 		{
 			name:     "NodesDir",
 			method:   cfg.NodesDir,
@@ -133,6 +357,77 @@ func TestConfigPathHelpers(t *testing.T) {
 	}
 }
 
+func TestConfigPath_Env(t *testing.T) {
+	t.Setenv(EnvConfigPath, "")
+	t.Setenv(EnvRootDir, "")
+	if got := ConfigPath(); got != "/etc/signet/signet.conf" {
+		t.Errorf("ConfigPath() = %v, want /etc/signet/signet.conf", got)
+	}
+	if got := DefaultRootDir(); got != defaultRootDir {
+		t.Errorf("DefaultRootDir() = %v, want %v", got, defaultRootDir)
+	}
+
+	// SIGNET_ROOT の下の signet.conf を読み、RootDir の指定がなければ SIGNET_ROOT を使う
+	root := t.TempDir()
+	t.Setenv(EnvRootDir, root)
+	if got, want := ConfigPath(), filepath.Join(root, "signet.conf"); got != want {
+		t.Errorf("ConfigPath() = %v, want %v", got, want)
+	}
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if cfg.RootDir != root {
+		t.Errorf("RootDir = %v, want %v", cfg.RootDir, root)
+	}
+
+	// SIGNET_CONFIG が優先される
+	t.Setenv(EnvConfigPath, "/custom/signet.conf")
+	if got := ConfigPath(); got != "/custom/signet.conf" {
+		t.Errorf("ConfigPath() = %v, want /custom/signet.conf", got)
+	}
+}
+
+func TestListenAddress(t *testing.T) {
+	tests := []struct {
+		address, port, want string
+	}{
+		{"192.168.1.1", DefaultPort, "192.168.1.1:8080"},
+		{"192.168.1.1:9090", DefaultPort, "192.168.1.1:9090"},
+		{"192.168.1.1:9090", "7000", "192.168.1.1:7000"},
+		{"fe80::1", "", "[fe80::1]:8080"},
+	}
+	for _, tt := range tests {
+		cfg := &Config{Address: tt.address, Port: tt.port}
+		if got := cfg.ListenAddress(); got != tt.want {
+			t.Errorf("ListenAddress(%q, %q) = %v, want %v", tt.address, tt.port, got, tt.want)
+		}
+	}
+}
+
+func TestLoadConfigFrom_InvalidTLS(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "unsupported min version", content: "TLSMinVersion = 1.1\n"},
+		{name: "cert without key", content: "TLSCertFile = /etc/signet/node.crt\n"},
+		{name: "CA without cert", content: "TLSCAFile = /etc/signet/ca.crt\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			confPath := filepath.Join(t.TempDir(), "signet.conf")
+			if err := writeFile(confPath, tt.content); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+			if _, err := LoadConfigFrom(confPath); err == nil {
+				t.Error("LoadConfigFrom() should return error")
+			}
+		})
+	}
+}
+
 func TestNodeFilePath(t *testing.T) {
 	cfg := &Config{
 		RootDir: "/test/signet",
@@ -175,6 +470,30 @@ func TestParseAddress(t *testing.T) {
 			wantHost: "localhost",
 			wantPort: DefaultPort,
 		},
+		{
+			name:     "IPv6 with port",
+			addr:     "[::1]:9090",
+			wantHost: "::1",
+			wantPort: "9090",
+		},
+		{
+			name:     "IPv6 without port",
+			addr:     "fe80::1",
+			wantHost: "fe80::1",
+			wantPort: DefaultPort,
+		},
+		{
+			name:     "bracketed IPv6 without port",
+			addr:     "[2001:db8::1]",
+			wantHost: "2001:db8::1",
+			wantPort: DefaultPort,
+		},
+		{
+			name:     "hostname with empty port",
+			addr:     "node.example:",
+			wantHost: "node.example",
+			wantPort: DefaultPort,
+		},
 	}
 
 	for _, tt := range tests {
@@ -189,3 +508,26 @@ func TestParseAddress(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeAddress(t *testing.T) {
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{"10.0.0.1", "10.0.0.1:" + DefaultPort},
+		{"10.0.0.1:9090", "10.0.0.1:9090"},
+		{"localhost", "localhost:" + DefaultPort},
+		{"node.example:9090", "node.example:9090"},
+		{"::1", "[::1]:" + DefaultPort},
+		{"[::1]", "[::1]:" + DefaultPort},
+		{"[fe80::1]:9090", "[fe80::1]:9090"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			if got := NormalizeAddress(tt.addr); got != tt.want {
+				t.Errorf("NormalizeAddress(%q) = %v, want %v", tt.addr, got, tt.want)
+			}
+		})
+	}
+}
@@ -4,16 +4,41 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 )
 
+// ValidateBlock が返すエラーの種類（errors.Is で判別する）
+var (
+	ErrInvalidBlockHash   = errors.New("invalid block hash")
+	ErrInvalidPayloadType = errors.New("invalid payload type")
+	ErrInvalidTxRoot      = errors.New("invalid tx root")
+	ErrUnknownVersion     = errors.New("unknown block version")
+)
+
+// ブロックの形式のバージョン（BlockHeader.Version）
+const (
+	// BlockVersion1 は当初の形式。ハッシュに Version と TxRoot を含まない（Version 未設定の 0 も同じ扱い）
+	BlockVersion1 = 1
+	// BlockVersion2 はヘッダーにペイロードの Merkle ルート（TxRoot）を持ち、Version と TxRoot をハッシュに含める
+	BlockVersion2 = 2
+	// BlockVersion3 はペイロードのデータを CanonicalJSON で正規化してから TxRoot とハッシュを計算する
+	// TxRoot は葉と内部ノードを区別する TaggedMerkleRoot で計算する
+	BlockVersion3 = 3
+	// CurrentBlockVersion は NewBlock が生成するブロックのバージョン
+	CurrentBlockVersion = BlockVersion3
+)
+
 // BlockHeader はブロックのヘッダーを表す
+// Version を持たない既存のブロックはバージョン1として扱う。TxRoot はバージョン2以降のブロックのみが持つ
 type BlockHeader struct {
+	Version   int       `json:"version,omitempty"`
 	Index     int       `json:"index"`
 	CreatedAt time.Time `json:"created_at"`
 	PrevHash  string    `json:"prev_hash"`
+	TxRoot    string    `json:"tx_root,omitempty"`
 	Hash      string    `json:"hash"`
 }
 
@@ -32,34 +57,93 @@ type Block struct {
 }
 
 // CalcBlockHash はブロックのハッシュを計算する
-// Index + CreatedAt(RFC3339) + PrevHash + Payload(JSON) を連結してSHA-256
+// バージョン1: Index + CreatedAt(RFC3339) + PrevHash + Payload(JSON) を連結してSHA-256
+// バージョン2以降: Version + Index + CreatedAt(RFC3339) + PrevHash + TxRoot + Payload(JSON) を連結してSHA-256
+// バージョン3以降は Payload のデータを CanonicalJSON で正規化してから直列化する
 func CalcBlockHash(b *Block) string {
-	payloadJSON, err := json.Marshal(b.Payload)
+	payload := b.Payload
+	if b.Header.Version >= BlockVersion3 {
+		data, err := canonicalPayloadData(payload.Data)
+		if err != nil {
+			return ""
+		}
+		payload.Data = data
+	}
+	payloadJSON, err := json.Marshal(payload)
 	if err != nil {
 		return ""
 	}
 
-	data := fmt.Sprintf("%d%s%s%s", b.Header.Index, b.Header.CreatedAt.Format(time.RFC3339), b.Header.PrevHash, string(payloadJSON))
+	var data string
+	if b.Header.Version >= BlockVersion2 {
+		data = fmt.Sprintf("%d%d%s%s%s%s", b.Header.Version, b.Header.Index, b.Header.CreatedAt.Format(time.RFC3339), b.Header.PrevHash, b.Header.TxRoot, string(payloadJSON))
+	} else {
+		data = fmt.Sprintf("%d%s%s%s", b.Header.Index, b.Header.CreatedAt.Format(time.RFC3339), b.Header.PrevHash, string(payloadJSON))
+	}
 	h := sha256.New()
 	h.Write([]byte(data))
 	return hex.EncodeToString(h.Sum(nil))
 }
 
+// CalcTxRoot はブロックのペイロードデータの Merkle ルートを計算する
+// 現在のブロックはペイロードを1つだけ持つため、バージョン2はデータの SHA-256、
+// バージョン3以降は正規化したデータを葉とする TaggedMerkleRoot（SHA-256(0x00 || データ)）になる
+func CalcTxRoot(b *Block) string {
+	if b.Header.Version < BlockVersion3 {
+		return MerkleRoot([][]byte{b.Payload.Data})
+	}
+	canonical, err := canonicalPayloadData(b.Payload.Data)
+	if err != nil {
+		return ""
+	}
+	return TaggedMerkleRoot([][]byte{canonical})
+}
+
+// canonicalPayloadData はペイロードのデータを CanonicalJSON で正規化する（データがなければそのまま返す）
+func canonicalPayloadData(data json.RawMessage) (json.RawMessage, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+	return CanonicalJSON(data)
+}
+
+// VerifyTxRoot はヘッダーの TxRoot がペイロードと一致するかを返す
+// バージョン1のブロックは TxRoot を持たないため、TxRoot が空なら true を返す
+func (b *Block) VerifyTxRoot() bool {
+	if b.Header.Version < BlockVersion2 {
+		return b.Header.TxRoot == ""
+	}
+	return b.Header.TxRoot == CalcTxRoot(b)
+}
+
 // NewBlock は新しいブロックを生成する
 func NewBlock(index int, prevHash string, payload BlockPayload) *Block {
 	now := time.Now().UTC()
 	block := &Block{
 		Header: BlockHeader{
+			Version:   CurrentBlockVersion,
 			Index:     index,
 			CreatedAt: now,
 			PrevHash:  prevHash,
 		},
 		Payload: payload,
 	}
+	block.Header.TxRoot = CalcTxRoot(block)
 	block.Header.Hash = CalcBlockHash(block)
 	return block
 }
 
+// ClampCreatedAt は作成時刻が直前のブロック prev より前であれば prev の作成時刻に揃え、ハッシュを計算し直す
+// 時計が進んだピアのブロック（MaxClockSkew の範囲内で未来のもの）の後に自ノードでブロックを生成しても、
+// 自ノードの時計が追いつくまで AddBlock に拒否されないようにする
+func (b *Block) ClampCreatedAt(prev *Block) {
+	if !b.Header.CreatedAt.Before(prev.Header.CreatedAt) {
+		return
+	}
+	b.Header.CreatedAt = prev.Header.CreatedAt
+	b.Header.Hash = CalcBlockHash(b)
+}
+
 // NewGenesisBlock はジェネシスブロックを生成する
 // 全ノード共通の固定データで生成し、チェーンのルートを統一する
 func NewGenesisBlock() *Block {
@@ -76,6 +160,7 @@ func NewGenesisBlock() *Block {
 
 	block := &Block{
 		Header: BlockHeader{
+			Version:   BlockVersion1, // バージョン1はハッシュに Version を含まないため、既存チェーンのジェネシスと同じハッシュになる
 			Index:     0,
 			CreatedAt: time.Time{}.UTC(), // ゼロ値
 			PrevHash:  "0",
@@ -87,10 +172,20 @@ func NewGenesisBlock() *Block {
 }
 
 // ValidateBlock はブロックのハッシュが正しいか検証する
+// このノードが知らないバージョン（CurrentBlockVersion より新しい、または負）のブロックはハッシュの計算方法が分からないため拒否する
 func ValidateBlock(b *Block) error {
+	if b.Header.Version < 0 || b.Header.Version > CurrentBlockVersion {
+		return fmt.Errorf("%w: %d (supported up to %d)", ErrUnknownVersion, b.Header.Version, CurrentBlockVersion)
+	}
+
 	calculatedHash := CalcBlockHash(b)
 	if calculatedHash != b.Header.Hash {
-		return fmt.Errorf("invalid block hash: expected %s, got %s", calculatedHash, b.Header.Hash)
+		return fmt.Errorf("%w: expected %s, got %s", ErrInvalidBlockHash, calculatedHash, b.Header.Hash)
+	}
+
+	// ハッシュは TxRoot を含むだけなので、TxRoot がペイロードと一致するかは別に確かめる
+	if !b.VerifyTxRoot() {
+		return fmt.Errorf("%w: expected %s, got %s", ErrInvalidTxRoot, CalcTxRoot(b), b.Header.TxRoot)
 	}
 
 	// PayloadのTypeが有効かチェック
@@ -99,7 +194,7 @@ func ValidateBlock(b *Block) error {
 		"add_node":    true,
 	}
 	if !validTypes[b.Payload.Type] {
-		return fmt.Errorf("invalid payload type: %s", b.Payload.Type)
+		return fmt.Errorf("%w: %s", ErrInvalidPayloadType, b.Payload.Type)
 	}
 
 	return nil
@@ -140,6 +235,19 @@ func (b *Block) GetAddNodeData() (*AddNodeData, error) {
 	return &data, nil
 }
 
+// DecodePayload はペイロードを Type に応じた構造体にデコードして返す
+// transaction なら *TransactionData、add_node なら *AddNodeData を返す
+func (b *Block) DecodePayload() (any, error) {
+	switch BlockType(b.Payload.Type) {
+	case BlockTypeTransaction:
+		return b.GetTransactionData()
+	case BlockTypeAddNode:
+		return b.GetAddNodeData()
+	default:
+		return nil, fmt.Errorf("unknown block type: %s", b.Payload.Type)
+	}
+}
+
 // SetTransactionData はペイロードにTransactionDataを設定する
 func SetTransactionData(tx *TransactionData) (json.RawMessage, error) {
 	data, err := json.Marshal(tx)
@@ -193,14 +301,18 @@ func CreateBlockWithAddNode(index int, prevHash string, addNode *AddNodeData) (*
 }
 
 // MakeSigningPayload は署名対象のペイロードバイト列を作成する
-// Type + Data をJSON直列化して連結
+// Type + Data（CanonicalJSON で正規化したもの）をJSON直列化して連結
 func MakeSigningPayload(payload *BlockPayload) ([]byte, error) {
+	data, err := canonicalPayloadData(payload.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to canonicalize payload data: %w", err)
+	}
 	typeData := struct {
 		Type string          `json:"type"`
 		Data json.RawMessage `json:"data"`
 	}{
 		Type: payload.Type,
-		Data: payload.Data,
+		Data: data,
 	}
 
 	jsonData, err := json.Marshal(typeData)
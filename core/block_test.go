@@ -1,7 +1,10 @@
 package core
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 )
@@ -168,6 +171,195 @@ func TestCalcBlockHash_Deterministic(t *testing.T) {
 	}
 }
 
+func TestNewBlock_TxRoot(t *testing.T) {
+	data, _ := SetTransactionData(&TransactionData{From: "node1", To: "node2", Amount: 1000, Title: "test"})
+	block := NewBlock(1, "prev", BlockPayload{Type: "transaction", Data: data, FromSignature: "sig1", ToSignature: "sig2"})
+
+	if block.Header.Version != CurrentBlockVersion {
+		t.Errorf("Version = %d, want %d", block.Header.Version, CurrentBlockVersion)
+	}
+	canonical, err := CanonicalJSON(data)
+	if err != nil {
+		t.Fatalf("CanonicalJSON() error = %v", err)
+	}
+	sum := sha256.Sum256(append([]byte{0x00}, canonical...))
+	if block.Header.TxRoot != hex.EncodeToString(sum[:]) {
+		t.Errorf("TxRoot = %s, want SHA-256 of the leaf-tagged canonical payload data", block.Header.TxRoot)
+	}
+	// バージョン2の TxRoot は従来どおり受け取ったデータの SHA-256
+	v2 := *block
+	v2.Header.Version = BlockVersion2
+	if raw := sha256.Sum256(data); CalcTxRoot(&v2) != hex.EncodeToString(raw[:]) {
+		t.Errorf("version 2 TxRoot = %s, want SHA-256 of the payload data", CalcTxRoot(&v2))
+	}
+	if !block.VerifyTxRoot() {
+		t.Error("VerifyTxRoot() = false for a fresh block")
+	}
+	if err := ValidateBlock(block); err != nil {
+		t.Fatalf("ValidateBlock() error = %v", err)
+	}
+
+	// ペイロードを改ざんしてハッシュを再計算しても TxRoot と一致しない
+	tampered := *block
+	tampered.Payload.Data, _ = SetTransactionData(&TransactionData{From: "node1", To: "node2", Amount: 9999, Title: "test"})
+	tampered.Header.Hash = CalcBlockHash(&tampered)
+	if tampered.VerifyTxRoot() {
+		t.Error("VerifyTxRoot() = true after tampering with the payload")
+	}
+	if err := ValidateBlock(&tampered); !errors.Is(err, ErrInvalidTxRoot) {
+		t.Errorf("ValidateBlock(tampered payload) error = %v, want ErrInvalidTxRoot", err)
+	}
+
+	// TxRoot を書き換えるとハッシュが一致しない
+	rerooted := *block
+	rerooted.Header.TxRoot = CalcTxRoot(&tampered)
+	if err := ValidateBlock(&rerooted); !errors.Is(err, ErrInvalidBlockHash) {
+		t.Errorf("ValidateBlock(tampered tx root) error = %v, want ErrInvalidBlockHash", err)
+	}
+}
+
+func TestCalcBlockHash_Version1(t *testing.T) {
+	data, _ := SetTransactionData(&TransactionData{From: "node1", To: "node2", Amount: 1000, Title: "test"})
+	block := &Block{
+		Header: BlockHeader{
+			Index:     1,
+			CreatedAt: time.Date(2026, 2, 18, 12, 0, 0, 0, time.UTC),
+			PrevHash:  "prev",
+		},
+		Payload: BlockPayload{Type: "transaction", Data: data},
+	}
+
+	// バージョン1（Version 未設定）のブロックは TxRoot を含まない従来のハッシュで検証する
+	payloadJSON, _ := json.Marshal(block.Payload)
+	want := CalcSHA256("1" + "2026-02-18T12:00:00Z" + "prev" + string(payloadJSON))
+	if got := CalcBlockHash(block); got != want {
+		t.Errorf("CalcBlockHash() = %s, want legacy hash %s", got, want)
+	}
+	block.Header.Hash = want
+	if !block.VerifyTxRoot() {
+		t.Error("VerifyTxRoot() = false for a version 1 block without TxRoot")
+	}
+	if err := ValidateBlock(block); err != nil {
+		t.Errorf("ValidateBlock() error = %v", err)
+	}
+
+	// 同じ内容でもバージョン2ではハッシュが変わる
+	v2 := *block
+	v2.Header.Version = BlockVersion2
+	v2.Header.TxRoot = CalcTxRoot(&v2)
+	if CalcBlockHash(&v2) == want {
+		t.Error("version 2 hash should differ from the version 1 hash")
+	}
+}
+
+func TestCalcBlockHash_CanonicalData(t *testing.T) {
+	compact := NewBlock(1, "prev", BlockPayload{
+		Type: "transaction",
+		Data: json.RawMessage(`{"from":"node1","to":"node2","amount":1000,"title":"test"}`),
+	})
+	// 同じ内容をキー順と空白を変えて送られた場合
+	reordered := *compact
+	reordered.Payload.Data = json.RawMessage("{\n  \"title\": \"test\",\n  \"amount\": 1000,\n  \"to\": \"node2\",\n  \"from\": \"node1\"\n}")
+
+	if got := CalcTxRoot(&reordered); got != compact.Header.TxRoot {
+		t.Errorf("CalcTxRoot(reordered) = %s, want %s", got, compact.Header.TxRoot)
+	}
+	if got := CalcBlockHash(&reordered); got != compact.Header.Hash {
+		t.Errorf("CalcBlockHash(reordered) = %s, want %s", got, compact.Header.Hash)
+	}
+	if err := ValidateBlock(&reordered); err != nil {
+		t.Errorf("ValidateBlock(reordered) error = %v", err)
+	}
+
+	// 内容が変われば正規化後も一致しない
+	changed := *compact
+	changed.Payload.Data = json.RawMessage(`{"from":"node1","to":"node2","amount":1001,"title":"test"}`)
+	if err := ValidateBlock(&changed); err == nil {
+		t.Error("ValidateBlock() should fail for changed payload data")
+	}
+
+	// バージョン2以前は従来どおり受け取ったバイト列でハッシュする
+	v2 := *compact
+	v2.Header.Version = BlockVersion2
+	v2.Header.TxRoot = CalcTxRoot(&v2)
+	v2.Header.Hash = CalcBlockHash(&v2)
+	v2Reordered := v2
+	v2Reordered.Payload.Data = reordered.Payload.Data
+	if CalcTxRoot(&v2Reordered) == v2.Header.TxRoot {
+		t.Error("version 2 TxRoot should depend on the raw payload bytes")
+	}
+	if err := ValidateBlock(&v2); err != nil {
+		t.Errorf("ValidateBlock(v2) error = %v", err)
+	}
+
+	// 正規化できないデータのブロックは検証に通らない
+	invalid := *compact
+	invalid.Payload.Data = json.RawMessage(`{"from":`)
+	if err := ValidateBlock(&invalid); err == nil {
+		t.Error("ValidateBlock() should fail for invalid payload data")
+	}
+}
+
+func TestClampCreatedAt(t *testing.T) {
+	prev := NewBlock(1, "prev", BlockPayload{Type: "add_node", Data: json.RawMessage(`{"node_name":"a"}`)})
+	prev.Header.CreatedAt = time.Now().Add(time.Minute).UTC().Truncate(time.Second)
+	prev.Header.Hash = CalcBlockHash(prev)
+
+	// 直前のブロックより前の時刻は揃えてハッシュを付け直す
+	block := NewBlock(2, prev.Header.Hash, BlockPayload{Type: "add_node", Data: json.RawMessage(`{"node_name":"b"}`)})
+	block.ClampCreatedAt(prev)
+	if !block.Header.CreatedAt.Equal(prev.Header.CreatedAt) {
+		t.Errorf("CreatedAt = %v, want %v", block.Header.CreatedAt, prev.Header.CreatedAt)
+	}
+	if err := ValidateBlock(block); err != nil {
+		t.Errorf("ValidateBlock() error = %v", err)
+	}
+	if err := ValidateTimestamp(prev, block); err != nil {
+		t.Errorf("ValidateTimestamp() error = %v", err)
+	}
+
+	// 直前のブロック以降であれば変えない
+	later := NewBlock(2, prev.Header.Hash, BlockPayload{Type: "add_node", Data: json.RawMessage(`{"node_name":"b"}`)})
+	later.Header.CreatedAt = prev.Header.CreatedAt.Add(time.Second)
+	later.Header.Hash = CalcBlockHash(later)
+	hash := later.Header.Hash
+	later.ClampCreatedAt(prev)
+	if later.Header.Hash != hash {
+		t.Error("ClampCreatedAt should not change a block created after prev")
+	}
+}
+
+func TestValidateBlock_Version(t *testing.T) {
+	genesis := NewGenesisBlock()
+	if genesis.Header.Version != BlockVersion1 {
+		t.Errorf("genesis Version = %d, want %d", genesis.Header.Version, BlockVersion1)
+	}
+	if err := ValidateBlock(genesis); err != nil {
+		t.Errorf("ValidateBlock(genesis) error = %v", err)
+	}
+	// Version を持たない既存チェーンのジェネシスとハッシュが変わらない
+	legacy := *genesis
+	legacy.Header.Version = 0
+	if got := CalcBlockHash(&legacy); got != genesis.Header.Hash {
+		t.Errorf("legacy genesis hash = %s, want %s", got, genesis.Header.Hash)
+	}
+
+	data, _ := SetTransactionData(&TransactionData{From: "node1", To: "node2", Amount: 1000, Title: "test"})
+	future := NewBlock(1, genesis.Header.Hash, BlockPayload{Type: "transaction", Data: data})
+	future.Header.Version = CurrentBlockVersion + 1
+	future.Header.Hash = CalcBlockHash(future)
+	if err := ValidateBlock(future); !errors.Is(err, ErrUnknownVersion) {
+		t.Errorf("ValidateBlock(future version) error = %v, want ErrUnknownVersion", err)
+	}
+
+	negative := *future
+	negative.Header.Version = -1
+	negative.Header.Hash = CalcBlockHash(&negative)
+	if err := ValidateBlock(&negative); !errors.Is(err, ErrUnknownVersion) {
+		t.Errorf("ValidateBlock(negative version) error = %v, want ErrUnknownVersion", err)
+	}
+}
+
 func TestGetTransactionData(t *testing.T) {
 	txData := &TransactionData{
 		From:   "node1",
@@ -254,6 +446,58 @@ func TestGetAddNodeData(t *testing.T) {
 	}
 }
 
+func TestDecodePayload(t *testing.T) {
+	t.Run("transaction", func(t *testing.T) {
+		data, _ := SetTransactionData(&TransactionData{From: "node1", To: "node2", Amount: 100, Title: "test"})
+		block := NewBlock(1, "prev", BlockPayload{Type: "transaction", Data: data})
+
+		decoded, err := block.DecodePayload()
+		if err != nil {
+			t.Fatalf("DecodePayload failed: %v", err)
+		}
+		tx, ok := decoded.(*TransactionData)
+		if !ok {
+			t.Fatalf("DecodePayload returned %T, want *TransactionData", decoded)
+		}
+		if tx.From != "node1" || tx.To != "node2" || tx.Amount != 100 {
+			t.Errorf("decoded = %+v", tx)
+		}
+	})
+
+	t.Run("add_node", func(t *testing.T) {
+		data, _ := SetAddNodeData(&AddNodeData{NodeName: "node1", NickName: "Tanaka"})
+		block := NewBlock(1, "prev", BlockPayload{Type: "add_node", Data: data})
+
+		decoded, err := block.DecodePayload()
+		if err != nil {
+			t.Fatalf("DecodePayload failed: %v", err)
+		}
+		addNode, ok := decoded.(*AddNodeData)
+		if !ok {
+			t.Fatalf("DecodePayload returned %T, want *AddNodeData", decoded)
+		}
+		if addNode.NodeName != "node1" || addNode.NickName != "Tanaka" {
+			t.Errorf("decoded = %+v", addNode)
+		}
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		block := NewBlock(1, "prev", BlockPayload{Type: "unknown", Data: json.RawMessage(`{}`)})
+
+		if _, err := block.DecodePayload(); err == nil {
+			t.Error("Expected error for unknown type, got nil")
+		}
+	})
+
+	t.Run("malformed data", func(t *testing.T) {
+		block := NewBlock(1, "prev", BlockPayload{Type: "transaction", Data: json.RawMessage(`"x"`)})
+
+		if _, err := block.DecodePayload(); err == nil {
+			t.Error("Expected error for malformed data, got nil")
+		}
+	})
+}
+
 func TestCreateBlockWithTransaction(t *testing.T) {
 	tx := &TransactionData{
 		From:   "node1",
@@ -1,26 +1,37 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"sync"
+	"time"
 )
 
+// BlockLoader は position 番目のブロックを読み込む（NewLazyChain で遅延読み込みに使う）
+type BlockLoader func(position int) (*Block, error)
+
 // Chain はブロックチェーンを表す
 type Chain struct {
-	mu      sync.RWMutex
-	blocks  []*Block
-	hashSet map[string]struct{} // 重複検知用
+	mu     sync.RWMutex
+	blocks []*Block
+	byHash map[string]int // ハッシュ → 位置の索引（重複検知・ハッシュ検索用）
+
+	// loader は blocks のうち未読み込み（nil）のブロックを読み込む。nil なら全ブロックを保持している
+	// 読み込んだブロックは blocks に保持する。loadMu は読み取りロック中の読み込み同士を排他する
+	// 末尾のブロックは常に読み込み済みにしておく（LastBlock などは読み込みを行わず、失敗しない）
+	loader BlockLoader
+	loadMu sync.Mutex
 }
 
 // NewChain は新しいブロックチェーンを作成する
 func NewChain() *Chain {
 	genesis := NewGenesisBlock()
-	hashSet := make(map[string]struct{})
-	hashSet[genesis.Header.Hash] = struct{}{}
+	byHash := make(map[string]int)
+	byHash[genesis.Header.Hash] = 0
 
 	return &Chain{
-		blocks:  []*Block{genesis},
-		hashSet: hashSet,
+		blocks: []*Block{genesis},
+		byHash: byHash,
 	}
 }
 
@@ -35,20 +46,122 @@ func NewChainFromBlocks(blocks []*Block) (*Chain, error) {
 		return nil, fmt.Errorf("first block is not a genesis block")
 	}
 
-	hashSet := make(map[string]struct{})
-	for _, b := range blocks {
-		hashSet[b.Header.Hash] = struct{}{}
+	// 不正なインデックス（負数・飛び・重複）のファイルを読み込むと
+	// GetBlockByIndex や GetLastIndex が位置とずれるためここで弾く
+	for i := 1; i < len(blocks); i++ {
+		if err := validateIndexSequence(blocks[i-1], blocks[i], i); err != nil {
+			return nil, err
+		}
+	}
+
+	byHash := make(map[string]int, len(blocks))
+	for i, b := range blocks {
+		byHash[b.Header.Hash] = i
 	}
 
 	chain := &Chain{
-		blocks:  make([]*Block, len(blocks)),
-		hashSet: hashSet,
+		blocks: make([]*Block, len(blocks)),
+		byHash: byHash,
 	}
 	copy(chain.blocks, blocks)
 
 	return chain, nil
 }
 
+// NewLazyChain はブロックのハッシュ列（ジェネシスから順）からチェーンを構築する
+// ブロック本体は必要になった時点で load で読み込み、読み込みに失敗すれば各メソッドがエラーを返す
+// ジェネシスと末尾のブロックはここで読み込んで検証する（末尾を参照するメソッドはエラーにならない）
+func NewLazyChain(hashes []string, load BlockLoader) (*Chain, error) {
+	if len(hashes) == 0 {
+		return nil, fmt.Errorf("hashes is empty")
+	}
+
+	byHash := make(map[string]int, len(hashes))
+	for i, hash := range hashes {
+		if _, exists := byHash[hash]; exists {
+			return nil, fmt.Errorf("duplicate block: %s", hash)
+		}
+		byHash[hash] = i
+	}
+
+	chain := &Chain{
+		blocks: make([]*Block, len(hashes)),
+		byHash: byHash,
+		loader: load,
+	}
+
+	genesis, err := chain.blockAt(0)
+	if err != nil {
+		return nil, err
+	}
+	if !genesis.IsGenesisBlock() {
+		return nil, fmt.Errorf("first block is not a genesis block")
+	}
+	// 末尾のブロックは AddBlock などで毎回参照するため先に読み込む
+	last, err := chain.blockAt(len(hashes) - 1)
+	if err != nil {
+		return nil, err
+	}
+	if last.Header.Index != len(hashes)-1 {
+		return nil, NewValidationError(len(hashes)-1, ValidationKindIndex,
+			fmt.Errorf("invalid index: expected %d, got %d", len(hashes)-1, last.Header.Index))
+	}
+
+	return chain, nil
+}
+
+// blockAt は position 番目のブロックを返す。未読み込みなら loader で読み込んで保持する
+// 呼び出し側で mu（読み取りか書き込み）を取得していること
+func (c *Chain) blockAt(position int) (*Block, error) {
+	if c.loader == nil {
+		return c.blocks[position], nil
+	}
+
+	c.loadMu.Lock()
+	defer c.loadMu.Unlock()
+
+	if b := c.blocks[position]; b != nil {
+		return b, nil
+	}
+	b, err := c.loader(position)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load block %d: %w", position, err)
+	}
+	if b.Header.Index != position {
+		return nil, fmt.Errorf("failed to load block %d: got block with index %d", position, b.Header.Index)
+	}
+	if pos, ok := c.byHash[b.Header.Hash]; !ok || pos != position {
+		return nil, fmt.Errorf("failed to load block %d: hash %s does not match the chain", position, b.Header.Hash)
+	}
+	c.blocks[position] = b
+	return b, nil
+}
+
+// lastBlock は末尾のブロックを返す（末尾は常に読み込み済み。呼び出し側で mu を取得していること）
+func (c *Chain) lastBlock() *Block {
+	if len(c.blocks) == 0 {
+		return nil
+	}
+	return c.blocks[len(c.blocks)-1]
+}
+
+// loadRange は from <= position < to のブロックを返す（呼び出し側で mu を取得していること）
+func (c *Chain) loadRange(from, to int) ([]*Block, error) {
+	blocks := make([]*Block, to-from)
+	if c.loader == nil {
+		copy(blocks, c.blocks[from:to])
+		return blocks, nil
+	}
+	for i := from; i < to; i++ {
+		b, err := c.blockAt(i)
+		if err != nil {
+			return nil, err
+		}
+		blocks[i-from] = b
+	}
+	return blocks, nil
+}
+
 // AddBlock はブロックをチェーンに追加する
 func (c *Chain) AddBlock(b *Block) error {
 	c.mu.Lock()
@@ -60,8 +173,7 @@ func (c *Chain) AddBlock(b *Block) error {
 	}
 
 	// 前のブロックのハッシュをチェック
-	if len(c.blocks) > 0 {
-		lastBlock := c.blocks[len(c.blocks)-1]
+	if lastBlock := c.lastBlock(); lastBlock != nil {
 		if b.Header.PrevHash != lastBlock.Header.Hash {
 			return fmt.Errorf("prev_hash mismatch: expected %s, got %s", lastBlock.Header.Hash, b.Header.PrevHash)
 		}
@@ -70,27 +182,31 @@ func (c *Chain) AddBlock(b *Block) error {
 		if b.Header.Index != lastBlock.Header.Index+1 {
 			return fmt.Errorf("index mismatch: expected %d, got %d", lastBlock.Header.Index+1, b.Header.Index)
 		}
+
+		// 作成時刻が直前のブロックより前でないかチェック
+		if err := ValidateTimestamp(lastBlock, b); err != nil {
+			return err
+		}
 	}
 
 	// 重複チェック
-	if _, exists := c.hashSet[b.Header.Hash]; exists {
+	if _, exists := c.byHash[b.Header.Hash]; exists {
 		return fmt.Errorf("duplicate block: %s", b.Header.Hash)
 	}
 
 	c.blocks = append(c.blocks, b)
-	c.hashSet[b.Header.Hash] = struct{}{}
+	c.byHash[b.Header.Hash] = len(c.blocks) - 1
 
 	return nil
 }
 
 // GetBlocks は全ブロックのコピーを返す
-func (c *Chain) GetBlocks() []*Block {
+// 遅延読み込みのチェーンでブロックを読み込めなければエラーを返す
+func (c *Chain) GetBlocks() ([]*Block, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	blocks := make([]*Block, len(c.blocks))
-	copy(blocks, c.blocks)
-	return blocks
+	return c.loadRange(0, len(c.blocks))
 }
 
 // LastBlock は最後のブロックを返す
@@ -98,10 +214,7 @@ func (c *Chain) LastBlock() *Block {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	if len(c.blocks) == 0 {
-		return nil
-	}
-	return c.blocks[len(c.blocks)-1]
+	return c.lastBlock()
 }
 
 // Len はチェーンの長さを返す
@@ -112,7 +225,42 @@ func (c *Chain) Len() int {
 	return len(c.blocks)
 }
 
+// ValidationErrorKind はチェーン検証で失敗した検査の種類を表す
+type ValidationErrorKind string
+
+const (
+	ValidationKindGenesis   ValidationErrorKind = "genesis"   // 先頭がジェネシスブロックでない
+	ValidationKindHash      ValidationErrorKind = "hash"      // ブロックのハッシュが内容と一致しない
+	ValidationKindPayload   ValidationErrorKind = "payload"   // ペイロードの種類が不正
+	ValidationKindLink      ValidationErrorKind = "link"      // prev_hash が直前のブロックのハッシュと一致しない
+	ValidationKindIndex     ValidationErrorKind = "index"     // インデックスが直前のブロックの次でない
+	ValidationKindTimestamp ValidationErrorKind = "timestamp" // 作成時刻が直前のブロックより前
+	ValidationKindSignature ValidationErrorKind = "signature" // 署名が不正（署名を検証する場合のみ）
+)
+
+// ValidationError はチェーン検証で最初に見つかった不正なブロックの位置と種類を表す
+// errors.As で取り出し、ツールや /chain/verify が構造化した診断を返せるようにする
+type ValidationError struct {
+	Index int
+	Kind  ValidationErrorKind
+	Err   error
+}
+
+// NewValidationError は位置 index のブロックの検証エラーを作成する
+func NewValidationError(index int, kind ValidationErrorKind, err error) *ValidationError {
+	return &ValidationError{Index: index, Kind: kind, Err: err}
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("block at index %d: %v", e.Index, e.Err)
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
 // ValidateChain はチェーン全体の整合性を検証する
+// 不正なブロックがあれば *ValidationError を返す
 func (c *Chain) ValidateChain() error {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
@@ -121,39 +269,93 @@ func (c *Chain) ValidateChain() error {
 		return fmt.Errorf("empty chain")
 	}
 
+	blocks, err := c.loadRange(0, len(c.blocks))
+	if err != nil {
+		return err
+	}
+
 	// ジェネシスブロックのチェック
-	genesis := c.blocks[0]
+	genesis := blocks[0]
 	if !genesis.IsGenesisBlock() {
-		return fmt.Errorf("first block is not a valid genesis block")
+		return NewValidationError(0, ValidationKindGenesis, fmt.Errorf("first block is not a valid genesis block"))
 	}
 
 	// 各ブロックの検証
-	for i := 1; i < len(c.blocks); i++ {
-		current := c.blocks[i]
-		prev := c.blocks[i-1]
-
-		// ブロック自体のハッシュ検証
-		if err := ValidateBlock(current); err != nil {
-			return fmt.Errorf("block at index %d validation failed: %w", i, err)
+	for i := 1; i < len(blocks); i++ {
+		if err := ValidateLink(blocks[i-1], blocks[i], i); err != nil {
+			return err
 		}
+	}
 
-		// 前のブロックとの連結検証
-		if current.Header.PrevHash != prev.Header.Hash {
-			return fmt.Errorf("block at index %d has invalid prev_hash: expected %s, got %s",
-				i, prev.Header.Hash, current.Header.PrevHash)
-		}
+	return nil
+}
 
-		// インデックスの連続性
-		if current.Header.Index != prev.Header.Index+1 {
-			return fmt.Errorf("block at index %d has invalid index: expected %d, got %d",
-				i, prev.Header.Index+1, current.Header.Index)
+// ValidateLink は position 番目のブロック current を直前のブロック prev に続くブロックとして検証する
+// ブロック自体（ハッシュ・ペイロードの種類）、連結、インデックスの連続性、作成時刻の単調性の順に検査し、失敗すれば *ValidationError を返す
+func ValidateLink(prev, current *Block, position int) error {
+	if err := ValidateBlock(current); err != nil {
+		kind := ValidationKindHash
+		if errors.Is(err, ErrInvalidPayloadType) {
+			kind = ValidationKindPayload
 		}
+		return NewValidationError(position, kind, err)
+	}
+
+	if current.Header.PrevHash != prev.Header.Hash {
+		return NewValidationError(position, ValidationKindLink,
+			fmt.Errorf("invalid prev_hash: expected %s, got %s", prev.Header.Hash, current.Header.PrevHash))
+	}
+
+	if err := validateIndexSequence(prev, current, position); err != nil {
+		return err
+	}
+
+	if err := ValidateTimestamp(prev, current); err != nil {
+		return NewValidationError(position, ValidationKindTimestamp, err)
+	}
+
+	return nil
+}
+
+// ValidateTimestamp はブロック current の作成時刻が直前のブロック prev 以降であるかを検証する
+// ジェネシスブロックはゼロ時刻なので、その次のブロックはゼロ時刻より後であればよい
+// ブロック受信時（server.Block）は秒単位に丸められるため秒単位で比較する
+func ValidateTimestamp(prev, current *Block) error {
+	prevAt := prev.Header.CreatedAt.Truncate(time.Second)
+	currentAt := current.Header.CreatedAt.Truncate(time.Second)
+	if prev.IsGenesisBlock() && !currentAt.After(prevAt) {
+		return fmt.Errorf("invalid created_at: %s is not after genesis", current.Header.CreatedAt.Format(time.RFC3339))
 	}
+	if currentAt.Before(prevAt) {
+		return fmt.Errorf("invalid created_at: %s is before previous block's %s",
+			current.Header.CreatedAt.Format(time.RFC3339), prev.Header.CreatedAt.Format(time.RFC3339))
+	}
+	return nil
+}
 
+// validateIndexSequence は position 番目のブロック current のインデックスが直前のブロック prev の次であるかを検証する
+func validateIndexSequence(prev, current *Block, position int) error {
+	if current.Header.Index != prev.Header.Index+1 {
+		return NewValidationError(position, ValidationKindIndex,
+			fmt.Errorf("invalid index: expected %d, got %d", prev.Header.Index+1, current.Header.Index))
+	}
 	return nil
 }
 
-// ReplaceChain はチェーンを置換する（最長チェーンルール）
+// PreferChain はフォーク選択規則で candidate が current より優先されるかを返す
+// 長いチェーンを優先し、同じ長さなら末尾ブロックのハッシュが辞書順で小さい方を優先する。
+// 全ノードが同じ規則で選ぶため、同じ長さのフォークも同じチェーンに収束する
+func PreferChain(candidate, current []*Block) bool {
+	if len(candidate) == 0 {
+		return false
+	}
+	if len(current) == 0 || len(candidate) != len(current) {
+		return len(candidate) > len(current)
+	}
+	return candidate[len(candidate)-1].Header.Hash < current[len(current)-1].Header.Hash
+}
+
+// ReplaceChain はチェーンを置換する（最長チェーンルール。同じ長さは PreferChain で決める）
 func (c *Chain) ReplaceChain(blocks []*Block) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -163,30 +365,34 @@ func (c *Chain) ReplaceChain(blocks []*Block) error {
 		return fmt.Errorf("new chain is empty")
 	}
 
-	// 新しいチェーンが現在より長いこと
-	if len(blocks) <= len(c.blocks) {
-		return fmt.Errorf("new chain is not longer: new length %d, current length %d",
+	// 新しいチェーンが現在より優先されること（比較には長さと末尾のブロックのみ使う）
+	current := make([]*Block, len(c.blocks))
+	if len(current) > 0 {
+		current[len(current)-1] = c.lastBlock()
+	}
+	if !PreferChain(blocks, current) {
+		return fmt.Errorf("new chain is not preferred: new length %d, current length %d",
 			len(blocks), len(c.blocks))
 	}
 
 	// 新しいチェーンの検証
 	newChain := &Chain{
-		blocks:  make([]*Block, len(blocks)),
-		hashSet: make(map[string]struct{}),
+		blocks: make([]*Block, len(blocks)),
+		byHash: make(map[string]int, len(blocks)),
 	}
 	copy(newChain.blocks, blocks)
 
-	for _, b := range blocks {
+	for i, b := range blocks {
 		// ブロックの検証
 		if err := ValidateBlock(b); err != nil {
 			return fmt.Errorf("new chain contains invalid block: %w", err)
 		}
 
 		// 重複チェック
-		if _, exists := newChain.hashSet[b.Header.Hash]; exists {
+		if _, exists := newChain.byHash[b.Header.Hash]; exists {
 			return fmt.Errorf("new chain contains duplicate block: %s", b.Header.Hash)
 		}
-		newChain.hashSet[b.Header.Hash] = struct{}{}
+		newChain.byHash[b.Header.Hash] = i
 	}
 
 	// 連結性の検証
@@ -205,21 +411,56 @@ func (c *Chain) ReplaceChain(blocks []*Block) error {
 		if current.Header.Index != prev.Header.Index+1 {
 			return fmt.Errorf("new chain has invalid index at %d", i)
 		}
+
+		if err := ValidateTimestamp(prev, current); err != nil {
+			return fmt.Errorf("new chain has invalid timestamp at %d: %w", i, err)
+		}
 	}
 
-	// チェーンを置換
+	// チェーンを置換（新しいチェーンは全ブロックを保持している）
 	c.blocks = newChain.blocks
-	c.hashSet = newChain.hashSet
+	c.byHash = newChain.byHash
+	c.loader = nil
 
 	return nil
 }
 
+// RemoveAfter はインデックスが index より大きいブロックを取り除き、チェーンを index まで巻き戻す（管理者による復旧用）
+// ジェネシスブロックは取り除けないため index が負の場合はエラー、末尾より大きい場合もエラーを返す
+// 取り除いたブロックを古い順に返す
+func (c *Chain) RemoveAfter(index int) ([]*Block, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if index < 0 {
+		return nil, fmt.Errorf("cannot roll back past the genesis block: index %d", index)
+	}
+	if index >= len(c.blocks) {
+		return nil, fmt.Errorf("index %d is beyond the last block %d", index, len(c.blocks)-1)
+	}
+
+	removed, err := c.loadRange(index+1, len(c.blocks))
+	if err != nil {
+		return nil, err
+	}
+	// 巻き戻した後の末尾のブロックも読み込んでおく
+	if _, err := c.blockAt(index); err != nil {
+		return nil, err
+	}
+	for _, b := range removed {
+		delete(c.byHash, b.Header.Hash)
+	}
+	c.blocks = c.blocks[: index+1 : index+1]
+
+	return removed, nil
+}
+
 // HasBlock は指定したハッシュのブロックが存在するかを返す
 func (c *Chain) HasBlock(hash string) bool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	_, exists := c.hashSet[hash]
+	_, exists := c.byHash[hash]
 	return exists
 }
 
@@ -232,7 +473,23 @@ func (c *Chain) GetBlockByIndex(index int) (*Block, error) {
 		return nil, fmt.Errorf("index out of range: %d", index)
 	}
 
-	return c.blocks[index], nil
+	return c.blockAt(index)
+}
+
+// GetRange は from <= Index < to のブロックを返す
+// 範囲外の値はチェーンの範囲に丸める。from > to の場合はエラーを返す
+func (c *Chain) GetRange(from, to int) ([]*Block, error) {
+	if from > to {
+		return nil, fmt.Errorf("invalid range: from %d is greater than to %d", from, to)
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	from = max(0, min(from, len(c.blocks)))
+	to = max(0, min(to, len(c.blocks)))
+
+	return c.loadRange(from, to)
 }
 
 // GetBlockByHash は指定したハッシュのブロックを返す
@@ -240,10 +497,8 @@ func (c *Chain) GetBlockByHash(hash string) (*Block, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	for _, b := range c.blocks {
-		if b.Header.Hash == hash {
-			return b, nil
-		}
+	if position, ok := c.byHash[hash]; ok {
+		return c.blockAt(position)
 	}
 
 	return nil, fmt.Errorf("block not found: %s", hash)
@@ -254,7 +509,11 @@ func (c *Chain) ForEach(fn func(b *Block) error) error {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	for _, b := range c.blocks {
+	for i := range c.blocks {
+		b, err := c.blockAt(i)
+		if err != nil {
+			return err
+		}
 		if err := fn(b); err != nil {
 			return err
 		}
@@ -269,16 +528,19 @@ func (c *Chain) Clone() *Chain {
 	defer c.mu.RUnlock()
 
 	blocks := make([]*Block, len(c.blocks))
+	c.loadMu.Lock()
 	copy(blocks, c.blocks)
+	c.loadMu.Unlock()
 
-	hashSet := make(map[string]struct{}, len(c.hashSet))
-	for k := range c.hashSet {
-		hashSet[k] = struct{}{}
+	byHash := make(map[string]int, len(c.byHash))
+	for k, position := range c.byHash {
+		byHash[k] = position
 	}
 
 	return &Chain{
-		blocks:  blocks,
-		hashSet: hashSet,
+		blocks: blocks,
+		byHash: byHash,
+		loader: c.loader,
 	}
 }
 
@@ -291,7 +553,7 @@ func (c *Chain) GetLastHash() string {
 		return ""
 	}
 
-	return c.blocks[len(c.blocks)-1].Header.Hash
+	return c.lastBlock().Header.Hash
 }
 
 // GetLastIndex は最後のブロックのインデックスを返す
@@ -303,5 +565,90 @@ func (c *Chain) GetLastIndex() int {
 		return -1
 	}
 
-	return c.blocks[len(c.blocks)-1].Header.Index
+	return c.lastBlock().Header.Index
+}
+
+// FindCommonAncestor はリモートチェーンのブロックハッシュ列（ジェネシスから順）を受け取り、
+// ローカルにも存在する最後のハッシュのブロックインデックス（共通祖先）を返す
+// 共通するブロックが1つもなければ -1 を返す
+func (c *Chain) FindCommonAncestor(hashes []string) int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for i := len(hashes) - 1; i >= 0; i-- {
+		if position, ok := c.byHash[hashes[i]]; ok {
+			return position
+		}
+	}
+
+	return -1
+}
+
+// Balances はチェーン上の全 transaction ブロックを再生し、ノードごとの残高を返す
+// From から Amount を引き、To に Amount を足す。ジェネシスと add_node ブロックは対象外
+// 遅延読み込みのブロックが読めない場合はエラーを返す（一部だけの残高は返さない）
+func (c *Chain) Balances() (map[string]int64, error) {
+	balances := make(map[string]int64)
+	err := c.ForEach(func(b *Block) error {
+		if b.IsGenesisBlock() || BlockType(b.Payload.Type) != BlockTypeTransaction {
+			return nil
+		}
+		tx, err := b.GetTransactionData()
+		if err != nil {
+			// AddBlock 時に検証済みのため通常は起こらない。残高計算からは除外する
+			return nil
+		}
+		balances[tx.From] -= tx.Amount
+		balances[tx.To] += tx.Amount
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return balances, nil
+}
+
+// BalanceOf は指定ノードの残高を返す（取引がなければ 0）
+func (c *Chain) BalanceOf(node string) (int64, error) {
+	balances, err := c.Balances()
+	if err != nil {
+		return 0, err
+	}
+	return balances[node], nil
+}
+
+// TransactionEntry はチェーン上の取引1件と、それを含むブロックの位置・作成日時を表す
+type TransactionEntry struct {
+	Index       int
+	CreatedAt   time.Time
+	Transaction *TransactionData
+}
+
+// TransactionsFor は From または To が node の取引をチェーン順に返す
+// node が空文字列なら全ての取引を返す。ブロックが読めない場合はエラーを返す
+func (c *Chain) TransactionsFor(node string) ([]*TransactionEntry, error) {
+	var entries []*TransactionEntry
+	err := c.ForEach(func(b *Block) error {
+		if b.IsGenesisBlock() || BlockType(b.Payload.Type) != BlockTypeTransaction {
+			return nil
+		}
+		tx, err := b.GetTransactionData()
+		if err != nil {
+			// AddBlock 時に検証済みのため通常は起こらない。履歴からは除外する
+			return nil
+		}
+		if node != "" && tx.From != node && tx.To != node {
+			return nil
+		}
+		entries = append(entries, &TransactionEntry{
+			Index:       b.Header.Index,
+			CreatedAt:   b.Header.CreatedAt,
+			Transaction: tx,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
 }
@@ -1,8 +1,10 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 )
 
 func TestNewChain(t *testing.T) {
@@ -22,6 +24,31 @@ func TestNewChain(t *testing.T) {
 	}
 }
 
+func TestNewChain_SharedGenesis(t *testing.T) {
+	// 別々に初期化したノードのチェーンでも、ジェネシスのハッシュは一致しなければならない
+	chain1 := NewChain()
+	time.Sleep(time.Millisecond)
+	chain2 := NewChain()
+
+	genesis1, err := chain1.GetBlockByIndex(0)
+	if err != nil {
+		t.Fatalf("GetBlockByIndex(0) error = %v", err)
+	}
+	genesis2, err := chain2.GetBlockByIndex(0)
+	if err != nil {
+		t.Fatalf("GetBlockByIndex(0) error = %v", err)
+	}
+	if genesis1.Header.Hash != genesis2.Header.Hash {
+		t.Errorf("genesis hashes differ: %s vs %s", genesis1.Header.Hash, genesis2.Header.Hash)
+	}
+	if genesis1.Header.Hash != NewGenesisBlock().Header.Hash {
+		t.Error("NewChain genesis differs from NewGenesisBlock")
+	}
+	if !genesis1.Header.CreatedAt.IsZero() {
+		t.Errorf("genesis CreatedAt = %v, want zero time", genesis1.Header.CreatedAt)
+	}
+}
+
 func TestAddBlock(t *testing.T) {
 	chain := NewChain()
 
@@ -63,6 +90,68 @@ func TestAddBlock_InvalidPrevHash(t *testing.T) {
 	}
 }
 
+// blockAt は CreatedAt を指定したブロックを作成する（ハッシュも再計算する）
+func blockAt(t *testing.T, index int, prevHash string, createdAt time.Time) *Block {
+	t.Helper()
+
+	tx := &TransactionData{From: "a", To: "b", Amount: 100, Title: "test"}
+	block, err := CreateBlockWithTransaction(index, prevHash, tx, "sig1", "sig2")
+	if err != nil {
+		t.Fatalf("CreateBlockWithTransaction failed: %v", err)
+	}
+	block.Header.CreatedAt = createdAt
+	block.Header.Hash = CalcBlockHash(block)
+	return block
+}
+
+func TestAddBlock_BackwardTimestamp(t *testing.T) {
+	chain := NewChain()
+	now := time.Now().UTC()
+
+	first := blockAt(t, 1, chain.GetLastHash(), now)
+	if err := chain.AddBlock(first); err != nil {
+		t.Fatalf("AddBlock failed: %v", err)
+	}
+
+	// 直前のブロックより前の時刻は拒否
+	if err := chain.AddBlock(blockAt(t, 2, first.Header.Hash, now.Add(-time.Minute))); err == nil {
+		t.Error("Expected error for backward timestamp, got nil")
+	}
+
+	// 同じ秒（丸めで同時刻になる時刻）は許可
+	if err := chain.AddBlock(blockAt(t, 2, first.Header.Hash, now.Truncate(time.Second))); err != nil {
+		t.Errorf("AddBlock with equal timestamp failed: %v", err)
+	}
+}
+
+func TestAddBlock_ZeroTimestampAfterGenesis(t *testing.T) {
+	chain := NewChain()
+
+	if err := chain.AddBlock(blockAt(t, 1, chain.GetLastHash(), time.Time{})); err == nil {
+		t.Error("Expected error for block not after genesis, got nil")
+	}
+}
+
+func TestValidateChain_BackwardTimestamp(t *testing.T) {
+	now := time.Now().UTC()
+	genesis := NewGenesisBlock()
+	first := blockAt(t, 1, genesis.Header.Hash, now)
+	second := blockAt(t, 2, first.Header.Hash, now.Add(-time.Hour))
+
+	// NewChainFromBlocks は既存ファイルの読み込み用のため時刻は検証しない
+	chain, err := NewChainFromBlocks([]*Block{genesis, first, second})
+	if err != nil {
+		t.Fatalf("NewChainFromBlocks failed: %v", err)
+	}
+	if err := chain.ValidateChain(); err == nil {
+		t.Error("Expected ValidateChain error for backward timestamp, got nil")
+	}
+
+	if err := NewChain().ReplaceChain([]*Block{genesis, first, second}); err == nil {
+		t.Error("Expected ReplaceChain error for backward timestamp, got nil")
+	}
+}
+
 func TestAddBlock_Duplicate(t *testing.T) {
 	chain := NewChain()
 
@@ -108,7 +197,10 @@ func TestAddBlock_InvalidHash(t *testing.T) {
 func TestGetBlocks(t *testing.T) {
 	chain := NewChain()
 
-	blocks := chain.GetBlocks()
+	blocks, err := chain.GetBlocks()
+	if err != nil {
+		t.Fatalf("GetBlocks failed: %v", err)
+	}
 	if len(blocks) != 1 {
 		t.Errorf("GetBlocks length = %d, want 1", len(blocks))
 	}
@@ -116,7 +208,7 @@ func TestGetBlocks(t *testing.T) {
 	// 返されたスライスを修改しても元に影響しないことを確認
 	blocks[0] = nil
 
-	blocks2 := chain.GetBlocks()
+	blocks2 := chainBlocks(t, chain)
 	if blocks2[0] == nil {
 		t.Error("Modifying returned slice affected original chain")
 	}
@@ -175,10 +267,51 @@ func TestValidateChain_ValidChain(t *testing.T) {
 	}
 }
 
+func TestValidateChain_ValidationError(t *testing.T) {
+	tests := []struct {
+		name     string
+		tamper   func(blocks []*Block)
+		wantKind ValidationErrorKind
+	}{
+		// ハッシュを再計算して内容とハッシュは整合させたまま、前のブロックとの連結だけを壊す
+		{"link break", func(blocks []*Block) {
+			blocks[2].Header.PrevHash = blocks[0].Header.Hash
+			blocks[2].Header.Hash = CalcBlockHash(blocks[2])
+		}, ValidationKindLink},
+		{"hash mismatch", func(blocks []*Block) {
+			blocks[2].Payload.FromSignature = "forged"
+		}, ValidationKindHash},
+		{"index gap", func(blocks []*Block) {
+			blocks[2].Header.Index = 5
+			blocks[2].Header.Hash = CalcBlockHash(blocks[2])
+			blocks[3].Header.PrevHash = blocks[2].Header.Hash
+			blocks[3].Header.Hash = CalcBlockHash(blocks[3])
+		}, ValidationKindIndex},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := NewChain()
+			appendTestBlocks(t, chain, 3, "validation")
+			blocks := chainBlocks(t, chain)
+			tt.tamper(blocks)
+
+			err := chain.ValidateChain()
+			var validationErr *ValidationError
+			if !errors.As(err, &validationErr) {
+				t.Fatalf("ValidateChain() error = %v, want *ValidationError", err)
+			}
+			if validationErr.Index != 2 || validationErr.Kind != tt.wantKind {
+				t.Errorf("ValidationError = index %d kind %s, want index 2 kind %s", validationErr.Index, validationErr.Kind, tt.wantKind)
+			}
+		})
+	}
+}
+
 func TestValidateChain_EmptyChain(t *testing.T) {
 	chain := &Chain{
-		blocks:  []*Block{},
-		hashSet: map[string]struct{}{},
+		blocks: []*Block{},
+		byHash: map[string]int{},
 	}
 
 	err := chain.ValidateChain()
@@ -206,7 +339,7 @@ func TestReplaceChain_LongerChain(t *testing.T) {
 	}
 
 	// chain1をchain2で置換
-	err := chain1.ReplaceChain(chain2.GetBlocks())
+	err := chain1.ReplaceChain(chainBlocks(t, chain2))
 	if err != nil {
 		t.Fatalf("ReplaceChain failed: %v", err)
 	}
@@ -233,12 +366,46 @@ func TestReplaceChain_ShorterChain(t *testing.T) {
 	chain2.AddBlock(block)
 
 	// 短いチェーンで置換しようとする
-	err := chain1.ReplaceChain(chain2.GetBlocks())
+	err := chain1.ReplaceChain(chainBlocks(t, chain2))
 	if err == nil {
 		t.Error("Expected error for shorter chain, got nil")
 	}
 }
 
+func TestReplaceChain_EqualLengthFork(t *testing.T) {
+	chain1 := NewChain()
+	appendTestBlocks(t, chain1, 3, "fork1")
+	chain2 := NewChain()
+	appendTestBlocks(t, chain2, 3, "fork2")
+
+	// 末尾ハッシュが辞書順で小さい方が勝つ
+	winner, loser := chain1, chain2
+	if chain2.GetLastHash() < chain1.GetLastHash() {
+		winner, loser = chain2, chain1
+	}
+
+	if !PreferChain(chainBlocks(t, winner), chainBlocks(t, loser)) {
+		t.Error("PreferChain(winner, loser) = false, want true")
+	}
+	if PreferChain(chainBlocks(t, loser), chainBlocks(t, winner)) {
+		t.Error("PreferChain(loser, winner) = true, want false")
+	}
+	if PreferChain(chainBlocks(t, winner), chainBlocks(t, winner)) {
+		t.Error("PreferChain() should be false for the same chain")
+	}
+
+	if err := winner.Clone().ReplaceChain(chainBlocks(t, loser)); err == nil {
+		t.Error("ReplaceChain() should reject the losing fork")
+	}
+	replaced := loser.Clone()
+	if err := replaced.ReplaceChain(chainBlocks(t, winner)); err != nil {
+		t.Fatalf("ReplaceChain() error = %v", err)
+	}
+	if replaced.GetLastHash() != winner.GetLastHash() {
+		t.Error("ReplaceChain() did not switch to the winning fork")
+	}
+}
+
 func TestReplaceChain_BrokenChain(t *testing.T) {
 	chain := NewChain()
 
@@ -302,6 +469,50 @@ func TestGetBlockByIndex(t *testing.T) {
 	}
 }
 
+func TestGetRange(t *testing.T) {
+	chain := NewChain()
+	for i := 1; i <= 4; i++ {
+		tx := &TransactionData{From: "a", To: "b", Amount: int64(i), Title: "test"}
+		block, _ := CreateBlockWithTransaction(i, chain.GetLastHash(), tx, "sig1", "sig2")
+		if err := chain.AddBlock(block); err != nil {
+			t.Fatalf("AddBlock failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		from, to  int
+		wantFirst int
+		wantLen   int
+	}{
+		{"partial", 1, 3, 1, 2},
+		{"full", 0, 5, 0, 5},
+		{"to beyond end", 3, 100, 3, 2},
+		{"negative from", -5, 2, 0, 2},
+		{"from beyond end", 10, 20, 0, 0},
+		{"empty", 2, 2, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			blocks, err := chain.GetRange(tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("GetRange(%d, %d) failed: %v", tt.from, tt.to, err)
+			}
+			if len(blocks) != tt.wantLen {
+				t.Fatalf("GetRange(%d, %d) returned %d blocks, want %d", tt.from, tt.to, len(blocks), tt.wantLen)
+			}
+			if len(blocks) > 0 && blocks[0].Header.Index != tt.wantFirst {
+				t.Errorf("first index = %d, want %d", blocks[0].Header.Index, tt.wantFirst)
+			}
+		})
+	}
+
+	if _, err := chain.GetRange(3, 1); err == nil {
+		t.Error("Expected error for from > to, got nil")
+	}
+}
+
 func TestGetBlockByHash(t *testing.T) {
 	chain := NewChain()
 
@@ -421,3 +632,373 @@ func TestForEach(t *testing.T) {
 		t.Error("ForEach did not call function for all blocks")
 	}
 }
+
+// appendTestBlocks はチェーンに title の異なる取引ブロックを count 個追加する
+func appendTestBlocks(t *testing.T, chain *Chain, count int, title string) {
+	t.Helper()
+	for i := 0; i < count; i++ {
+		tx := &TransactionData{From: "a", To: "b", Amount: int64(i + 1), Title: title}
+		block, _ := CreateBlockWithTransaction(chain.GetLastIndex()+1, chain.GetLastHash(), tx, "sig1", "sig2")
+		if err := chain.AddBlock(block); err != nil {
+			t.Fatalf("AddBlock failed: %v", err)
+		}
+	}
+}
+
+func TestRemoveAfter(t *testing.T) {
+	chain := NewChain()
+	appendTestBlocks(t, chain, 4, "rollback")
+	hashes := chainHashes(t, chain)
+
+	removed, err := chain.RemoveAfter(2)
+	if err != nil {
+		t.Fatalf("RemoveAfter(2) error = %v", err)
+	}
+	if len(removed) != 2 || removed[0].Header.Index != 3 || removed[1].Header.Index != 4 {
+		t.Errorf("removed = %d blocks, want indexes 3 and 4", len(removed))
+	}
+	if chain.Len() != 3 || chain.GetLastHash() != hashes[2] {
+		t.Errorf("chain length = %d, tip = %s, want 3 and %s", chain.Len(), chain.GetLastHash(), hashes[2])
+	}
+	// ハッシュの索引からも取り除かれ、同じ位置に新しいブロックを追加できる
+	if chain.HasBlock(hashes[3]) || chain.HasBlock(hashes[4]) {
+		t.Error("removed blocks are still indexed by hash")
+	}
+	appendTestBlocks(t, chain, 1, "after rollback")
+	if err := chain.ValidateChain(); err != nil {
+		t.Errorf("ValidateChain() after rollback error = %v", err)
+	}
+
+	// ジェネシスより前や末尾より後は拒否し、チェーンは変わらない
+	for _, index := range []int{-1, chain.Len()} {
+		if _, err := chain.RemoveAfter(index); err == nil {
+			t.Errorf("RemoveAfter(%d) error = nil, want error", index)
+		}
+	}
+	if chain.Len() != 4 {
+		t.Errorf("chain length = %d after rejected rollbacks, want 4", chain.Len())
+	}
+
+	// ジェネシスだけを残すことはできる
+	if _, err := chain.RemoveAfter(0); err != nil || chain.Len() != 1 {
+		t.Errorf("RemoveAfter(0) error = %v, length = %d, want nil and 1", err, chain.Len())
+	}
+}
+
+// chainBlocks はチェーンの全ブロックを返す（読み込みに失敗したらテストを中断する）
+func chainBlocks(t *testing.T, chain *Chain) []*Block {
+	t.Helper()
+	blocks, err := chain.GetBlocks()
+	if err != nil {
+		t.Fatalf("GetBlocks failed: %v", err)
+	}
+	return blocks
+}
+
+func chainHashes(t *testing.T, chain *Chain) []string {
+	t.Helper()
+	var hashes []string
+	for _, b := range chainBlocks(t, chain) {
+		hashes = append(hashes, b.Header.Hash)
+	}
+	return hashes
+}
+
+func TestFindCommonAncestor(t *testing.T) {
+	t.Run("shared prefix", func(t *testing.T) {
+		local := NewChain()
+		appendTestBlocks(t, local, 3, "shared")
+		remote := local.Clone()
+		appendTestBlocks(t, local, 2, "local")
+		appendTestBlocks(t, remote, 4, "remote")
+
+		if got := local.FindCommonAncestor(chainHashes(t, remote)); got != 3 {
+			t.Errorf("FindCommonAncestor = %d, want 3", got)
+		}
+	})
+
+	t.Run("only genesis in common", func(t *testing.T) {
+		local := NewChain()
+		appendTestBlocks(t, local, 2, "local")
+		remote := NewChain()
+		appendTestBlocks(t, remote, 3, "remote")
+
+		if got := local.FindCommonAncestor(chainHashes(t, remote)); got != 0 {
+			t.Errorf("FindCommonAncestor = %d, want 0", got)
+		}
+	})
+
+	t.Run("identical chains", func(t *testing.T) {
+		local := NewChain()
+		appendTestBlocks(t, local, 4, "same")
+
+		if got := local.FindCommonAncestor(chainHashes(t, local.Clone())); got != 4 {
+			t.Errorf("FindCommonAncestor = %d, want 4", got)
+		}
+	})
+
+	t.Run("no common block", func(t *testing.T) {
+		local := NewChain()
+
+		if got := local.FindCommonAncestor([]string{"unknown1", "unknown2"}); got != -1 {
+			t.Errorf("FindCommonAncestor = %d, want -1", got)
+		}
+	})
+}
+
+func TestNewChainFromBlocks_InvalidIndex(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(blocks []*Block)
+	}{
+		{"negative index", func(blocks []*Block) { blocks[1].Header.Index = -1 }},
+		{"gap index", func(blocks []*Block) { blocks[2].Header.Index = 5 }},
+		{"repeated index", func(blocks []*Block) { blocks[2].Header.Index = 1 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			chain := NewChain()
+			appendTestBlocks(t, chain, 3, "load")
+			blocks := chainBlocks(t, chain)
+			tt.tamper(blocks)
+
+			if _, err := NewChainFromBlocks(blocks); err == nil {
+				t.Error("Expected error for invalid index, got nil")
+			}
+		})
+	}
+
+	t.Run("valid indices", func(t *testing.T) {
+		chain := NewChain()
+		appendTestBlocks(t, chain, 3, "load")
+
+		loaded, err := NewChainFromBlocks(chainBlocks(t, chain))
+		if err != nil {
+			t.Fatalf("NewChainFromBlocks failed: %v", err)
+		}
+		if loaded.GetLastIndex() != 3 {
+			t.Errorf("GetLastIndex = %d, want 3", loaded.GetLastIndex())
+		}
+	})
+}
+
+func TestNewLazyChain(t *testing.T) {
+	source := NewChain()
+	appendTestBlocks(t, source, 4, "lazy")
+	blocks := chainBlocks(t, source)
+	hashes := chainHashes(t, source)
+
+	var loads []int
+	load := func(position int) (*Block, error) {
+		loads = append(loads, position)
+		if position >= len(blocks) {
+			return nil, fmt.Errorf("no block at %d", position)
+		}
+		return blocks[position], nil
+	}
+
+	chain, err := NewLazyChain(hashes, load)
+	if err != nil {
+		t.Fatalf("NewLazyChain failed: %v", err)
+	}
+	// 構築時に読み込むのはジェネシスと末尾のみ
+	if len(loads) != 2 {
+		t.Errorf("loaded %v at construction, want genesis and last only", loads)
+	}
+	if !chain.HasBlock(blocks[2].Header.Hash) || chain.GetLastHash() != source.GetLastHash() || chain.Len() != source.Len() {
+		t.Error("lazy chain does not match the source chain")
+	}
+	if len(loads) != 2 {
+		t.Errorf("HasBlock/GetLastHash loaded blocks: %v", loads)
+	}
+
+	b, err := chain.GetBlockByHash(blocks[2].Header.Hash)
+	if err != nil || b != blocks[2] {
+		t.Fatalf("GetBlockByHash = %v, %v", b, err)
+	}
+	chain.GetBlockByHash(blocks[2].Header.Hash)
+	if len(loads) != 3 {
+		t.Errorf("block 2 loaded %d times, want once", len(loads)-2)
+	}
+	if err := chain.ValidateChain(); err != nil {
+		t.Errorf("ValidateChain failed: %v", err)
+	}
+
+	// 追加・巻き戻しも通常のチェーンと同じように扱える
+	appendTestBlocks(t, chain, 1, "lazy-next")
+	if removed, err := chain.RemoveAfter(2); err != nil || len(removed) != 3 {
+		t.Errorf("RemoveAfter = %d blocks, %v; want 3", len(removed), err)
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		if _, err := NewLazyChain(nil, load); err == nil {
+			t.Error("Expected error for empty hashes")
+		}
+		if _, err := NewLazyChain([]string{hashes[0], hashes[0]}, load); err == nil {
+			t.Error("Expected error for duplicate hashes")
+		}
+		// 読み込んだブロックのハッシュが一致しない
+		if _, err := NewLazyChain([]string{hashes[0], hashes[2]}, load); err == nil {
+			t.Error("Expected error for mismatched hash")
+		}
+		short := func(position int) (*Block, error) {
+			if position > 0 {
+				return nil, fmt.Errorf("read error")
+			}
+			return blocks[0], nil
+		}
+		if _, err := NewLazyChain(hashes, short); err == nil {
+			t.Error("Expected error when the last block cannot be loaded")
+		}
+	})
+
+	t.Run("read error", func(t *testing.T) {
+		// 途中のブロックだけ読み込めないストレージ
+		flaky := func(position int) (*Block, error) {
+			if position == 2 {
+				return nil, fmt.Errorf("read error")
+			}
+			return blocks[position], nil
+		}
+		chain, err := NewLazyChain(hashes, flaky)
+		if err != nil {
+			t.Fatalf("NewLazyChain failed: %v", err)
+		}
+
+		// 全体の取得・検証はエラーを返し、panic しない
+		if _, err := chain.GetBlocks(); err == nil {
+			t.Error("GetBlocks should fail when a block cannot be loaded")
+		}
+		if err := chain.ValidateChain(); err == nil {
+			t.Error("ValidateChain should fail when a block cannot be loaded")
+		}
+		// 残高・履歴は一部だけの結果を返さずエラーにする
+		if balances, err := chain.Balances(); err == nil {
+			t.Errorf("Balances() = %v, want an error", balances)
+		}
+		if _, err := chain.BalanceOf("b"); err == nil {
+			t.Error("BalanceOf should fail when a block cannot be loaded")
+		}
+		if entries, err := chain.TransactionsFor(""); err == nil {
+			t.Errorf("TransactionsFor() returned %d entries, want an error", len(entries))
+		}
+		// 末尾を参照するメソッドと追加は読み込みに依存しない
+		if chain.LastBlock() != blocks[len(blocks)-1] || chain.GetLastHash() != source.GetLastHash() || chain.GetLastIndex() != source.GetLastIndex() {
+			t.Error("tip accessors do not match the source chain")
+		}
+		appendTestBlocks(t, chain, 1, "flaky-next")
+		if _, err := chain.GetRange(3, chain.Len()); err != nil {
+			t.Errorf("GetRange of loadable blocks failed: %v", err)
+		}
+	})
+}
+
+func TestBalances(t *testing.T) {
+	chain := NewChain()
+
+	addNode, _ := CreateBlockWithAddNode(1, chain.GetLastHash(), &AddNodeData{NodeName: "a", NickName: "A"})
+	if err := chain.AddBlock(addNode); err != nil {
+		t.Fatalf("AddBlock failed: %v", err)
+	}
+	// a -> b に 1, 2, 3
+	appendTestBlocks(t, chain, 3, "lunch")
+	tx := &TransactionData{From: "b", To: "c", Amount: 10, Title: "dinner"}
+	block, _ := CreateBlockWithTransaction(chain.GetLastIndex()+1, chain.GetLastHash(), tx, "sig1", "sig2")
+	if err := chain.AddBlock(block); err != nil {
+		t.Fatalf("AddBlock failed: %v", err)
+	}
+
+	balances, err := chain.Balances()
+	if err != nil {
+		t.Fatalf("Balances() error = %v", err)
+	}
+	want := map[string]int64{"a": -6, "b": -4, "c": 10}
+	if len(balances) != len(want) {
+		t.Errorf("Balances() = %v, want %v", balances, want)
+	}
+	for node, amount := range want {
+		if balances[node] != amount {
+			t.Errorf("Balances()[%s] = %d, want %d", node, balances[node], amount)
+		}
+		if got, err := chain.BalanceOf(node); err != nil || got != amount {
+			t.Errorf("BalanceOf(%s) = %d, %v, want %d", node, got, err, amount)
+		}
+	}
+	if got, err := chain.BalanceOf("unknown"); err != nil || got != 0 {
+		t.Errorf("BalanceOf(unknown) = %d, %v, want 0", got, err)
+	}
+}
+
+func TestTransactionsFor(t *testing.T) {
+	chain := NewChain()
+
+	addNode, _ := CreateBlockWithAddNode(1, chain.GetLastHash(), &AddNodeData{NodeName: "a", NickName: "A"})
+	if err := chain.AddBlock(addNode); err != nil {
+		t.Fatalf("AddBlock failed: %v", err)
+	}
+	// a -> b に 1, 2
+	appendTestBlocks(t, chain, 2, "lunch")
+	tx := &TransactionData{From: "b", To: "c", Amount: 10, Title: "dinner"}
+	block, _ := CreateBlockWithTransaction(chain.GetLastIndex()+1, chain.GetLastHash(), tx, "sig1", "sig2")
+	if err := chain.AddBlock(block); err != nil {
+		t.Fatalf("AddBlock failed: %v", err)
+	}
+
+	tests := []struct {
+		node    string
+		indexes []int
+	}{
+		{node: "a", indexes: []int{2, 3}},
+		{node: "b", indexes: []int{2, 3, 4}},
+		{node: "c", indexes: []int{4}},
+		{node: "", indexes: []int{2, 3, 4}},
+		{node: "unknown", indexes: nil},
+	}
+	for _, tt := range tests {
+		entries, err := chain.TransactionsFor(tt.node)
+		if err != nil {
+			t.Fatalf("TransactionsFor(%q) error = %v", tt.node, err)
+		}
+		if len(entries) != len(tt.indexes) {
+			t.Errorf("TransactionsFor(%q) returned %d entries, want %d", tt.node, len(entries), len(tt.indexes))
+			continue
+		}
+		for i, entry := range entries {
+			if entry.Index != tt.indexes[i] {
+				t.Errorf("TransactionsFor(%q)[%d].Index = %d, want %d", tt.node, i, entry.Index, tt.indexes[i])
+			}
+			b, _ := chain.GetBlockByIndex(entry.Index)
+			if !entry.CreatedAt.Equal(b.Header.CreatedAt) {
+				t.Errorf("TransactionsFor(%q)[%d].CreatedAt = %v, want %v", tt.node, i, entry.CreatedAt, b.Header.CreatedAt)
+			}
+		}
+	}
+
+	entries, err := chain.TransactionsFor("c")
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("TransactionsFor(c) = %d entries, %v", len(entries), err)
+	}
+	if last := entries[0].Transaction; *last != *tx {
+		t.Errorf("TransactionsFor(c)[0].Transaction = %+v, want %+v", last, tx)
+	}
+}
+
+func TestBalances_Concurrent(t *testing.T) {
+	chain := NewChain()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		appendTestBlocks(t, chain, 50, "concurrent")
+	}()
+	for i := 0; i < 50; i++ {
+		if _, err := chain.Balances(); err != nil {
+			t.Errorf("Balances() error = %v", err)
+		}
+	}
+	<-done
+
+	if got, err := chain.BalanceOf("b"); err != nil || got != 50*51/2 {
+		t.Errorf("BalanceOf(b) = %d, %v, want %d", got, err, 50*51/2)
+	}
+}
@@ -1,8 +1,12 @@
 package core
 
 import (
+	"bytes"
 	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
 )
 
 // CalcSHA256 は与えられた文字列のSHA-256ハッシュを計算し、hexエンコードして返す
@@ -11,3 +15,83 @@ func CalcSHA256(data string) string {
 	h.Write([]byte(data))
 	return hex.EncodeToString(h.Sum(nil))
 }
+
+// MerkleRoot は leaves の SHA-256 Merkle ルートを hex で返す（バージョン2のブロックの TxRoot）
+// 葉は各要素の SHA-256、内部ノードは左右の子のハッシュを連結した SHA-256。要素が奇数の段は末尾を複製する
+// 要素が1つならその葉のハッシュ、空なら空文字列を返す
+// 末尾を複製するため [a,b,c] と [a,b,c,c] が同じルートになり、葉と内部ノードも区別しない。新しいブロックには TaggedMerkleRoot を使う
+func MerkleRoot(leaves [][]byte) string {
+	if len(leaves) == 0 {
+		return ""
+	}
+
+	level := make([][32]byte, len(leaves))
+	for i, leaf := range leaves {
+		level[i] = sha256.Sum256(leaf)
+	}
+	for len(level) > 1 {
+		if len(level)%2 == 1 {
+			level = append(level, level[len(level)-1])
+		}
+		next := make([][32]byte, len(level)/2)
+		for i := range next {
+			next[i] = sha256.Sum256(append(level[2*i][:], level[2*i+1][:]...))
+		}
+		level = next
+	}
+	return hex.EncodeToString(level[0][:])
+}
+
+// Merkle 木のハッシュの先頭に付けるドメイン分離用のタグ（TaggedMerkleRoot）
+const (
+	merkleLeafTag = 0x00
+	merkleNodeTag = 0x01
+)
+
+// TaggedMerkleRoot は leaves の SHA-256 Merkle ルートを hex で返す（バージョン3以降のブロックの TxRoot）
+// 葉は SHA-256(0x00 || 要素)、内部ノードは SHA-256(0x01 || 左 || 右)。要素が奇数の段は末尾をそのまま上の段に繰り上げる
+// 葉と内部ノードを取り違えられず、末尾を複製した要素列とも同じルートにならない
+// 要素が1つならその葉のハッシュ、空なら空文字列を返す
+func TaggedMerkleRoot(leaves [][]byte) string {
+	if len(leaves) == 0 {
+		return ""
+	}
+
+	level := make([][32]byte, len(leaves))
+	for i, leaf := range leaves {
+		level[i] = sha256.Sum256(append([]byte{merkleLeafTag}, leaf...))
+	}
+	for len(level) > 1 {
+		next := make([][32]byte, 0, (len(level)+1)/2)
+		for i := 0; i+1 < len(level); i += 2 {
+			buf := make([]byte, 0, 1+2*sha256.Size)
+			buf = append(buf, merkleNodeTag)
+			buf = append(buf, level[i][:]...)
+			buf = append(buf, level[i+1][:]...)
+			next = append(next, sha256.Sum256(buf))
+		}
+		if len(level)%2 == 1 {
+			next = append(next, level[len(level)-1])
+		}
+		level = next
+	}
+	return hex.EncodeToString(level[0][:])
+}
+
+// CanonicalJSON は data を正規化した JSON を返す（ハッシュ・署名の対象）
+// オブジェクトのキーを辞書順に並べ、意味のない空白を取り除く。数値は元の表記のまま残す
+// 送信側の整形やキー順が違っても同じ内容なら同じバイト列になる。値の後ろに空白以外のデータがあればエラーを返す
+func CanonicalJSON(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	// 1つ目の値の後ろに続くデータを無視すると、異なるバイト列が同じ正規形になってしまう
+	if err := dec.Decode(&struct{}{}); err != io.EOF {
+		return nil, fmt.Errorf("unexpected data after the JSON value")
+	}
+	// map のキーは encoding/json により辞書順で出力される
+	return json.Marshal(v)
+}
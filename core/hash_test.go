@@ -1,6 +1,8 @@
 package core
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"testing"
 )
 
@@ -46,3 +48,119 @@ func TestCalcSHA256_Deterministic(t *testing.T) {
 		t.Errorf("CalcSHA256 is not deterministic: %q != %q", result1, result2)
 	}
 }
+
+func TestMerkleRoot(t *testing.T) {
+	sum := func(b []byte) []byte {
+		h := sha256.Sum256(b)
+		return h[:]
+	}
+	pair := func(l, r []byte) string {
+		return hex.EncodeToString(sum(append(append([]byte{}, l...), r...)))
+	}
+	a, b, c := []byte("a"), []byte("b"), []byte("c")
+
+	if got := MerkleRoot(nil); got != "" {
+		t.Errorf("MerkleRoot(nil) = %q, want empty", got)
+	}
+	// 要素が1つなら葉のハッシュそのもの
+	if got, want := MerkleRoot([][]byte{a}), hex.EncodeToString(sum(a)); got != want {
+		t.Errorf("MerkleRoot(a) = %s, want %s", got, want)
+	}
+	if got, want := MerkleRoot([][]byte{a, b}), pair(sum(a), sum(b)); got != want {
+		t.Errorf("MerkleRoot(a, b) = %s, want %s", got, want)
+	}
+	// 奇数個の段は末尾を複製する
+	ab, _ := hex.DecodeString(pair(sum(a), sum(b)))
+	cc, _ := hex.DecodeString(pair(sum(c), sum(c)))
+	if got, want := MerkleRoot([][]byte{a, b, c}), pair(ab, cc); got != want {
+		t.Errorf("MerkleRoot(a, b, c) = %s, want %s", got, want)
+	}
+	if MerkleRoot([][]byte{a, b}) == MerkleRoot([][]byte{b, a}) {
+		t.Error("MerkleRoot should depend on leaf order")
+	}
+}
+
+func TestTaggedMerkleRoot(t *testing.T) {
+	tagged := func(tag byte, parts ...[]byte) []byte {
+		buf := []byte{tag}
+		for _, p := range parts {
+			buf = append(buf, p...)
+		}
+		h := sha256.Sum256(buf)
+		return h[:]
+	}
+	a, b, c := []byte("a"), []byte("b"), []byte("c")
+	la, lb, lc := tagged(0x00, a), tagged(0x00, b), tagged(0x00, c)
+
+	if got := TaggedMerkleRoot(nil); got != "" {
+		t.Errorf("TaggedMerkleRoot(nil) = %q, want empty", got)
+	}
+	if got, want := TaggedMerkleRoot([][]byte{a}), hex.EncodeToString(la); got != want {
+		t.Errorf("TaggedMerkleRoot(a) = %s, want %s", got, want)
+	}
+	ab := tagged(0x01, la, lb)
+	if got, want := TaggedMerkleRoot([][]byte{a, b}), hex.EncodeToString(ab); got != want {
+		t.Errorf("TaggedMerkleRoot(a, b) = %s, want %s", got, want)
+	}
+	// 奇数個の段は末尾をそのまま繰り上げる
+	if got, want := TaggedMerkleRoot([][]byte{a, b, c}), hex.EncodeToString(tagged(0x01, ab, lc)); got != want {
+		t.Errorf("TaggedMerkleRoot(a, b, c) = %s, want %s", got, want)
+	}
+
+	// 末尾を複製した要素列とはルートが異なる（バージョン2の MerkleRoot では一致してしまう）
+	three, four := [][]byte{a, b, c}, [][]byte{a, b, c, c}
+	if MerkleRoot(three) != MerkleRoot(four) {
+		t.Fatal("MerkleRoot is expected to collide for a duplicated last leaf")
+	}
+	if TaggedMerkleRoot(three) == TaggedMerkleRoot(four) {
+		t.Error("TaggedMerkleRoot(a, b, c) should differ from TaggedMerkleRoot(a, b, c, c)")
+	}
+	// 内部ノードの値を葉として渡しても同じルートにならない
+	if TaggedMerkleRoot([][]byte{append(append([]byte{}, la...), lb...)}) == TaggedMerkleRoot([][]byte{a, b}) {
+		t.Error("an inner node should not be confused with a leaf")
+	}
+	if TaggedMerkleRoot([][]byte{a, b}) == TaggedMerkleRoot([][]byte{b, a}) {
+		t.Error("TaggedMerkleRoot should depend on leaf order")
+	}
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"sorted keys", `{"b":1,"a":2}`, `{"a":2,"b":1}`},
+		{"whitespace", "{ \"a\" : [ 1, 2 ],\n  \"b\": null }", `{"a":[1,2],"b":null}`},
+		{"nested objects", `{"z":{"y":true,"x":"s"},"a":[{"d":1,"c":2}]}`, `{"a":[{"c":2,"d":1}],"z":{"x":"s","y":true}}`},
+		{"numbers keep their notation", `{"n":1.50,"big":12345678901234567890}`, `{"big":12345678901234567890,"n":1.50}`},
+		{"scalar", ` "text" `, `"text"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CanonicalJSON([]byte(tt.input))
+			if err != nil {
+				t.Fatalf("CanonicalJSON() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("CanonicalJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := CanonicalJSON([]byte(`{"a":`)); err == nil {
+		t.Error("CanonicalJSON() should fail for invalid JSON")
+	}
+
+	// 値の後ろにデータが続く入力は1つ目の値と同じ正規形にしない
+	for _, input := range []string{`{"a":1}{"b":2}`, `{"a":1} garbage`, `{"a":1}{`, `1 2`} {
+		if got, err := CanonicalJSON([]byte(input)); err == nil {
+			t.Errorf("CanonicalJSON(%q) = %s, want an error for trailing data", input, got)
+		}
+	}
+	// 末尾の空白は許容する
+	if got, err := CanonicalJSON([]byte("{\"a\":1}\n\t ")); err != nil || string(got) != `{"a":1}` {
+		t.Errorf("CanonicalJSON(trailing whitespace) = %s, %v, want {\"a\":1}", got, err)
+	}
+}
@@ -2,11 +2,16 @@ package core
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// ErrEquivalentPending は From/To/Amount/Title が同じ承認待ちトランザクションが既にプールにあることを表す
+var ErrEquivalentPending = errors.New("equivalent transaction already pending")
+
 // PendingTransaction は承認待ちのトランザクションを表す
 type PendingTransaction struct {
 	ID        string       `json:"id"`
@@ -14,25 +19,74 @@ type PendingTransaction struct {
 	Payload   BlockPayload `json:"payload"`
 }
 
+// 承認待ちトランザクションがプールから取り除かれた理由
+const (
+	ArchiveReasonExpired  = "expired"  // 有効期限切れ（自ノードの掃除、または To ノードからの期限切れ通知）
+	ArchiveReasonRejected = "rejected" // To ノードによる拒否
+	ArchiveReasonApproved = "approved" // To ノードの承認したブロックがチェーンに入った（提案元のノードでの状態の照会用）
+)
+
+// ArchivedTransaction はプールから取り除かれた承認待ちトランザクションの記録（監査・状態の照会用）
+type ArchivedTransaction struct {
+	PendingTransaction
+	Reason     string    `json:"reason"`
+	ArchivedAt time.Time `json:"archived_at"`
+}
+
 // PendingPool は承認待ちトランザクションのプールを表す
 type PendingPool struct {
 	mu    sync.RWMutex
 	items map[string]*PendingTransaction
+
+	// From/To ノード名 → ID の二次インデックス（GetByToNode/GetByFromNode 用）
+	// Add 時に一度だけペイロードを解析し、検索のたびに全件 JSON を解析しないようにする
+	byTo   map[string]map[string]struct{}
+	byFrom map[string]map[string]struct{}
 }
 
 // NewPendingPool は新しい承認待ちプールを作成する
 func NewPendingPool() *PendingPool {
 	return &PendingPool{
-		items: make(map[string]*PendingTransaction),
+		items:  make(map[string]*PendingTransaction),
+		byTo:   make(map[string]map[string]struct{}),
+		byFrom: make(map[string]map[string]struct{}),
 	}
 }
 
 // Add は承認待ちトランザクションを追加する
+// 同じIDが既に存在する場合は置き換える
 func (p *PendingPool) Add(pt *PendingTransaction) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	if old, exists := p.items[pt.ID]; exists {
+		p.unindex(old)
+	}
+	p.items[pt.ID] = pt
+	p.index(pt)
+}
+
+// AddIfNoEquivalent は pt と From/To/Amount/Title が同じ承認待ちトランザクションがなければ追加する
+// 検査と追加を同じロックの中で行うため、同じ内容の提案が同時に届いても追加されるのは1件だけになる
+// 既にあれば ErrEquivalentPending を返す
+func (p *PendingPool) AddIfNoEquivalent(pt *PendingTransaction) error {
+	txData, err := pt.GetTransactionData()
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.hasEquivalent(txData) {
+		return ErrEquivalentPending
+	}
+	if old, exists := p.items[pt.ID]; exists {
+		p.unindex(old)
+	}
 	p.items[pt.ID] = pt
+	p.index(pt)
+	return nil
 }
 
 // Remove は指定したIDの承認待ちトランザクションを削除する
@@ -40,7 +94,89 @@ func (p *PendingPool) Remove(id string) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	delete(p.items, id)
+	if pt, exists := p.items[id]; exists {
+		p.unindex(pt)
+		delete(p.items, id)
+	}
+}
+
+// RemoveByFromSignature は From 署名が fromSignature の承認待ちトランザクションを全て削除し、削除したものを返す
+func (p *PendingPool) RemoveByFromSignature(fromSignature string) []*PendingTransaction {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var removed []*PendingTransaction
+	for id, pt := range p.items {
+		if pt.Payload.FromSignature != fromSignature {
+			continue
+		}
+		p.unindex(pt)
+		delete(p.items, id)
+		removed = append(removed, pt)
+	}
+	return removed
+}
+
+// index は pt を二次インデックスに登録する（呼び出し側でロックを保持すること）
+func (p *PendingPool) index(pt *PendingTransaction) {
+	txData, err := pt.GetTransactionData()
+	if err != nil {
+		return
+	}
+	addToIndex(p.byTo, txData.To, pt.ID)
+	addToIndex(p.byFrom, txData.From, pt.ID)
+}
+
+// unindex は pt を二次インデックスから削除する（呼び出し側でロックを保持すること）
+func (p *PendingPool) unindex(pt *PendingTransaction) {
+	txData, err := pt.GetTransactionData()
+	if err != nil {
+		return
+	}
+	removeFromIndex(p.byTo, txData.To, pt.ID)
+	removeFromIndex(p.byFrom, txData.From, pt.ID)
+}
+
+func addToIndex(idx map[string]map[string]struct{}, key, id string) {
+	ids, ok := idx[key]
+	if !ok {
+		ids = make(map[string]struct{})
+		idx[key] = ids
+	}
+	ids[id] = struct{}{}
+}
+
+func removeFromIndex(idx map[string]map[string]struct{}, key, id string) {
+	ids, ok := idx[key]
+	if !ok {
+		return
+	}
+	delete(ids, id)
+	if len(ids) == 0 {
+		delete(idx, key)
+	}
+}
+
+// HasEquivalent は txData と From/To/Amount/Title が同じ承認待ちトランザクションがあるかを返す
+func (p *PendingPool) HasEquivalent(txData *TransactionData) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.hasEquivalent(txData)
+}
+
+// hasEquivalent は HasEquivalent の本体（呼び出し側が p.mu を保持していること）
+func (p *PendingPool) hasEquivalent(txData *TransactionData) bool {
+	for _, pt := range p.lookup(p.byFrom, txData.From) {
+		existing, err := pt.GetTransactionData()
+		if err != nil {
+			continue
+		}
+		if *existing == *txData {
+			return true
+		}
+	}
+	return false
 }
 
 // Get は指定したIDの承認待ちトランザクションを返す
@@ -100,6 +236,8 @@ func (p *PendingPool) Clear() {
 	defer p.mu.Unlock()
 
 	p.items = make(map[string]*PendingTransaction)
+	p.byTo = make(map[string]map[string]struct{})
+	p.byFrom = make(map[string]map[string]struct{})
 }
 
 // GetByToNode は指定したノード宛のトランザクションを返す
@@ -107,31 +245,41 @@ func (p *PendingPool) GetByToNode(nodeName string) []*PendingTransaction {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
-	var result []*PendingTransaction
-	for _, pt := range p.items {
-		if pt.Payload.Type == "transaction" {
-			var txData TransactionData
-			if err := json.Unmarshal(pt.Payload.Data, &txData); err == nil && txData.To == nodeName {
-				result = append(result, pt)
-			}
-		}
+	return p.lookup(p.byTo, nodeName)
+}
+
+// GetByFromNode は指定したノードが提案したトランザクションを返す
+func (p *PendingPool) GetByFromNode(nodeName string) []*PendingTransaction {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.lookup(p.byFrom, nodeName)
+}
+
+// lookup はインデックスから該当するトランザクションを集める（呼び出し側でロックを保持すること）
+func (p *PendingPool) lookup(idx map[string]map[string]struct{}, key string) []*PendingTransaction {
+	ids := idx[key]
+	if len(ids) == 0 {
+		return nil
 	}
 
+	result := make([]*PendingTransaction, 0, len(ids))
+	for id := range ids {
+		result = append(result, p.items[id])
+	}
 	return result
 }
 
-// GetByFromNode は指定したノードが提案したトランザクションを返す
-func (p *PendingPool) GetByFromNode(nodeName string) []*PendingTransaction {
+// Expired は作成から maxAge 以上経過した承認待ちトランザクションを返す
+func (p *PendingPool) Expired(maxAge time.Duration) []*PendingTransaction {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
+	cutoff := time.Now().UTC().Add(-maxAge)
 	var result []*PendingTransaction
 	for _, pt := range p.items {
-		if pt.Payload.Type == "transaction" {
-			var txData TransactionData
-			if err := json.Unmarshal(pt.Payload.Data, &txData); err == nil && txData.From == nodeName {
-				result = append(result, pt)
-			}
+		if !pt.CreatedAt.After(cutoff) {
+			result = append(result, pt)
 		}
 	}
 
@@ -147,9 +295,13 @@ func NewPendingTransaction(id string, payload BlockPayload) *PendingTransaction
 	}
 }
 
+// idSeq は GenerateID の呼び出しごとに増える通し番号
+var idSeq atomic.Uint64
+
 // GenerateID は一意なIDを生成する（ハッシュベース）
+// 時刻とペイロードが同じでも（時計の分解能が粗い環境での同時提案など）衝突しないよう、プロセス内の通し番号を含める
 func GenerateID(payload BlockPayload, t time.Time) string {
-	data := fmt.Sprintf("%d%s%s", t.UnixNano(), payload.Type, string(payload.Data))
+	data := fmt.Sprintf("%d%d%s%s", t.UnixNano(), idSeq.Add(1), payload.Type, string(payload.Data))
 	return CalcSHA256(data)
 }
 
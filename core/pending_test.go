@@ -2,6 +2,10 @@ package core
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -160,6 +164,105 @@ func TestPendingPool_Has(t *testing.T) {
 	}
 }
 
+func TestPendingPool_HasEquivalent(t *testing.T) {
+	pool := NewPendingPool()
+
+	txData := &TransactionData{From: "a", To: "b", Amount: 100, Title: "test"}
+	data, _ := json.Marshal(txData)
+	pool.Add(NewPendingTransaction("id1", BlockPayload{
+		Type:          "transaction",
+		Data:          json.RawMessage(data),
+		FromSignature: "sig1",
+	}))
+
+	if !pool.HasEquivalent(&TransactionData{From: "a", To: "b", Amount: 100, Title: "test"}) {
+		t.Error("HasEquivalent should return true for identical transaction data")
+	}
+
+	others := []*TransactionData{
+		{From: "a", To: "b", Amount: 200, Title: "test"},
+		{From: "a", To: "b", Amount: 100, Title: "other"},
+		{From: "a", To: "c", Amount: 100, Title: "test"},
+		{From: "c", To: "b", Amount: 100, Title: "test"},
+	}
+	for _, other := range others {
+		if pool.HasEquivalent(other) {
+			t.Errorf("HasEquivalent(%+v) should return false", other)
+		}
+	}
+
+	pool.Remove("id1")
+	if pool.HasEquivalent(txData) {
+		t.Error("HasEquivalent should return false after the entry is removed")
+	}
+}
+
+func TestPendingPool_AddIfNoEquivalent(t *testing.T) {
+	pool := NewPendingPool()
+
+	data, _ := json.Marshal(&TransactionData{From: "a", To: "b", Amount: 100, Title: "test"})
+	otherData, _ := json.Marshal(&TransactionData{From: "a", To: "b", Amount: 200, Title: "test"})
+
+	// 同じ内容を同時に追加しても1件だけ入る
+	const adders = 8
+	var wg sync.WaitGroup
+	var added atomic.Int32
+	start := make(chan struct{})
+	for i := 0; i < adders; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			err := pool.AddIfNoEquivalent(NewPendingTransaction(fmt.Sprintf("id%d", i), BlockPayload{Type: "transaction", Data: data}))
+			switch {
+			case err == nil:
+				added.Add(1)
+			case !errors.Is(err, ErrEquivalentPending):
+				t.Errorf("AddIfNoEquivalent() error = %v, want ErrEquivalentPending", err)
+			}
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	if got := added.Load(); got != 1 {
+		t.Errorf("%d entries added, want 1", got)
+	}
+	if got := pool.Len(); got != 1 {
+		t.Errorf("Len() = %d, want 1", got)
+	}
+
+	// 内容が異なれば追加できる
+	if err := pool.AddIfNoEquivalent(NewPendingTransaction("other", BlockPayload{Type: "transaction", Data: otherData})); err != nil {
+		t.Errorf("AddIfNoEquivalent(distinct) error = %v", err)
+	}
+	if err := pool.AddIfNoEquivalent(NewPendingTransaction("broken", BlockPayload{Type: "transaction", Data: json.RawMessage(`{`)})); err == nil {
+		t.Error("AddIfNoEquivalent should fail for unparsable transaction data")
+	}
+}
+
+func TestPendingPool_RemoveByFromSignature(t *testing.T) {
+	pool := NewPendingPool()
+
+	data, _ := json.Marshal(&TransactionData{From: "a", To: "b", Amount: 100, Title: "test"})
+	pool.Add(NewPendingTransaction("id1", BlockPayload{Type: "transaction", Data: data, FromSignature: "sig1"}))
+	pool.Add(NewPendingTransaction("id2", BlockPayload{Type: "transaction", Data: data, FromSignature: "sig2"}))
+
+	removed := pool.RemoveByFromSignature("sig1")
+	if len(removed) != 1 || removed[0].ID != "id1" {
+		t.Fatalf("RemoveByFromSignature() = %v, want id1", removed)
+	}
+	if pool.Has("id1") || !pool.Has("id2") {
+		t.Error("RemoveByFromSignature should remove only the matching entry")
+	}
+	if got := pool.GetByFromNode("a"); len(got) != 1 || got[0].ID != "id2" {
+		t.Errorf("GetByFromNode() = %v, want only id2", got)
+	}
+	if removed := pool.RemoveByFromSignature("unknown"); len(removed) != 0 {
+		t.Errorf("RemoveByFromSignature(unknown) = %v, want none", removed)
+	}
+}
+
 func TestPendingPool_Clear(t *testing.T) {
 	pool := NewPendingPool()
 
@@ -362,8 +465,9 @@ func TestGenerateID(t *testing.T) {
 	id1 := GenerateID(payload, fixedTime)
 	id2 := GenerateID(payload, fixedTime)
 
-	if id1 != id2 {
-		t.Errorf("GenerateID is not deterministic: %s != %s", id1, id2)
+	// 時刻とペイロードが同じでも衝突しない
+	if id1 == id2 {
+		t.Errorf("GenerateID should produce different IDs for identical inputs: %s", id1)
 	}
 
 	// 時刻が違えばIDも違うはず
@@ -423,3 +527,118 @@ func TestPendingPool_ReplaceExisting(t *testing.T) {
 		t.Errorf("Payload was not replaced: FromSignature = %s, want sig2", retrieved.Payload.FromSignature)
 	}
 }
+
+func TestPendingPool_Expired(t *testing.T) {
+	pool := NewPendingPool()
+
+	txData := &TransactionData{From: "a", To: "b", Amount: 100, Title: "test"}
+	data, _ := json.Marshal(txData)
+	payload := BlockPayload{Type: "transaction", Data: json.RawMessage(data)}
+
+	old := NewPendingTransaction("old", payload)
+	old.CreatedAt = time.Now().UTC().Add(-2 * time.Hour)
+	fresh := NewPendingTransaction("fresh", payload)
+	pool.Add(old)
+	pool.Add(fresh)
+
+	expired := pool.Expired(time.Hour)
+	if len(expired) != 1 {
+		t.Fatalf("Expired returned %d items, want 1", len(expired))
+	}
+	if expired[0].ID != "old" {
+		t.Errorf("Expired ID = %s, want old", expired[0].ID)
+	}
+
+	// Expired はプールから削除しない
+	if pool.Len() != 2 {
+		t.Errorf("Pool length = %d, want 2", pool.Len())
+	}
+}
+
+// newTestPending はテスト用の承認待ちトランザクションを作成する
+func newTestPending(id, from, to string) *PendingTransaction {
+	data, _ := json.Marshal(&TransactionData{From: from, To: to, Amount: 100, Title: "test"})
+	return NewPendingTransaction(id, BlockPayload{
+		Type:          "transaction",
+		Data:          json.RawMessage(data),
+		FromSignature: "sig-" + id,
+	})
+}
+
+func TestPendingPool_IndexConsistency(t *testing.T) {
+	pool := NewPendingPool()
+
+	pool.Add(newTestPending("id1", "alice", "bob"))
+	pool.Add(newTestPending("id2", "alice", "carol"))
+	pool.Add(newTestPending("id3", "carol", "bob"))
+
+	if got := len(pool.GetByToNode("bob")); got != 2 {
+		t.Errorf("GetByToNode(bob) = %d items, want 2", got)
+	}
+	if got := len(pool.GetByFromNode("alice")); got != 2 {
+		t.Errorf("GetByFromNode(alice) = %d items, want 2", got)
+	}
+
+	// 削除するとインデックスからも消える
+	pool.Remove("id1")
+	if got := len(pool.GetByToNode("bob")); got != 1 {
+		t.Errorf("GetByToNode(bob) after remove = %d items, want 1", got)
+	}
+	if got := len(pool.GetByFromNode("alice")); got != 1 {
+		t.Errorf("GetByFromNode(alice) after remove = %d items, want 1", got)
+	}
+
+	// 同じIDで置き換えると古い From/To のインデックスは消える
+	pool.Add(newTestPending("id2", "dave", "erin"))
+	if got := len(pool.GetByFromNode("alice")); got != 0 {
+		t.Errorf("GetByFromNode(alice) after replace = %d items, want 0", got)
+	}
+	if got := len(pool.GetByToNode("carol")); got != 0 {
+		t.Errorf("GetByToNode(carol) after replace = %d items, want 0", got)
+	}
+	if got := pool.GetByToNode("erin"); len(got) != 1 || got[0].ID != "id2" {
+		t.Errorf("GetByToNode(erin) after replace = %v, want [id2]", got)
+	}
+
+	// 存在しないIDの削除は何もしない
+	pool.Remove("nonexistent")
+	if got := len(pool.GetByToNode("bob")); got != 1 {
+		t.Errorf("GetByToNode(bob) after no-op remove = %d items, want 1", got)
+	}
+
+	// Clear でインデックスも空になる
+	pool.Clear()
+	if got := len(pool.GetByToNode("bob")); got != 0 {
+		t.Errorf("GetByToNode(bob) after clear = %d items, want 0", got)
+	}
+	if got := len(pool.GetByFromNode("dave")); got != 0 {
+		t.Errorf("GetByFromNode(dave) after clear = %d items, want 0", got)
+	}
+}
+
+func TestPendingPool_GetByFromNode(t *testing.T) {
+	pool := NewPendingPool()
+	pool.Add(newTestPending("id1", "node1", "node2"))
+	pool.Add(newTestPending("id2", "node3", "node2"))
+
+	results := pool.GetByFromNode("node1")
+	if len(results) != 1 || results[0].ID != "id1" {
+		t.Errorf("GetByFromNode(node1) = %v, want [id1]", results)
+	}
+	if got := len(pool.GetByFromNode("nonexistent")); got != 0 {
+		t.Errorf("GetByFromNode(nonexistent) = %d items, want 0", got)
+	}
+}
+
+func BenchmarkPendingPool_GetByToNode(b *testing.B) {
+	pool := NewPendingPool()
+	for i := 0; i < 10000; i++ {
+		to := fmt.Sprintf("node%d", i%100)
+		pool.Add(newTestPending(fmt.Sprintf("id%d", i), "alice", to))
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = pool.GetByToNode("node42")
+	}
+}
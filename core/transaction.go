@@ -1,5 +1,16 @@
 package core
 
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidTitle は取引のタイトルが長すぎる・制御文字を含むことを表す
+var ErrInvalidTitle = errors.New("invalid title")
+
 // TransactionData は金銭的取引のデータを表す
 type TransactionData struct {
 	From   string `json:"from"`
@@ -8,6 +19,43 @@ type TransactionData struct {
 	Title  string `json:"title"`
 }
 
+// Canonical はトランザクションの正規化バイト列を返す（署名・ハッシュの対象）
+// キーを辞書順（amount, from, title, to）に並べた空白なしの JSON で、文字列は encoding/json と同じ規則でエスケープする。
+// 構造体のフィールド順やフィールド追加に影響されないよう、対象キーを明示して組み立てる
+func (tx *TransactionData) Canonical() []byte {
+	// map のキーは encoding/json により辞書順で出力される
+	data, _ := json.Marshal(map[string]any{
+		"amount": tx.Amount,
+		"from":   tx.From,
+		"title":  tx.Title,
+		"to":     tx.To,
+	})
+	return data
+}
+
+// Hash は Canonical() の SHA-256 を hex で返す
+func (tx *TransactionData) Hash() string {
+	return CalcSHA256(string(tx.Canonical()))
+}
+
+// Validate はタイトルを検証する
+// maxTitleLength（文字数。0 以下なら上限なし）を超えるタイトルと、改行・NUL などの制御文字を含むタイトルは ErrInvalidTitle を返す
+// タイトルはチェーンに残り続け、ログや表示にそのまま出るため、提案とブロック受信の両方で検証する
+func (tx *TransactionData) Validate(maxTitleLength int) error {
+	if n := utf8.RuneCountInString(tx.Title); maxTitleLength > 0 && n > maxTitleLength {
+		return fmt.Errorf("%w: %d characters exceeds the limit of %d", ErrInvalidTitle, n, maxTitleLength)
+	}
+	if !utf8.ValidString(tx.Title) {
+		return fmt.Errorf("%w: not valid UTF-8", ErrInvalidTitle)
+	}
+	for i, r := range tx.Title {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("%w: control character %U at byte %d", ErrInvalidTitle, r, i)
+		}
+	}
+	return nil
+}
+
 // AddNodeData はノード追加のデータを表す
 type AddNodeData struct {
 	PublicKey string `json:"public_key"`
@@ -15,3 +63,34 @@ type AddNodeData struct {
 	NickName  string `json:"nick_name"`
 	Address   string `json:"address"`
 }
+
+// AmountPolicy は取引金額の許容範囲を表す
+// Min が 1 未満の場合は 1、Max が 0 以下の場合は上限なしとして扱う
+type AmountPolicy struct {
+	Min int64
+	Max int64
+}
+
+// AmountError は金額が AmountPolicy の範囲外であることを表すエラー
+type AmountError struct {
+	Amount int64
+	Min    int64
+	Max    int64 // 0 は上限なし
+}
+
+func (e *AmountError) Error() string {
+	if e.Max > 0 {
+		return fmt.Sprintf("amount %d is out of range [%d, %d]", e.Amount, e.Min, e.Max)
+	}
+	return fmt.Sprintf("amount %d is below minimum %d", e.Amount, e.Min)
+}
+
+// Check は amount が許容範囲内かを検証し、範囲外なら *AmountError を返す
+func (p AmountPolicy) Check(amount int64) error {
+	minAmount := max(p.Min, 1)
+	maxAmount := max(p.Max, 0)
+	if amount < minAmount || (maxAmount > 0 && amount > maxAmount) {
+		return &AmountError{Amount: amount, Min: minAmount, Max: maxAmount}
+	}
+	return nil
+}
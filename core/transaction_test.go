@@ -2,6 +2,8 @@ package core
 
 import (
 	"encoding/json"
+	"errors"
+	"strings"
 	"testing"
 )
 
@@ -68,3 +70,93 @@ func TestAddNodeData_JSON(t *testing.T) {
 		t.Errorf("Address = %q, want %q", decoded.Address, data.Address)
 	}
 }
+
+func TestTransactionData_Canonical(t *testing.T) {
+	tx := &TransactionData{From: "node1", To: "node2", Amount: 5000, Title: "飲み会<&>"}
+
+	want := `{"amount":5000,"from":"node1","title":"飲み会\u003c\u0026\u003e","to":"node2"}`
+	if got := string(tx.Canonical()); got != want {
+		t.Errorf("Canonical() = %s, want %s", got, want)
+	}
+
+	// 同じ内容なら何度呼んでも同じバイト列・同じハッシュになる
+	same := &TransactionData{Title: tx.Title, Amount: tx.Amount, To: tx.To, From: tx.From}
+	if string(same.Canonical()) != string(tx.Canonical()) {
+		t.Error("Canonical() is not stable for equal transactions")
+	}
+	if same.Hash() != tx.Hash() {
+		t.Error("Hash() is not stable for equal transactions")
+	}
+
+	other := *tx
+	other.Amount = 5001
+	if other.Hash() == tx.Hash() {
+		t.Error("Hash() should differ for different transactions")
+	}
+}
+
+func TestAmountPolicy_Check(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  AmountPolicy
+		amount  int64
+		wantErr bool
+	}{
+		{"in range", AmountPolicy{Min: 10, Max: 1000}, 500, false},
+		{"at min", AmountPolicy{Min: 10, Max: 1000}, 10, false},
+		{"at max", AmountPolicy{Min: 10, Max: 1000}, 1000, false},
+		{"below min", AmountPolicy{Min: 10, Max: 1000}, 9, true},
+		{"above max", AmountPolicy{Min: 10, Max: 1000}, 1001, true},
+		{"default allows large amounts", AmountPolicy{}, 1 << 40, false},
+		{"default rejects zero", AmountPolicy{}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Check(tt.amount)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check(%d) error = %v, wantErr %v", tt.amount, err, tt.wantErr)
+			}
+			if err != nil {
+				var amountErr *AmountError
+				if !errors.As(err, &amountErr) {
+					t.Fatalf("error type = %T, want *AmountError", err)
+				}
+				if amountErr.Amount != tt.amount {
+					t.Errorf("AmountError.Amount = %d, want %d", amountErr.Amount, tt.amount)
+				}
+			}
+		})
+	}
+}
+
+func TestTransactionData_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		title   string
+		max     int
+		wantErr bool
+	}{
+		{"within limit", "ランチ代", 4, false},
+		{"limit counts characters not bytes", strings.Repeat("あ", 256), 256, false},
+		{"over limit", strings.Repeat("a", 257), 256, true},
+		{"no limit", strings.Repeat("a", 10000), 0, false},
+		{"newline", "lunch\ndinner", 0, true},
+		{"tab", "lunch\tdinner", 0, true},
+		{"NUL", "lunch\x00", 0, true},
+		{"invalid UTF-8", "lunch\xff", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx := &TransactionData{From: "alice", To: "bob", Amount: 100, Title: tt.title}
+			err := tx.Validate(tt.max)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidTitle) {
+				t.Errorf("Validate() error = %v, want ErrInvalidTitle", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+var (
+	// ErrSignatureEncoding は署名が Base64 として不正、またはデコード後の長さが不正であることを表す
+	ErrSignatureEncoding = errors.New("malformed signature encoding")
+	// ErrSignatureMismatch は署名の形式は正しいが、公開鍵・メッセージと一致しないことを表す
+	ErrSignatureMismatch = errors.New("signature does not match")
+)
+
+// batchParallelThreshold 未満の件数では goroutine を起動せず逐次検証する
+const batchParallelThreshold = 64
+
+// BatchItem は一括検証する署名1件を表す
+type BatchItem struct {
+	PubKey    ed25519.PublicKey
+	Message   []byte
+	Signature string // Base64エンコードされた署名
+	Fallback  []byte // Message で検証できなかった場合に受け入れる代替メッセージ（nil なら使わない）
+}
+
+// VerifyBatch は複数の署名をまとめて検証し、検証に失敗した要素のインデックスを昇順で返す
+// 標準ライブラリに ed25519 のバッチ検証はないため、CPU 数分のワーカーで並列に検証する
+func VerifyBatch(items []BatchItem) []int {
+	ok := make([]bool, len(items))
+
+	workers := runtime.GOMAXPROCS(0)
+	if len(items) < batchParallelThreshold || workers == 1 {
+		for i := range items {
+			ok[i] = verifyItem(&items[i])
+		}
+	} else {
+		var wg sync.WaitGroup
+		chunk := (len(items) + workers - 1) / workers
+		for start := 0; start < len(items); start += chunk {
+			end := min(start+chunk, len(items))
+			wg.Add(1)
+			go func(start, end int) {
+				defer wg.Done()
+				for i := start; i < end; i++ {
+					ok[i] = verifyItem(&items[i])
+				}
+			}(start, end)
+		}
+		wg.Wait()
+	}
+
+	var failed []int
+	for i, valid := range ok {
+		if !valid {
+			failed = append(failed, i)
+		}
+	}
+	return failed
+}
+
+// verifyItem は1件の署名を検証する。公開鍵や署名の長さが不正な場合は false を返す
+func verifyItem(item *BatchItem) bool {
+	return item.Check() == nil
+}
+
+// Check は署名1件を検証し、失敗の原因を区別したエラーを返す
+// 公開鍵が不正なら ErrInvalidPublicKey、署名の Base64・長さが不正なら ErrSignatureEncoding、
+// 形式は正しいが一致しなければ ErrSignatureMismatch を（errors.Is で判定できる形で）返す
+func (item *BatchItem) Check() error {
+	if len(item.PubKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w: size %d", ErrInvalidPublicKey, len(item.PubKey))
+	}
+	signature, err := base64.StdEncoding.DecodeString(item.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrSignatureEncoding, err)
+	}
+	if len(signature) != ed25519.SignatureSize {
+		return fmt.Errorf("%w: size %d", ErrSignatureEncoding, len(signature))
+	}
+	if ed25519.VerifyWithOptions(item.PubKey, item.Message, signature, &ed25519.Options{}) == nil {
+		return nil
+	}
+	if item.Fallback != nil && ed25519.VerifyWithOptions(item.PubKey, item.Fallback, signature, &ed25519.Options{}) == nil {
+		return nil
+	}
+	return ErrSignatureMismatch
+}
@@ -0,0 +1,107 @@
+package crypto
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// newBatchItems は count 件の正しい署名を持つ BatchItem を作成する
+func newBatchItems(t testing.TB, count int) ([]BatchItem, ed25519.PrivateKey) {
+	t.Helper()
+	pub, priv, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+
+	items := make([]BatchItem, count)
+	for i := range items {
+		msg := []byte(fmt.Sprintf("message-%d", i))
+		items[i] = BatchItem{PubKey: pub, Message: msg, Signature: Sign(priv, msg)}
+	}
+	return items, priv
+}
+
+func TestVerifyBatch(t *testing.T) {
+	for _, count := range []int{10, 500} {
+		t.Run(fmt.Sprintf("%d items", count), func(t *testing.T) {
+			items, _ := newBatchItems(t, count)
+			if failed := VerifyBatch(items); len(failed) != 0 {
+				t.Fatalf("VerifyBatch flagged valid items: %v", failed)
+			}
+
+			// 1件だけ別メッセージの署名に差し替える
+			forged := count / 2
+			items[forged].Signature = items[forged+1].Signature
+
+			failed := VerifyBatch(items)
+			if len(failed) != 1 || failed[0] != forged {
+				t.Errorf("VerifyBatch failed = %v, want [%d]", failed, forged)
+			}
+		})
+	}
+}
+
+func TestVerifyBatch_Malformed(t *testing.T) {
+	items, _ := newBatchItems(t, 3)
+	items[0].Signature = "not-base64!"
+	items[2].PubKey = []byte("short")
+
+	failed := VerifyBatch(items)
+	if len(failed) != 2 || failed[0] != 0 || failed[1] != 2 {
+		t.Errorf("VerifyBatch failed = %v, want [0 2]", failed)
+	}
+}
+
+func TestBatchItemCheck(t *testing.T) {
+	items, _ := newBatchItems(t, 1)
+	valid := items[0]
+
+	if err := valid.Check(); err != nil {
+		t.Fatalf("Check() error = %v for a valid signature", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(item *BatchItem)
+		want   error
+	}{
+		{"bad base64", func(item *BatchItem) { item.Signature = "%%%" }, ErrSignatureEncoding},
+		{"bad signature length", func(item *BatchItem) { item.Signature = base64.StdEncoding.EncodeToString([]byte("short")) }, ErrSignatureEncoding},
+		{"bad public key", func(item *BatchItem) { item.PubKey = item.PubKey[:10] }, ErrInvalidPublicKey},
+		{"tampered message", func(item *BatchItem) { item.Message = []byte("tampered") }, ErrSignatureMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := valid
+			tt.modify(&item)
+			if err := item.Check(); !errors.Is(err, tt.want) {
+				t.Errorf("Check() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := HexToPublicKey("zz"); !errors.Is(err, ErrInvalidPublicKey) {
+		t.Errorf("HexToPublicKey() error = %v, want ErrInvalidPublicKey", err)
+	}
+}
+
+func BenchmarkVerifyBatch(b *testing.B) {
+	items, _ := newBatchItems(b, 1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		VerifyBatch(items)
+	}
+}
+
+func BenchmarkVerifySerial(b *testing.B) {
+	items, _ := newBatchItems(b, 1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, item := range items {
+			Verify(item.PubKey, item.Message, item.Signature)
+		}
+	}
+}
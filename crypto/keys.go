@@ -1,14 +1,30 @@
 package crypto
 
 import (
+	"crypto/aes"
+	"crypto/cipher"
 	"crypto/ed25519"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/pem"
+	"errors"
 	"fmt"
 	"os"
+	"strconv"
 )
 
+// ErrInvalidPublicKey は公開鍵のデコード（hex・長さ）に失敗したことを表す
+var ErrInvalidPublicKey = errors.New("invalid public key")
+
+// ErrEncryptedPrivateKey は暗号化された秘密鍵ファイルを LoadPrivateKey で読もうとしたことを表す
+var ErrEncryptedPrivateKey = errors.New("private key is encrypted; use LoadPrivateKeyEncrypted with the passphrase")
+
+// ErrIncorrectPassphrase はパスフレーズが誤っているか、暗号化された秘密鍵が改ざんされていることを表す
+var ErrIncorrectPassphrase = errors.New("incorrect passphrase or corrupted private key")
+
 // GenerateKeyPair はEd25519の鍵ペアを生成する
 func GenerateKeyPair() (ed25519.PublicKey, ed25519.PrivateKey, error) {
 	return ed25519.GenerateKey(nil)
@@ -25,7 +41,7 @@ func SavePrivateKey(path string, key ed25519.PrivateKey) error {
 
 	// PEM形式で保存（人間が識別しやすくするため）
 	block := &pem.Block{
-		Type:  "ED25519 PRIVATE KEY",
+		Type:  pemTypePrivateKey,
 		Bytes: []byte(encoded),
 	}
 
@@ -60,42 +76,179 @@ func SavePrivateKeyRaw(path string, key ed25519.PrivateKey) error {
 	return nil
 }
 
+// 暗号化した秘密鍵の PEM とその鍵導出パラメータ
+const (
+	pemTypePrivateKey          = "ED25519 PRIVATE KEY"
+	pemTypeEncryptedPrivateKey = "ENCRYPTED ED25519 PRIVATE KEY"
+
+	encryptedKeyKDF        = "pbkdf2-sha256"
+	encryptedKeyIterations = 600000
+	encryptedKeySaltSize   = 16
+)
+
+// SavePrivateKeyEncrypted は秘密鍵をパスフレーズで暗号化して PEM 形式で保存する
+// パスフレーズから PBKDF2-SHA256 で鍵を導出して AES-256-GCM で暗号化し、ソルトと nonce は PEM ヘッダーに記録する
+func SavePrivateKeyEncrypted(path string, key ed25519.PrivateKey, passphrase []byte) error {
+	if len(key) != ed25519.PrivateKeySize {
+		return fmt.Errorf("invalid private key size: %d", len(key))
+	}
+	if len(passphrase) == 0 {
+		return fmt.Errorf("passphrase is required")
+	}
+
+	salt := make([]byte, encryptedKeySaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return fmt.Errorf("failed to generate salt: %w", err)
+	}
+	gcm, err := newKeyCipher(passphrase, salt, encryptedKeyIterations)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	block := &pem.Block{
+		Type: pemTypeEncryptedPrivateKey,
+		Headers: map[string]string{
+			"KDF":        encryptedKeyKDF,
+			"Iterations": strconv.Itoa(encryptedKeyIterations),
+			"Salt":       base64.StdEncoding.EncodeToString(salt),
+			"Nonce":      base64.StdEncoding.EncodeToString(nonce),
+		},
+		Bytes: gcm.Seal(nil, nonce, key, nil),
+	}
+
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+		return fmt.Errorf("failed to write private key file: %w", err)
+	}
+
+	return nil
+}
+
+// LoadPrivateKeyEncrypted は SavePrivateKeyEncrypted で保存した秘密鍵をパスフレーズで復号して読み込む
+// パスフレーズが誤っていれば ErrIncorrectPassphrase を返す
+func LoadPrivateKeyEncrypted(path string, passphrase []byte) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read private key file: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != pemTypeEncryptedPrivateKey {
+		return nil, fmt.Errorf("private key file is not an encrypted PEM")
+	}
+	if kdf := block.Headers["KDF"]; kdf != encryptedKeyKDF {
+		return nil, fmt.Errorf("unsupported key derivation: %q", kdf)
+	}
+	iterations, err := strconv.Atoi(block.Headers["Iterations"])
+	if err != nil || iterations <= 0 {
+		return nil, fmt.Errorf("invalid iterations: %q", block.Headers["Iterations"])
+	}
+	salt, err := base64.StdEncoding.DecodeString(block.Headers["Salt"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode salt: %w", err)
+	}
+	nonce, err := base64.StdEncoding.DecodeString(block.Headers["Nonce"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode nonce: %w", err)
+	}
+
+	gcm, err := newKeyCipher(passphrase, salt, iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return nil, fmt.Errorf("invalid nonce size: %d", len(nonce))
+	}
+	key, err := gcm.Open(nil, nonce, block.Bytes, nil)
+	if err != nil {
+		return nil, ErrIncorrectPassphrase
+	}
+
+	if len(key) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("invalid private key size: %d", len(key))
+	}
+
+	return ed25519.PrivateKey(key), nil
+}
+
+// newKeyCipher はパスフレーズとソルトから導出した鍵で AES-256-GCM を作る
+func newKeyCipher(passphrase, salt []byte, iterations int) (cipher.AEAD, error) {
+	derived, err := pbkdf2.Key(sha256.New, string(passphrase), salt, iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	block, err := aes.NewCipher(derived)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return gcm, nil
+}
+
+// 秘密鍵ファイルの形式
+const (
+	KeyFormatPEM = "pem" // SavePrivateKey で保存した PEM 形式
+	KeyFormatRaw = "raw" // SavePrivateKeyRaw で保存した生の Base64 形式
+)
+
 // LoadPrivateKey はファイルから秘密鍵を読み込む
 func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
-	// まずPEM形式を試みる
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read private key file: %w", err)
 	}
 
-	// PEMデコードを試みる
-	block, _ := pem.Decode(data)
-	if block != nil && block.Type == "ED25519 PRIVATE KEY" {
-		// PEM形式
-		encoded := string(block.Bytes)
-		key, err := base64.StdEncoding.DecodeString(encoded)
-		if err != nil {
-			return nil, fmt.Errorf("failed to decode base64 private key: %w", err)
-		}
+	_, key, err := decodePrivateKey(data)
+	return key, err
+}
 
-		if len(key) != ed25519.PrivateKeySize {
-			return nil, fmt.Errorf("invalid private key size: %d", len(key))
-		}
+// InspectKeyFile は秘密鍵ファイルの形式と、対応する公開鍵を返す（秘密鍵自体は返さない）
+// 稼働中のノードに読み込ませずに鍵ファイルを確認するために使う
+func InspectKeyFile(path string) (format string, pubKey ed25519.PublicKey, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read private key file: %w", err)
+	}
 
-		return ed25519.PrivateKey(key), nil
+	format, key, err := decodePrivateKey(data)
+	if err != nil {
+		return "", nil, err
 	}
+	return format, GetPublicKeyFromPrivateKey(key), nil
+}
 
-	// 生のBase64形式として試みる
-	key, err := base64.StdEncoding.DecodeString(string(data))
+// decodePrivateKey は秘密鍵ファイルの内容をデコードし、形式と秘密鍵を返す
+// まず PEM 形式を試み、PEM でなければ生の Base64 形式として扱う
+// 暗号化された PEM であれば ErrEncryptedPrivateKey を返す
+func decodePrivateKey(data []byte) (string, ed25519.PrivateKey, error) {
+	format := KeyFormatRaw
+	encoded := string(data)
+
+	block, _ := pem.Decode(data)
+	if block != nil && block.Type == pemTypeEncryptedPrivateKey {
+		return "", nil, ErrEncryptedPrivateKey
+	}
+	if block != nil && block.Type == pemTypePrivateKey {
+		format = KeyFormatPEM
+		encoded = string(block.Bytes)
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode base64 private key: %w", err)
+		return "", nil, fmt.Errorf("failed to decode base64 private key: %w", err)
 	}
 
 	if len(key) != ed25519.PrivateKeySize {
-		return nil, fmt.Errorf("invalid private key size: %d", len(key))
+		return "", nil, fmt.Errorf("invalid private key size: %d", len(key))
 	}
 
-	return ed25519.PrivateKey(key), nil
+	return format, ed25519.PrivateKey(key), nil
 }
 
 // PublicKeyToBase64 は公開鍵をBase64エンコードして文字列にする
@@ -145,11 +298,11 @@ func GetPublicKeyFromPrivateKey(priv ed25519.PrivateKey) ed25519.PublicKey {
 func HexToPublicKey(s string) (ed25519.PublicKey, error) {
 	data, err := hex.DecodeString(s)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode hex public key: %w", err)
+		return nil, fmt.Errorf("%w: failed to decode hex: %v", ErrInvalidPublicKey, err)
 	}
 
 	if len(data) != ed25519.PublicKeySize {
-		return nil, fmt.Errorf("invalid public key size: %d", len(data))
+		return nil, fmt.Errorf("%w: size %d", ErrInvalidPublicKey, len(data))
 	}
 
 	return ed25519.PublicKey(data), nil
@@ -2,8 +2,10 @@ package crypto
 
 import (
 	"crypto/ed25519"
+	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -199,6 +201,72 @@ func TestSavePrivateKeyPEM_LoadPrivateKey(t *testing.T) {
 	}
 }
 
+func TestSavePrivateKeyEncrypted_LoadPrivateKeyEncrypted(t *testing.T) {
+	tmpDir := t.TempDir()
+	keyPath := filepath.Join(tmpDir, "test_key.pem")
+	passphrase := []byte("correct horse battery staple")
+
+	_, priv, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+
+	if err := SavePrivateKeyEncrypted(keyPath, priv, passphrase); err != nil {
+		t.Fatalf("SavePrivateKeyEncrypted failed: %v", err)
+	}
+
+	// 平文の鍵がファイルに含まれていないこと
+	data, err := os.ReadFile(keyPath)
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if strings.Contains(string(data), PrivateKeyToBase64(priv)) {
+		t.Error("Encrypted key file contains the plaintext key")
+	}
+
+	loaded, err := LoadPrivateKeyEncrypted(keyPath, passphrase)
+	if err != nil {
+		t.Fatalf("LoadPrivateKeyEncrypted failed: %v", err)
+	}
+	if string(loaded) != string(priv) {
+		t.Error("Loaded private key does not match original")
+	}
+
+	// 暗号化されていない読み込みは専用のエラーで案内する
+	if _, err := LoadPrivateKey(keyPath); !errors.Is(err, ErrEncryptedPrivateKey) {
+		t.Errorf("LoadPrivateKey error = %v, want ErrEncryptedPrivateKey", err)
+	}
+	if _, _, err := InspectKeyFile(keyPath); !errors.Is(err, ErrEncryptedPrivateKey) {
+		t.Errorf("InspectKeyFile error = %v, want ErrEncryptedPrivateKey", err)
+	}
+}
+
+func TestLoadPrivateKeyEncrypted_WrongPassphrase(t *testing.T) {
+	tmpDir := t.TempDir()
+	keyPath := filepath.Join(tmpDir, "test_key.pem")
+
+	_, priv, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+	if err := SavePrivateKeyEncrypted(keyPath, priv, []byte("secret")); err != nil {
+		t.Fatalf("SavePrivateKeyEncrypted failed: %v", err)
+	}
+
+	if _, err := LoadPrivateKeyEncrypted(keyPath, []byte("wrong")); !errors.Is(err, ErrIncorrectPassphrase) {
+		t.Errorf("LoadPrivateKeyEncrypted error = %v, want ErrIncorrectPassphrase", err)
+	}
+
+	// 暗号化されていない鍵ファイルは受け付けない
+	plainPath := filepath.Join(tmpDir, "plain_key.pem")
+	if err := SavePrivateKey(plainPath, priv); err != nil {
+		t.Fatalf("SavePrivateKey failed: %v", err)
+	}
+	if _, err := LoadPrivateKeyEncrypted(plainPath, []byte("secret")); err == nil {
+		t.Error("Expected error for unencrypted key file, got nil")
+	}
+}
+
 func TestLoadPrivateKey_FileNotFound(t *testing.T) {
 	_, err := LoadPrivateKey("/nonexistent/path/key.priv")
 	if err == nil {
@@ -206,6 +274,60 @@ func TestLoadPrivateKey_FileNotFound(t *testing.T) {
 	}
 }
 
+func TestInspectKeyFile(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	pub, priv, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		save   func(path string, key ed25519.PrivateKey) error
+		format string
+	}{
+		{"PEM", SavePrivateKey, KeyFormatPEM},
+		{"raw", SavePrivateKeyRaw, KeyFormatRaw},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyPath := filepath.Join(tmpDir, tt.name+".priv")
+			if err := tt.save(keyPath, priv); err != nil {
+				t.Fatalf("save failed: %v", err)
+			}
+
+			format, gotPub, err := InspectKeyFile(keyPath)
+			if err != nil {
+				t.Fatalf("InspectKeyFile failed: %v", err)
+			}
+			if format != tt.format {
+				t.Errorf("format = %s, want %s", format, tt.format)
+			}
+			if !gotPub.Equal(pub) {
+				t.Error("public key does not match original")
+			}
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		keyPath := filepath.Join(tmpDir, "invalid.priv")
+		if err := os.WriteFile(keyPath, []byte("not a key"), 0600); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+		if _, _, err := InspectKeyFile(keyPath); err == nil {
+			t.Error("Expected error for invalid key file, got nil")
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		if _, _, err := InspectKeyFile(filepath.Join(tmpDir, "missing.priv")); err == nil {
+			t.Error("Expected error for non-existent file, got nil")
+		}
+	})
+}
+
 func TestSavePrivateKey_InvalidSize(t *testing.T) {
 	tmpDir := t.TempDir()
 	keyPath := filepath.Join(tmpDir, "invalid_key.priv")
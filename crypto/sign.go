@@ -3,7 +3,6 @@ package crypto
 import (
 	"crypto/ed25519"
 	"encoding/base64"
-	"encoding/json"
 	"fmt"
 
 	"signet/core"
@@ -25,26 +24,14 @@ func Verify(pubKey ed25519.PublicKey, data []byte, signatureBase64 string) bool
 	return ed25519.Verify(pubKey, data, signature)
 }
 
-// MakeSigningPayload は署名対象のペイロードバイト列を作成する
-// Type + Data をJSON直列化して連結
+// MakeSigningPayload は署名対象のペイロードバイト列を作成する（core.MakeSigningPayload と同じ）
+// Type + Data（CanonicalJSON で正規化したもの）をJSON直列化して連結するため、Data の整形やキー順に影響されない
 func MakeSigningPayload(payload *core.BlockPayload) ([]byte, error) {
-	typeData := struct {
-		Type string          `json:"type"`
-		Data json.RawMessage `json:"data"`
-	}{
-		Type: payload.Type,
-		Data: payload.Data,
-	}
-
-	jsonData, err := json.Marshal(typeData)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal signing payload: %w", err)
-	}
-
-	return jsonData, nil
+	return core.MakeSigningPayload(payload)
 }
 
 // SignPayload はBlockPayloadに署名する
+// 注意: トランザクションの From/To 署名には使わないこと（SignTransaction を使う）
 func SignPayload(privKey ed25519.PrivateKey, payload *core.BlockPayload) (string, error) {
 	signingData, err := MakeSigningPayload(payload)
 	if err != nil {
@@ -55,6 +42,7 @@ func SignPayload(privKey ed25519.PrivateKey, payload *core.BlockPayload) (string
 }
 
 // VerifyPayloadSignature はペイロードの署名を検証する
+// 注意: トランザクションの From/To 署名の検証には VerifyTransactionSignature を使う
 func VerifyPayloadSignature(pubKey ed25519.PublicKey, payload *core.BlockPayload, signatureBase64 string) bool {
 	signingData, err := MakeSigningPayload(payload)
 	if err != nil {
@@ -64,9 +52,26 @@ func VerifyPayloadSignature(pubKey ed25519.PublicKey, payload *core.BlockPayload
 	return Verify(pubKey, signingData, signatureBase64)
 }
 
-// SignTransaction はトランザクションデータに署名する
+// TransactionSigningPayload はトランザクション署名の対象バイト列を返す
+// From 署名・To 署名ともに TransactionData.Canonical()（キー辞書順の JSON）に対して行う。
+// MakeSigningPayload の {type,data} 形式とは異なるため混在させないこと
+func TransactionSigningPayload(tx *core.TransactionData) ([]byte, error) {
+	return tx.Canonical(), nil
+}
+
+// LegacyTransactionSigningPayload は Canonical 導入前の署名対象（構造体のフィールド順の JSON）を返す
+// 導入前に作成されたチェーン上の署名を検証するためだけに使い、新たな署名には使わないこと
+func LegacyTransactionSigningPayload(tx *core.TransactionData) ([]byte, error) {
+	data, err := core.MarshalTransactionData(tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal transaction data: %w", err)
+	}
+	return data, nil
+}
+
+// SignTransaction はトランザクションデータに署名する（From/To 署名の唯一の生成方法）
 func SignTransaction(privKey ed25519.PrivateKey, tx *core.TransactionData) (string, error) {
-	data, err := json.Marshal(tx)
+	data, err := TransactionSigningPayload(tx)
 	if err != nil {
 		return "", err
 	}
@@ -74,14 +79,38 @@ func SignTransaction(privKey ed25519.PrivateKey, tx *core.TransactionData) (stri
 	return Sign(privKey, data), nil
 }
 
-// VerifyTransactionSignature はトランザクションの署名を検証する
+// VerifyTransactionSignature はトランザクションの署名を検証する（From/To 署名の唯一の検証方法）
+// Canonical 導入前の署名も受け入れる
 func VerifyTransactionSignature(pubKey ed25519.PublicKey, tx *core.TransactionData, signatureBase64 string) bool {
-	data, err := json.Marshal(tx)
+	data, err := TransactionSigningPayload(tx)
 	if err != nil {
 		return false
 	}
+	if Verify(pubKey, data, signatureBase64) {
+		return true
+	}
+
+	legacy, err := LegacyTransactionSigningPayload(tx)
+	if err != nil {
+		return false
+	}
+	return Verify(pubKey, legacy, signatureBase64)
+}
+
+// ExpirySigningPayload は期限切れ通知の署名対象（To ノードが取引の From 署名に対して行う）を返す
+// 取引そのものへの To 署名と取り違えられないよう、接頭辞を付けて区別する
+func ExpirySigningPayload(fromSignature string) []byte {
+	return []byte("signet-expired:" + fromSignature)
+}
+
+// SignExpiry は From 署名が fromSignature の取引の期限切れ通知に署名する
+func SignExpiry(privKey ed25519.PrivateKey, fromSignature string) string {
+	return Sign(privKey, ExpirySigningPayload(fromSignature))
+}
 
-	return Verify(pubKey, data, signatureBase64)
+// VerifyExpirySignature は期限切れ通知の署名を検証する
+func VerifyExpirySignature(pubKey ed25519.PublicKey, fromSignature, signatureBase64 string) bool {
+	return Verify(pubKey, ExpirySigningPayload(fromSignature), signatureBase64)
 }
 
 // SignData は生データに署名するヘルパー関数
@@ -166,6 +166,40 @@ func TestSignPayload_VerifyPayloadSignature(t *testing.T) {
 	}
 }
 
+func TestSignPayload_CanonicalData(t *testing.T) {
+	pub, priv, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+
+	payload := &core.BlockPayload{
+		Type: "transaction",
+		Data: json.RawMessage(`{"from":"node1","to":"node2","amount":1000,"title":"test"}`),
+	}
+	signature, err := SignPayload(priv, payload)
+	if err != nil {
+		t.Fatalf("SignPayload failed: %v", err)
+	}
+
+	// キー順や空白が違っても同じ内容なら署名は有効
+	reordered := &core.BlockPayload{
+		Type: "transaction",
+		Data: json.RawMessage(`{ "title": "test", "amount": 1000, "to": "node2", "from": "node1" }`),
+	}
+	if !VerifyPayloadSignature(pub, reordered, signature) {
+		t.Error("VerifyPayloadSignature failed for reformatted payload data")
+	}
+
+	// 不正な JSON は署名対象を作れない
+	invalid := &core.BlockPayload{Type: "transaction", Data: json.RawMessage(`{"from":`)}
+	if _, err := SignPayload(priv, invalid); err == nil {
+		t.Error("SignPayload should fail for invalid payload data")
+	}
+	if VerifyPayloadSignature(pub, invalid, signature) {
+		t.Error("VerifyPayloadSignature should fail for invalid payload data")
+	}
+}
+
 func TestSignTransaction_VerifyTransactionSignature(t *testing.T) {
 	pub, priv, err := GenerateKeyPair()
 	if err != nil {
@@ -203,6 +237,102 @@ func TestSignTransaction_VerifyTransactionSignature(t *testing.T) {
 	}
 }
 
+func TestTransactionSigningPayload(t *testing.T) {
+	pub, priv, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+
+	tx := &core.TransactionData{From: "node1", To: "node2", Amount: 5000, Title: "dinner"}
+
+	payload, err := TransactionSigningPayload(tx)
+	if err != nil {
+		t.Fatalf("TransactionSigningPayload failed: %v", err)
+	}
+
+	// 署名対象は Canonical() のバイト列であること
+	if string(payload) != string(tx.Canonical()) {
+		t.Errorf("signing payload = %s, want canonical %s", payload, tx.Canonical())
+	}
+	blockData, err := core.SetTransactionData(tx)
+	if err != nil {
+		t.Fatalf("SetTransactionData failed: %v", err)
+	}
+
+	// SignTransaction の署名は生のペイロードに対する Verify でも検証できる
+	signature, err := SignTransaction(priv, tx)
+	if err != nil {
+		t.Fatalf("SignTransaction failed: %v", err)
+	}
+	if !Verify(pub, payload, signature) {
+		t.Error("Verify failed for SignTransaction signature")
+	}
+
+	// {type,data} 形式の署名はトランザクション署名として通らない
+	payloadSig, err := SignPayload(priv, &core.BlockPayload{Type: "transaction", Data: blockData})
+	if err != nil {
+		t.Fatalf("SignPayload failed: %v", err)
+	}
+	if VerifyTransactionSignature(pub, tx, payloadSig) {
+		t.Error("VerifyTransactionSignature should reject a {type,data} payload signature")
+	}
+}
+
+func TestSignExpiry_VerifyExpirySignature(t *testing.T) {
+	pub, priv, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+
+	tx := &core.TransactionData{From: "node1", To: "node2", Amount: 5000, Title: "dinner"}
+	fromSig, _ := SignTransaction(priv, tx)
+
+	sig := SignExpiry(priv, fromSig)
+	if !VerifyExpirySignature(pub, fromSig, sig) {
+		t.Error("VerifyExpirySignature failed for valid signature")
+	}
+	if VerifyExpirySignature(pub, "other", sig) {
+		t.Error("VerifyExpirySignature should fail for a different from signature")
+	}
+
+	// 取引への署名と期限切れ通知の署名は互いに通らない
+	if VerifyTransactionSignature(pub, tx, sig) {
+		t.Error("VerifyTransactionSignature should reject an expiry signature")
+	}
+	toSig, _ := SignTransaction(priv, tx)
+	if VerifyExpirySignature(pub, fromSig, toSig) {
+		t.Error("VerifyExpirySignature should reject a transaction signature")
+	}
+}
+
+func TestVerifyTransactionSignature_Legacy(t *testing.T) {
+	pub, priv, err := GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair failed: %v", err)
+	}
+
+	tx := &core.TransactionData{From: "node1", To: "node2", Amount: 5000, Title: "dinner"}
+
+	// Canonical 導入前の形式（構造体フィールド順の JSON）で作られた署名も検証できる
+	legacy, err := LegacyTransactionSigningPayload(tx)
+	if err != nil {
+		t.Fatalf("LegacyTransactionSigningPayload failed: %v", err)
+	}
+	legacySig := Sign(priv, legacy)
+	if !VerifyTransactionSignature(pub, tx, legacySig) {
+		t.Error("VerifyTransactionSignature should accept a legacy signature")
+	}
+
+	items := []BatchItem{{PubKey: pub, Message: tx.Canonical(), Signature: legacySig, Fallback: legacy}}
+	if failed := VerifyBatch(items); len(failed) != 0 {
+		t.Errorf("VerifyBatch() failed = %v, want none with fallback", failed)
+	}
+	items[0].Fallback = nil
+	if failed := VerifyBatch(items); len(failed) != 1 {
+		t.Errorf("VerifyBatch() failed = %v, want [0] without fallback", failed)
+	}
+}
+
 func TestSignData_VerifyDataSignature(t *testing.T) {
 	pub, priv, err := GenerateKeyPair()
 	if err != nil {
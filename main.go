@@ -9,7 +9,7 @@ import (
 func main() {
 	if len(os.Args) < 2 {
 		fmt.Fprintln(os.Stderr, "Usage: signet <command> [options]")
-		fmt.Fprintln(os.Stderr, "Commands: init, start, stop")
+		fmt.Fprintln(os.Stderr, "Commands: init, start, stop, restart, bench, nickname, peers, key-info, keygen, send, pending, approve, reject, verify, export, import, version")
 		os.Exit(1)
 	}
 
@@ -20,6 +20,34 @@ func main() {
 		cmd.RunStart(os.Args[2:])
 	case "stop":
 		cmd.RunStop(os.Args[2:])
+	case "restart":
+		cmd.RunRestart(os.Args[2:])
+	case "bench":
+		cmd.RunBench(os.Args[2:])
+	case "nickname":
+		cmd.RunNickname(os.Args[2:])
+	case "peers":
+		cmd.RunPeers(os.Args[2:])
+	case "key-info":
+		cmd.RunKeyInfo(os.Args[2:])
+	case "keygen":
+		cmd.RunKeygen(os.Args[2:])
+	case "send":
+		cmd.RunSend(os.Args[2:])
+	case "pending":
+		cmd.RunPending(os.Args[2:])
+	case "approve":
+		cmd.RunApprove(os.Args[2:])
+	case "reject":
+		cmd.RunReject(os.Args[2:])
+	case "verify":
+		cmd.RunVerify(os.Args[2:])
+	case "export":
+		cmd.RunExport(os.Args[2:])
+	case "import":
+		cmd.RunImport(os.Args[2:])
+	case "version":
+		cmd.RunVersion(os.Args[2:])
 	default:
 		fmt.Fprintf(os.Stderr, "Unknown command: %s\n", os.Args[1])
 		os.Exit(1)
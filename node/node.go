@@ -2,11 +2,14 @@ package node
 
 import (
 	"bytes"
+	"context"
 	"crypto/ed25519"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
 	"signet/config"
 	"signet/core"
@@ -14,26 +17,72 @@ import (
 	"signet/p2p"
 	"signet/server"
 	"signet/storage"
+	"signet/version"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-// httpClient はタイムアウト付きHTTPクライアント
-var httpClient = &http.Client{
-	Timeout: 10 * time.Second,
-}
+// Node が server.NodeService を満たすことをコンパイル時に保証する
+// （server から node は import できないため node 側に置く）
+var _ server.NodeService = (*Node)(nil)
 
 // Node は全コンポーネントを統合するノード構造体
 type Node struct {
-	Config       *config.Config
-	Chain        *core.Chain
-	PendingPool  *core.PendingPool
-	BlockStore   *storage.BlockStore
-	NodeStore    *storage.NodeStore
-	PendingStore *storage.PendingStore
-	PrivKey      ed25519.PrivateKey
-	PubKey       ed25519.PublicKey
+	Config        *config.Config
+	Chain         *core.Chain
+	PendingPool   *core.PendingPool
+	BlockStore    *storage.BlockStore
+	NodeStore     *storage.NodeStore
+	PendingStore  *storage.PendingStore
+	ArchiveStore  *storage.ArchiveStore
+	SeenStore     *storage.SeenStore
+	PrivKey       ed25519.PrivateKey
+	PubKey        ed25519.PublicKey
 	broadcastLock sync.Mutex
+
+	// chainLock はチェーンを変更する一連の処理（末尾確認→追加→永続化）を直列化する
+	// core.Chain 自体のロックだけでは受信と同期置換が交互に走った際にストレージと不整合になる
+	chainLock sync.Mutex
+
+	// peerClient はピアとの通信に使う HTTP クライアント（タイムアウトは Config.PeerTimeoutSeconds）
+	peerClient *http.Client
+
+	// reachability はピアの到達性（ブロードキャスト・同期の結果）を追跡する
+	reachability *p2p.Reachability
+
+	// seenBlocks は BroadcastBlock で転送済みのブロックのハッシュ（直近 seenBlocksSize 件）
+	seenBlocks *p2p.SeenSet
+
+	// peerVersions はバージョンの不一致を警告したピアとそのバージョン（ノード名 → バージョン）
+	// 同期のたびに同じ警告を出さないよう、バージョンが変わったときだけ警告する
+	peerVersions sync.Map
+
+	// syncQueued は ReceiveBlock が予約したチェーン同期が実行中かを表す（同期を重複して起動しない）
+	syncQueued atomic.Bool
+
+	// readOnly はディスク容量不足により書き込みを停止しているかを表す（DiskFullPolicy = read_only）
+	readOnly atomic.Bool
+
+	// blocksReceived は ReceiveBlock で受信したブロック数、broadcastsSent は BroadcastBlock でピアへ送信したブロック数（GET /metrics 用）
+	blocksReceived atomic.Uint64
+	broadcastsSent atomic.Uint64
+
+	// archive はプールから取り除かれた承認待ちトランザクションの記録（古い順、最大 Config.ExpiredLogSize 件）
+	archiveMu sync.Mutex
+	archive   []*core.ArchivedTransaction
+
+	// background は Go で起動したバックグラウンド処理のコンテキスト。Shutdown でキャンセルする
+	background     context.Context
+	stopBackground context.CancelFunc
+	backgroundWG   sync.WaitGroup
+
+	// shutdownHooks は OnShutdown で登録された停止処理。shutdownDone は Shutdown 済みかを表す
+	shutdownMu    sync.Mutex
+	shutdownHooks []func(ctx context.Context) error
+	shutdownDone  bool
 }
 
 // NewNode は新しいノードを作成・初期化する
@@ -49,26 +98,30 @@ func NewNode(cfg *config.Config) (*Node, error) {
 
 	// ストレージ初期化
 	blockStore := storage.NewBlockStore(cfg.BlockFilePath())
+	blockStore.SetMaxBlockSize(cfg.MaxBlockSizeBytes)
+	if cfg.SyncPolicy != "" {
+		policy, err := storage.ParseSyncPolicy(cfg.SyncPolicy)
+		if err != nil {
+			return nil, err
+		}
+		if err := blockStore.SetSyncPolicy(policy, cfg.SyncInterval()); err != nil {
+			return nil, fmt.Errorf("failed to set sync policy: %w", err)
+		}
+	}
 	nodeStore := storage.NewNodeStore(cfg.NodesDir())
 	pendingStore := storage.NewPendingStore(cfg.PendingFilePath())
+	archiveStore := storage.NewArchiveStore(cfg.ExpiredFilePath())
+	seenStore := storage.NewSeenStore(cfg.SeenBlocksFilePath(), seenBlocksSize)
 
 	// ブロックチェーン読み込み
-	blocks, err := blockStore.LoadAll()
-	if err != nil {
-		return nil, fmt.Errorf("failed to load blocks: %w", err)
-	}
-
 	var chain *core.Chain
-	if len(blocks) == 0 {
-		// ブロックがなければジェネシスブロックで初期化（フォールバック）
-		chain = core.NewChain()
+	if cfg.BlockIndex {
+		chain, err = loadIndexedChain(blockStore)
 	} else {
-		// ストレージのブロックからチェーンを直接構築（ジェネシス二重生成を防止）
-		var chainErr error
-		chain, chainErr = core.NewChainFromBlocks(blocks)
-		if chainErr != nil {
-			return nil, fmt.Errorf("failed to build chain from blocks: %w", chainErr)
-		}
+		chain, err = loadChain(blockStore)
+	}
+	if err != nil {
+		return nil, err
 	}
 
 	// 承認待ちトランザクション読み込み
@@ -83,26 +136,183 @@ func NewNode(cfg *config.Config) (*Node, error) {
 		pendingPool.Add(item)
 	}
 
+	// 期限切れ・拒否の記録読み込み
+	archive, err := archiveStore.Load()
+	if err != nil {
+		log.Printf("Warning: failed to load archived transactions: %v", err)
+		archive = []*core.ArchivedTransaction{}
+	}
+
+	// 転送済みブロックの記録読み込み（Shutdown で保存したもの。再起動前に転送したブロックを再送しない）
+	seenBlocks := p2p.NewSeenSet(seenBlocksSize)
+	seenHashes, err := seenStore.Load()
+	if err != nil {
+		log.Printf("Warning: failed to load seen blocks: %v", err)
+	}
+	for _, hash := range seenHashes {
+		seenBlocks.Mark(hash)
+	}
+
+	background, stopBackground := context.WithCancel(context.Background())
+
 	return &Node{
-		Config:       cfg,
-		Chain:        chain,
-		PendingPool:  pendingPool,
-		BlockStore:   blockStore,
-		NodeStore:    nodeStore,
-		PendingStore: pendingStore,
-		PrivKey:      privKey,
-		PubKey:       pubKey,
+		Config:         cfg,
+		Chain:          chain,
+		PendingPool:    pendingPool,
+		BlockStore:     blockStore,
+		NodeStore:      nodeStore,
+		PendingStore:   pendingStore,
+		ArchiveStore:   archiveStore,
+		SeenStore:      seenStore,
+		PrivKey:        privKey,
+		PubKey:         pubKey,
+		archive:        archive,
+		peerClient:     p2p.NewClient(cfg.PeerTimeout()),
+		reachability:   p2p.NewReachability(),
+		seenBlocks:     seenBlocks,
+		background:     background,
+		stopBackground: stopBackground,
 	}, nil
 }
 
+// loadChain は block.jsonl の全ブロックを読み込んでチェーンを構築する
+func loadChain(blockStore *storage.BlockStore) (*core.Chain, error) {
+	blocks, err := blockStore.LoadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load blocks: %w", err)
+	}
+
+	if len(blocks) == 0 {
+		// ブロックがなければジェネシスブロックで初期化（フォールバック）
+		return core.NewChain(), nil
+	}
+
+	// ストレージのブロックからチェーンを直接構築（ジェネシス二重生成を防止）
+	chain, err := core.NewChainFromBlocks(blocks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build chain from blocks: %w", err)
+	}
+	return chain, nil
+}
+
+// loadIndexedChain はインデックスファイルからブロックのハッシュ列を読み、ブロック本体を遅延読み込みするチェーンを構築する
+// インデックスがない・block.jsonl と一致しない場合は block.jsonl を全て読んで作り直す
+func loadIndexedChain(blockStore *storage.BlockStore) (*core.Chain, error) {
+	entries, err := blockStore.LoadIndex()
+	if errors.Is(err, storage.ErrStaleIndex) {
+		log.Printf("Rebuilding block index: %v", err)
+		entries, err = blockStore.RebuildIndex()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load block index: %w", err)
+	}
+
+	if len(entries) == 0 {
+		return core.NewChain(), nil
+	}
+
+	hashes := make([]string, len(entries))
+	for i, e := range entries {
+		hashes[i] = e.Hash
+	}
+	chain, err := core.NewLazyChain(hashes, blockStore.ReadBlock)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build chain from block index: %w", err)
+	}
+	return chain, nil
+}
+
+// Go は fn をバックグラウンドで実行する。fn に渡す ctx は Shutdown でキャンセルされ、Shutdown は fn の終了を待つ
+func (n *Node) Go(fn func(ctx context.Context)) {
+	n.backgroundWG.Add(1)
+	go func() {
+		defer n.backgroundWG.Done()
+		fn(n.background)
+	}()
+}
+
+// OnShutdown は Shutdown 時に実行する停止処理を登録する（HTTP サーバーの停止、PID ファイル削除など）
+// 登録と逆の順序で実行する
+func (n *Node) OnShutdown(hook func(ctx context.Context) error) {
+	n.shutdownMu.Lock()
+	defer n.shutdownMu.Unlock()
+	n.shutdownHooks = append(n.shutdownHooks, hook)
+}
+
+// Shutdown はノードを停止する
+// 登録された停止処理 → バックグラウンド処理の停止 → 承認待ちトランザクションとブロックファイルの書き出し の順に行う。
+// 2回目以降の呼び出しは何もしない。各段階のエラーはまとめて返す
+func (n *Node) Shutdown(ctx context.Context) error {
+	n.shutdownMu.Lock()
+	if n.shutdownDone {
+		n.shutdownMu.Unlock()
+		return nil
+	}
+	n.shutdownDone = true
+	hooks := n.shutdownHooks
+	n.shutdownHooks = nil
+	n.shutdownMu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := hooks[i](ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	// バックグラウンド処理の終了を待つ（ctx の期限まで）
+	n.stopBackground()
+	done := make(chan struct{})
+	go func() {
+		n.backgroundWG.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		errs = append(errs, fmt.Errorf("background tasks did not stop: %w", ctx.Err()))
+	}
+
+	if err := n.PendingStore.Save(n.PendingPool.List()); err != nil {
+		errs = append(errs, fmt.Errorf("failed to save pending transactions: %w", err))
+	}
+	// 再起動後に同じブロックを再ブロードキャストしないよう、転送済みブロックの記録を保存する
+	if err := n.SeenStore.Save(n.seenBlocks.Hashes()); err != nil {
+		errs = append(errs, fmt.Errorf("failed to save seen blocks: %w", err))
+	}
+	// バッファ済みのブロックを書き出す（sync_interval の場合）
+	if err := n.BlockStore.Close(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to flush block file: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
 // GetChain はチェーンを返す（server.NodeServiceインターフェース実装）
-func (n *Node) GetChain() []*server.Block {
-	blocks := n.Chain.GetBlocks()
+func (n *Node) GetChain() ([]*server.Block, error) {
+	blocks, err := n.Chain.GetBlocks()
+	if err != nil {
+		return nil, err
+	}
 	result := make([]*server.Block, len(blocks))
 	for i, b := range blocks {
 		result[i] = convertBlockToServer(b)
 	}
-	return result
+	return result, nil
+}
+
+// GetChainRange は from <= Index < to のブロックを返す（server.NodeServiceインターフェース実装）
+// 範囲外の値はチェーンの範囲に丸める
+func (n *Node) GetChainRange(from, to int) ([]*server.Block, error) {
+	blocks, err := n.Chain.GetRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*server.Block, len(blocks))
+	for i, b := range blocks {
+		result[i] = convertBlockToServer(b)
+	}
+	return result, nil
 }
 
 // GetChainLen はチェーンの長さを返す
@@ -110,74 +320,384 @@ func (n *Node) GetChainLen() int {
 	return n.Chain.Len()
 }
 
+// GetBlockByIndex は指定インデックスのブロックを返す（server.NodeServiceインターフェース実装）
+// 範囲外の場合は server.ErrNotFound を含むエラーを返す
+func (n *Node) GetBlockByIndex(index int) (*server.Block, error) {
+	block, err := n.Chain.GetBlockByIndex(index)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", server.ErrNotFound, err)
+	}
+	return convertBlockToServer(block), nil
+}
+
+// GetBlockByHash は指定ハッシュのブロックを返す（server.NodeServiceインターフェース実装）
+// 存在しない場合は server.ErrNotFound を含むエラーを返す
+func (n *Node) GetBlockByHash(hash string) (*server.Block, error) {
+	block, err := n.Chain.GetBlockByHash(hash)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", server.ErrNotFound, err)
+	}
+	return convertBlockToServer(block), nil
+}
+
 // verifyBlockSignatures はトランザクションブロックの署名を暗号学的に検証する
 func (n *Node) verifyBlockSignatures(block *core.Block) error {
+	if block.Payload.Type == "add_node" {
+		return n.verifyNodeUpdateSignature(block)
+	}
 	if block.Payload.Type != "transaction" {
-		return nil // add_node ブロックには署名不要
+		return nil
 	}
 
-	txData, err := block.GetTransactionData()
+	peers, err := n.NodeStore.LoadAll()
 	if err != nil {
-		return fmt.Errorf("failed to get transaction data: %w", err)
+		return fmt.Errorf("failed to load peers for signature verification: %w", err)
 	}
 
-	txDataBytes, err := json.Marshal(txData)
+	items, roles, err := transactionSignatureItems(block, peers)
 	if err != nil {
-		return fmt.Errorf("failed to marshal transaction data: %w", err)
+		return err
+	}
+	if failed := crypto.VerifyBatch(items); len(failed) > 0 {
+		return fmt.Errorf("invalid %s signature: %w", roles[failed[0]], items[failed[0]].Check())
 	}
 
-	peers, err := n.NodeStore.LoadAll()
+	return nil
+}
+
+// transactionSignatureItems はトランザクションブロックの From/To 署名を一括検証用の要素に変換する
+// roles[i] は items[i] が "from" / "to" のどちらの署名かを表す
+func transactionSignatureItems(block *core.Block, peers map[string]*storage.NodeInfo) ([]crypto.BatchItem, []string, error) {
+	txData, err := block.GetTransactionData()
 	if err != nil {
-		return fmt.Errorf("failed to load peers for signature verification: %w", err)
+		return nil, nil, fmt.Errorf("failed to get transaction data: %w", err)
+	}
+	message, err := crypto.TransactionSigningPayload(txData)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Canonical 導入前に作成されたブロックの署名も受け入れる
+	legacy, err := crypto.LegacyTransactionSigningPayload(txData)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	signers := []struct {
+		role, node, signature string
+	}{
+		{"from", txData.From, block.Payload.FromSignature},
+		{"to", txData.To, block.Payload.ToSignature},
+	}
+
+	items := make([]crypto.BatchItem, 0, len(signers))
+	roles := make([]string, 0, len(signers))
+	for _, signer := range signers {
+		if signer.signature == "" {
+			return nil, nil, fmt.Errorf("missing %s signature", signer.role)
+		}
+		peer, ok := peers[signer.node]
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown %s node: %s", signer.role, signer.node)
+		}
+		pubKey, err := crypto.HexToPublicKey(peer.PublicKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decode %s node's public key: %w", signer.role, err)
+		}
+		items = append(items, crypto.BatchItem{PubKey: pubKey, Message: message, Signature: signer.signature, Fallback: legacy})
+		roles = append(roles, signer.role)
 	}
 
-	// From 署名検証
+	return items, roles, nil
+}
+
+// verifyNodeUpdateSignature は署名付き add_node（ノード情報の更新）ブロックの署名を検証する
+// 署名のない add_node（通常のノード登録）は従来通り検証不要
+func (n *Node) verifyNodeUpdateSignature(block *core.Block) error {
 	if block.Payload.FromSignature == "" {
-		return fmt.Errorf("missing from signature")
+		return nil
 	}
-	fromPeer, ok := peers[txData.From]
-	if !ok {
-		return fmt.Errorf("unknown from node: %s", txData.From)
+
+	addNodeData, err := block.GetAddNodeData()
+	if err != nil {
+		return fmt.Errorf("failed to get add_node data: %w", err)
 	}
-	fromPubKey, err := crypto.HexToPublicKey(fromPeer.PublicKey)
+
+	known, err := n.NodeStore.Load(addNodeData.NodeName)
 	if err != nil {
-		return fmt.Errorf("failed to decode from node's public key: %w", err)
+		return fmt.Errorf("unknown node for update: %s", addNodeData.NodeName)
+	}
+	// 更新で公開鍵を差し替えることはできない
+	if addNodeData.PublicKey != known.PublicKey {
+		return fmt.Errorf("public key mismatch for node update: %s", addNodeData.NodeName)
+	}
+	pubKey, err := crypto.HexToPublicKey(known.PublicKey)
+	if err != nil {
+		return fmt.Errorf("failed to decode node's public key: %w", err)
+	}
+
+	dataBytes, err := json.Marshal(addNodeData)
+	if err != nil {
+		return fmt.Errorf("failed to marshal add_node data: %w", err)
 	}
-	if !crypto.Verify(fromPubKey, txDataBytes, block.Payload.FromSignature) {
-		return fmt.Errorf("invalid from signature")
+	if !crypto.Verify(pubKey, dataBytes, block.Payload.FromSignature) {
+		return fmt.Errorf("invalid node update signature")
+	}
+
+	return nil
+}
+
+// applyNodeUpdate は署名付き add_node ブロックの内容（ニックネーム）をノードファイルに反映する
+func (n *Node) applyNodeUpdate(block *core.Block) {
+	if block.Payload.Type != "add_node" || block.Payload.FromSignature == "" {
+		return
 	}
 
-	// To 署名検証
-	if block.Payload.ToSignature == "" {
-		return fmt.Errorf("missing to signature")
+	addNodeData, err := block.GetAddNodeData()
+	if err != nil {
+		return
+	}
+	info, err := n.NodeStore.Load(addNodeData.NodeName)
+	if err != nil {
+		log.Printf("Warning: failed to load node %s for update: %v", addNodeData.NodeName, err)
+		return
 	}
-	toPeer, ok := peers[txData.To]
-	if !ok {
-		return fmt.Errorf("unknown to node: %s", txData.To)
+	info.NickName = addNodeData.NickName
+	if err := n.NodeStore.Save(addNodeData.NodeName, info); err != nil {
+		log.Printf("Warning: failed to save node file: %v", n.storageError(err))
 	}
-	toPubKey, err := crypto.HexToPublicKey(toPeer.PublicKey)
+}
+
+// ValidateChainWithSignatures はチェーン全体の構造（ハッシュ・連結・インデックス）と署名を検証する
+// 失敗した場合はそのブロックのインデックスとエラーを返す。成功時は -1 と nil を返す
+func (n *Node) ValidateChainWithSignatures() (int, error) {
+	blocks, err := n.Chain.GetBlocks()
 	if err != nil {
-		return fmt.Errorf("failed to decode to node's public key: %w", err)
+		return -1, err
+	}
+	return n.validateBlocksWithSignatures(blocks)
+}
+
+// validateBlocksWithSignatures は blocks の構造と署名を ReceiveBlock と同じ基準（既知ノードの公開鍵）で検証する
+// 同期で受け取った候補チェーンの検証にも使う
+func (n *Node) validateBlocksWithSignatures(blocks []*core.Block) (int, error) {
+	if len(blocks) == 0 {
+		return -1, fmt.Errorf("empty chain")
 	}
-	if !crypto.Verify(toPubKey, txDataBytes, block.Payload.ToSignature) {
-		return fmt.Errorf("invalid to signature")
+	if !blocks[0].IsGenesisBlock() {
+		return 0, core.NewValidationError(0, core.ValidationKindGenesis, fmt.Errorf("first block is not a valid genesis block"))
 	}
 
-	return nil
+	peers, err := n.NodeStore.LoadAll()
+	if err != nil {
+		return -1, fmt.Errorf("failed to load peers for signature verification: %w", err)
+	}
+
+	// 構造検証を先に行い、トランザクション署名はまとめて VerifyBatch で検証する
+	var items []crypto.BatchItem
+	var itemBlocks []int // items[k] が属するブロックの位置
+	var itemRoles []string
+	failAt, failErr := -1, error(nil)
+
+	for i := 1; i < len(blocks); i++ {
+		current := blocks[i]
+		prev := blocks[i-1]
+
+		if err := core.ValidateLink(prev, current, i); err != nil {
+			failAt, failErr = i, err
+			break
+		}
+
+		if current.Payload.Type != "transaction" {
+			if err := n.verifyBlockSignatures(current); err != nil {
+				failAt, failErr = i, core.NewValidationError(i, core.ValidationKindSignature, err)
+				break
+			}
+			continue
+		}
+		blockItems, roles, err := transactionSignatureItems(current, peers)
+		if err != nil {
+			failAt, failErr = i, core.NewValidationError(i, core.ValidationKindSignature, err)
+			break
+		}
+		for range blockItems {
+			itemBlocks = append(itemBlocks, i)
+		}
+		items = append(items, blockItems...)
+		itemRoles = append(itemRoles, roles...)
+	}
+
+	// 失敗インデックスは昇順なので先頭が最も手前の不正署名
+	if failed := crypto.VerifyBatch(items); len(failed) > 0 {
+		if k := failed[0]; failAt < 0 || itemBlocks[k] < failAt {
+			failAt, failErr = itemBlocks[k], core.NewValidationError(itemBlocks[k], core.ValidationKindSignature,
+				fmt.Errorf("invalid %s signature: %w", itemRoles[k], items[k].Check()))
+		}
+	}
+
+	return failAt, failErr
+}
+
+// ValidateChainStructure はチェーンの構造（ハッシュ・連結・インデックス）を検証する（server.NodeServiceインターフェース実装）
+// 署名は検証しないため ValidateChainWithSignatures より軽い
+func (n *Node) ValidateChainStructure() error {
+	return n.Chain.ValidateChain()
+}
+
+// VerifyChain はチェーン検証結果を返す（server.NodeServiceインターフェース実装）
+func (n *Node) VerifyChain() *server.ChainVerification {
+	index, err := n.ValidateChainWithSignatures()
+	if err == nil {
+		return &server.ChainVerification{Valid: true}
+	}
+
+	result := &server.ChainVerification{
+		Valid:  false,
+		Reason: err.Error(),
+	}
+	if index >= 0 {
+		result.Index = &index
+	}
+	var validationErr *core.ValidationError
+	if errors.As(err, &validationErr) {
+		result.Kind = string(validationErr.Kind)
+	}
+	return result
+}
+
+// ReceiveBlockFrom は送信元ノード名付きでブロックを受信する
+// origin が空でなければ既知のノードであることを確認し、その到達性を記録してから ReceiveBlock と同じ処理を行う
+// チェーンより先のブロックを受け取った場合は、不足しているブロックを送信元から取得して追いつく
+func (n *Node) ReceiveBlockFrom(b *server.Block, origin string) (server.ReceiveStatus, error) {
+	senderAddr := ""
+	if origin != "" {
+		peer, err := n.NodeStore.Load(origin)
+		if err != nil {
+			return server.ReceiveRejected, fmt.Errorf("unknown origin node: %s", origin)
+		}
+		n.recordReachability(origin, peer.Address, true)
+		senderAddr = peer.Address
+	}
+	return n.receiveBlock(b, senderAddr)
 }
 
 // ReceiveBlock はブロックを受信してチェーンに追加する
+// 重複したブロックは無視し、チェーンより先のブロックは同期を予約する（いずれもエラーにしない）
 func (n *Node) ReceiveBlock(b *server.Block) error {
+	_, err := n.receiveBlock(b, "")
+	return err
+}
+
+// receiveBlock は ReceiveBlock の処理を行い、受信したブロックをどう扱ったかを返す
+// チェーンより先のブロックは、senderAddr が分かればそこから不足分を取得して追いつき、できなければ同期を予約する
+// 検証に失敗した・チェーンと競合するブロックはエラーとともに server.ReceiveRejected を返す
+func (n *Node) receiveBlock(b *server.Block, senderAddr string) (server.ReceiveStatus, error) {
+	n.blocksReceived.Add(1)
+	status, err := n.applyReceivedBlock(b)
+	if err != nil {
+		return server.ReceiveRejected, err
+	}
+	if status == server.ReceiveSyncQueued && senderAddr != "" {
+		if caughtUp, err := n.catchUpFrom(senderAddr, b); err != nil {
+			log.Printf("Warning: failed to catch up from %s: %v", senderAddr, err)
+		} else {
+			status = caughtUp
+		}
+	}
+	if status == server.ReceiveSyncQueued {
+		n.queueSync()
+	}
+	return status, nil
+}
+
+// catchUpFrom は b より前に不足しているブロック（ローカルの末尾の次から b の直前まで）を senderAddr の GET /chain で取得し、
+// 順に追加してから b を追加する。取得したブロックがローカルの末尾につながらない（フォーク）場合はエラーを返す
+func (n *Node) catchUpFrom(senderAddr string, b *server.Block) (server.ReceiveStatus, error) {
+	from := n.Chain.GetLastIndex() + 1
+	missing, err := p2p.FetchChainRange[*server.Block](n.background, n.peerClient, senderAddr, from, b.Header.Index)
+	if err != nil {
+		return "", err
+	}
+	// 範囲指定に対応していないピアはチェーン全体を返す
+	if len(missing) > 0 && missing[0].Header.Index == 0 && from < len(missing) {
+		missing = missing[from:]
+	}
+
+	for _, sb := range missing {
+		if sb.Header.Index >= b.Header.Index {
+			break
+		}
+		status, err := n.applyReceivedBlock(sb)
+		if err != nil {
+			return "", fmt.Errorf("block %d: %w", sb.Header.Index, err)
+		}
+		if status == server.ReceiveSyncQueued {
+			return "", fmt.Errorf("block %d does not extend the local chain", sb.Header.Index)
+		}
+	}
+
+	status, err := n.applyReceivedBlock(b)
+	if err != nil {
+		return "", err
+	}
+	if status == server.ReceiveSyncQueued {
+		return "", fmt.Errorf("sender returned %d of the missing blocks before %d", len(missing), b.Header.Index)
+	}
+	log.Printf("Caught up %d missing blocks from %s", len(missing), senderAddr)
+	return status, nil
+}
+
+// queueSync はチェーン同期をバックグラウンドで実行する。既に予約済みなら何もしない
+func (n *Node) queueSync() {
+	if !n.syncQueued.CompareAndSwap(false, true) {
+		return
+	}
+	n.Go(func(ctx context.Context) {
+		defer n.syncQueued.Store(false)
+		if err := n.SyncChain(); err != nil {
+			log.Printf("Warning: queued chain sync failed: %v", err)
+		}
+	})
+}
+
+// applyReceivedBlock は受信したブロックを検証し、自分の末尾につながればチェーンに追加する
+func (n *Node) applyReceivedBlock(b *server.Block) (server.ReceiveStatus, error) {
+	if err := n.checkWritable(); err != nil {
+		return "", err
+	}
+
+	n.chainLock.Lock()
+	defer n.chainLock.Unlock()
+
 	coreBlock := convertServerToBlock(b)
 
 	// ハッシュ再計算チェック
 	if err := core.ValidateBlock(coreBlock); err != nil {
-		return fmt.Errorf("block validation failed: %w", err)
+		return "", fmt.Errorf("block validation failed: %w", err)
 	}
 
 	// 署名検証
 	if err := n.verifyBlockSignatures(coreBlock); err != nil {
-		return fmt.Errorf("signature verification failed: %w", err)
+		return "", fmt.Errorf("signature verification failed: %w", err)
+	}
+
+	// 作成時刻が未来すぎないか（過去方向は AddBlock で直前のブロックと比較する）
+	if skew := n.Config.MaxClockSkew(); skew > 0 && coreBlock.Header.CreatedAt.After(time.Now().Add(skew)) {
+		return "", fmt.Errorf("block created_at %s is more than %s ahead of local time",
+			coreBlock.Header.CreatedAt.Format(time.RFC3339), skew)
+	}
+
+	// 金額ポリシー
+	if coreBlock.Payload.Type == "transaction" {
+		txData, err := coreBlock.GetTransactionData()
+		if err != nil {
+			return "", fmt.Errorf("failed to get transaction data: %w", err)
+		}
+		if err := n.amountPolicy().Check(txData.Amount); err != nil {
+			return "", fmt.Errorf("amount policy violation: %w", err)
+		}
+		if err := txData.Validate(n.Config.MaxTitleLength); err != nil {
+			return "", err
+		}
 	}
 
 	lastHash := n.Chain.GetLastHash()
@@ -187,34 +707,77 @@ func (n *Node) ReceiveBlock(b *server.Block) error {
 	if coreBlock.Header.PrevHash == lastHash {
 		// 自分の末尾と一致→追加
 		if err := n.Chain.AddBlock(coreBlock); err != nil {
-			return fmt.Errorf("failed to add block: %w", err)
+			return "", fmt.Errorf("failed to add block: %w", err)
 		}
 		// 永続化
 		if err := n.BlockStore.Append(coreBlock); err != nil {
-			return fmt.Errorf("failed to persist block: %w", err)
+			return "", fmt.Errorf("failed to persist block: %w", n.storageError(err))
 		}
+		// ノード情報の更新ブロックであればノードファイルに反映
+		n.applyNodeUpdate(coreBlock)
+		// 自ノードが提案した取引の承認であればプールから取り除く
+		n.removeCommittedPending([]*core.Block{coreBlock})
 		// ブロードキャスト
 		go n.BroadcastBlock(b)
-		return nil
+		return server.ReceiveAdded, nil
 	}
 
-	// Indexが大きい→同期
+	// Indexが大きい→同期（取りこぼしたブロックをピアから取得する）
 	if coreBlock.Header.Index > lastIndex {
-		return fmt.Errorf("block index %d is ahead of our chain %d, sync needed", coreBlock.Header.Index, lastIndex)
+		return server.ReceiveSyncQueued, nil
 	}
 
 	// Index以下→無視（既に持っているか、競合）
 	if n.Chain.HasBlock(coreBlock.Header.Hash) {
-		return nil // 重複ブロックは無視
+		return server.ReceiveDuplicate, nil // 重複ブロックは無視
+	}
+
+	return "", fmt.Errorf("%w: block index %d is behind or equal to our chain %d", server.ErrBlockBehind, coreBlock.Header.Index, lastIndex)
+}
+
+// checkWritable は書き込みを停止中であれば server.ErrInsufficientStorage を返す
+// 更新系の処理は状態を変更する前にこれを呼び、書き込めない要求を受け付けない
+func (n *Node) checkWritable() error {
+	if n.readOnly.Load() {
+		return fmt.Errorf("node is read-only after running out of disk space: %w", server.ErrInsufficientStorage)
+	}
+	return nil
+}
+
+// storageError はストレージへの書き込みエラーを呼び出し元へ返す形に変換する
+// ディスク容量不足なら server.ErrInsufficientStorage を付与し、DiskFullPolicy が read_only なら以降の書き込みを停止する
+func (n *Node) storageError(err error) error {
+	if !storage.IsDiskFull(err) {
+		return err
+	}
+	if n.Config.DiskFullPolicy != "retry" && !n.readOnly.Swap(true) {
+		log.Printf("Warning: disk is full; rejecting further writes until restart: %v", err)
 	}
+	return fmt.Errorf("%w: %w", server.ErrInsufficientStorage, err)
+}
 
-	return fmt.Errorf("block index %d is behind or equal to our chain %d", coreBlock.Header.Index, lastIndex)
+// amountPolicy は設定から取引金額の許容範囲を返す
+func (n *Node) amountPolicy() core.AmountPolicy {
+	return core.AmountPolicy{Min: n.Config.MinAmount, Max: n.Config.MaxAmount}
 }
 
 // ProposeTransaction はトランザクションを提案する
 // fromSignature が空の場合は自ノードの秘密鍵で自動署名する（ローカル提案）
-// fromSignature が指定されている場合はそのまま使用する（他ノードからの転送）
+// fromSignature が指定されている場合は From ノードの公開鍵で検証してから使用する（他ノードからの転送）
 func (n *Node) ProposeTransaction(data *server.TransactionData, fromSignature string) error {
+	_, err := n.ProposeTransactionDetailed(data, fromSignature)
+	return err
+}
+
+// ProposeTransactionDetailed は ProposeTransaction と同じ処理を行い、作成した承認待ちトランザクションを返す
+func (n *Node) ProposeTransactionDetailed(data *server.TransactionData, fromSignature string) (*server.PendingTransaction, error) {
+	if err := n.checkWritable(); err != nil {
+		return nil, err
+	}
+	if err := n.amountPolicy().Check(data.Amount); err != nil {
+		return nil, err
+	}
+
 	// 署名用ペイロード作成
 	txData := &core.TransactionData{
 		From:   data.From,
@@ -222,16 +785,40 @@ func (n *Node) ProposeTransaction(data *server.TransactionData, fromSignature st
 		Amount: data.Amount,
 		Title:  data.Title,
 	}
+	if err := txData.Validate(n.Config.MaxTitleLength); err != nil {
+		return nil, err
+	}
+
+	// 同じ内容の提案が承認待ちなら二重に登録しない（署名の前に弾く。同時に届いた提案は追加時に弾く）
+	if n.PendingPool.HasEquivalent(txData) {
+		return nil, fmt.Errorf("proposal already pending: %s -> %s %d %q: %w", data.From, data.To, data.Amount, data.Title, server.ErrDuplicateProposal)
+	}
 
 	// TransactionDataをJSONに変換
 	txDataBytes, err := json.Marshal(txData)
 	if err != nil {
-		return fmt.Errorf("failed to marshal transaction data: %w", err)
+		return nil, fmt.Errorf("failed to marshal transaction data: %w", err)
 	}
 
-	// From側の署名（未指定の場合は自動生成）
+	// From側の署名（未指定の場合は自動生成、転送されてきた場合は From ノードの公開鍵で検証）
 	if fromSignature == "" {
-		fromSignature = crypto.Sign(n.PrivKey, txDataBytes)
+		fromSignature, err = crypto.SignTransaction(n.PrivKey, txData)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign transaction: %w", err)
+		}
+	} else if err := n.verifyFromSignature(txData, fromSignature); err != nil {
+		return nil, err
+	}
+
+	// 残高超過の検査（AllowNegativeBalance が false の場合のみ）
+	if !n.Config.AllowNegativeBalance {
+		balance, err := n.Chain.BalanceOf(data.From)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compute balance of %s: %w", data.From, err)
+		}
+		if data.Amount > balance {
+			return nil, fmt.Errorf("insufficient balance: %s has %d, cannot send %d", data.From, balance, data.Amount)
+		}
 	}
 
 	// BlockPayload作成
@@ -248,15 +835,36 @@ func (n *Node) ProposeTransaction(data *server.TransactionData, fromSignature st
 	// PendingTransaction作成
 	pendingTx := core.NewPendingTransaction(id, payload)
 
-	// プールに追加
-	n.PendingPool.Add(pendingTx)
+	// プールに追加（検査との間に同じ内容の提案が追加されていれば登録しない）
+	if err := n.PendingPool.AddIfNoEquivalent(pendingTx); err != nil {
+		if errors.Is(err, core.ErrEquivalentPending) {
+			return nil, fmt.Errorf("proposal already pending: %s -> %s %d %q: %w", data.From, data.To, data.Amount, data.Title, server.ErrDuplicateProposal)
+		}
+		return nil, fmt.Errorf("failed to add pending transaction: %w", err)
+	}
 
 	// 永続化
 	items := n.PendingPool.List()
 	if err := n.PendingStore.Save(items); err != nil {
+		err = n.storageError(err)
+		if errors.Is(err, server.ErrInsufficientStorage) {
+			// 永続化できない提案は受け付けず、プールからも取り除く
+			n.PendingPool.Remove(id)
+			return nil, fmt.Errorf("failed to save pending transaction: %w", err)
+		}
 		log.Printf("Warning: failed to save pending transaction: %v", err)
 	}
 
+	// 自ノード宛で AutoApproveSelf が有効ならその場で承認してブロックを確定する
+	if n.Config.AutoApproveSelf && data.To == n.Config.NodeName {
+		block, err := n.ApproveTransaction(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to auto-approve transaction: %w", err)
+		}
+		go n.BroadcastBlock(block)
+		return convertPendingToServer(pendingTx), nil
+	}
+
 	// Toノードが別ノードの場合は送信
 	if data.To != n.Config.NodeName {
 		peers, err := n.NodeStore.LoadAll()
@@ -267,6 +875,22 @@ func (n *Node) ProposeTransaction(data *server.TransactionData, fromSignature st
 		}
 	}
 
+	return convertPendingToServer(pendingTx), nil
+}
+
+// verifyFromSignature は他ノードから転送された提案の From 署名を NodeStore の公開鍵で検証する
+func (n *Node) verifyFromSignature(txData *core.TransactionData, fromSignature string) error {
+	peer, err := n.NodeStore.Load(txData.From)
+	if err != nil {
+		return fmt.Errorf("unknown from node: %s", txData.From)
+	}
+	pubKey, err := crypto.HexToPublicKey(peer.PublicKey)
+	if err != nil {
+		return fmt.Errorf("failed to decode from node's public key: %w", err)
+	}
+	if !crypto.VerifyTransactionSignature(pubKey, txData, fromSignature) {
+		return fmt.Errorf("invalid from signature for transaction from %s", txData.From)
+	}
 	return nil
 }
 
@@ -296,8 +920,8 @@ func (n *Node) sendProposeTransaction(addr string, tx *core.PendingTransaction)
 		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	url := fmt.Sprintf("http://%s/transaction/propose", addr)
-	resp, err := httpClient.Post(url, "application/json", bytes.NewReader(data))
+	url := p2p.PeerURL(addr, "/transaction/propose")
+	resp, err := n.peerClient.Post(url, "application/json", bytes.NewReader(data))
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
@@ -313,7 +937,16 @@ func (n *Node) sendProposeTransaction(addr string, tx *core.PendingTransaction)
 }
 
 // ApproveTransaction はトランザクションを承認する
+// 同じ ID の承認が同時に届いても（二重クリックや再送）ブロックを1つだけ作るよう、
+// プールからの取得から削除までを chainLock の下で行う
 func (n *Node) ApproveTransaction(id string) (*server.Block, error) {
+	if err := n.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	n.chainLock.Lock()
+	defer n.chainLock.Unlock()
+
 	// プールから取得
 	pendingTx := n.PendingPool.Get(id)
 	if pendingTx == nil {
@@ -331,12 +964,11 @@ func (n *Node) ApproveTransaction(id string) (*server.Block, error) {
 		return nil, fmt.Errorf("only the recipient node can approve this transaction")
 	}
 
-	// 自分（To）の署名を追加（From署名と同じ形式: トランザクションデータに対して署名）
-	txDataBytes, err := json.Marshal(txData)
+	// 自分（To）の署名を追加（From署名と同じ形式: crypto.SignTransaction）
+	toSignature, err := crypto.SignTransaction(n.PrivKey, txData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal transaction data for signing: %w", err)
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
 	}
-	toSignature := crypto.Sign(n.PrivKey, txDataBytes)
 
 	// ブロック生成
 	lastBlock := n.Chain.LastBlock()
@@ -347,6 +979,7 @@ func (n *Node) ApproveTransaction(id string) (*server.Block, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create block: %w", err)
 	}
+	block.ClampCreatedAt(lastBlock)
 
 	// チェーンに追加
 	if err := n.Chain.AddBlock(block); err != nil {
@@ -355,45 +988,380 @@ func (n *Node) ApproveTransaction(id string) (*server.Block, error) {
 
 	// 永続化
 	if err := n.BlockStore.Append(block); err != nil {
-		return nil, fmt.Errorf("failed to persist block: %w", err)
+		return nil, fmt.Errorf("failed to persist block: %w", n.storageError(err))
+	}
+
+	// プールから削除
+	n.PendingPool.Remove(id)
+	items := n.PendingPool.List()
+	if err := n.PendingStore.Save(items); err != nil {
+		log.Printf("Warning: failed to save pending transactions: %v", n.storageError(err))
+	}
+
+	return convertBlockToServer(block), nil
+}
+
+// RejectTransaction はトランザクションを拒否する
+func (n *Node) RejectTransaction(id string) error {
+	if err := n.checkWritable(); err != nil {
+		return err
+	}
+
+	// 同じ ID の承認と同時に処理されないよう、取得から記録までを ApproveTransaction と同じロックで直列化する
+	n.chainLock.Lock()
+	defer n.chainLock.Unlock()
+
+	// プールから取得
+	pendingTx := n.PendingPool.Get(id)
+	if pendingTx == nil {
+		return fmt.Errorf("pending transaction not found: %s", id)
+	}
+
+	// 自ノードが受取側(to)であることを確認
+	txData, err := pendingTx.GetTransactionData()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction data: %w", err)
+	}
+	if txData.To != n.Config.NodeName {
+		return fmt.Errorf("only the recipient node can reject this transaction")
 	}
 
 	// プールから削除
 	n.PendingPool.Remove(id)
+
+	// 永続化
+	items := n.PendingPool.List()
+	if err := n.PendingStore.Save(items); err != nil {
+		log.Printf("Warning: failed to save pending transactions: %v", n.storageError(err))
+	}
+	n.archivePending([]*core.PendingTransaction{pendingTx}, core.ArchiveReasonRejected)
+
+	return nil
+}
+
+// ExpirePending は有効期限切れの承認待ちトランザクションを削除し、提案元(From)ノードに通知する
+// 削除した件数を返す。有効期限が設定されていない場合は何もしない
+func (n *Node) ExpirePending() int {
+	ttl := n.Config.PendingTTL()
+	if ttl <= 0 {
+		return 0
+	}
+
+	expired := n.PendingPool.Expired(ttl)
+	if len(expired) == 0 {
+		return 0
+	}
+
+	for _, item := range expired {
+		n.PendingPool.Remove(item.ID)
+	}
+
+	// 永続化
+	items := n.PendingPool.List()
+	if err := n.PendingStore.Save(items); err != nil {
+		log.Printf("Warning: failed to save pending transactions: %v", n.storageError(err))
+	}
+	n.archivePending(expired, core.ArchiveReasonExpired)
+
+	peers, err := n.NodeStore.LoadAll()
+	if err != nil {
+		log.Printf("Warning: failed to load peers for expiry notification: %v", err)
+		return len(expired)
+	}
+
+	for _, item := range expired {
+		txData, err := item.GetTransactionData()
+		if err != nil {
+			continue
+		}
+		log.Printf("Pending transaction expired: %s (%s -> %s)", item.ID, txData.From, txData.To)
+
+		// 提案元が別ノードの場合は通知（自分が提案元なら通知不要）
+		if txData.From == n.Config.NodeName {
+			continue
+		}
+		if peer, exists := peers[txData.From]; exists {
+			go func(addr string, tx *core.PendingTransaction) {
+				if err := n.sendExpiredNotification(addr, tx); err != nil {
+					log.Printf("Warning: failed to notify expiry to %s: %v", addr, err)
+				}
+			}(peer.Address, item)
+		}
+	}
+
+	return len(expired)
+}
+
+// StartExpirySweeper は interval ごとに ExpirePending を実行する。ctx がキャンセルされると終了する
+func (n *Node) StartExpirySweeper(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n.ExpirePending()
+		}
+	}
+}
+
+// StartSyncLoop は interval ごとに SyncChain を実行する。ctx がキャンセルされると終了する
+// 同期の失敗はログに残して次の周期で再試行する
+func (n *Node) StartSyncLoop(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := n.SyncChain(); err != nil {
+				log.Printf("Warning: periodic chain sync failed: %v", err)
+			}
+		}
+	}
+}
+
+// NotifyExpired は To ノードから期限切れ通知を受け取り、該当する承認待ちトランザクションを削除する
+// ノード間でIDは共有されないため、トランザクション内容と From 署名で照合する
+// From 署名は GET /transaction/pending で公開されているため、To ノードの公開鍵で toSignature（crypto.SignExpiry）を検証する
+func (n *Node) NotifyExpired(data *server.TransactionData, fromSignature, toSignature string) error {
+	if err := n.checkWritable(); err != nil {
+		return err
+	}
+
+	peer, err := n.NodeStore.Load(data.To)
+	if err != nil {
+		return fmt.Errorf("unknown to node: %s", data.To)
+	}
+	pubKey, err := crypto.HexToPublicKey(peer.PublicKey)
+	if err != nil {
+		return fmt.Errorf("failed to decode to node's public key: %w", err)
+	}
+	if !crypto.VerifyExpirySignature(pubKey, fromSignature, toSignature) {
+		return fmt.Errorf("invalid expiry signature from %s", data.To)
+	}
+
+	var removed []*core.PendingTransaction
+	for _, item := range n.PendingPool.List() {
+		if item.Payload.FromSignature != fromSignature {
+			continue
+		}
+		txData, err := item.GetTransactionData()
+		if err != nil {
+			continue
+		}
+		if txData.From != data.From || txData.To != data.To || txData.Amount != data.Amount || txData.Title != data.Title {
+			continue
+		}
+		n.PendingPool.Remove(item.ID)
+		removed = append(removed, item)
+	}
+
+	if len(removed) == 0 {
+		return fmt.Errorf("pending transaction not found")
+	}
+
+	// 永続化
+	items := n.PendingPool.List()
+	if err := n.PendingStore.Save(items); err != nil {
+		log.Printf("Warning: failed to save pending transactions: %v", n.storageError(err))
+	}
+	n.archivePending(removed, core.ArchiveReasonExpired)
+
+	return nil
+}
+
+// removeCommittedPending は blocks の取引ブロックと From 署名が一致する承認待ちトランザクションをプールから取り除き、承認済みとして記録する
+// 提案元のノードでは To ノードの承認がブロックとして届くため、ここで取り除かないと同じ内容の提案を二度と受け付けなくなる
+func (n *Node) removeCommittedPending(blocks []*core.Block) {
+	var removed []*core.PendingTransaction
+	for _, b := range blocks {
+		if b.Payload.Type != "transaction" || b.Payload.FromSignature == "" {
+			continue
+		}
+		removed = append(removed, n.PendingPool.RemoveByFromSignature(b.Payload.FromSignature)...)
+	}
+	if len(removed) == 0 {
+		return
+	}
+
+	// 永続化
 	items := n.PendingPool.List()
 	if err := n.PendingStore.Save(items); err != nil {
-		log.Printf("Warning: failed to save pending transactions: %v", err)
+		log.Printf("Warning: failed to save pending transactions: %v", n.storageError(err))
+	}
+	n.archivePending(removed, core.ArchiveReasonApproved)
+}
+
+// archivePending はプールから取り除かれた承認待ちトランザクションを理由付きで記録する
+// Config.ExpiredLogSize を超えた分は古いものから削除する。0 以下なら記録しない
+func (n *Node) archivePending(removed []*core.PendingTransaction, reason string) {
+	limit := n.Config.ExpiredLogSize
+	if limit <= 0 || len(removed) == 0 {
+		return
+	}
+
+	now := time.Now().UTC()
+	n.archiveMu.Lock()
+	defer n.archiveMu.Unlock()
+
+	for _, item := range removed {
+		n.archive = append(n.archive, &core.ArchivedTransaction{
+			PendingTransaction: *item,
+			Reason:             reason,
+			ArchivedAt:         now,
+		})
+	}
+	if len(n.archive) > limit {
+		n.archive = append([]*core.ArchivedTransaction(nil), n.archive[len(n.archive)-limit:]...)
+	}
+
+	if err := n.ArchiveStore.Save(n.archive); err != nil {
+		log.Printf("Warning: failed to save archived transactions: %v", n.storageError(err))
+	}
+}
+
+// GetTransactionStatus は指定 ID の承認待ちトランザクションの状態を返す（server.NodeServiceインターフェース実装）
+// プールから取り除かれた ID は記録の理由を返し、From 署名が一致するトランザクションブロックがチェーンにあれば approved とする
+// （同じ内容の取引は From 署名も同じになるため、プールに残っている ID はチェーンと照合しない）
+// プールにも記録にもない ID は server.ErrNotFound を含むエラーを返す
+func (n *Node) GetTransactionStatus(id string) (*server.TransactionStatus, error) {
+	status := &server.TransactionStatus{ID: id}
+
+	var fromSignature string
+	if pt := n.PendingPool.Get(id); pt != nil {
+		status.Status = "pending"
+	} else {
+		n.archiveMu.Lock()
+		for i := len(n.archive) - 1; i >= 0; i-- {
+			if n.archive[i].ID == id {
+				status.Status = n.archive[i].Reason
+				fromSignature = n.archive[i].Payload.FromSignature
+				break
+			}
+		}
+		n.archiveMu.Unlock()
+	}
+	if status.Status == "" {
+		return nil, fmt.Errorf("%w: transaction %s", server.ErrNotFound, id)
+	}
+
+	if fromSignature != "" {
+		err := n.Chain.ForEach(func(b *core.Block) error {
+			if b.Payload.Type == "transaction" && b.Payload.FromSignature == fromSignature {
+				status.Status = "approved"
+				status.BlockHash = b.Header.Hash
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read chain: %w", err)
+		}
+	}
+
+	return status, nil
+}
+
+// GetTransactionsFor はチェーンに確定した取引のうち From または To が node のものをチェーン順に返す（server.NodeServiceインターフェース実装）
+// node が空なら全ての取引を返し、Direction は付けない。チェーンのブロックが読めない場合はエラーを返す
+func (n *Node) GetTransactionsFor(node string) ([]*server.TransactionRecord, error) {
+	entries, err := n.Chain.TransactionsFor(node)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chain: %w", err)
+	}
+	result := make([]*server.TransactionRecord, 0, len(entries))
+	for _, entry := range entries {
+		record := &server.TransactionRecord{
+			Index:     entry.Index,
+			CreatedAt: entry.CreatedAt.Unix(),
+			Transaction: &server.TransactionData{
+				From:   entry.Transaction.From,
+				To:     entry.Transaction.To,
+				Amount: entry.Transaction.Amount,
+				Title:  entry.Transaction.Title,
+			},
+		}
+		switch node {
+		case "":
+		case entry.Transaction.From:
+			record.Direction = "sent"
+		default:
+			record.Direction = "received"
+		}
+		result = append(result, record)
+	}
+	return result, nil
+}
+
+// ListExpired は期限切れ・拒否により取り除かれた承認待ちトランザクションの記録を新しい順に返す（承認済みの記録は含めない）
+func (n *Node) ListExpired() []*server.ArchivedTransaction {
+	n.archiveMu.Lock()
+	defer n.archiveMu.Unlock()
+
+	result := make([]*server.ArchivedTransaction, 0, len(n.archive))
+	for i := len(n.archive) - 1; i >= 0; i-- {
+		item := n.archive[i]
+		if item.Reason == core.ArchiveReasonApproved {
+			continue
+		}
+		pt := convertPendingToServer(&item.PendingTransaction)
+		if pt == nil {
+			continue
+		}
+		result = append(result, &server.ArchivedTransaction{
+			PendingTransaction: *pt,
+			Reason:             item.Reason,
+			ArchivedAt:         item.ArchivedAt.Unix(),
+		})
+	}
+	return result
+}
+
+// sendExpiredNotification は指定したアドレスに期限切れ通知を送信する
+func (n *Node) sendExpiredNotification(addr string, tx *core.PendingTransaction) error {
+	txData, err := tx.GetTransactionData()
+	if err != nil {
+		return fmt.Errorf("failed to get transaction data: %w", err)
+	}
+
+	reqBody := struct {
+		From          string `json:"from"`
+		To            string `json:"to"`
+		Amount        int64  `json:"amount"`
+		Title         string `json:"title"`
+		FromSignature string `json:"from_signature"`
+		ToSignature   string `json:"to_signature"`
+	}{
+		From:          txData.From,
+		To:            txData.To,
+		Amount:        txData.Amount,
+		Title:         txData.Title,
+		FromSignature: tx.Payload.FromSignature,
+		ToSignature:   crypto.SignExpiry(n.PrivKey, tx.Payload.FromSignature),
 	}
 
-	return convertBlockToServer(block), nil
-}
-
-// RejectTransaction はトランザクションを拒否する
-func (n *Node) RejectTransaction(id string) error {
-	// プールから取得
-	pendingTx := n.PendingPool.Get(id)
-	if pendingTx == nil {
-		return fmt.Errorf("pending transaction not found: %s", id)
+	data, err := json.Marshal(reqBody)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	// 自ノードが受取側(to)であることを確認
-	txData, err := pendingTx.GetTransactionData()
+	url := p2p.PeerURL(addr, "/transaction/expired")
+	resp, err := n.peerClient.Post(url, "application/json", bytes.NewReader(data))
 	if err != nil {
-		return fmt.Errorf("failed to get transaction data: %w", err)
-	}
-	if txData.To != n.Config.NodeName {
-		return fmt.Errorf("only the recipient node can reject this transaction")
+		return fmt.Errorf("failed to send request: %w", err)
 	}
+	defer resp.Body.Close()
 
-	// プールから削除
-	n.PendingPool.Remove(id)
-
-	// 永続化
-	items := n.PendingPool.List()
-	if err := n.PendingStore.Save(items); err != nil {
-		log.Printf("Warning: failed to save pending transactions: %v", err)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
 	}
 
+	log.Printf("Expiry notification sent to %s", addr)
 	return nil
 }
 
@@ -402,20 +1370,9 @@ func (n *Node) ListPending() []*server.PendingTransaction {
 	items := n.PendingPool.GetByToNode(n.Config.NodeName)
 	result := make([]*server.PendingTransaction, 0, len(items))
 	for _, item := range items {
-		txData, err := item.GetTransactionData()
-		if err != nil {
-			continue
+		if pt := convertPendingToServer(item); pt != nil {
+			result = append(result, pt)
 		}
-		result = append(result, &server.PendingTransaction{
-			Transaction: &server.TransactionData{
-				From:   txData.From,
-				To:     txData.To,
-				Amount: txData.Amount,
-				Title:  txData.Title,
-			},
-			FromSig: item.Payload.FromSignature,
-			ID:      item.ID,
-		})
 	}
 	return result
 }
@@ -425,20 +1382,9 @@ func (n *Node) ListProposed() []*server.PendingTransaction {
 	items := n.PendingPool.GetByFromNode(n.Config.NodeName)
 	result := make([]*server.PendingTransaction, 0, len(items))
 	for _, item := range items {
-		txData, err := item.GetTransactionData()
-		if err != nil {
-			continue
+		if pt := convertPendingToServer(item); pt != nil {
+			result = append(result, pt)
 		}
-		result = append(result, &server.PendingTransaction{
-			Transaction: &server.TransactionData{
-				From:   txData.From,
-				To:     txData.To,
-				Amount: txData.Amount,
-				Title:  txData.Title,
-			},
-			FromSig: item.Payload.FromSignature,
-			ID:      item.ID,
-		})
 	}
 	return result
 }
@@ -449,6 +1395,12 @@ func (n *Node) GetPending(id string) *server.PendingTransaction {
 	if item == nil {
 		return nil
 	}
+	return convertPendingToServer(item)
+}
+
+// convertPendingToServer はcore.PendingTransactionをserver.PendingTransactionに変換する
+// トランザクションデータを取り出せない場合は nil を返す
+func convertPendingToServer(item *core.PendingTransaction) *server.PendingTransaction {
 	txData, err := item.GetTransactionData()
 	if err != nil {
 		return nil
@@ -467,6 +1419,32 @@ func (n *Node) GetPending(id string) *server.PendingTransaction {
 
 // RegisterNode はノードを登録する
 func (n *Node) RegisterNode(nodeName, nickName, address, publicKey string) (*server.Block, error) {
+	if err := n.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	n.chainLock.Lock()
+	defer n.chainLock.Unlock()
+
+	if _, err := crypto.HexToPublicKey(publicKey); err != nil {
+		return nil, err
+	}
+
+	// 自ノードを指すアドレスの別ノードを登録すると、ブロードキャストや同期が自ノードに戻ってくる
+	// 自ノード自身の登録（アドレス・ニックネームの更新）は名前で除外されるため受け付ける
+	if nodeName != n.Config.NodeName && n.isSelfAddress(address) {
+		return nil, fmt.Errorf("address %s points to this node", address)
+	}
+
+	// ノード名は大文字・小文字を区別しない（ブロック生成前に弾く）
+	if existing := n.NodeStore.CaseConflict(nodeName); existing != "" {
+		return nil, fmt.Errorf("node name conflicts with existing node %s (names are case-insensitive)", existing)
+	}
+	// 登録済みのノードを別の鍵で上書きさせない（同じ鍵での再登録はアドレス・ニックネームの更新として受け付ける）
+	if existing, err := n.NodeStore.Load(nodeName); err == nil && !strings.EqualFold(existing.PublicKey, publicKey) {
+		return nil, fmt.Errorf("%w: %s", server.ErrNodeConflict, nodeName)
+	}
+
 	// ブロック生成
 	lastBlock := n.Chain.LastBlock()
 	prevHash := lastBlock.Header.Hash
@@ -483,6 +1461,7 @@ func (n *Node) RegisterNode(nodeName, nickName, address, publicKey string) (*ser
 	if err != nil {
 		return nil, fmt.Errorf("failed to create block: %w", err)
 	}
+	block.ClampCreatedAt(lastBlock)
 
 	// チェーンに追加
 	if err := n.Chain.AddBlock(block); err != nil {
@@ -491,7 +1470,7 @@ func (n *Node) RegisterNode(nodeName, nickName, address, publicKey string) (*ser
 
 	// 永続化
 	if err := n.BlockStore.Append(block); err != nil {
-		return nil, fmt.Errorf("failed to persist block: %w", err)
+		return nil, fmt.Errorf("failed to persist block: %w", n.storageError(err))
 	}
 
 	// ノードファイル保存
@@ -502,13 +1481,71 @@ func (n *Node) RegisterNode(nodeName, nickName, address, publicKey string) (*ser
 		PublicKey: publicKey,
 	}
 	if err := n.NodeStore.Save(nodeName, nodeInfo); err != nil {
-		log.Printf("Warning: failed to save node file: %v", err)
+		log.Printf("Warning: failed to save node file: %v", n.storageError(err))
+	}
+
+	return convertBlockToServer(block), nil
+}
+
+// UpdateNickname は自ノードのニックネームを変更する
+// 自ノードの鍵で署名した add_node ブロックをチェーンに追加し、ピアはこれを受信してノードファイルを更新する
+func (n *Node) UpdateNickname(nickName string) (*server.Block, error) {
+	if err := n.checkWritable(); err != nil {
+		return nil, err
+	}
+
+	self, err := n.NodeStore.Load(n.Config.NodeName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load own node info: %w", err)
 	}
 
+	addNodeData := &core.AddNodeData{
+		PublicKey: self.PublicKey,
+		NodeName:  n.Config.NodeName,
+		NickName:  nickName,
+		Address:   self.Address,
+	}
+	dataBytes, err := json.Marshal(addNodeData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal add_node data: %w", err)
+	}
+
+	payload := core.BlockPayload{
+		Type:          "add_node",
+		Data:          dataBytes,
+		FromSignature: crypto.Sign(n.PrivKey, dataBytes),
+		ToSignature:   "",
+	}
+
+	n.chainLock.Lock()
+	defer n.chainLock.Unlock()
+
+	lastBlock := n.Chain.LastBlock()
+	block := core.NewBlock(lastBlock.Header.Index+1, lastBlock.Header.Hash, payload)
+	block.ClampCreatedAt(lastBlock)
+
+	// チェーンに追加
+	if err := n.Chain.AddBlock(block); err != nil {
+		return nil, fmt.Errorf("failed to add block to chain: %w", err)
+	}
+
+	// 永続化
+	if err := n.BlockStore.Append(block); err != nil {
+		return nil, fmt.Errorf("failed to persist block: %w", n.storageError(err))
+	}
+
+	// 自ノードのノードファイルと設定を更新
+	self.NickName = nickName
+	if err := n.NodeStore.Save(n.Config.NodeName, self); err != nil {
+		log.Printf("Warning: failed to save node file: %v", n.storageError(err))
+	}
+	n.Config.NickName = nickName
+
 	return convertBlockToServer(block), nil
 }
 
 // GetPeers はピアノード情報を返す
+// 呼び出しごとに新しい NodeInfo を作って返すため、呼び出し側が変更してもノードの状態には影響しない
 func (n *Node) GetPeers() map[string]*server.NodeInfo {
 	peers, err := n.NodeStore.LoadAll()
 	if err != nil {
@@ -528,16 +1565,129 @@ func (n *Node) GetPeers() map[string]*server.NodeInfo {
 	return result
 }
 
+// PingPeers は自ノード以外の各ピアの GET /info に並行して問い合わせ、到達できたかをピア名ごとに返す
+// 結果はブロードキャストと同じ到達性の記録に反映する（到達不能から復帰したピアには直近のブロックを再送する）
+func (n *Node) PingPeers(ctx context.Context) map[string]bool {
+	peers, err := n.NodeStore.LoadAll()
+	if err != nil {
+		log.Printf("Warning: failed to load peers for ping: %v", err)
+		return map[string]bool{}
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	result := make(map[string]bool, len(peers))
+	for name, peer := range peers {
+		if name == n.Config.NodeName || n.isSelfAddress(peer.Address) {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := p2p.FetchInfo(ctx, n.peerClient, peer.Address)
+			reachable := p2p.IsReachable(err)
+			n.recordReachability(name, peer.Address, reachable)
+
+			mu.Lock()
+			result[name] = reachable
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return result
+}
+
+// CheckPeerHealth は PingPeers でピアに問い合わせ、到達性と最後に応答があった時刻を返す（server.NodeServiceインターフェース実装）
+func (n *Node) CheckPeerHealth(ctx context.Context) map[string]*server.PeerHealth {
+	result := make(map[string]*server.PeerHealth)
+	for name, reachable := range n.PingPeers(ctx) {
+		health := &server.PeerHealth{Reachable: reachable}
+		if st, ok := n.reachability.Status(name); ok {
+			health.Failures = st.Failures
+			if !st.LastSeen.IsZero() {
+				health.LastSeen = st.LastSeen.Unix()
+			}
+		}
+		result[name] = health
+	}
+	return result
+}
+
 // GetNodeName は自ノード名を返す
 func (n *Node) GetNodeName() string {
 	return n.Config.NodeName
 }
 
+// GetMetrics は監視用の統計値を返す（server.NodeServiceインターフェース実装）
+func (n *Node) GetMetrics() *server.Metrics {
+	metrics := &server.Metrics{
+		ChainLength:    n.Chain.Len(),
+		PendingCount:   n.PendingPool.Len(),
+		BlocksReceived: n.blocksReceived.Load(),
+		BroadcastsSent: n.broadcastsSent.Load(),
+	}
+	for name := range n.GetPeers() {
+		if name != n.Config.NodeName {
+			metrics.Peers++
+		}
+	}
+	return metrics
+}
+
+// StartupReport は起動時に読み込んだ状態のまとめを表す
+type StartupReport struct {
+	Height      int
+	GenesisHash string
+	Peers       int
+	Pending     int
+	ListenAddr  string
+	SyncChanged bool
+}
+
+// String はレポートを key=value 形式の1行で返す
+func (r *StartupReport) String() string {
+	return fmt.Sprintf("Startup report: height=%d genesis=%s peers=%d pending=%d listen=%s sync_changed=%t",
+		r.Height, r.GenesisHash, r.Peers, r.Pending, r.ListenAddr, r.SyncChanged)
+}
+
+// StartupReport は現在のノード状態から起動レポートを作成する
+// syncChanged には起動時の同期でチェーンが変わったかを渡す
+func (n *Node) StartupReport(listenAddr string, syncChanged bool) *StartupReport {
+	report := &StartupReport{
+		Height:      n.Chain.GetLastIndex(),
+		Pending:     n.PendingPool.Len(),
+		ListenAddr:  listenAddr,
+		SyncChanged: syncChanged,
+	}
+
+	if genesis, err := n.Chain.GetBlockByIndex(0); err == nil {
+		report.GenesisHash = genesis.Header.Hash
+	}
+
+	for name := range n.GetPeers() {
+		if name != n.Config.NodeName {
+			report.Peers++
+		}
+	}
+
+	return report
+}
+
+// seenBlocksSize は転送済みとして覚えておくブロック数
+// 同じブロックが戻ってくるのは直後に限られるため、直近の分だけで十分
+const seenBlocksSize = 1024
+
 // BroadcastBlock はブロックを全ピアにブロードキャストする
+// 自ノードで生成したブロックの最初のブロードキャストは server のハンドラー（approve / register / nickname）が、
+// 受信したブロックの転送は ReceiveBlock が行う。既に転送したブロック（ハッシュで判定）は再送しない
 func (n *Node) BroadcastBlock(b *server.Block) {
+	if !n.seenBlocks.Mark(b.Header.Hash) {
+		return
+	}
+	n.broadcastsSent.Add(1)
+
 	n.broadcastLock.Lock()
 	defer n.broadcastLock.Unlock()
-
 	// ピア取得
 	peers, err := n.NodeStore.LoadAll()
 	if err != nil {
@@ -545,87 +1695,453 @@ func (n *Node) BroadcastBlock(b *server.Block) {
 		return
 	}
 
+	// 到達不能なピアへの送信がタイムアウトするまで到達可能なピアへの伝播が遅れないよう、
+	// 到達不能なピアは DeadPeerSkip の間見送り、期間が過ぎたピアにはバックグラウンドで再試行する
+	live := make(map[string]*storage.NodeInfo, len(peers))
+	dead := make(map[string]*storage.NodeInfo)
+	for name, peer := range peers {
+		// 名前が異なっても自ノードを指すピアには送らない
+		if name != n.Config.NodeName && n.isSelfAddress(peer.Address) {
+			continue
+		}
+		if st, ok := n.reachability.Status(name); ok && !st.Reachable {
+			if n.reachability.ShouldSkip(name, n.Config.DeadPeerSkip()) {
+				continue
+			}
+			dead[name] = peer
+			continue
+		}
+		live[name] = peer
+	}
+
+	if len(dead) > 0 {
+		go n.broadcastTo(b, dead)
+	}
+	n.broadcastTo(b, live)
+}
+
+// broadcastTo は peers にブロックを送信し、到達性を記録する
+func (n *Node) broadcastTo(b *server.Block, peers map[string]*storage.NodeInfo) {
 	// server.Block をそのまま渡す（受信側も server.Block でデコードする）
-	p2p.BroadcastBlock(b, peers, n.Config.NodeName)
+	results := p2p.BroadcastBlock(n.background, n.peerClient, b, peers, n.Config.NodeName)
+	for name, err := range results {
+		n.recordReachability(name, peers[name].Address, p2p.IsReachable(err))
+	}
+}
+
+// recordReachability はピアの到達性を記録し、到達不能から復帰したピアには直近のブロックを再送する
+func (n *Node) recordReachability(name, addr string, reachable bool) {
+	if n.reachability.Record(name, reachable) {
+		log.Printf("Peer %s (%s) is reachable again", name, addr)
+		go n.resendRecentBlocks(name, addr)
+	}
+}
+
+// resendRecentBlocks は直近 RebroadcastBlocks 個のブロックを古い順にピアへ送信する
+// ピアが停止中に取りこぼしたブロックを補う（既に持っているブロックは受信側で無視される）
+func (n *Node) resendRecentBlocks(name, addr string) {
+	depth := n.Config.RebroadcastBlocks
+	if depth <= 0 {
+		return
+	}
+
+	last := n.Chain.GetLastIndex()
+	blocks, err := n.Chain.GetRange(last+1-depth, last+1)
+	if err != nil {
+		log.Printf("Warning: failed to load recent blocks to re-broadcast to %s: %v", name, err)
+		return
+	}
+
+	for _, b := range blocks {
+		if err := p2p.SendBlock(n.background, n.peerClient, addr, n.Config.NodeName, convertBlockToServer(b)); err != nil {
+			if !p2p.IsReachable(err) {
+				n.reachability.Record(name, false)
+				log.Printf("Warning: peer %s became unreachable during re-broadcast: %v", name, err)
+				return
+			}
+			// 取りこぼしが再送数より多い場合などは受信側で拒否される（同期で補う）
+			log.Printf("Warning: peer %s rejected re-broadcast block %d: %v", name, b.Header.Index, err)
+		}
+	}
+	log.Printf("Re-broadcast %d recent blocks to %s", len(blocks), name)
+}
+
+// syncStragglerTimeout は同期で採用できる候補が見つかった後、応答の遅いピアを待つ時間
+// 待ちきれなかったピアのチェーンは次回の同期で比較する
+var syncStragglerTimeout = 2 * time.Second
+
+// syncResult は同期で1つのピアから取得した候補チェーン（または取得エラー）を表す
+type syncResult struct {
+	name   string
+	blocks []*core.Block
+	err    error
 }
 
-// SyncChain は全ピアからチェーンを取得し、最長チェーンで同期する
+// syncSelection は同期中の候補チェーンの選択状態を表す
+// 信頼ピアとそれ以外のピアの最良候補を分けて持ち、最後に TrustedPeerPolicy に従って選ぶ
+type syncSelection struct {
+	local            []*core.Block
+	trusted          map[string]bool
+	trustedBest      []*core.Block
+	otherBest        []*core.Block
+	trustedResponded bool
+}
+
+// SyncChain は全ピアから並行してチェーンを取得し、最長チェーンで同期する
 func (n *Node) SyncChain() error {
+	if err := n.checkWritable(); err != nil {
+		return err
+	}
+
 	peers, err := n.NodeStore.LoadAll()
 	if err != nil {
 		return fmt.Errorf("failed to load peers: %w", err)
 	}
 
-	var longestBlocks []*core.Block
-	maxLen := n.Chain.Len()
+	local, err := n.Chain.GetBlocks()
+	if err != nil {
+		return fmt.Errorf("failed to load local chain: %w", err)
+	}
+
+	// ピアからの取得は時間がかかるためロック外で、ピアに並行して行う
+	// 候補は core.PreferChain（長さ優先、同じ長さなら末尾ハッシュが小さい方）で選ぶ
+	// 同じ長さなら信頼ピアのチェーンを優先する
+	sel := &syncSelection{
+		local:   local,
+		trusted: make(map[string]bool, len(n.Config.TrustedPeers)),
+	}
+	for _, name := range n.Config.TrustedPeers {
+		sel.trusted[name] = true
+	}
+
+	// authoritative: 先に信頼ピアに問い合わせ、いずれかから取得できればそれ以外のピアは問い合わせない
+	order := n.syncOrder(peers)
+	authoritative := n.Config.TrustedPeerPolicy != "prefer"
+	tiers := [][]string{order}
+	if authoritative {
+		var trustedNames, others []string
+		for _, name := range order {
+			if sel.trusted[name] {
+				trustedNames = append(trustedNames, name)
+			} else {
+				others = append(others, name)
+			}
+		}
+		tiers = [][]string{trustedNames, others}
+	}
 
-	for name, peer := range peers {
-		if name == n.Config.NodeName {
-			continue
+	// 同期を終えたら（Shutdown でも）応答待ちのリクエストを中断する
+	ctx, cancel := context.WithCancel(n.background)
+	defer cancel()
+	for _, names := range tiers {
+		if authoritative && sel.trustedResponded {
+			break
 		}
+		n.collectSyncCandidates(ctx, names, peers, sel)
+	}
 
-		serverBlocks, err := n.fetchChain(peer.Address)
-		if err != nil {
-			log.Printf("Warning: failed to fetch chain from %s (%s): %v", name, peer.Address, err)
+	bestBlocks := sel.trustedBest
+	if !(authoritative && sel.trustedResponded) && sel.otherBest != nil &&
+		preferSyncCandidate(sel.otherBest, false, sel.local, sel.trustedBest, sel.trustedBest != nil) {
+		bestBlocks = sel.otherBest
+	}
+
+	n.chainLock.Lock()
+	defer n.chainLock.Unlock()
+
+	// 自分より優先されるチェーンが見つかった場合は置換（取得中に伸びている可能性があるためロック下で再比較）
+	// ローカルのチェーンを先頭に含む（単に遅れている）場合は不足分だけを追記する
+	current, err := n.Chain.GetBlocks()
+	if err != nil {
+		return fmt.Errorf("failed to load local chain: %w", err)
+	}
+	if bestBlocks != nil && core.PreferChain(bestBlocks, current) && bestBlocks[len(current)-1].Header.Hash == current[len(current)-1].Header.Hash {
+		for _, b := range bestBlocks[len(current):] {
+			if err := n.Chain.AddBlock(b); err != nil {
+				return fmt.Errorf("failed to add synced block: %w", err)
+			}
+			if err := n.BlockStore.Append(b); err != nil {
+				return fmt.Errorf("failed to persist synced block: %w", n.storageError(err))
+			}
+			n.applyNodeUpdate(b)
+		}
+		n.removeCommittedPending(bestBlocks[len(current):])
+		log.Printf("Chain synced: appended %d blocks", len(bestBlocks)-len(current))
+	} else if bestBlocks != nil && core.PreferChain(bestBlocks, current) {
+		if err := n.Chain.ReplaceChain(bestBlocks); err != nil {
+			return fmt.Errorf("failed to replace chain: %w", err)
+		}
+		// 永続化
+		if err := n.BlockStore.ReplaceAll(bestBlocks); err != nil {
+			return fmt.Errorf("failed to persist replaced chain: %w", n.storageError(err))
+		}
+		n.removeCommittedPending(bestBlocks)
+		log.Printf("Chain synced: %d blocks", len(bestBlocks))
+	}
+
+	return nil
+}
+
+// collectSyncCandidates は names のピアに並行してチェーンを問い合わせ、検証した候補を sel に反映する
+// 採用できる候補が見つかったら、応答の遅いピアは syncStragglerTimeout だけ待って打ち切る（ctx のキャンセルは呼び出し側で行う）
+func (n *Node) collectSyncCandidates(ctx context.Context, names []string, peers map[string]*storage.NodeInfo, sel *syncSelection) {
+	results := make(chan syncResult, len(names))
+	for _, name := range names {
+		addr := peers[name].Address
+		go func() {
+			blocks, err := n.fetchSyncCandidate(ctx, addr, sel.local)
+			results <- syncResult{name: name, blocks: blocks, err: err}
+		}()
+	}
+
+	var straggler <-chan time.Time
+	for remaining := len(names); remaining > 0; remaining-- {
+		var res syncResult
+		select {
+		case res = <-results:
+		case <-straggler:
+			log.Printf("Chain sync: not waiting for %d slow peers", remaining)
+			return
+		}
+
+		addr := peers[res.name].Address
+		n.recordReachability(res.name, addr, p2p.IsReachable(res.err))
+		if res.err != nil {
+			log.Printf("Warning: failed to fetch chain from %s (%s): %v", res.name, addr, res.err)
+			continue
+		}
+		trusted := sel.trusted[res.name]
+		if res.blocks == nil {
+			// ピアのチェーンはローカルより長くない（同じ長さなら末尾も一致）ため採用候補にならない
+			if trusted {
+				sel.trustedResponded = true
+			}
 			continue
 		}
 
-		// server.Block -> core.Block に変換
-		coreBlocks := make([]*core.Block, len(serverBlocks))
-		for i, sb := range serverBlocks {
-			coreBlocks[i] = convertServerToBlock(sb)
+		// 長さとハッシュだけでは偽造された取引を含むチェーンを採用してしまうため、署名まで検証する
+		// （信頼ピアは応答の有無で authoritative の判定に使うため、優先されないチェーンでも検証する）
+		best := &sel.otherBest
+		if trusted {
+			best = &sel.trustedBest
+		}
+		if !trusted && !preferSyncCandidate(res.blocks, false, sel.local, *best, false) {
+			continue
 		}
+		if index, err := n.validateBlocksWithSignatures(res.blocks); err != nil {
+			log.Printf("Warning: rejected chain from %s (%s): block %d: %v", res.name, addr, index, err)
+			continue
+		}
+		if trusted {
+			sel.trustedResponded = true
+		}
+		if preferSyncCandidate(res.blocks, trusted, sel.local, *best, trusted) {
+			*best = res.blocks
+			if straggler == nil {
+				straggler = time.After(syncStragglerTimeout)
+			}
+		}
+	}
+}
+
+// RollbackChain はチェーンをインデックス index のブロックまで巻き戻し、取り除いたブロック数を返す（管理者による復旧用）
+// block.jsonl を先に切り詰めてからメモリ上のチェーンを巻き戻す。ジェネシスより前や末尾より後は指定できない
+// 取り除いたブロックに含まれるノード登録は nodes/ に残る
+func (n *Node) RollbackChain(index int) (int, error) {
+	if err := n.checkWritable(); err != nil {
+		return 0, err
+	}
+
+	n.chainLock.Lock()
+	defer n.chainLock.Unlock()
+
+	if last := n.Chain.GetLastIndex(); index < 0 || index > last {
+		return 0, fmt.Errorf("invalid rollback index %d: must be between 0 and %d", index, last)
+	}
+	if err := n.BlockStore.TruncateAfter(index); err != nil {
+		return 0, fmt.Errorf("failed to truncate block file: %w", n.storageError(err))
+	}
+	removed, err := n.Chain.RemoveAfter(index)
+	if err != nil {
+		return 0, fmt.Errorf("failed to roll back chain: %w", err)
+	}
+
+	log.Printf("Chain rolled back to index %d: removed %d blocks", index, len(removed))
+	return len(removed), nil
+}
 
-		if len(coreBlocks) > maxLen {
-			maxLen = len(coreBlocks)
-			longestBlocks = coreBlocks
+// syncOrder は同期時にピアへ問い合わせる順序を返す
+// TrustedPeers（設定順）を先に、それ以外のピアを名前順に並べる。自ノードと未登録の信頼ピアは含めない
+func (n *Node) syncOrder(peers map[string]*storage.NodeInfo) []string {
+	order := make([]string, 0, len(peers))
+	seen := make(map[string]bool, len(peers))
+	for _, name := range n.Config.TrustedPeers {
+		if peer, ok := peers[name]; ok && name != n.Config.NodeName && !n.isSelfAddress(peer.Address) && !seen[name] {
+			order = append(order, name)
+			seen[name] = true
 		}
 	}
 
-	// 自分より長いチェーンが見つかった場合は置換
-	if longestBlocks != nil && len(longestBlocks) > n.Chain.Len() {
-		if err := n.Chain.ReplaceChain(longestBlocks); err != nil {
-			return fmt.Errorf("failed to replace chain: %w", err)
+	rest := make([]string, 0, len(peers))
+	for name, peer := range peers {
+		if name != n.Config.NodeName && !n.isSelfAddress(peer.Address) && !seen[name] {
+			rest = append(rest, name)
 		}
-		// 永続化
-		if err := n.BlockStore.ReplaceAll(longestBlocks); err != nil {
-			return fmt.Errorf("failed to persist replaced chain: %w", err)
+	}
+	sort.Strings(rest)
+	return append(order, rest...)
+}
+
+// isSelfAddress は addr が自ノードの待ち受けアドレス（Config.ListenAddress）を指すかを返す
+// ポートが同じで、ホストが一致する場合のほか、ループバック（localhost を含む）で待ち受けていればループバックのホスト、
+// すべてのインターフェース（0.0.0.0 など）で待ち受けていればループバックと自ホストの IP も自ノードとみなす
+func (n *Node) isSelfAddress(addr string) bool {
+	if addr == "" {
+		return false
+	}
+	host, port := config.ParseAddress(addr)
+	selfHost, selfPort := config.ParseAddress(n.Config.ListenAddress())
+	if port != selfPort {
+		return false
+	}
+	if strings.EqualFold(host, selfHost) {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	loopback := strings.EqualFold(host, "localhost") || (ip != nil && ip.IsLoopback())
+	selfIP := net.ParseIP(selfHost)
+	switch {
+	case selfHost == "" || (selfIP != nil && selfIP.IsUnspecified()):
+		return loopback || (ip != nil && isLocalIP(ip))
+	case strings.EqualFold(selfHost, "localhost") || (selfIP != nil && selfIP.IsLoopback()):
+		return loopback
+	}
+	return false
+}
+
+// isLocalIP は ip が自ホストのネットワークインターフェースのアドレスかを返す
+func isLocalIP(ip net.IP) bool {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false
+	}
+	for _, a := range addrs {
+		if ipNet, ok := a.(*net.IPNet); ok && ipNet.IP.Equal(ip) {
+			return true
 		}
-		log.Printf("Chain synced: %d blocks", len(longestBlocks))
 	}
+	return false
+}
 
-	return nil
+// preferSyncCandidate は同期の候補チェーンが、ローカルのチェーンとそれまでの最良候補の両方より優先されるかを返す
+// 最良候補と同じ長さの場合は信頼ピアのチェーンを優先し、どちらも同じ扱いなら core.PreferChain で決める
+func preferSyncCandidate(candidate []*core.Block, candidateTrusted bool, local, best []*core.Block, bestTrusted bool) bool {
+	if !core.PreferChain(candidate, local) {
+		return false
+	}
+	if best == nil {
+		return true
+	}
+	if len(candidate) == len(best) && candidateTrusted != bestTrusted {
+		return candidateTrusted
+	}
+	return core.PreferChain(candidate, best)
 }
 
-// fetchChain は指定したアドレスからチェーンを取得する
-func (n *Node) fetchChain(addr string) ([]*server.Block, error) {
-	url := fmt.Sprintf("http://%s/chain", addr)
-	resp, err := httpClient.Get(url)
+// checkPeerVersion はピアのバージョンが自ノードと異なれば警告する
+// バージョンを返さない古いピアは比較しない。プロトコルの互換性は判定せず、混在の把握を助けるためのもの
+func (n *Node) checkPeerVersion(addr string, info *p2p.PeerInfo) {
+	if info.Version == "" || info.Version == version.Version {
+		n.peerVersions.Delete(info.NodeName)
+		return
+	}
+	if prev, ok := n.peerVersions.Swap(info.NodeName, info.Version); !ok || prev != info.Version {
+		log.Printf("Warning: peer %s (%s) runs version %s, local version is %s", info.NodeName, addr, info.Version, version.Version)
+	}
+}
+
+// fetchSyncCandidate は同期の候補となるピアのチェーン全体を返す
+// まず GET /info でチェーン長を確認し、ローカルより長ければ不足分のみを GET /chain?from= で取得して
+// ローカルのチェーンの後ろにつなげる。末尾がつながらない（フォーク）場合や同じ長さで末尾が異なる場合はチェーン全体を取得する。
+// ピアのチェーンがローカルより長くない（同じ長さなら末尾も一致）場合は nil を返す
+func (n *Node) fetchSyncCandidate(ctx context.Context, addr string, local []*core.Block) ([]*core.Block, error) {
+	info, err := p2p.FetchInfo(ctx, n.peerClient, addr)
 	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
+		var statusErr *p2p.StatusError
+		if !errors.As(err, &statusErr) {
+			return nil, err
+		}
+		// /info に対応していないピアはチェーン全体で比較する
+		return n.fetchFullChain(ctx, addr)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+	n.checkPeerVersion(addr, info)
+
+	tip := local[len(local)-1]
+	if info.ChainLength < len(local) {
+		return nil, nil
 	}
 
-	var blocks []*server.Block
-	if err := json.NewDecoder(resp.Body).Decode(&blocks); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	// 同じ長さなら末尾だけ、長ければ不足分（末尾の次から）を取得する
+	from := len(local)
+	if info.ChainLength == len(local) {
+		from = len(local) - 1
 	}
+	serverBlocks, err := p2p.FetchChainFrom[*server.Block](ctx, n.peerClient, addr, from)
+	if err != nil {
+		return nil, err
+	}
+	fetched := make([]*core.Block, len(serverBlocks))
+	for i, sb := range serverBlocks {
+		fetched[i] = convertServerToBlock(sb)
+	}
+
+	switch {
+	case len(fetched) > 0 && fetched[0].Header.Index == tip.Header.Index && fetched[0].Header.Hash == tip.Header.Hash:
+		// 同じ長さで末尾が一致（取得までに伸びていればその分を候補にする）
+		if len(fetched) == 1 {
+			return nil, nil
+		}
+		return append(local[:len(local):len(local)], fetched[1:]...), nil
+	case len(fetched) > 0 && fetched[0].Header.Index == 0:
+		// 範囲指定に対応していないピアはチェーン全体を返す
+		return fetched, nil
+	case len(fetched) > 0 && fetched[0].Header.Index == tip.Header.Index+1 && fetched[0].Header.PrevHash == tip.Header.Hash:
+		return append(local[:len(local):len(local)], fetched...), nil
+	default:
+		// フォーク（末尾がつながらない）ならチェーン全体で比較する
+		return n.fetchFullChain(ctx, addr)
+	}
+}
 
+// fetchFullChain はピアのチェーン全体を取得して core.Block に変換する
+func (n *Node) fetchFullChain(ctx context.Context, addr string) ([]*core.Block, error) {
+	serverBlocks, err := n.fetchChain(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	blocks := make([]*core.Block, len(serverBlocks))
+	for i, sb := range serverBlocks {
+		blocks[i] = convertServerToBlock(sb)
+	}
 	return blocks, nil
 }
 
+// fetchChain は指定したアドレスからチェーンを取得する
+func (n *Node) fetchChain(ctx context.Context, addr string) ([]*server.Block, error) {
+	return p2p.FetchChain[*server.Block](ctx, n.peerClient, addr)
+}
+
 // convertBlockToServer はcore.Blockをserver.Blockに変換する
 func convertBlockToServer(b *core.Block) *server.Block {
 	serverBlock := &server.Block{
 		Header: server.BlockHeader{
+			Version:   b.Header.Version,
 			Index:     b.Header.Index,
 			CreatedAt: b.Header.CreatedAt.Unix(),
 			PrevHash:  b.Header.PrevHash,
+			TxRoot:    b.Header.TxRoot,
 			Hash:      b.Header.Hash,
 		},
 		Payload: server.BlockPayload{
@@ -636,23 +2152,24 @@ func convertBlockToServer(b *core.Block) *server.Block {
 	}
 
 	// ペイロードデータをコピー
-	if b.Payload.Type == "transaction" {
-		if txData, err := b.GetTransactionData(); err == nil {
-			serverBlock.Payload.Transaction = &server.TransactionData{
-				From:   txData.From,
-				To:     txData.To,
-				Amount: txData.Amount,
-				Title:  txData.Title,
-			}
+	data, err := b.DecodePayload()
+	if err != nil {
+		return serverBlock
+	}
+	switch d := data.(type) {
+	case *core.TransactionData:
+		serverBlock.Payload.Transaction = &server.TransactionData{
+			From:   d.From,
+			To:     d.To,
+			Amount: d.Amount,
+			Title:  d.Title,
 		}
-	} else if b.Payload.Type == "add_node" {
-		if addNodeData, err := b.GetAddNodeData(); err == nil {
-			serverBlock.Payload.AddNode = &server.AddNodeData{
-				PublicKey: addNodeData.PublicKey,
-				NodeName:  addNodeData.NodeName,
-				NickName:  addNodeData.NickName,
-				Address:   addNodeData.Address,
-			}
+	case *core.AddNodeData:
+		serverBlock.Payload.AddNode = &server.AddNodeData{
+			PublicKey: d.PublicKey,
+			NodeName:  d.NodeName,
+			NickName:  d.NickName,
+			Address:   d.Address,
 		}
 	}
 
@@ -663,9 +2180,11 @@ func convertBlockToServer(b *core.Block) *server.Block {
 func convertServerToBlock(b *server.Block) *core.Block {
 	coreBlock := &core.Block{
 		Header: core.BlockHeader{
+			Version:   b.Header.Version,
 			Index:     b.Header.Index,
 			CreatedAt: time.Unix(b.Header.CreatedAt, 0).UTC(),
 			PrevHash:  b.Header.PrevHash,
+			TxRoot:    b.Header.TxRoot,
 			Hash:      b.Header.Hash,
 		},
 		Payload: core.BlockPayload{
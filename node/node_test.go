@@ -0,0 +1,2483 @@
+package node
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"signet/config"
+	"signet/core"
+	"signet/crypto"
+	"signet/server"
+	"signet/storage"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// newTestNode は一時ディレクトリ上に初期化済みのノードを作成する（signet init 相当）
+func newTestNode(t *testing.T, name string) *Node {
+	t.Helper()
+
+	rootDir := t.TempDir()
+	cfg := &config.Config{
+		RootDir:  rootDir,
+		Address:  "127.0.0.1:0",
+		NickName: name,
+		NodeName: name,
+		Port:     config.DefaultPort,
+
+		AllowNegativeBalance: true,
+	}
+
+	pubKey, privKey, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+	if err := crypto.SavePrivateKey(cfg.PrivKeyPath(), privKey); err != nil {
+		t.Fatalf("SavePrivateKey() error = %v", err)
+	}
+	if err := storage.NewBlockStore(cfg.BlockFilePath()).Append(core.NewGenesisBlock()); err != nil {
+		t.Fatalf("Append(genesis) error = %v", err)
+	}
+	self := &storage.NodeInfo{
+		Name:      name,
+		NickName:  name,
+		Address:   cfg.Address,
+		PublicKey: hex.EncodeToString(pubKey),
+	}
+	if err := storage.NewNodeStore(filepath.Join(rootDir, "nodes")).Save(name, self); err != nil {
+		t.Fatalf("Save(self) error = %v", err)
+	}
+
+	n, err := NewNode(cfg)
+	if err != nil {
+		t.Fatalf("NewNode() error = %v", err)
+	}
+	return n
+}
+
+// addPeer は other をピアとして n のノードストアに登録する
+func addPeer(t *testing.T, n *Node, other *Node, addr string) {
+	t.Helper()
+
+	info := &storage.NodeInfo{
+		Name:      other.Config.NodeName,
+		NickName:  other.Config.NickName,
+		Address:   addr,
+		PublicKey: hex.EncodeToString(other.PubKey),
+	}
+	if err := n.NodeStore.Save(other.Config.NodeName, info); err != nil {
+		t.Fatalf("Save(peer) error = %v", err)
+	}
+}
+
+// serveNode は n を HTTP サーバーとして起動し、そのアドレス（host:port）を返す
+func serveNode(t *testing.T, n *Node) string {
+	t.Helper()
+
+	ts := httptest.NewServer(server.NewServer("", n).Handler())
+	t.Cleanup(ts.Close)
+	return strings.TrimPrefix(ts.URL, "http://")
+}
+
+// waitFor は cond が真になるまで最大 timeout 待機する
+func waitFor(t *testing.T, timeout time.Duration, cond func() bool) bool {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return cond()
+}
+
+// copyPending は pt を別ノードのプールに積むためのコピー（転送時と同様にIDは別になる）
+func copyPending(pt *core.PendingTransaction, id string) *core.PendingTransaction {
+	cp := *pt
+	cp.ID = id
+	return &cp
+}
+
+func TestExpirePending_NotifiesProposer(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+	bob.Config.PendingTTLSeconds = 60
+
+	aliceAddr := serveNode(t, alice)
+	addPeer(t, bob, alice, aliceAddr)
+	addPeer(t, alice, bob, "127.0.0.1:1")
+
+	// alice が提案した取引が双方のプールにある状態を作る（期限切れの時刻で）
+	txData := &core.TransactionData{From: "alice", To: "bob", Amount: 500, Title: "ランチ"}
+	data, _ := core.SetTransactionData(txData)
+	sig, _ := crypto.SignTransaction(alice.PrivKey, txData)
+	payload := core.BlockPayload{Type: "transaction", Data: data, FromSignature: sig}
+
+	pt := core.NewPendingTransaction("alice-id", payload)
+	pt.CreatedAt = time.Now().UTC().Add(-2 * time.Minute)
+	alice.PendingPool.Add(pt)
+	bob.PendingPool.Add(copyPending(pt, "bob-id"))
+
+	if removed := bob.ExpirePending(); removed != 1 {
+		t.Fatalf("ExpirePending() = %d, want 1", removed)
+	}
+	if bob.PendingPool.Len() != 0 {
+		t.Errorf("bob pool length = %d, want 0", bob.PendingPool.Len())
+	}
+
+	if !waitFor(t, 2*time.Second, func() bool { return alice.PendingPool.Len() == 0 }) {
+		t.Error("proposer was not notified of expiry")
+	}
+
+	// 永続化も反映されていること
+	items, err := bob.PendingStore.Load()
+	if err != nil {
+		t.Fatalf("PendingStore.Load() error = %v", err)
+	}
+	if len(items) != 0 {
+		t.Errorf("persisted pending length = %d, want 0", len(items))
+	}
+}
+
+func TestNotifyExpired_RequiresToSignature(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+	addPeer(t, alice, bob, "127.0.0.1:1")
+
+	txData := &core.TransactionData{From: "alice", To: "bob", Amount: 500, Title: "ランチ"}
+	data, _ := core.SetTransactionData(txData)
+	fromSig, _ := crypto.SignTransaction(alice.PrivKey, txData)
+	alice.PendingPool.Add(core.NewPendingTransaction("alice-id", core.BlockPayload{Type: "transaction", Data: data, FromSignature: fromSig}))
+
+	notice := &server.TransactionData{From: "alice", To: "bob", Amount: 500, Title: "ランチ"}
+	_, malloryPriv, _ := crypto.GenerateKeyPair()
+	toSig, _ := crypto.SignTransaction(bob.PrivKey, txData)
+	forged := []struct {
+		name      string
+		signature string
+	}{
+		{"unsigned", ""},
+		{"signed by another key", crypto.SignExpiry(malloryPriv, fromSig)},
+		// 取引への To 署名は期限切れ通知の署名として使えない
+		{"transaction to signature", toSig},
+	}
+	for _, tt := range forged {
+		if err := alice.NotifyExpired(notice, fromSig, tt.signature); err == nil {
+			t.Errorf("%s: NotifyExpired() should reject the notification", tt.name)
+		}
+	}
+	if !alice.PendingPool.Has("alice-id") {
+		t.Fatal("forged notification removed the pending transaction")
+	}
+
+	if err := alice.NotifyExpired(notice, fromSig, crypto.SignExpiry(bob.PrivKey, fromSig)); err != nil {
+		t.Fatalf("NotifyExpired() error = %v", err)
+	}
+	if alice.PendingPool.Has("alice-id") {
+		t.Error("NotifyExpired() did not remove the pending transaction")
+	}
+}
+
+func TestExpirePending_DisabledByDefault(t *testing.T) {
+	n := newTestNode(t, "bob")
+
+	txData := &core.TransactionData{From: "alice", To: "bob", Amount: 500, Title: "ランチ"}
+	data, _ := core.SetTransactionData(txData)
+	pt := core.NewPendingTransaction("id", core.BlockPayload{Type: "transaction", Data: data})
+	pt.CreatedAt = time.Now().UTC().Add(-24 * time.Hour)
+	n.PendingPool.Add(pt)
+
+	if removed := n.ExpirePending(); removed != 0 {
+		t.Errorf("ExpirePending() = %d, want 0", removed)
+	}
+	if n.PendingPool.Len() != 1 {
+		t.Errorf("pool length = %d, want 1", n.PendingPool.Len())
+	}
+}
+
+func TestListExpired(t *testing.T) {
+	n := newTestNode(t, "bob")
+	n.Config.PendingTTLSeconds = 60
+	n.Config.ExpiredLogSize = 10
+
+	newPending := func(id string, age time.Duration) *core.PendingTransaction {
+		txData := &core.TransactionData{From: "alice", To: "bob", Amount: 500, Title: id}
+		data, _ := core.SetTransactionData(txData)
+		pt := core.NewPendingTransaction(id, core.BlockPayload{Type: "transaction", Data: data, FromSignature: "sig-" + id})
+		pt.CreatedAt = time.Now().UTC().Add(-age)
+		return pt
+	}
+	n.PendingPool.Add(newPending("old", 2*time.Minute))
+	n.PendingPool.Add(newPending("fresh", 0))
+
+	before := time.Now().Unix()
+	if removed := n.ExpirePending(); removed != 1 {
+		t.Fatalf("ExpirePending() = %d, want 1", removed)
+	}
+	if err := n.RejectTransaction("fresh"); err != nil {
+		t.Fatalf("RejectTransaction() error = %v", err)
+	}
+
+	// 新しい順に、理由と時刻付きで記録されていること
+	got := n.ListExpired()
+	if len(got) != 2 {
+		t.Fatalf("ListExpired() returned %d items, want 2", len(got))
+	}
+	if got[0].ID != "fresh" || got[0].Reason != core.ArchiveReasonRejected {
+		t.Errorf("got[0] = %s/%s, want fresh/%s", got[0].ID, got[0].Reason, core.ArchiveReasonRejected)
+	}
+	if got[1].ID != "old" || got[1].Reason != core.ArchiveReasonExpired {
+		t.Errorf("got[1] = %s/%s, want old/%s", got[1].ID, got[1].Reason, core.ArchiveReasonExpired)
+	}
+	if got[1].Transaction == nil || got[1].Transaction.Title != "old" || got[1].FromSig != "sig-old" {
+		t.Errorf("got[1] transaction = %+v / %s, want title old / sig-old", got[1].Transaction, got[1].FromSig)
+	}
+	for _, item := range got {
+		if item.ArchivedAt < before {
+			t.Errorf("%s ArchivedAt = %d, want >= %d", item.ID, item.ArchivedAt, before)
+		}
+	}
+
+	// 再起動後も記録が残ること
+	restarted, err := NewNode(n.Config)
+	if err != nil {
+		t.Fatalf("NewNode() error = %v", err)
+	}
+	if len(restarted.ListExpired()) != 2 {
+		t.Errorf("ListExpired() after restart returned %d items, want 2", len(restarted.ListExpired()))
+	}
+}
+
+func TestBroadcastBlock_SeenAcrossRestart(t *testing.T) {
+	n := newTestNode(t, "alice")
+
+	block, err := core.CreateBlockWithAddNode(1, n.Chain.GetLastHash(), &core.AddNodeData{NodeName: "dave", NickName: "d", Address: "10.0.0.4", PublicKey: strings.Repeat("ab", 32)})
+	if err != nil {
+		t.Fatalf("CreateBlockWithAddNode() error = %v", err)
+	}
+	sb := convertBlockToServer(block)
+	n.BroadcastBlock(sb)
+	if got := n.GetMetrics().BroadcastsSent; got != 1 {
+		t.Fatalf("BroadcastsSent = %d, want 1", got)
+	}
+
+	// 停止時に保存した記録により、再起動後も転送済みのブロックは再送しない
+	if err := n.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	restarted, err := NewNode(n.Config)
+	if err != nil {
+		t.Fatalf("NewNode() error = %v", err)
+	}
+	restarted.BroadcastBlock(sb)
+	if got := restarted.GetMetrics().BroadcastsSent; got != 0 {
+		t.Errorf("BroadcastsSent after restart = %d, want 0", got)
+	}
+}
+
+func TestListExpired_LogSize(t *testing.T) {
+	n := newTestNode(t, "bob")
+	n.Config.PendingTTLSeconds = 60
+	n.Config.ExpiredLogSize = 2
+
+	for _, id := range []string{"a", "b", "c"} {
+		txData := &core.TransactionData{From: "alice", To: "bob", Amount: 500, Title: id}
+		data, _ := core.SetTransactionData(txData)
+		pt := core.NewPendingTransaction(id, core.BlockPayload{Type: "transaction", Data: data})
+		pt.CreatedAt = time.Now().UTC().Add(-2 * time.Minute)
+		n.PendingPool.Add(pt)
+		n.ExpirePending()
+	}
+
+	got := n.ListExpired()
+	if len(got) != 2 || got[0].ID != "c" || got[1].ID != "b" {
+		ids := make([]string, len(got))
+		for i, item := range got {
+			ids[i] = item.ID
+		}
+		t.Errorf("ListExpired() IDs = %v, want [c b]", ids)
+	}
+
+	// 0 なら記録しない
+	n.Config.ExpiredLogSize = 0
+	txData := &core.TransactionData{From: "alice", To: "bob", Amount: 500, Title: "d"}
+	data, _ := core.SetTransactionData(txData)
+	n.PendingPool.Add(core.NewPendingTransaction("d", core.BlockPayload{Type: "transaction", Data: data}))
+	if err := n.RejectTransaction("d"); err != nil {
+		t.Fatalf("RejectTransaction() error = %v", err)
+	}
+	if len(n.ListExpired()) != 2 {
+		t.Errorf("ListExpired() returned %d items with ExpiredLogSize = 0, want 2", len(n.ListExpired()))
+	}
+}
+
+// registerDummyNodes は n に count 個の add_node ブロックを追加する
+func registerDummyNodes(t *testing.T, n *Node, count int) {
+	t.Helper()
+
+	for i := 0; i < count; i++ {
+		pub, _, err := crypto.GenerateKeyPair()
+		if err != nil {
+			t.Fatalf("GenerateKeyPair() error = %v", err)
+		}
+		name := "dummy" + string(rune('a'+i))
+		if _, err := n.RegisterNode(name, name, "10.0.0.1", hex.EncodeToString(pub)); err != nil {
+			t.Fatalf("RegisterNode() error = %v", err)
+		}
+	}
+}
+
+func TestSyncChain_EqualLengthFork(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+	// 同じ長さで内容の異なるフォークを作る
+	registerDummyNodes(t, alice, 2)
+	if _, err := bob.RegisterNode("other", "other", "10.0.0.2", strings.Repeat("cd", 32)); err != nil {
+		t.Fatalf("RegisterNode() error = %v", err)
+	}
+	if _, err := bob.RegisterNode("other2", "other2", "10.0.0.2", strings.Repeat("cd", 32)); err != nil {
+		t.Fatalf("RegisterNode() error = %v", err)
+	}
+	if alice.Chain.Len() != bob.Chain.Len() || alice.Chain.GetLastHash() == bob.Chain.GetLastHash() {
+		t.Fatal("test setup: expected equal-length forks")
+	}
+
+	want := min(alice.Chain.GetLastHash(), bob.Chain.GetLastHash())
+
+	addPeer(t, alice, bob, serveNode(t, bob))
+	addPeer(t, bob, alice, serveNode(t, alice))
+	if err := alice.SyncChain(); err != nil {
+		t.Fatalf("alice.SyncChain() error = %v", err)
+	}
+	if err := bob.SyncChain(); err != nil {
+		t.Fatalf("bob.SyncChain() error = %v", err)
+	}
+
+	// どちらから同期しても末尾ハッシュの小さいフォークに収束する
+	for _, n := range []*Node{alice, bob} {
+		if got := n.Chain.GetLastHash(); got != want {
+			t.Errorf("%s tip = %s, want %s", n.Config.NodeName, got, want)
+		}
+	}
+}
+
+func TestSyncChain_RejectsForgedSignatures(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+	addPeer(t, bob, alice, "127.0.0.1:1")
+	_, mallory, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("GenerateKeyPair() error = %v", err)
+	}
+
+	// ジェネシス + alice→bob の取引1件のチェーンを配信するピア（署名鍵を指定）
+	servePeerChain := func(fromKey, toKey ed25519.PrivateKey) string {
+		txData := &core.TransactionData{From: "alice", To: "bob", Amount: 500, Title: "ランチ"}
+		fromSig, _ := crypto.SignTransaction(fromKey, txData)
+		toSig, _ := crypto.SignTransaction(toKey, txData)
+		genesis := core.NewGenesisBlock()
+		block, err := core.CreateBlockWithTransaction(1, genesis.Header.Hash, txData, fromSig, toSig)
+		if err != nil {
+			t.Fatalf("CreateBlockWithTransaction() error = %v", err)
+		}
+		chain := []*server.Block{convertBlockToServer(genesis), convertBlockToServer(block)}
+
+		// GET /chain のみ応答する（/info は 404 なのでチェーン全体の取得にフォールバックする）
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /chain", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(chain)
+		})
+		ts := httptest.NewServer(mux)
+		t.Cleanup(ts.Close)
+		return strings.TrimPrefix(ts.URL, "http://")
+	}
+
+	// 署名を偽造した（ハッシュは正しい）より長いチェーンは採用しない
+	peerInfo := &storage.NodeInfo{Name: "carol", NickName: "carol", Address: servePeerChain(alice.PrivKey, mallory), PublicKey: strings.Repeat("cd", 32)}
+	if err := bob.NodeStore.Save("carol", peerInfo); err != nil {
+		t.Fatalf("Save(carol) error = %v", err)
+	}
+	if err := bob.SyncChain(); err != nil {
+		t.Fatalf("SyncChain() error = %v", err)
+	}
+	if bob.Chain.Len() != 1 {
+		t.Fatalf("chain length = %d after syncing forged chain, want 1", bob.Chain.Len())
+	}
+
+	// 正しく署名されたチェーンは採用する
+	peerInfo.Address = servePeerChain(alice.PrivKey, bob.PrivKey)
+	if err := bob.NodeStore.Save("carol", peerInfo); err != nil {
+		t.Fatalf("Save(carol) error = %v", err)
+	}
+	if err := bob.SyncChain(); err != nil {
+		t.Fatalf("SyncChain() error = %v", err)
+	}
+	if bob.Chain.Len() != 2 {
+		t.Errorf("chain length = %d after syncing valid chain, want 2", bob.Chain.Len())
+	}
+}
+
+func TestSyncChain_Incremental(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+
+	var mu sync.Mutex
+	var infoCalls int
+	var chainQueries []string
+	handler := server.NewServer("", alice).Handler()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		switch r.URL.Path {
+		case "/info":
+			infoCalls++
+		case "/chain":
+			chainQueries = append(chainQueries, r.URL.RawQuery)
+		}
+		mu.Unlock()
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(ts.Close)
+	addPeer(t, bob, alice, strings.TrimPrefix(ts.URL, "http://"))
+
+	if err := bob.SyncChain(); err != nil {
+		t.Fatalf("SyncChain() error = %v", err)
+	}
+	if bob.Chain.Len() != alice.Chain.Len() {
+		t.Fatalf("chain length = %d after first sync, want %d", bob.Chain.Len(), alice.Chain.Len())
+	}
+
+	// alice だけが 3 ブロック進んだ状態から再同期する
+	prevLen := bob.Chain.Len()
+	registerDummyNodes(t, alice, 3)
+	mu.Lock()
+	infoCalls, chainQueries = 0, nil
+	mu.Unlock()
+	if err := bob.SyncChain(); err != nil {
+		t.Fatalf("SyncChain() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if infoCalls != 1 {
+		t.Errorf("GET /info calls = %d, want 1", infoCalls)
+	}
+	// 不足している 3 ブロックだけを取得する
+	if want := []string{fmt.Sprintf("from=%d", prevLen)}; !slices.Equal(chainQueries, want) {
+		t.Errorf("GET /chain queries = %v, want %v", chainQueries, want)
+	}
+	if bob.Chain.Len() != prevLen+3 {
+		t.Errorf("chain length = %d, want %d", bob.Chain.Len(), prevLen+3)
+	}
+	if bob.Chain.GetLastHash() != alice.Chain.GetLastHash() {
+		t.Errorf("tip = %s, want %s", bob.Chain.GetLastHash(), alice.Chain.GetLastHash())
+	}
+}
+
+func TestSyncChain_HangingPeer(t *testing.T) {
+	prev := syncStragglerTimeout
+	syncStragglerTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { syncStragglerTimeout = prev })
+
+	alice := newTestNode(t, "alice")
+	carol := newTestNode(t, "carol")
+	bob := newTestNode(t, "bob")
+	registerDummyNodes(t, alice, 3)
+
+	// carol は応答を返さない（リクエストが中断されるまで待つ）
+	aborted := make(chan struct{}, 1)
+	hang := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+		aborted <- struct{}{}
+	}))
+	t.Cleanup(hang.Close)
+	addPeer(t, bob, carol, strings.TrimPrefix(hang.URL, "http://"))
+	addPeer(t, bob, alice, serveNode(t, alice))
+
+	start := time.Now()
+	if err := bob.SyncChain(); err != nil {
+		t.Fatalf("SyncChain() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("SyncChain() took %v with a hanging peer", elapsed)
+	}
+	if bob.Chain.GetLastHash() != alice.Chain.GetLastHash() {
+		t.Errorf("bob did not adopt alice's chain (length %d)", bob.Chain.Len())
+	}
+
+	// 打ち切ったピアへのリクエストはキャンセルされる
+	select {
+	case <-aborted:
+	case <-time.After(2 * time.Second):
+		t.Error("request to the hanging peer was not cancelled")
+	}
+}
+
+func TestRollbackChain(t *testing.T) {
+	n := newTestNode(t, "alice")
+	registerDummyNodes(t, n, 3)
+	keep, err := n.Chain.GetBlockByIndex(1)
+	if err != nil {
+		t.Fatalf("GetBlockByIndex() error = %v", err)
+	}
+
+	for _, index := range []int{-1, n.Chain.Len()} {
+		if _, err := n.RollbackChain(index); err == nil {
+			t.Errorf("RollbackChain(%d) error = nil, want error", index)
+		}
+	}
+
+	removed, err := n.RollbackChain(1)
+	if err != nil {
+		t.Fatalf("RollbackChain(1) error = %v", err)
+	}
+	if removed != 2 || n.Chain.Len() != 2 || n.Chain.GetLastHash() != keep.Header.Hash {
+		t.Errorf("removed = %d, length = %d, want 2 removed and tip at index 1", removed, n.Chain.Len())
+	}
+
+	// block.jsonl も切り詰められている
+	stored, err := n.BlockStore.LoadAll()
+	if err != nil {
+		t.Fatalf("LoadAll() error = %v", err)
+	}
+	if len(stored) != 2 || stored[1].Header.Hash != keep.Header.Hash {
+		t.Errorf("stored %d blocks, want 2 ending at %s", len(stored), keep.Header.Hash)
+	}
+}
+
+func TestStartSyncLoop(t *testing.T) {
+	n := newTestNode(t, "bob")
+
+	// 同期のたびに最初に呼ばれる GET /info を数える偽のピア（失敗を返しても同期を続ける）
+	var attempts atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/info" {
+			attempts.Add(1)
+		}
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(ts.Close)
+	if err := n.NodeStore.Save("alice", &storage.NodeInfo{Name: "alice", NickName: "alice", Address: strings.TrimPrefix(ts.URL, "http://"), PublicKey: strings.Repeat("ab", 32)}); err != nil {
+		t.Fatalf("Save(alice) error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan struct{})
+	go func() {
+		n.StartSyncLoop(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+
+	if !waitFor(t, 2*time.Second, func() bool { return attempts.Load() >= 2 }) {
+		t.Fatalf("sync attempts = %d, want >= 2", attempts.Load())
+	}
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("StartSyncLoop did not return after cancel")
+	}
+}
+
+// chainOrderHandler は GET /chain を受けたノード名を order に記録してから handler に渡す
+type chainOrderHandler struct {
+	name    string
+	mu      *sync.Mutex
+	order   *[]string
+	handler http.Handler
+}
+
+func (h *chainOrderHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet && r.URL.Path == "/chain" {
+		h.mu.Lock()
+		*h.order = append(*h.order, h.name)
+		h.mu.Unlock()
+	}
+	h.handler.ServeHTTP(w, r)
+}
+
+func TestSyncChain_TrustedPeers(t *testing.T) {
+	// alice と carol はそれぞれ別のフォークを持ち、bob は carol を信頼する
+	setup := func(t *testing.T, aliceBlocks, carolBlocks int, policy string) (bob, alice, carol *Node, order *[]string) {
+		alice = newTestNode(t, "alice")
+		carol = newTestNode(t, "carol")
+		bob = newTestNode(t, "bob")
+		registerDummyNodes(t, alice, aliceBlocks)
+		for i := 0; i < carolBlocks; i++ {
+			if _, err := carol.RegisterNode(fmt.Sprintf("carol%d", i), "c", "10.0.0.3", strings.Repeat("cd", 32)); err != nil {
+				t.Fatalf("RegisterNode() error = %v", err)
+			}
+		}
+		bob.Config.TrustedPeers = []string{"carol"}
+		bob.Config.TrustedPeerPolicy = policy
+
+		order = &[]string{}
+		var mu sync.Mutex
+		for _, peer := range []*Node{alice, carol} {
+			ts := httptest.NewServer(&chainOrderHandler{name: peer.Config.NodeName, mu: &mu, order: order, handler: server.NewServer("", peer).Handler()})
+			t.Cleanup(ts.Close)
+			addPeer(t, bob, peer, strings.TrimPrefix(ts.URL, "http://"))
+		}
+		return bob, alice, carol, order
+	}
+
+	t.Run("authoritative ignores longer untrusted chain", func(t *testing.T) {
+		bob, _, carol, order := setup(t, 3, 1, "authoritative")
+		if err := bob.SyncChain(); err != nil {
+			t.Fatalf("SyncChain() error = %v", err)
+		}
+		if bob.Chain.GetLastHash() != carol.Chain.GetLastHash() {
+			t.Errorf("bob did not adopt the trusted chain (length %d)", bob.Chain.Len())
+		}
+		if len(*order) != 1 || (*order)[0] != "carol" {
+			t.Errorf("queried peers = %v, want [carol]", *order)
+		}
+	})
+
+	t.Run("authoritative falls back when trusted peer is unreachable", func(t *testing.T) {
+		bob, alice, carol, _ := setup(t, 3, 1, "authoritative")
+		addPeer(t, bob, carol, "127.0.0.1:1")
+		if err := bob.SyncChain(); err != nil {
+			t.Fatalf("SyncChain() error = %v", err)
+		}
+		if bob.Chain.GetLastHash() != alice.Chain.GetLastHash() {
+			t.Errorf("bob did not fall back to the untrusted chain (length %d)", bob.Chain.Len())
+		}
+	})
+
+	t.Run("prefer queries all peers and adopts longer chain", func(t *testing.T) {
+		bob, alice, _, order := setup(t, 3, 1, "prefer")
+		if err := bob.SyncChain(); err != nil {
+			t.Fatalf("SyncChain() error = %v", err)
+		}
+		if bob.Chain.GetLastHash() != alice.Chain.GetLastHash() {
+			t.Errorf("bob did not adopt the longest chain (length %d)", bob.Chain.Len())
+		}
+		// prefer では全ピアに並行して問い合わせる
+		if len(*order) != 2 {
+			t.Errorf("queried peers = %v, want both alice and carol", *order)
+		}
+	})
+
+	t.Run("prefer picks trusted chain on tie", func(t *testing.T) {
+		bob, _, carol, _ := setup(t, 2, 2, "prefer")
+		if bob.Chain.GetLastHash() == carol.Chain.GetLastHash() {
+			t.Fatal("test setup: expected different forks")
+		}
+		if err := bob.SyncChain(); err != nil {
+			t.Fatalf("SyncChain() error = %v", err)
+		}
+		if bob.Chain.GetLastHash() != carol.Chain.GetLastHash() {
+			t.Error("bob did not prefer the trusted chain on a tie")
+		}
+	})
+}
+
+func TestConcurrentReceiveAndSync(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+	registerDummyNodes(t, bob, 6)
+
+	bobAddr := serveNode(t, bob)
+	addPeer(t, alice, bob, bobAddr)
+
+	blocks, err := bob.GetChain()
+	if err != nil {
+		t.Fatalf("GetChain() error = %v", err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for _, b := range blocks[1:] {
+			_ = alice.ReceiveBlock(b)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 5; i++ {
+			_ = alice.SyncChain()
+		}
+	}()
+	wg.Wait()
+
+	if alice.Chain.Len() != len(blocks) {
+		t.Fatalf("chain length = %d, want %d", alice.Chain.Len(), len(blocks))
+	}
+
+	// メモリ上のチェーンと永続化されたブロックが一致すること
+	stored, err := alice.BlockStore.LoadAll()
+	if err != nil {
+		t.Fatalf("BlockStore.LoadAll() error = %v", err)
+	}
+	inMemory, err := alice.Chain.GetBlocks()
+	if err != nil {
+		t.Fatalf("GetBlocks() error = %v", err)
+	}
+	if len(stored) != len(inMemory) {
+		t.Fatalf("stored length = %d, in-memory length = %d", len(stored), len(inMemory))
+	}
+	for i := range stored {
+		if stored[i].Header.Hash != inMemory[i].Header.Hash {
+			t.Errorf("block %d hash mismatch: stored %s, in-memory %s", i, stored[i].Header.Hash, inMemory[i].Header.Hash)
+		}
+	}
+}
+
+func TestValidateChainWithSignatures(t *testing.T) {
+	t.Run("valid chain", func(t *testing.T) {
+		n := newTestNode(t, "alice")
+		registerDummyNodes(t, n, 3)
+
+		index, err := n.ValidateChainWithSignatures()
+		if err != nil {
+			t.Fatalf("ValidateChainWithSignatures() error = %v (index %d)", err, index)
+		}
+		if !n.VerifyChain().Valid {
+			t.Error("VerifyChain().Valid = false, want true")
+		}
+	})
+
+	t.Run("tampered block", func(t *testing.T) {
+		n := newTestNode(t, "alice")
+		registerDummyNodes(t, n, 3)
+
+		// メモリ上のブロック2のペイロードを改ざん
+		b, _ := n.Chain.GetBlockByIndex(2)
+		b.Payload.Data = []byte(`{"node_name":"mallory"}`)
+
+		index, err := n.ValidateChainWithSignatures()
+		if err == nil {
+			t.Fatal("ValidateChainWithSignatures() should fail for tampered chain")
+		}
+		if index != 2 {
+			t.Errorf("failing index = %d, want 2", index)
+		}
+
+		result := n.VerifyChain()
+		if result.Valid || result.Index == nil || *result.Index != 2 || result.Kind != string(core.ValidationKindHash) {
+			t.Errorf("VerifyChain() = %+v, want invalid hash at index 2", result)
+		}
+	})
+
+	t.Run("forged transaction signature", func(t *testing.T) {
+		alice := newTestNode(t, "alice")
+		bob := newTestNode(t, "bob")
+		addPeer(t, bob, alice, "127.0.0.1:1")
+
+		for i := 0; i < 3; i++ {
+			tx := &core.TransactionData{From: "alice", To: "bob", Amount: int64(100 + i), Title: "立替"}
+			fromSig, err := crypto.SignTransaction(alice.PrivKey, tx)
+			if err != nil {
+				t.Fatalf("SignTransaction() error = %v", err)
+			}
+			pending, err := bob.ProposeTransactionDetailed(&server.TransactionData{
+				From: tx.From, To: tx.To, Amount: tx.Amount, Title: tx.Title,
+			}, fromSig)
+			if err != nil {
+				t.Fatalf("ProposeTransactionDetailed() error = %v", err)
+			}
+			if _, err := bob.ApproveTransaction(pending.ID); err != nil {
+				t.Fatalf("ApproveTransaction() error = %v", err)
+			}
+		}
+		if index, err := bob.ValidateChainWithSignatures(); err != nil {
+			t.Fatalf("ValidateChainWithSignatures() error = %v (index %d)", err, index)
+		}
+
+		// 末尾ブロックの From 署名を別取引のものに差し替え、ハッシュは再計算する
+		last := bob.Chain.LastBlock()
+		other, _ := bob.Chain.GetBlockByIndex(last.Header.Index - 1)
+		last.Payload.FromSignature = other.Payload.FromSignature
+		last.Header.Hash = core.CalcBlockHash(last)
+
+		index, err := bob.ValidateChainWithSignatures()
+		if err == nil || !strings.Contains(err.Error(), "invalid from signature") {
+			t.Fatalf("ValidateChainWithSignatures() error = %v, want invalid from signature", err)
+		}
+		if index != last.Header.Index {
+			t.Errorf("failing index = %d, want %d", index, last.Header.Index)
+		}
+		var validationErr *core.ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Kind != core.ValidationKindSignature || validationErr.Index != last.Header.Index {
+			t.Errorf("error = %#v, want signature ValidationError at %d", err, last.Header.Index)
+		}
+	})
+}
+
+func TestUpdateNickname_PropagatesToPeer(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+
+	bobAddr := serveNode(t, bob)
+	addPeer(t, alice, bob, bobAddr)
+	addPeer(t, bob, alice, "127.0.0.1:1")
+
+	block, err := alice.UpdateNickname("アリス改")
+	if err != nil {
+		t.Fatalf("UpdateNickname() error = %v", err)
+	}
+	if got := alice.GetPeers()["alice"].NickName; got != "アリス改" {
+		t.Errorf("own nickname = %s, want アリス改", got)
+	}
+
+	alice.BroadcastBlock(block)
+
+	if got := bob.GetPeers()["alice"].NickName; got != "アリス改" {
+		t.Errorf("peer's view of nickname = %s, want アリス改", got)
+	}
+	if bob.Chain.Len() != 2 {
+		t.Errorf("peer chain length = %d, want 2", bob.Chain.Len())
+	}
+}
+
+func TestGetPeers_ReturnsCopies(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+	addPeer(t, alice, bob, "10.0.0.2:8080")
+
+	// 返された NodeInfo を書き換えながら並行して読み出しても、内部の状態は変わらない（-race で検出）
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				peers := alice.GetPeers()
+				peers["bob"].NickName = "mutated"
+				peers["bob"].Address = "127.0.0.1:1"
+				delete(peers, "alice")
+			}
+		}()
+	}
+	wg.Wait()
+
+	peers := alice.GetPeers()
+	if peers["bob"].NickName != "bob" || peers["bob"].Address != "10.0.0.2:8080" {
+		t.Errorf("peer bob = %+v, want unchanged", peers["bob"])
+	}
+	if _, ok := peers["alice"]; !ok {
+		t.Error("own node disappeared from GetPeers()")
+	}
+}
+
+func TestGetMetrics(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+	addPeer(t, bob, alice, "127.0.0.1:1")
+
+	block, err := core.CreateBlockWithAddNode(bob.Chain.GetLastIndex()+1, bob.Chain.GetLastHash(), &core.AddNodeData{NodeName: "dave", NickName: "d", Address: "10.0.0.4", PublicKey: strings.Repeat("ab", 32)})
+	if err != nil {
+		t.Fatalf("CreateBlockWithAddNode() error = %v", err)
+	}
+	sb := convertBlockToServer(block)
+	if err := bob.ReceiveBlock(sb); err != nil {
+		t.Fatalf("ReceiveBlock() error = %v", err)
+	}
+	// 重複したブロックも受信数に数える
+	if err := bob.ReceiveBlock(sb); err != nil {
+		t.Fatalf("ReceiveBlock(duplicate) error = %v", err)
+	}
+	// 受信したブロックの転送と同じブロックは二重に数えない
+	bob.BroadcastBlock(sb)
+
+	deadline := time.Now().Add(time.Second)
+	for bob.GetMetrics().BroadcastsSent == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	m := bob.GetMetrics()
+	want := server.Metrics{ChainLength: 2, PendingCount: 0, Peers: 1, BlocksReceived: 2, BroadcastsSent: 1}
+	if *m != want {
+		t.Errorf("GetMetrics() = %+v, want %+v", *m, want)
+	}
+}
+
+func TestPingPeers(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+	addPeer(t, bob, alice, serveNode(t, alice))
+
+	// 停止済みのサーバーのアドレスを到達できないピアとして登録する
+	dead := httptest.NewServer(http.NotFoundHandler())
+	deadAddr := strings.TrimPrefix(dead.URL, "http://")
+	dead.Close()
+	if err := bob.NodeStore.Save("carol", &storage.NodeInfo{Name: "carol", NickName: "carol", Address: deadAddr, PublicKey: strings.Repeat("ab", 32)}); err != nil {
+		t.Fatalf("Save(carol) error = %v", err)
+	}
+
+	result := bob.PingPeers(context.Background())
+	if want := map[string]bool{"alice": true, "carol": false}; !maps.Equal(result, want) {
+		t.Errorf("PingPeers() = %v, want %v", result, want)
+	}
+
+	health := bob.CheckPeerHealth(context.Background())
+	if h := health["alice"]; h == nil || !h.Reachable || h.LastSeen == 0 {
+		t.Errorf("health[alice] = %+v, want reachable with last_seen", h)
+	}
+	// 2回の問い合わせでどちらも到達できなかった
+	if h := health["carol"]; h == nil || h.Reachable || h.LastSeen != 0 || h.Failures != 2 {
+		t.Errorf("health[carol] = %+v, want unreachable with 2 failures", h)
+	}
+	if _, ok := health["bob"]; ok {
+		t.Error("health should not include the node itself")
+	}
+}
+
+func TestReceiveBlock_FutureTimestamp(t *testing.T) {
+	n := newTestNode(t, "bob")
+	n.Config.MaxClockSkewSeconds = 300
+
+	blockAt := func(createdAt time.Time) *server.Block {
+		block, err := core.CreateBlockWithAddNode(1, n.Chain.GetLastHash(), &core.AddNodeData{NodeName: "dave", NickName: "d", Address: "10.0.0.4", PublicKey: strings.Repeat("ab", 32)})
+		if err != nil {
+			t.Fatalf("CreateBlockWithAddNode() error = %v", err)
+		}
+		block.Header.CreatedAt = createdAt.UTC()
+		block.Header.Hash = core.CalcBlockHash(block)
+		return convertBlockToServer(block)
+	}
+
+	if err := n.ReceiveBlock(blockAt(time.Now().Add(10 * time.Minute))); err == nil {
+		t.Error("ReceiveBlock() should reject a block too far in the future")
+	}
+	if err := n.ReceiveBlock(blockAt(time.Now().Add(time.Minute))); err != nil {
+		t.Errorf("ReceiveBlock() within skew error = %v", err)
+	}
+	if n.Chain.Len() != 2 {
+		t.Errorf("chain length = %d, want 2", n.Chain.Len())
+	}
+}
+
+func TestApproveTransaction_Concurrent(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+	addPeer(t, bob, alice, "127.0.0.1:1")
+
+	txData := &core.TransactionData{From: "alice", To: "bob", Amount: 500, Title: "ランチ"}
+	fromSig, _ := crypto.SignTransaction(alice.PrivKey, txData)
+	pending, err := bob.ProposeTransactionDetailed(&server.TransactionData{From: "alice", To: "bob", Amount: 500, Title: "ランチ"}, fromSig)
+	if err != nil {
+		t.Fatalf("ProposeTransactionDetailed() error = %v", err)
+	}
+
+	// 二重クリックや再送で同じ ID の承認が同時に届いても、ブロックは1つだけ作られる
+	const approvers = 8
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	start := make(chan struct{})
+	succeeded := 0
+	for i := 0; i < approvers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			if _, err := bob.ApproveTransaction(pending.ID); err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if succeeded != 1 {
+		t.Errorf("%d approvals succeeded, want 1", succeeded)
+	}
+	if got := bob.Chain.Len(); got != 2 {
+		t.Errorf("chain length = %d, want 2", got)
+	}
+	if bob.PendingPool.Has(pending.ID) {
+		t.Error("approved transaction is still pending")
+	}
+}
+
+func TestApproveRejectTransaction_Concurrent(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+	bob.Config.ExpiredLogSize = 10
+	addPeer(t, bob, alice, "127.0.0.1:1")
+
+	txData := &core.TransactionData{From: "alice", To: "bob", Amount: 500, Title: "ランチ"}
+	fromSig, _ := crypto.SignTransaction(alice.PrivKey, txData)
+	pending, err := bob.ProposeTransactionDetailed(&server.TransactionData{From: "alice", To: "bob", Amount: 500, Title: "ランチ"}, fromSig)
+	if err != nil {
+		t.Fatalf("ProposeTransactionDetailed() error = %v", err)
+	}
+
+	// 同じ ID の承認と拒否が同時に届いても、どちらか一方だけが成功する
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	var approveErr, rejectErr error
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		<-start
+		rejectErr = bob.RejectTransaction(pending.ID)
+	}()
+	go func() {
+		defer wg.Done()
+		<-start
+		_, approveErr = bob.ApproveTransaction(pending.ID)
+	}()
+	close(start)
+	wg.Wait()
+
+	if (approveErr == nil) == (rejectErr == nil) {
+		t.Fatalf("approve error = %v, reject error = %v, want exactly one to succeed", approveErr, rejectErr)
+	}
+	wantLen, wantArchived := 2, 0
+	if rejectErr == nil {
+		wantLen, wantArchived = 1, 1
+	}
+	if got := bob.Chain.Len(); got != wantLen {
+		t.Errorf("chain length = %d, want %d", got, wantLen)
+	}
+	if got := len(bob.ListExpired()); got != wantArchived {
+		t.Errorf("archived records = %d, want %d", got, wantArchived)
+	}
+	if bob.PendingPool.Has(pending.ID) {
+		t.Error("transaction is still pending")
+	}
+}
+
+func TestCreateBlock_AfterFutureBlock(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+	bob.Config.MaxClockSkewSeconds = 300
+	addPeer(t, bob, alice, "127.0.0.1:1")
+
+	// 時計が進んだピアが作った（許容範囲内で未来の）ブロックを受信する
+	future, err := core.CreateBlockWithAddNode(bob.Chain.GetLastIndex()+1, bob.Chain.GetLastHash(), &core.AddNodeData{NodeName: "dave", NickName: "d", Address: "10.0.0.4", PublicKey: strings.Repeat("ab", 32)})
+	if err != nil {
+		t.Fatalf("CreateBlockWithAddNode() error = %v", err)
+	}
+	future.Header.CreatedAt = time.Now().Add(4 * time.Minute).UTC().Truncate(time.Second)
+	future.Header.Hash = core.CalcBlockHash(future)
+	if err := bob.ReceiveBlock(convertBlockToServer(future)); err != nil {
+		t.Fatalf("ReceiveBlock() error = %v", err)
+	}
+
+	// 自ノードの時計が追いつく前でも承認・登録・ニックネーム変更のブロックを追加できる
+	txData := &core.TransactionData{From: "alice", To: "bob", Amount: 500, Title: "ランチ"}
+	fromSig, _ := crypto.SignTransaction(alice.PrivKey, txData)
+	pending, err := bob.ProposeTransactionDetailed(&server.TransactionData{From: "alice", To: "bob", Amount: 500, Title: "ランチ"}, fromSig)
+	if err != nil {
+		t.Fatalf("ProposeTransactionDetailed() error = %v", err)
+	}
+	approved, err := bob.ApproveTransaction(pending.ID)
+	if err != nil {
+		t.Fatalf("ApproveTransaction() error = %v", err)
+	}
+	if approved.Header.CreatedAt < future.Header.CreatedAt.Unix() {
+		t.Errorf("approved block created_at = %d, want not before %d", approved.Header.CreatedAt, future.Header.CreatedAt.Unix())
+	}
+	if _, err := bob.RegisterNode("carol", "carol", "10.0.0.3", strings.Repeat("cd", 32)); err != nil {
+		t.Errorf("RegisterNode() error = %v", err)
+	}
+	if _, err := bob.UpdateNickname("ボブ"); err != nil {
+		t.Errorf("UpdateNickname() error = %v", err)
+	}
+	if err := bob.ValidateChainStructure(); err != nil {
+		t.Errorf("ValidateChainStructure() error = %v", err)
+	}
+}
+
+func TestReceiveBlock_UnknownVersion(t *testing.T) {
+	n := newTestNode(t, "bob")
+
+	block, err := core.CreateBlockWithAddNode(1, n.Chain.GetLastHash(), &core.AddNodeData{NodeName: "dave", NickName: "d", Address: "10.0.0.4", PublicKey: strings.Repeat("ab", 32)})
+	if err != nil {
+		t.Fatalf("CreateBlockWithAddNode() error = %v", err)
+	}
+	block.Header.Version = core.CurrentBlockVersion + 1
+	block.Header.Hash = core.CalcBlockHash(block)
+
+	if err := n.ReceiveBlock(convertBlockToServer(block)); !errors.Is(err, core.ErrUnknownVersion) {
+		t.Errorf("ReceiveBlock() error = %v, want ErrUnknownVersion", err)
+	}
+	if n.Chain.Len() != 1 {
+		t.Errorf("chain length = %d, want 1", n.Chain.Len())
+	}
+}
+
+func TestReceiveBlock_RejectsForgedNodeUpdate(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+	addPeer(t, bob, alice, "127.0.0.1:1")
+
+	// alice の名義で別の鍵（mallory）が署名した更新ブロック
+	_, malloryPriv, _ := crypto.GenerateKeyPair()
+	data, _ := core.SetAddNodeData(&core.AddNodeData{
+		PublicKey: hex.EncodeToString(alice.PubKey),
+		NodeName:  "alice",
+		NickName:  "乗っ取り",
+		Address:   "127.0.0.1:1",
+	})
+	payload := core.BlockPayload{Type: "add_node", Data: data, FromSignature: crypto.Sign(malloryPriv, data)}
+	last := bob.Chain.LastBlock()
+	forged := core.NewBlock(last.Header.Index+1, last.Header.Hash, payload)
+
+	if err := bob.ReceiveBlock(convertBlockToServer(forged)); err == nil {
+		t.Fatal("ReceiveBlock() should reject forged node update")
+	}
+	if got := bob.GetPeers()["alice"].NickName; got != "alice" {
+		t.Errorf("nickname = %s, want unchanged alice", got)
+	}
+}
+
+func TestTransactionSignatures_ConsistentAcrossNodes(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+	carol := newTestNode(t, "carol")
+	addPeer(t, bob, alice, "127.0.0.1:1")
+	addPeer(t, carol, alice, "127.0.0.1:1")
+	addPeer(t, carol, bob, "127.0.0.1:1")
+
+	// alice が CLI 等で SignTransaction により作成した From 署名を bob に転送
+	txData := &core.TransactionData{From: "alice", To: "bob", Amount: 1200, Title: "タクシー"}
+	fromSig, err := crypto.SignTransaction(alice.PrivKey, txData)
+	if err != nil {
+		t.Fatalf("SignTransaction() error = %v", err)
+	}
+	err = bob.ProposeTransaction(&server.TransactionData{
+		From: txData.From, To: txData.To, Amount: txData.Amount, Title: txData.Title,
+	}, fromSig)
+	if err != nil {
+		t.Fatalf("ProposeTransaction() error = %v", err)
+	}
+
+	pending := bob.ListPending()
+	if len(pending) != 1 {
+		t.Fatalf("pending count = %d, want 1", len(pending))
+	}
+	block, err := bob.ApproveTransaction(pending[0].ID)
+	if err != nil {
+		t.Fatalf("ApproveTransaction() error = %v", err)
+	}
+
+	// To 署名も同じ方式で検証できる
+	if !crypto.VerifyTransactionSignature(bob.PubKey, txData, block.Payload.ToSignature) {
+		t.Error("to signature does not verify with VerifyTransactionSignature")
+	}
+
+	// 第三者ノードも同じ方式で検証して受理する
+	if err := carol.ReceiveBlock(block); err != nil {
+		t.Fatalf("ReceiveBlock() error = %v", err)
+	}
+	if _, err := bob.ValidateChainWithSignatures(); err != nil {
+		t.Errorf("ValidateChainWithSignatures() error = %v", err)
+	}
+}
+
+func TestTransactionSignatures_RejectsPayloadScheme(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+	addPeer(t, bob, alice, "127.0.0.1:1")
+
+	// {type,data} 形式で作られた From 署名はブロック検証で拒否される
+	txData := &core.TransactionData{From: "alice", To: "bob", Amount: 300, Title: "コーヒー"}
+	data, _ := core.SetTransactionData(txData)
+	fromSig, _ := crypto.SignPayload(alice.PrivKey, &core.BlockPayload{Type: "transaction", Data: data})
+	toSig, _ := crypto.SignTransaction(bob.PrivKey, txData)
+
+	last := alice.Chain.LastBlock()
+	block, err := core.CreateBlockWithTransaction(last.Header.Index+1, last.Header.Hash, txData, fromSig, toSig)
+	if err != nil {
+		t.Fatalf("CreateBlockWithTransaction() error = %v", err)
+	}
+	if err := bob.verifyBlockSignatures(block); err == nil {
+		t.Error("verifyBlockSignatures() should reject a {type,data} from signature")
+	}
+}
+
+func TestVerifyBlockSignatures_FailureKinds(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+	addPeer(t, bob, alice, "127.0.0.1:1")
+
+	txData := &core.TransactionData{From: "alice", To: "bob", Amount: 500, Title: "ランチ"}
+	fromSig, _ := crypto.SignTransaction(alice.PrivKey, txData)
+	toSig, _ := crypto.SignTransaction(bob.PrivKey, txData)
+	_, malloryPriv, _ := crypto.GenerateKeyPair()
+	forgedSig, _ := crypto.SignTransaction(malloryPriv, txData)
+
+	newBlock := func(fromSig string) *core.Block {
+		last := bob.Chain.LastBlock()
+		block, err := core.CreateBlockWithTransaction(last.Header.Index+1, last.Header.Hash, txData, fromSig, toSig)
+		if err != nil {
+			t.Fatalf("CreateBlockWithTransaction() error = %v", err)
+		}
+		return block
+	}
+
+	tests := []struct {
+		name    string
+		fromSig string
+		want    error
+	}{
+		{"bad base64", "!!not-base64!!", crypto.ErrSignatureEncoding},
+		{"bad length", "c2hvcnQ=", crypto.ErrSignatureEncoding},
+		{"wrong key", forgedSig, crypto.ErrSignatureMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := bob.verifyBlockSignatures(newBlock(tt.fromSig))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("verifyBlockSignatures() error = %v, want %v", err, tt.want)
+			}
+			if err != nil && !strings.Contains(err.Error(), "invalid from signature") {
+				t.Errorf("error = %v, want it to name the from signature", err)
+			}
+		})
+	}
+
+	t.Run("bad public key", func(t *testing.T) {
+		peers, err := bob.NodeStore.LoadAll()
+		if err != nil {
+			t.Fatalf("LoadAll() error = %v", err)
+		}
+		peers["alice"].PublicKey = "zz"
+		_, _, err = transactionSignatureItems(newBlock(fromSig), peers)
+		if !errors.Is(err, crypto.ErrInvalidPublicKey) {
+			t.Errorf("transactionSignatureItems() error = %v, want ErrInvalidPublicKey", err)
+		}
+	})
+
+	if err := bob.verifyBlockSignatures(newBlock(fromSig)); err != nil {
+		t.Errorf("verifyBlockSignatures() error = %v for valid signatures", err)
+	}
+}
+
+// toggleHandler は down の間はコネクションを切断し、到達不能なピアを模擬する
+type toggleHandler struct {
+	down    atomic.Bool
+	handler http.Handler
+}
+
+func (h *toggleHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.down.Load() {
+		if hj, ok := w.(http.Hijacker); ok {
+			conn, _, _ := hj.Hijack()
+			conn.Close()
+			return
+		}
+	}
+	h.handler.ServeHTTP(w, r)
+}
+
+func TestBroadcastBlock_ResendsMissedBlocksOnRecovery(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	alice.Config.RebroadcastBlocks = 10
+	bob := newTestNode(t, "bob")
+
+	h := &toggleHandler{handler: server.NewServer("", bob).Handler()}
+	h.down.Store(true)
+	ts := httptest.NewServer(h)
+	defer ts.Close()
+	addPeer(t, alice, bob, strings.TrimPrefix(ts.URL, "http://"))
+	addPeer(t, bob, alice, "127.0.0.1:1")
+
+	// bob が停止中に2ブロック生成
+	for i := 0; i < 2; i++ {
+		block, err := alice.RegisterNode(fmt.Sprintf("node%d", i), "n", "10.0.0.1", strings.Repeat("ab", 32))
+		if err != nil {
+			t.Fatalf("RegisterNode() error = %v", err)
+		}
+		alice.BroadcastBlock(block)
+	}
+	if bob.Chain.Len() != 1 {
+		t.Fatalf("bob chain length = %d, want 1 while down", bob.Chain.Len())
+	}
+
+	// bob 復帰後の最初のブロードキャストで取りこぼし分が再送される
+	h.down.Store(false)
+	block, err := alice.RegisterNode("node2", "n", "10.0.0.1", strings.Repeat("ab", 32))
+	if err != nil {
+		t.Fatalf("RegisterNode() error = %v", err)
+	}
+	alice.BroadcastBlock(block)
+
+	if !waitFor(t, 2*time.Second, func() bool { return bob.Chain.Len() == alice.Chain.Len() }) {
+		t.Fatalf("bob chain length = %d, want %d", bob.Chain.Len(), alice.Chain.Len())
+	}
+	if bob.Chain.GetLastHash() != alice.Chain.GetLastHash() {
+		t.Error("bob's tip does not match alice's tip")
+	}
+}
+
+func TestBroadcastBlock_SkipsDeadPeers(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	alice.Config.DeadPeerSkipSeconds = 60
+	bob := newTestNode(t, "bob")
+	addPeer(t, alice, bob, serveNode(t, bob))
+	addPeer(t, bob, alice, "127.0.0.1:1")
+
+	// carol は応答しない（タイムアウトするまで待たせる）ピア
+	var requests atomic.Int32
+	release := make(chan struct{})
+	carol := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		<-release
+	}))
+	t.Cleanup(carol.Close)
+	t.Cleanup(func() { close(release) })
+	if err := alice.NodeStore.Save("carol", &storage.NodeInfo{Name: "carol", NickName: "carol", Address: strings.TrimPrefix(carol.URL, "http://"), PublicKey: strings.Repeat("cd", 32)}); err != nil {
+		t.Fatalf("Save(carol) error = %v", err)
+	}
+	alice.reachability.Record("carol", false)
+
+	broadcast := func(name string) {
+		t.Helper()
+		block, err := alice.RegisterNode(name, "n", "10.0.0.1", strings.Repeat("ab", 32))
+		if err != nil {
+			t.Fatalf("RegisterNode() error = %v", err)
+		}
+		start := time.Now()
+		alice.BroadcastBlock(block)
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("BroadcastBlock() took %v, want prompt return", elapsed)
+		}
+		if bob.Chain.GetLastHash() != block.Header.Hash {
+			t.Errorf("live peer did not receive block %s", name)
+		}
+	}
+
+	// 見送り期間中の到達不能ピアには送信しない
+	broadcast("dave")
+	if got := requests.Load(); got != 0 {
+		t.Errorf("dead peer received %d requests within skip window, want 0", got)
+	}
+
+	// 期間が過ぎたピアにはバックグラウンドで再試行し、到達可能なピアへの伝播は待たせない
+	alice.Config.DeadPeerSkipSeconds = 0
+	broadcast("erin")
+	if !waitFor(t, 2*time.Second, func() bool { return requests.Load() == 1 }) {
+		t.Errorf("dead peer received %d requests after skip window, want 1", requests.Load())
+	}
+}
+
+func TestBroadcastBlock_ForwardsOnce(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+
+	// carol は受け取った POST /block の数を数えるだけのピア
+	var forwarded atomic.Int32
+	carol := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/block" {
+			forwarded.Add(1)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer carol.Close()
+	if err := bob.NodeStore.Save("carol", &storage.NodeInfo{Name: "carol", NickName: "carol", Address: strings.TrimPrefix(carol.URL, "http://"), PublicKey: strings.Repeat("cd", 32)}); err != nil {
+		t.Fatalf("Save(carol) error = %v", err)
+	}
+
+	block, err := alice.RegisterNode("dave", "d", "10.0.0.1", strings.Repeat("ab", 32))
+	if err != nil {
+		t.Fatalf("RegisterNode() error = %v", err)
+	}
+
+	// 同じブロックを2回受信し、さらに明示的にブロードキャストしても転送は1回だけ
+	for i := 0; i < 2; i++ {
+		if err := bob.ReceiveBlock(block); err != nil {
+			t.Fatalf("ReceiveBlock() #%d error = %v", i+1, err)
+		}
+	}
+	if !waitFor(t, 2*time.Second, func() bool { return forwarded.Load() >= 1 }) {
+		t.Fatal("received block was not forwarded")
+	}
+	bob.BroadcastBlock(block)
+
+	time.Sleep(100 * time.Millisecond)
+	if got := forwarded.Load(); got != 1 {
+		t.Errorf("block forwarded %d times, want 1", got)
+	}
+}
+
+func TestRegisterNode_CaseInsensitive(t *testing.T) {
+	n := newTestNode(t, "alice")
+	pubkey := strings.Repeat("ab", 32)
+
+	if _, err := n.RegisterNode("Carol", "キャロル", "10.0.0.3", pubkey); err != nil {
+		t.Fatalf("RegisterNode(Carol) error = %v", err)
+	}
+	before := n.Chain.Len()
+	if _, err := n.RegisterNode("carol", "偽物", "10.0.0.4", pubkey); err == nil {
+		t.Fatal("RegisterNode(carol) should fail when Carol is registered")
+	}
+	if n.Chain.Len() != before {
+		t.Errorf("chain length = %d, want %d (no block for rejected registration)", n.Chain.Len(), before)
+	}
+
+	peers, err := n.NodeStore.LoadAll()
+	if err != nil {
+		t.Fatalf("LoadAll() error = %v", err)
+	}
+	if _, ok := peers["carol"]; ok {
+		t.Error("carol should not be registered as a distinct peer")
+	}
+	if peers["Carol"] == nil || peers["Carol"].NickName != "キャロル" {
+		t.Errorf("Carol = %+v, want original registration", peers["Carol"])
+	}
+}
+
+func TestRegisterNode_ValidatesPublicKey(t *testing.T) {
+	n := newTestNode(t, "alice")
+	before := n.Chain.Len()
+
+	for _, key := range []string{"not-hex", strings.Repeat("ab", 31)} {
+		if _, err := n.RegisterNode("carol", "キャロル", "10.0.0.3", key); !errors.Is(err, crypto.ErrInvalidPublicKey) {
+			t.Errorf("RegisterNode(%q) error = %v, want ErrInvalidPublicKey", key, err)
+		}
+	}
+
+	if _, err := n.RegisterNode("carol", "キャロル", "10.0.0.3", strings.Repeat("ab", 32)); err != nil {
+		t.Fatalf("RegisterNode() error = %v", err)
+	}
+	// 同じ鍵での再登録は受け付け、別の鍵での上書きは拒否する
+	if _, err := n.RegisterNode("carol", "キャロル", "10.0.0.5", strings.Repeat("AB", 32)); err != nil {
+		t.Errorf("RegisterNode(same key) error = %v", err)
+	}
+	if _, err := n.RegisterNode("carol", "偽物", "10.0.0.4", strings.Repeat("cd", 32)); !errors.Is(err, server.ErrNodeConflict) {
+		t.Errorf("RegisterNode(conflicting key) error = %v, want ErrNodeConflict", err)
+	}
+	if got := n.Chain.Len(); got != before+2 {
+		t.Errorf("chain length = %d, want %d", got, before+2)
+	}
+}
+
+func TestSelfAddressPeer(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	addr := serveNode(t, alice)
+	alice.Config.Address = addr
+	_, port, _ := net.SplitHostPort(addr)
+
+	// 自ノードを指すアドレスで別のノードは登録させない
+	for _, a := range []string{addr, "localhost:" + port} {
+		if _, err := alice.RegisterNode("mirror", "mirror", a, strings.Repeat("ab", 32)); err == nil {
+			t.Errorf("RegisterNode(%s) should reject an address pointing to this node", a)
+		}
+	}
+	// 自ノード自身の登録は受け付ける
+	if _, err := alice.RegisterNode("alice", "alice", addr, hex.EncodeToString(alice.PubKey)); err != nil {
+		t.Errorf("RegisterNode(self) error = %v", err)
+	}
+
+	// 既に登録されているピアが自ノードを指していても送信・同期の対象にしない
+	if err := alice.NodeStore.Save("mirror", &storage.NodeInfo{Name: "mirror", NickName: "mirror", Address: addr, PublicKey: strings.Repeat("ab", 32)}); err != nil {
+		t.Fatalf("Save(mirror) error = %v", err)
+	}
+	alice.BroadcastBlock(convertBlockToServer(alice.Chain.LastBlock()))
+	if st, ok := alice.reachability.Status("mirror"); ok {
+		t.Errorf("BroadcastBlock sent to a peer at the node's own address (status %+v)", st)
+	}
+	peers, err := alice.NodeStore.LoadAll()
+	if err != nil {
+		t.Fatalf("LoadAll() error = %v", err)
+	}
+	if order := alice.syncOrder(peers); slices.Contains(order, "mirror") {
+		t.Errorf("syncOrder() = %v, should skip the peer at the node's own address", order)
+	}
+	if result := alice.PingPeers(context.Background()); len(result) != 0 {
+		t.Errorf("PingPeers() = %v, want no peers", result)
+	}
+}
+
+func TestDiskFull(t *testing.T) {
+	diskFull := &os.PathError{Op: "write", Path: "block.jsonl", Err: syscall.ENOSPC}
+	tx := &server.TransactionData{From: "alice", To: "bob", Amount: 100, Title: "test"}
+
+	t.Run("read_only rejects further writes with 507", func(t *testing.T) {
+		n := newTestNode(t, "alice")
+		n.Config.DiskFullPolicy = "read_only"
+
+		// 書き込み失敗を注入
+		if err := n.storageError(diskFull); !errors.Is(err, server.ErrInsufficientStorage) {
+			t.Fatalf("storageError() = %v, want ErrInsufficientStorage", err)
+		}
+		if err := n.storageError(errors.New("other")); errors.Is(err, server.ErrInsufficientStorage) {
+			t.Error("storageError() should not mark non-ENOSPC errors")
+		}
+
+		if _, err := n.ProposeTransactionDetailed(tx, ""); !errors.Is(err, server.ErrInsufficientStorage) {
+			t.Errorf("ProposeTransactionDetailed() error = %v, want ErrInsufficientStorage", err)
+		}
+		if len(n.PendingPool.List()) != 0 {
+			t.Error("rejected proposal should not be added to the pending pool")
+		}
+
+		req, _ := http.NewRequest("POST", "http://"+serveNode(t, n)+"/transaction/propose",
+			strings.NewReader(`{"from":"alice","to":"bob","amount":100,"title":"test"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("POST /transaction/propose error = %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusInsufficientStorage {
+			t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusInsufficientStorage)
+		}
+	})
+
+	t.Run("retry keeps accepting writes", func(t *testing.T) {
+		n := newTestNode(t, "alice")
+		n.Config.DiskFullPolicy = "retry"
+
+		if err := n.storageError(diskFull); !errors.Is(err, server.ErrInsufficientStorage) {
+			t.Fatalf("storageError() = %v, want ErrInsufficientStorage", err)
+		}
+		if _, err := n.ProposeTransactionDetailed(tx, ""); err != nil {
+			t.Errorf("ProposeTransactionDetailed() error = %v", err)
+		}
+	})
+}
+
+func TestServeChain_CorruptChain(t *testing.T) {
+	n := newTestNode(t, "alice")
+	registerDummyNodes(t, n, 2)
+
+	srv := server.NewServer("", n)
+	srv.SetValidateChain(true)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	// メモリ上のブロックを破損させる（部分書き込みなどを想定）
+	corrupt, err := n.Chain.GetBlockByIndex(1)
+	if err != nil {
+		t.Fatalf("GetBlockByIndex() error = %v", err)
+	}
+	corrupt.Header.PrevHash = "corrupt"
+
+	resp, err := http.Get(ts.URL + "/chain")
+	if err != nil {
+		t.Fatalf("GET /chain error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusInternalServerError)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	n := newTestNode(t, "alice")
+	if err := n.BlockStore.SetSyncPolicy(storage.SyncInterval, time.Hour); err != nil {
+		t.Fatalf("SetSyncPolicy() error = %v", err)
+	}
+
+	var hookCalls atomic.Int32
+	var order []string
+	n.OnShutdown(func(ctx context.Context) error {
+		hookCalls.Add(1)
+		order = append(order, "first")
+		return nil
+	})
+	n.OnShutdown(func(ctx context.Context) error {
+		order = append(order, "second")
+		return nil
+	})
+
+	stopped := make(chan struct{})
+	n.Go(func(ctx context.Context) {
+		<-ctx.Done()
+		close(stopped)
+	})
+
+	// バッファされたまま未書き出しのブロック
+	registerDummyNodes(t, n, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := n.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	if strings.Join(order, ",") != "second,first" {
+		t.Errorf("hook order = %v, want [second first]", order)
+	}
+	select {
+	case <-stopped:
+	default:
+		t.Error("background task was not stopped")
+	}
+	blocks, err := storage.NewBlockStore(n.Config.BlockFilePath()).LoadAll()
+	if err != nil {
+		t.Fatalf("LoadAll() error = %v", err)
+	}
+	if len(blocks) != n.Chain.Len() {
+		t.Errorf("persisted blocks = %d, want %d (buffered blocks flushed)", len(blocks), n.Chain.Len())
+	}
+
+	// 2回目は何もしない
+	if err := n.Shutdown(ctx); err != nil {
+		t.Errorf("second Shutdown() error = %v", err)
+	}
+	if hookCalls.Load() != 1 {
+		t.Errorf("hook called %d times, want 1", hookCalls.Load())
+	}
+}
+
+func TestNewNode_BlockIndex(t *testing.T) {
+	n := newTestNode(t, "alice")
+	registerDummyNodes(t, n, 3)
+
+	// restart は BlockIndex の設定で再起動し、全ブロックを読み直したチェーンと一致することを確認する
+	restart := func(blockIndex bool) *Node {
+		t.Helper()
+		cfg := *n.Config
+		cfg.BlockIndex = blockIndex
+		restarted, err := NewNode(&cfg)
+		if err != nil {
+			t.Fatalf("NewNode(BlockIndex=%v) error = %v", blockIndex, err)
+		}
+		t.Cleanup(func() { restarted.BlockStore.Close() })
+
+		want, err := storage.NewBlockStore(cfg.BlockFilePath()).LoadAll()
+		if err != nil {
+			t.Fatalf("LoadAll() error = %v", err)
+		}
+		got, err := restarted.Chain.GetBlocks()
+		if err != nil {
+			t.Fatalf("GetBlocks() error = %v", err)
+		}
+		if len(got) != len(want) {
+			t.Fatalf("chain length = %d, want %d", len(got), len(want))
+		}
+		for i := range want {
+			if got[i].Header.Hash != want[i].Header.Hash {
+				t.Errorf("block %d hash = %s, want %s", i, got[i].Header.Hash, want[i].Header.Hash)
+			}
+		}
+		return restarted
+	}
+	indexLines := func() int {
+		t.Helper()
+		data, err := os.ReadFile(n.BlockStore.IndexPath())
+		if err != nil {
+			t.Fatalf("ReadFile(index) error = %v", err)
+		}
+		return strings.Count(string(data), "\n")
+	}
+
+	// インデックスがなければ作り直す
+	indexed := restart(true)
+	if got := indexLines(); got != 4 {
+		t.Errorf("index has %d entries, want 4", got)
+	}
+
+	// インデックスを使うノードで追加したブロックはインデックスにも記録され、再起動後に読み込める
+	if _, err := indexed.RegisterNode("carol", "carol", "10.0.0.3", strings.Repeat("ab", 32)); err != nil {
+		t.Fatalf("RegisterNode() error = %v", err)
+	}
+	indexed.BlockStore.Close()
+	restart(true)
+	if got := indexLines(); got != 5 {
+		t.Errorf("index has %d entries, want 5", got)
+	}
+
+	// インデックスを使わないノードが追記すると block.jsonl と一致しなくなり、次の起動で作り直す
+	plain := restart(false)
+	if _, err := plain.RegisterNode("dave", "dave", "10.0.0.4", strings.Repeat("cd", 32)); err != nil {
+		t.Fatalf("RegisterNode() error = %v", err)
+	}
+	reindexed := restart(true)
+	if got := indexLines(); got != 6 {
+		t.Errorf("index has %d entries after rebuild, want 6", got)
+	}
+	if _, err := reindexed.GetBlockByHash(reindexed.Chain.GetLastHash()); err != nil {
+		t.Errorf("GetBlockByHash(last) error = %v", err)
+	}
+}
+
+func TestStartupReport(t *testing.T) {
+	n := newTestNode(t, "alice")
+	registerDummyNodes(t, n, 3)
+
+	// 再起動相当: ストレージから読み込み直したノードでレポートを作成
+	loaded, err := NewNode(n.Config)
+	if err != nil {
+		t.Fatalf("NewNode() error = %v", err)
+	}
+
+	report := loaded.StartupReport("127.0.0.1:8080", false)
+	if report.Height != loaded.Chain.Len()-1 {
+		t.Errorf("Height = %d, want %d", report.Height, loaded.Chain.Len()-1)
+	}
+	if report.Height != 3 {
+		t.Errorf("Height = %d, want 3", report.Height)
+	}
+	if report.GenesisHash != core.NewGenesisBlock().Header.Hash {
+		t.Errorf("GenesisHash = %s, want fixed genesis hash", report.GenesisHash)
+	}
+	if report.Peers != 3 {
+		t.Errorf("Peers = %d, want 3", report.Peers)
+	}
+	if report.Pending != 0 {
+		t.Errorf("Pending = %d, want 0", report.Pending)
+	}
+	if !strings.Contains(report.String(), "height=3") {
+		t.Errorf("String() = %q, want height=3", report.String())
+	}
+}
+
+func TestGetTransactionStatus(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+	alice.Config.ExpiredLogSize = 10
+	addPeer(t, alice, bob, "127.0.0.1:1")
+
+	data := &server.TransactionData{From: "alice", To: "bob", Amount: 500, Title: "ランチ"}
+	pending, err := alice.ProposeTransactionDetailed(data, "")
+	if err != nil {
+		t.Fatalf("ProposeTransactionDetailed() error = %v", err)
+	}
+
+	status, err := alice.GetTransactionStatus(pending.ID)
+	if err != nil {
+		t.Fatalf("GetTransactionStatus() error = %v", err)
+	}
+	if status.Status != "pending" || status.BlockHash != "" {
+		t.Errorf("status = %+v, want pending", status)
+	}
+
+	// bob が承認したブロックがチェーンに入ると approved になる
+	txData := &core.TransactionData{From: data.From, To: data.To, Amount: data.Amount, Title: data.Title}
+	toSig, _ := crypto.SignTransaction(bob.PrivKey, txData)
+	block, err := core.CreateBlockWithTransaction(1, alice.Chain.GetLastHash(), txData, pending.FromSig, toSig)
+	if err != nil {
+		t.Fatalf("CreateBlockWithTransaction() error = %v", err)
+	}
+	if err := alice.ReceiveBlock(convertBlockToServer(block)); err != nil {
+		t.Fatalf("ReceiveBlock() error = %v", err)
+	}
+	status, err = alice.GetTransactionStatus(pending.ID)
+	if err != nil {
+		t.Fatalf("GetTransactionStatus() error = %v", err)
+	}
+	if status.Status != "approved" || status.BlockHash != block.Header.Hash {
+		t.Errorf("status = %+v, want approved with block %s", status, block.Header.Hash)
+	}
+
+	// 拒否された提案は記録の理由を返す
+	otherData := &core.TransactionData{From: "bob", To: "alice", Amount: 100, Title: "返金"}
+	otherSig, _ := crypto.SignTransaction(bob.PrivKey, otherData)
+	other, err := alice.ProposeTransactionDetailed(&server.TransactionData{From: "bob", To: "alice", Amount: 100, Title: "返金"}, otherSig)
+	if err != nil {
+		t.Fatalf("ProposeTransactionDetailed() error = %v", err)
+	}
+	if err := alice.RejectTransaction(other.ID); err != nil {
+		t.Fatalf("RejectTransaction() error = %v", err)
+	}
+	status, err = alice.GetTransactionStatus(other.ID)
+	if err != nil {
+		t.Fatalf("GetTransactionStatus() error = %v", err)
+	}
+	if status.Status != core.ArchiveReasonRejected {
+		t.Errorf("status = %+v, want rejected", status)
+	}
+
+	if _, err := alice.GetTransactionStatus("unknown"); !errors.Is(err, server.ErrNotFound) {
+		t.Errorf("GetTransactionStatus(unknown) error = %v, want ErrNotFound", err)
+	}
+}
+
+// useFlakyChain は n のチェーンを、position 番目のブロックだけ読み込めない遅延読み込みのチェーンに置き換える
+func useFlakyChain(t *testing.T, n *Node, position int) {
+	t.Helper()
+
+	var blocks []*core.Block
+	var hashes []string
+	for i := 0; i < n.Chain.Len(); i++ {
+		b, err := n.Chain.GetBlockByIndex(i)
+		if err != nil {
+			t.Fatalf("GetBlockByIndex(%d) error = %v", i, err)
+		}
+		blocks = append(blocks, b)
+		hashes = append(hashes, b.Header.Hash)
+	}
+	chain, err := core.NewLazyChain(hashes, func(i int) (*core.Block, error) {
+		if i == position {
+			return nil, fmt.Errorf("read error")
+		}
+		return blocks[i], nil
+	})
+	if err != nil {
+		t.Fatalf("NewLazyChain() error = %v", err)
+	}
+	n.Chain = chain
+}
+
+func TestNewNode_PeerTimeout(t *testing.T) {
+	release := make(chan struct{})
+	hanging := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer hanging.Close()
+	defer close(release)
+
+	// 同じプロセスの2つのノードがそれぞれの PeerTimeoutSeconds のクライアントを持つ
+	alice := newTestNode(t, "alice")
+	cfg := *alice.Config
+	cfg.PeerTimeoutSeconds = 1
+	alice, err := NewNode(&cfg)
+	if err != nil {
+		t.Fatalf("NewNode() error = %v", err)
+	}
+	bob := newTestNode(t, "bob")
+	bob.Config.PeerTimeoutSeconds = 30
+	bob, err = NewNode(bob.Config)
+	if err != nil {
+		t.Fatalf("NewNode() error = %v", err)
+	}
+	if alice.peerClient == bob.peerClient {
+		t.Fatal("nodes share the peer HTTP client")
+	}
+	if got := alice.peerClient.Timeout; got != time.Second {
+		t.Errorf("alice peer timeout = %v, want 1s", got)
+	}
+	if got := bob.peerClient.Timeout; got != 30*time.Second {
+		t.Errorf("bob peer timeout = %v, want 30s", got)
+	}
+
+	// 応答しないピアへの問い合わせは alice のタイムアウトで打ち切られる
+	start := time.Now()
+	if _, err := alice.fetchSyncCandidate(context.Background(), strings.TrimPrefix(hanging.URL, "http://"), nil); err == nil {
+		t.Fatal("fetchSyncCandidate() should time out on a hanging peer")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("fetchSyncCandidate() returned after %v, want about 1s", elapsed)
+	}
+}
+
+func TestChainReadError(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+	alice.Config.ExpiredLogSize = 10
+	addPeer(t, alice, bob, "127.0.0.1:1")
+
+	// 拒否した提案の状態はチェーンと照合される
+	rejectedData := &core.TransactionData{From: "bob", To: "alice", Amount: 100, Title: "返金"}
+	rejectedSig, _ := crypto.SignTransaction(bob.PrivKey, rejectedData)
+	rejected, err := alice.ProposeTransactionDetailed(&server.TransactionData{From: "bob", To: "alice", Amount: 100, Title: "返金"}, rejectedSig)
+	if err != nil {
+		t.Fatalf("ProposeTransactionDetailed() error = %v", err)
+	}
+	if err := alice.RejectTransaction(rejected.ID); err != nil {
+		t.Fatalf("RejectTransaction() error = %v", err)
+	}
+
+	for _, amount := range []int64{1000, 200} {
+		block, err := core.CreateBlockWithTransaction(alice.Chain.GetLastIndex()+1, alice.Chain.GetLastHash(),
+			&core.TransactionData{From: "bob", To: "alice", Amount: amount, Title: "返済"}, "sig1", "sig2")
+		if err != nil {
+			t.Fatalf("CreateBlockWithTransaction() error = %v", err)
+		}
+		if err := alice.Chain.AddBlock(block); err != nil {
+			t.Fatalf("AddBlock() error = %v", err)
+		}
+	}
+	useFlakyChain(t, alice, 1)
+
+	// 読めないブロックがあれば一部だけの結果を返さずエラーにする
+	if records, err := alice.GetTransactionsFor("alice"); err == nil {
+		t.Errorf("GetTransactionsFor() returned %d records, want an error", len(records))
+	}
+	if status, err := alice.GetTransactionStatus(rejected.ID); err == nil {
+		t.Errorf("GetTransactionStatus() = %+v, want an error", status)
+	}
+
+	// 残高が分からなければ残高超過の検査を通さない
+	alice.Config.AllowNegativeBalance = false
+	if _, err := alice.ProposeTransactionDetailed(&server.TransactionData{From: "alice", To: "bob", Amount: 300, Title: "ランチ"}, ""); err == nil {
+		t.Error("ProposeTransactionDetailed() should fail when the balance cannot be computed")
+	}
+	if got := alice.PendingPool.Len(); got != 0 {
+		t.Errorf("pool size = %d, want 0", got)
+	}
+}
+
+func TestProposeTransaction_AfterApproval(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+	alice.Config.ExpiredLogSize = 10
+	addPeer(t, alice, bob, serveNode(t, bob))
+	addPeer(t, bob, alice, serveNode(t, alice))
+
+	// 毎週同じ内容の取引を提案する
+	data := &server.TransactionData{From: "alice", To: "bob", Amount: 500, Title: "ランチ"}
+	for week := 0; week < 2; week++ {
+		pending, err := alice.ProposeTransactionDetailed(data, "")
+		if err != nil {
+			t.Fatalf("week %d: ProposeTransactionDetailed() error = %v", week, err)
+		}
+		if !waitFor(t, 2*time.Second, func() bool { return len(bob.ListPending()) == 1 }) {
+			t.Fatalf("week %d: proposal did not reach bob", week)
+		}
+		block, err := bob.ApproveTransaction(bob.ListPending()[0].ID)
+		if err != nil {
+			t.Fatalf("week %d: ApproveTransaction() error = %v", week, err)
+		}
+		bob.BroadcastBlock(block)
+
+		// 承認ブロックを受け取った提案元のプールからも取り除かれる
+		if !waitFor(t, 2*time.Second, func() bool { return len(alice.ListProposed()) == 0 }) {
+			t.Fatalf("week %d: approved proposal is still pending on alice", week)
+		}
+		status, err := alice.GetTransactionStatus(pending.ID)
+		if err != nil {
+			t.Fatalf("week %d: GetTransactionStatus() error = %v", week, err)
+		}
+		if status.Status != "approved" || status.BlockHash != block.Header.Hash {
+			t.Errorf("week %d: status = %+v, want approved with block %s", week, status, block.Header.Hash)
+		}
+	}
+
+	if got := alice.Chain.Len(); got != 3 {
+		t.Errorf("alice chain length = %d, want 3", got)
+	}
+	if expired := alice.ListExpired(); len(expired) != 0 {
+		t.Errorf("ListExpired() = %d items, want approved proposals to be excluded", len(expired))
+	}
+}
+
+func TestProposeTransactionDetailed(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+	addPeer(t, bob, alice, "127.0.0.1:1")
+
+	data := &server.TransactionData{From: "alice", To: "bob", Amount: 500, Title: "ランチ"}
+	fromSig, err := crypto.SignTransaction(alice.PrivKey, &core.TransactionData{
+		From: data.From, To: data.To, Amount: data.Amount, Title: data.Title,
+	})
+	if err != nil {
+		t.Fatalf("SignTransaction() error = %v", err)
+	}
+
+	pending, err := bob.ProposeTransactionDetailed(data, fromSig)
+	if err != nil {
+		t.Fatalf("ProposeTransactionDetailed() error = %v", err)
+	}
+	if pending.ID == "" {
+		t.Fatal("returned pending transaction has empty ID")
+	}
+
+	stored := bob.GetPending(pending.ID)
+	if stored == nil {
+		t.Fatalf("pending %s not found in pool", pending.ID)
+	}
+	if *stored.Transaction != *pending.Transaction {
+		t.Errorf("stored transaction = %+v, want %+v", stored.Transaction, pending.Transaction)
+	}
+	if stored.FromSig != fromSig || pending.FromSig != fromSig {
+		t.Errorf("FromSig mismatch: stored=%s returned=%s", stored.FromSig, pending.FromSig)
+	}
+}
+
+func TestProposeTransactionDetailed_NegativeBalance(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	alice.Config.AllowNegativeBalance = false
+
+	// bob -> alice に 1000 の取引でアリスの残高を 1000 にする
+	block, err := core.CreateBlockWithTransaction(alice.Chain.GetLastIndex()+1, alice.Chain.GetLastHash(),
+		&core.TransactionData{From: "bob", To: "alice", Amount: 1000, Title: "返済"}, "sig1", "sig2")
+	if err != nil {
+		t.Fatalf("CreateBlockWithTransaction() error = %v", err)
+	}
+	if err := alice.Chain.AddBlock(block); err != nil {
+		t.Fatalf("AddBlock() error = %v", err)
+	}
+
+	// 残高を超える提案は拒否される
+	over := &server.TransactionData{From: "alice", To: "bob", Amount: 1001, Title: "ランチ"}
+	if _, err := alice.ProposeTransactionDetailed(over, ""); err == nil || !strings.Contains(err.Error(), "insufficient balance") {
+		t.Fatalf("ProposeTransactionDetailed(over balance) error = %v, want insufficient balance", err)
+	}
+	if got := alice.PendingPool.Len(); got != 0 {
+		t.Errorf("pool size = %d, want 0", got)
+	}
+
+	// 残高ちょうどまでは受け付ける
+	if _, err := alice.ProposeTransactionDetailed(&server.TransactionData{From: "alice", To: "bob", Amount: 1000, Title: "ランチ"}, ""); err != nil {
+		t.Fatalf("ProposeTransactionDetailed(within balance) error = %v", err)
+	}
+
+	// AllowNegativeBalance が true なら残高を超えても受け付ける
+	alice.Config.AllowNegativeBalance = true
+	if _, err := alice.ProposeTransactionDetailed(over, ""); err != nil {
+		t.Fatalf("ProposeTransactionDetailed(AllowNegativeBalance) error = %v", err)
+	}
+}
+
+func TestProposeTransactionDetailed_DuplicateProposal(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+	addPeer(t, bob, alice, "127.0.0.1:1")
+
+	// 同じ内容の提案が承認待ちなら2回目は拒否される
+	data := &server.TransactionData{From: "alice", To: "bob", Amount: 500, Title: "ランチ"}
+	fromSig, err := crypto.SignTransaction(alice.PrivKey, &core.TransactionData{
+		From: data.From, To: data.To, Amount: data.Amount, Title: data.Title,
+	})
+	if err != nil {
+		t.Fatalf("SignTransaction() error = %v", err)
+	}
+	first, err := bob.ProposeTransactionDetailed(data, fromSig)
+	if err != nil {
+		t.Fatalf("ProposeTransactionDetailed() error = %v", err)
+	}
+	if _, err := bob.ProposeTransactionDetailed(data, fromSig); !errors.Is(err, server.ErrDuplicateProposal) {
+		t.Fatalf("second ProposeTransactionDetailed() error = %v, want ErrDuplicateProposal", err)
+	}
+	if got := bob.PendingPool.Len(); got != 1 {
+		t.Errorf("pool size = %d, want 1", got)
+	}
+
+	// 金額が異なる提案は別物として受け付ける
+	other := &server.TransactionData{From: "alice", To: "bob", Amount: 600, Title: "ランチ"}
+	otherSig, err := crypto.SignTransaction(alice.PrivKey, &core.TransactionData{
+		From: other.From, To: other.To, Amount: other.Amount, Title: other.Title,
+	})
+	if err != nil {
+		t.Fatalf("SignTransaction() error = %v", err)
+	}
+	second, err := bob.ProposeTransactionDetailed(other, otherSig)
+	if err != nil {
+		t.Fatalf("ProposeTransactionDetailed(distinct) error = %v", err)
+	}
+	if first.ID == second.ID {
+		t.Fatalf("distinct proposals got the same ID %s", first.ID)
+	}
+
+	// 承認待ちから外れれば同じ内容を再度提案できる
+	if err := bob.RejectTransaction(first.ID); err != nil {
+		t.Fatalf("RejectTransaction() error = %v", err)
+	}
+	if _, err := bob.ProposeTransactionDetailed(data, fromSig); err != nil {
+		t.Errorf("ProposeTransactionDetailed() after reject error = %v", err)
+	}
+}
+
+func TestProposeTransactionDetailed_ConcurrentDuplicate(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+	addPeer(t, bob, alice, "127.0.0.1:1")
+
+	data := &server.TransactionData{From: "alice", To: "bob", Amount: 500, Title: "ランチ"}
+	fromSig, _ := crypto.SignTransaction(alice.PrivKey, &core.TransactionData{From: data.From, To: data.To, Amount: data.Amount, Title: data.Title})
+
+	// 再送などで同じ内容の提案が同時に届いても、承認待ちに入るのは1件だけ
+	const proposers = 8
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	start := make(chan struct{})
+	succeeded := 0
+	for i := 0; i < proposers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			_, err := bob.ProposeTransactionDetailed(data, fromSig)
+			if err == nil {
+				mu.Lock()
+				succeeded++
+				mu.Unlock()
+			} else if !errors.Is(err, server.ErrDuplicateProposal) {
+				t.Errorf("ProposeTransactionDetailed() error = %v, want ErrDuplicateProposal", err)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if succeeded != 1 {
+		t.Errorf("%d proposals succeeded, want 1", succeeded)
+	}
+	if got := bob.PendingPool.Len(); got != 1 {
+		t.Errorf("pool size = %d, want 1", got)
+	}
+}
+
+func TestProposeTransactionDetailed_VerifiesFromSignature(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+	mallory := newTestNode(t, "mallory")
+	addPeer(t, bob, alice, "127.0.0.1:1")
+
+	data := &server.TransactionData{From: "alice", To: "bob", Amount: 500, Title: "ランチ"}
+	txData := &core.TransactionData{From: data.From, To: data.To, Amount: data.Amount, Title: data.Title}
+
+	// alice 以外の鍵で署名された転送提案は拒否する
+	forged, err := crypto.SignTransaction(mallory.PrivKey, txData)
+	if err != nil {
+		t.Fatalf("SignTransaction() error = %v", err)
+	}
+	if _, err := bob.ProposeTransactionDetailed(data, forged); err == nil {
+		t.Fatal("ProposeTransactionDetailed(forged) error = nil, want signature error")
+	}
+	if _, err := bob.ProposeTransactionDetailed(data, "not-a-signature"); err == nil {
+		t.Fatal("ProposeTransactionDetailed(garbage) error = nil, want signature error")
+	}
+	if got := bob.PendingPool.Len(); got != 0 {
+		t.Fatalf("pool size = %d, want 0 after forged proposals", got)
+	}
+
+	// From が未知のノードなら検証できないので拒否する
+	unknown := &server.TransactionData{From: "mallory", To: "bob", Amount: 500, Title: "ランチ"}
+	sig, _ := crypto.SignTransaction(mallory.PrivKey, &core.TransactionData{From: "mallory", To: "bob", Amount: 500, Title: "ランチ"})
+	if _, err := bob.ProposeTransactionDetailed(unknown, sig); err == nil {
+		t.Fatal("ProposeTransactionDetailed(unknown from) error = nil, want error")
+	}
+
+	// alice の正しい署名は受け付ける
+	valid, err := crypto.SignTransaction(alice.PrivKey, txData)
+	if err != nil {
+		t.Fatalf("SignTransaction() error = %v", err)
+	}
+	if _, err := bob.ProposeTransactionDetailed(data, valid); err != nil {
+		t.Fatalf("ProposeTransactionDetailed(valid) error = %v", err)
+	}
+	if got := bob.PendingPool.Len(); got != 1 {
+		t.Errorf("pool size = %d, want 1", got)
+	}
+}
+
+func TestProposeTransactionDetailed_AutoApproveSelf(t *testing.T) {
+	data := &server.TransactionData{From: "alice", To: "bob", Amount: 500, Title: "ランチ"}
+
+	t.Run("default keeps pending", func(t *testing.T) {
+		bob := newTestNode(t, "bob")
+		pending, err := bob.ProposeTransactionDetailed(data, "")
+		if err != nil {
+			t.Fatalf("ProposeTransactionDetailed() error = %v", err)
+		}
+		if bob.GetPending(pending.ID) == nil {
+			t.Errorf("pending %s not found in pool", pending.ID)
+		}
+		if got := bob.Chain.Len(); got != 1 {
+			t.Errorf("chain length = %d, want 1", got)
+		}
+	})
+
+	t.Run("enabled commits block", func(t *testing.T) {
+		bob := newTestNode(t, "bob")
+		bob.Config.AutoApproveSelf = true
+		pending, err := bob.ProposeTransactionDetailed(data, "")
+		if err != nil {
+			t.Fatalf("ProposeTransactionDetailed() error = %v", err)
+		}
+		if bob.GetPending(pending.ID) != nil {
+			t.Errorf("pending %s still in pool", pending.ID)
+		}
+		if got := bob.Chain.Len(); got != 2 {
+			t.Fatalf("chain length = %d, want 2", got)
+		}
+		tx, err := bob.Chain.LastBlock().GetTransactionData()
+		if err != nil {
+			t.Fatalf("GetTransactionData() error = %v", err)
+		}
+		if tx.From != data.From || tx.To != data.To || tx.Amount != data.Amount {
+			t.Errorf("last block tx = %+v, want %+v", tx, data)
+		}
+		stored, err := bob.BlockStore.LoadAll()
+		if err != nil {
+			t.Fatalf("LoadAll() error = %v", err)
+		}
+		if len(stored) != 2 {
+			t.Errorf("stored blocks = %d, want 2", len(stored))
+		}
+	})
+}
+
+func TestAmountPolicy(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+	addPeer(t, bob, alice, "127.0.0.1:1")
+	addPeer(t, alice, bob, "127.0.0.1:1")
+
+	propose := func(n *Node, amount int64) (*server.PendingTransaction, error) {
+		tx := &core.TransactionData{From: "alice", To: "bob", Amount: amount, Title: "精算"}
+		fromSig, err := crypto.SignTransaction(alice.PrivKey, tx)
+		if err != nil {
+			t.Fatalf("SignTransaction() error = %v", err)
+		}
+		return n.ProposeTransactionDetailed(&server.TransactionData{
+			From: tx.From, To: tx.To, Amount: tx.Amount, Title: tx.Title,
+		}, fromSig)
+	}
+
+	t.Run("propose", func(t *testing.T) {
+		bob.Config.MinAmount, bob.Config.MaxAmount = 100, 1000
+		defer func() { bob.Config.MinAmount, bob.Config.MaxAmount = 0, 0 }()
+
+		var amountErr *core.AmountError
+		if _, err := propose(bob, 99); !errors.As(err, &amountErr) {
+			t.Errorf("below min: error = %v, want *core.AmountError", err)
+		}
+		if _, err := propose(bob, 1001); !errors.As(err, &amountErr) {
+			t.Errorf("above max: error = %v, want *core.AmountError", err)
+		}
+		if _, err := propose(bob, 500); err != nil {
+			t.Errorf("in range: error = %v", err)
+		}
+	})
+
+	t.Run("receive block", func(t *testing.T) {
+		// bob（上限なし）で確定したブロックを上限付きの alice が受信する
+		pending, err := propose(bob, 5000)
+		if err != nil {
+			t.Fatalf("propose error = %v", err)
+		}
+		block, err := bob.ApproveTransaction(pending.ID)
+		if err != nil {
+			t.Fatalf("ApproveTransaction() error = %v", err)
+		}
+
+		alice.Config.MaxAmount = 1000
+		err = alice.ReceiveBlock(block)
+		var amountErr *core.AmountError
+		if !errors.As(err, &amountErr) {
+			t.Fatalf("ReceiveBlock() error = %v, want *core.AmountError", err)
+		}
+		if alice.Chain.Len() != 1 {
+			t.Errorf("alice chain length = %d, want 1", alice.Chain.Len())
+		}
+
+		alice.Config.MaxAmount = 0
+		if err := alice.ReceiveBlock(block); err != nil {
+			t.Errorf("ReceiveBlock() without max error = %v", err)
+		}
+	})
+}
+
+func TestTitleValidation(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+	addPeer(t, bob, alice, "127.0.0.1:1")
+	addPeer(t, alice, bob, "127.0.0.1:1")
+
+	sign := func(priv ed25519.PrivateKey, tx *core.TransactionData) string {
+		sig, err := crypto.SignTransaction(priv, tx)
+		if err != nil {
+			t.Fatalf("SignTransaction() error = %v", err)
+		}
+		return sig
+	}
+	propose := func(n *Node, title string) (*server.PendingTransaction, error) {
+		tx := &core.TransactionData{From: "alice", To: "bob", Amount: 100, Title: title}
+		return n.ProposeTransactionDetailed(&server.TransactionData{
+			From: tx.From, To: tx.To, Amount: tx.Amount, Title: tx.Title,
+		}, sign(alice.PrivKey, tx))
+	}
+
+	t.Run("propose", func(t *testing.T) {
+		bob.Config.MaxTitleLength = 10
+		defer func() { bob.Config.MaxTitleLength = 0 }()
+
+		for _, title := range []string{strings.Repeat("あ", 11), "lunch\nextra", "nul\x00byte"} {
+			if _, err := propose(bob, title); !errors.Is(err, core.ErrInvalidTitle) {
+				t.Errorf("propose(%q) error = %v, want ErrInvalidTitle", title, err)
+			}
+		}
+		if _, err := propose(bob, strings.Repeat("あ", 10)); err != nil {
+			t.Errorf("propose(10 characters) error = %v", err)
+		}
+	})
+
+	t.Run("receive block", func(t *testing.T) {
+		// 上限なしの bob で確定した長いタイトルのブロックを上限付きの alice が受信する
+		pending, err := propose(bob, strings.Repeat("x", 300))
+		if err != nil {
+			t.Fatalf("propose error = %v", err)
+		}
+		block, err := bob.ApproveTransaction(pending.ID)
+		if err != nil {
+			t.Fatalf("ApproveTransaction() error = %v", err)
+		}
+		alice.Config.MaxTitleLength = 256
+		if err := alice.ReceiveBlock(block); !errors.Is(err, core.ErrInvalidTitle) {
+			t.Errorf("ReceiveBlock(long title) error = %v, want ErrInvalidTitle", err)
+		}
+
+		// 両者が署名していても制御文字を含むタイトルは受け付けない
+		tx := &core.TransactionData{From: "alice", To: "bob", Amount: 100, Title: "line1\nline2"}
+		data, _ := json.Marshal(tx)
+		last := alice.Chain.LastBlock()
+		forged := core.NewBlock(last.Header.Index+1, last.Header.Hash, core.BlockPayload{
+			Type: "transaction", Data: data, FromSignature: sign(alice.PrivKey, tx), ToSignature: sign(bob.PrivKey, tx),
+		})
+		if err := alice.ReceiveBlock(convertBlockToServer(forged)); !errors.Is(err, core.ErrInvalidTitle) {
+			t.Errorf("ReceiveBlock(control characters) error = %v, want ErrInvalidTitle", err)
+		}
+		if alice.Chain.Len() != 1 {
+			t.Errorf("alice chain length = %d, want 1", alice.Chain.Len())
+		}
+	})
+}
+
+func TestReceiveBlockFrom_Status(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+
+	var blocks []*server.Block
+	for _, name := range []string{"carol", "dave", "erin"} {
+		block, err := alice.RegisterNode(name, name, "10.0.0.1", strings.Repeat("ab", 32))
+		if err != nil {
+			t.Fatalf("RegisterNode(%s) error = %v", name, err)
+		}
+		blocks = append(blocks, block)
+	}
+
+	receive := func(b *server.Block) server.ReceiveStatus {
+		t.Helper()
+		status, _ := bob.ReceiveBlockFrom(b, "")
+		return status
+	}
+
+	if got := receive(blocks[0]); got != server.ReceiveAdded {
+		t.Errorf("first receive status = %q, want %q", got, server.ReceiveAdded)
+	}
+	if got := receive(blocks[0]); got != server.ReceiveDuplicate {
+		t.Errorf("second receive status = %q, want %q", got, server.ReceiveDuplicate)
+	}
+
+	tampered := *blocks[1]
+	tampered.Header.Hash = strings.Repeat("0", 64)
+	if got := receive(&tampered); got != server.ReceiveRejected {
+		t.Errorf("tampered block status = %q, want %q", got, server.ReceiveRejected)
+	}
+
+	// 持っているブロックと同じ位置の別のブロック（フォーク）は ErrBlockBehind で拒否する
+	genesis, _ := bob.Chain.GetBlockByIndex(0)
+	fork, err := core.CreateBlockWithAddNode(1, genesis.Header.Hash, &core.AddNodeData{NodeName: "mallory", NickName: "m", Address: "10.0.0.9", PublicKey: strings.Repeat("ef", 32)})
+	if err != nil {
+		t.Fatalf("CreateBlockWithAddNode() error = %v", err)
+	}
+	if status, err := bob.ReceiveBlockFrom(convertBlockToServer(fork), ""); status != server.ReceiveRejected || !errors.Is(err, server.ErrBlockBehind) {
+		t.Errorf("fork block = %q, %v; want %q, ErrBlockBehind", status, err, server.ReceiveRejected)
+	}
+
+	// 先のブロックはエラーにせず、既知のピアからチェーンを同期する
+	addPeer(t, bob, alice, serveNode(t, alice))
+	status, err := bob.ReceiveBlockFrom(blocks[2], "")
+	if err != nil || status != server.ReceiveSyncQueued {
+		t.Fatalf("ahead block = %q, %v; want %q", status, err, server.ReceiveSyncQueued)
+	}
+	if !waitFor(t, 2*time.Second, func() bool { return bob.Chain.Len() == alice.Chain.Len() }) {
+		t.Errorf("bob chain length = %d after queued sync, want %d", bob.Chain.Len(), alice.Chain.Len())
+	}
+}
+
+func TestReceiveBlockFrom_CatchUp(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+
+	// alice だけがブロック N+1, N+2 を持つ
+	var blocks []*server.Block
+	for _, name := range []string{"carol", "dave"} {
+		block, err := alice.RegisterNode(name, name, "10.0.0.1", strings.Repeat("ab", 32))
+		if err != nil {
+			t.Fatalf("RegisterNode(%s) error = %v", name, err)
+		}
+		blocks = append(blocks, block)
+	}
+
+	var mu sync.Mutex
+	var queries []string
+	handler := server.NewServer("", alice).Handler()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chain" {
+			mu.Lock()
+			queries = append(queries, r.URL.RawQuery)
+			mu.Unlock()
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(ts.Close)
+	addPeer(t, bob, alice, strings.TrimPrefix(ts.URL, "http://"))
+
+	// N+2 を受け取ると、送信元の alice から N+1 を取得してから N+2 を追加する
+	status, err := bob.ReceiveBlockFrom(blocks[1], "alice")
+	if err != nil {
+		t.Fatalf("ReceiveBlockFrom() error = %v", err)
+	}
+	if status != server.ReceiveAdded {
+		t.Errorf("status = %q, want %q", status, server.ReceiveAdded)
+	}
+	if bob.Chain.Len() != alice.Chain.Len() || bob.Chain.GetLastHash() != alice.Chain.GetLastHash() {
+		t.Errorf("bob chain = %d blocks (tip %s), want alice's %d blocks (tip %s)",
+			bob.Chain.Len(), bob.Chain.GetLastHash(), alice.Chain.Len(), alice.Chain.GetLastHash())
+	}
+	if !bob.Chain.HasBlock(blocks[0].Header.Hash) {
+		t.Error("bob is missing the caught-up block N+1")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := fmt.Sprintf("from=%d&to=%d", blocks[0].Header.Index, blocks[1].Header.Index)
+	if !slices.Equal(queries, []string{want}) {
+		t.Errorf("GET /chain queries = %v, want [%s]", queries, want)
+	}
+
+	// 永続化もされている
+	stored, err := bob.BlockStore.LoadAll()
+	if err != nil {
+		t.Fatalf("LoadAll() error = %v", err)
+	}
+	if len(stored) != alice.Chain.Len() {
+		t.Errorf("stored blocks = %d, want %d", len(stored), alice.Chain.Len())
+	}
+}
+
+func TestReceiveBlockFrom_Origin(t *testing.T) {
+	alice := newTestNode(t, "alice")
+	bob := newTestNode(t, "bob")
+	addPeer(t, alice, bob, "127.0.0.1:1")
+
+	block, err := alice.RegisterNode("carol", "キャロル", "10.0.0.3", strings.Repeat("ab", 32))
+	if err != nil {
+		t.Fatalf("RegisterNode() error = %v", err)
+	}
+
+	t.Run("unknown origin", func(t *testing.T) {
+		_, err := bob.ReceiveBlockFrom(block, "mallory")
+		if err == nil || !strings.Contains(err.Error(), "unknown origin") {
+			t.Fatalf("ReceiveBlockFrom() error = %v, want unknown origin", err)
+		}
+		if bob.Chain.Len() != 1 {
+			t.Errorf("bob chain length = %d, want 1", bob.Chain.Len())
+		}
+	})
+
+	t.Run("known origin", func(t *testing.T) {
+		addPeer(t, bob, alice, "127.0.0.1:1")
+
+		if _, err := bob.ReceiveBlockFrom(block, "alice"); err != nil {
+			t.Fatalf("ReceiveBlockFrom() error = %v", err)
+		}
+		if bob.Chain.Len() != 2 {
+			t.Errorf("bob chain length = %d, want 2", bob.Chain.Len())
+		}
+		if st, ok := bob.reachability.Status("alice"); !ok || !st.Reachable {
+			t.Errorf("reachability of alice = %+v, %v; want reachable", st, ok)
+		}
+	})
+}
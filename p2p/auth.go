@@ -0,0 +1,47 @@
+package p2p
+
+import "net/http"
+
+// apiKey は UseAPIKey で設定したピアの更新系エンドポイント用の共有鍵（空なら付与しない）
+var apiKey string
+
+// UseAPIKey はピアへの更新系リクエスト（GET / HEAD 以外）に Authorization: Bearer <key> を付与するよう設定する（空文字列で無効）
+// ノードの起動時、NewClient でクライアントを作成する前に呼ぶこと
+func UseAPIKey(key string) {
+	apiKey = key
+	updateTransport()
+}
+
+// transport は UseTLS・UseAPIKey の設定から組み立てた、NewClient が使うトランスポート（nil なら http.DefaultTransport）
+var transport http.RoundTripper
+
+// updateTransport は UseTLS・UseAPIKey の設定から transport を組み立てる
+func updateTransport() {
+	if apiKey == "" {
+		transport = tlsTransport
+		return
+	}
+	transport = &APIKeyTransport{Base: tlsTransport, Key: apiKey}
+}
+
+// APIKeyTransport は GET / HEAD 以外のリクエストに Authorization: Bearer <Key> を付与する http.RoundTripper
+// Base が nil なら http.DefaultTransport を使う
+type APIKeyTransport struct {
+	Base http.RoundTripper
+	Key  string
+}
+
+// RoundTrip は必要なら Authorization ヘッダーを付けたリクエストの複製を Base に渡す
+func (t *APIKeyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if t.Key == "" || req.Method == http.MethodGet || req.Method == http.MethodHead || req.Header.Get("Authorization") != "" {
+		return base.RoundTrip(req)
+	}
+	// RoundTripper は元のリクエストを書き換えてはならない
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+t.Key)
+	return base.RoundTrip(req)
+}
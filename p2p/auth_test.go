@@ -0,0 +1,48 @@
+package p2p
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUseAPIKey(t *testing.T) {
+	gotAuth := make(map[string]string)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth[r.Method] = r.Header.Get("Authorization")
+	}))
+	defer ts.Close()
+
+	UseAPIKey("shared-key")
+	defer UseAPIKey("")
+
+	resp, err := NewClient(DefaultTimeout).Post(ts.URL+"/block", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	resp.Body.Close()
+	resp, err = NewClient(DefaultTimeout).Get(ts.URL + "/chain")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if got := gotAuth[http.MethodPost]; got != "Bearer shared-key" {
+		t.Errorf("POST Authorization = %q, want %q", got, "Bearer shared-key")
+	}
+	if got := gotAuth[http.MethodGet]; got != "" {
+		t.Errorf("GET Authorization = %q, want empty", got)
+	}
+
+	// 無効化すると付与しない
+	UseAPIKey("")
+	resp, err = NewClient(DefaultTimeout).Post(ts.URL+"/block", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	resp.Body.Close()
+	if got := gotAuth[http.MethodPost]; got != "" {
+		t.Errorf("POST Authorization after disabling = %q, want empty", got)
+	}
+}
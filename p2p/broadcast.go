@@ -2,24 +2,70 @@ package p2p
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand/v2"
 	"net/http"
 	"signet/storage"
 	"sync"
 	"time"
 )
 
-// httpClient はタイムアウト付きHTTPクライアント
-var httpClient = &http.Client{
-	Timeout: 10 * time.Second,
+// OriginHeader は送信元ノード名を伝えるリクエストヘッダー
+const OriginHeader = "X-Signet-Origin"
+
+// DefaultTimeout はピアへの HTTP リクエストのデフォルトのタイムアウト
+const DefaultTimeout = 10 * time.Second
+
+// NewClient はピアとの通信に使う HTTP クライアントを作成する
+// timeout はピアへの1回のリクエスト（応答本体の読み込みを含む）のタイムアウト（0 でタイムアウトなし）
+// ノードごとに作成して FetchChain・BroadcastBlock などに渡す。トランスポートは UseTLS・UseAPIKey の設定を使うため、それらの後に呼ぶこと
+func NewClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+	}
+}
+
+// ブロック送信の再試行（一時的に到達できないピアが取りこぼさないよう、指数バックオフ＋ジッターで再送する）
+var (
+	sendAttempts  = 3
+	sendBaseDelay = 200 * time.Millisecond
+)
+
+// StatusError はピアが200以外のステータスを返したことを表す
+// ピアには到達できているため、到達性の判定では成功として扱う
+type StatusError struct {
+	Code int
+	Body string
 }
 
-// BroadcastBlock は全ピア（自分以外）にブロックを送信する
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d, body: %s", e.Code, e.Body)
+}
+
+// IsReachable は送信結果のエラーからピアに到達できたかを判定する
+func IsReachable(err error) bool {
+	if err == nil {
+		return true
+	}
+	var statusErr *StatusError
+	return errors.As(err, &statusErr)
+}
+
+// BroadcastBlock は client で全ピア（自分以外）にブロックを送信する
 // block は server.Block 型に変換済みのものを渡すこと
-func BroadcastBlock(block any, peers map[string]*storage.NodeInfo, selfName string) {
-	var wg sync.WaitGroup
+// 送信に失敗したピアには再試行し、ctx がキャンセルされたら再試行をやめる
+// ピア名ごとの送信結果（成功時は nil）を返す
+func BroadcastBlock(ctx context.Context, client *http.Client, block any, peers map[string]*storage.NodeInfo, selfName string) map[string]error {
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		results = make(map[string]error)
+	)
 
 	for name, peer := range peers {
 		if name == selfName {
@@ -30,27 +76,80 @@ func BroadcastBlock(block any, peers map[string]*storage.NodeInfo, selfName stri
 		go func(nodeName string, addr string) {
 			defer wg.Done()
 
-			if err := sendBlock(addr, block); err != nil {
+			err := sendBlock(ctx, client, addr, selfName, block)
+			if err != nil {
 				// エラーはログに出力するだけ（送信失敗しても続行）
 				fmt.Printf("Warning: failed to send block to %s (%s): %v\n", nodeName, addr, err)
 			}
+
+			mu.Lock()
+			results[nodeName] = err
+			mu.Unlock()
 		}(name, peer.Address)
 	}
 
 	wg.Wait()
+	return results
+}
+
+// SendBlock は client で指定したアドレスにブロックを1つ送信する
+// origin は送信元（自ノード）の名前で、OriginHeader に設定される
+func SendBlock(ctx context.Context, client *http.Client, addr, origin string, block any) error {
+	return sendBlock(ctx, client, addr, origin, block)
 }
 
 // sendBlock は指定したアドレスにブロックをPOSTする
-func sendBlock(addr string, block any) error {
+// 到達できない・ピアが一時的に処理できない（5xx / 429）場合は sendAttempts 回まで再試行する
+// 再試行の間隔は sendBaseDelay から倍々に伸ばし、ジッターを加える。ctx がキャンセルされたら最後のエラーを返す
+func sendBlock(ctx context.Context, client *http.Client, addr, origin string, block any) error {
 	// JSONエンコード
 	data, err := json.Marshal(block)
 	if err != nil {
 		return fmt.Errorf("failed to marshal block: %w", err)
 	}
 
+	delay := sendBaseDelay
+	for attempt := 1; ; attempt++ {
+		err = postBlock(ctx, client, addr, origin, data)
+		if err == nil || attempt >= sendAttempts || !retryable(err) {
+			return err
+		}
+
+		// delay の 0.5〜1.5 倍待つ
+		wait := delay/2 + rand.N(delay)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(wait):
+		}
+		delay *= 2
+	}
+}
+
+// retryable は送信エラーが再試行で解消しうるかを返す
+// ピアがブロックを拒否した（4xx）場合は同じブロックを再送しても結果は変わらない
+func retryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Code >= 500 || statusErr.Code == http.StatusTooManyRequests
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// postBlock はエンコード済みのブロックを1回だけPOSTする
+func postBlock(ctx context.Context, client *http.Client, addr, origin string, data []byte) error {
 	// POSTリクエスト（タイムアウト付き）
-	url := fmt.Sprintf("http://%s/block", addr)
-	resp, err := httpClient.Post(url, "application/json", bytes.NewReader(data))
+	url := PeerURL(addr, "/block")
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if origin != "" {
+		req.Header.Set(OriginHeader, origin)
+	}
+
+	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to send request: %w", err)
 	}
@@ -59,7 +158,7 @@ func sendBlock(addr string, block any) error {
 	// ステータスコードチェック
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("unexpected status code: %d, body: %s", resp.StatusCode, string(body))
+		return &StatusError{Code: resp.StatusCode, Body: string(body)}
 	}
 
 	return nil
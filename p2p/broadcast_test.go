@@ -0,0 +1,104 @@
+package p2p
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"signet/storage"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSendBlock_SetsOriginHeader(t *testing.T) {
+	var origin, contentType string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin = r.Header.Get(OriginHeader)
+		contentType = r.Header.Get("Content-Type")
+	}))
+	defer ts.Close()
+
+	if err := SendBlock(context.Background(), NewClient(DefaultTimeout), strings.TrimPrefix(ts.URL, "http://"), "alice", map[string]string{}); err != nil {
+		t.Fatalf("SendBlock() error = %v", err)
+	}
+	if origin != "alice" {
+		t.Errorf("%s = %q, want alice", OriginHeader, origin)
+	}
+	if contentType != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", contentType)
+	}
+}
+
+func TestSendBlock_Retry(t *testing.T) {
+	prev := sendBaseDelay
+	sendBaseDelay = 10 * time.Millisecond
+	t.Cleanup(func() { sendBaseDelay = prev })
+
+	// failures 回だけ status で失敗し、その後は成功するピア
+	newPeer := func(t *testing.T, failures int32, status int) (string, *atomic.Int32) {
+		var calls atomic.Int32
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) <= failures {
+				w.WriteHeader(status)
+			}
+		}))
+		t.Cleanup(ts.Close)
+		return strings.TrimPrefix(ts.URL, "http://"), &calls
+	}
+
+	t.Run("succeeds after transient failures", func(t *testing.T) {
+		addr, calls := newPeer(t, 2, http.StatusServiceUnavailable)
+		if err := SendBlock(context.Background(), NewClient(DefaultTimeout), addr, "alice", map[string]string{}); err != nil {
+			t.Fatalf("SendBlock() error = %v", err)
+		}
+		if got := calls.Load(); got != 3 {
+			t.Errorf("attempts = %d, want 3", got)
+		}
+	})
+
+	t.Run("gives up after max attempts", func(t *testing.T) {
+		addr, calls := newPeer(t, 10, http.StatusServiceUnavailable)
+		err := SendBlock(context.Background(), NewClient(DefaultTimeout), addr, "alice", map[string]string{})
+		if err == nil {
+			t.Fatal("SendBlock() error = nil, want error after retries")
+		}
+		if got := calls.Load(); got != int32(sendAttempts) {
+			t.Errorf("attempts = %d, want %d", got, sendAttempts)
+		}
+		// 結果はピア名ごとに返すだけで、失敗しても続行する
+		results := BroadcastBlock(context.Background(), NewClient(DefaultTimeout), map[string]string{}, map[string]*storage.NodeInfo{"bob": {Address: addr}}, "alice")
+		if results["bob"] == nil {
+			t.Error("BroadcastBlock() result for bob = nil, want error")
+		}
+	})
+
+	t.Run("does not retry rejected blocks", func(t *testing.T) {
+		addr, calls := newPeer(t, 10, http.StatusBadRequest)
+		if err := SendBlock(context.Background(), NewClient(DefaultTimeout), addr, "alice", map[string]string{}); err == nil {
+			t.Fatal("SendBlock() error = nil, want rejection")
+		}
+		if got := calls.Load(); got != 1 {
+			t.Errorf("attempts = %d, want 1", got)
+		}
+	})
+
+	t.Run("stops retrying when context is cancelled", func(t *testing.T) {
+		sendBaseDelay = time.Hour
+		t.Cleanup(func() { sendBaseDelay = 10 * time.Millisecond })
+
+		addr, calls := newPeer(t, 10, http.StatusServiceUnavailable)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		if err := SendBlock(ctx, NewClient(DefaultTimeout), addr, "alice", map[string]string{}); err == nil {
+			t.Fatal("SendBlock() error = nil, want error")
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("SendBlock() took %v after cancellation", elapsed)
+		}
+		if got := calls.Load(); got != 1 {
+			t.Errorf("attempts = %d, want 1", got)
+		}
+	})
+}
@@ -0,0 +1,128 @@
+package p2p
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// PeerInfo はピアの GET /info のレスポンスのうち同期に使う項目
+type PeerInfo struct {
+	NodeName    string `json:"node_name"`
+	ChainLength int    `json:"chain_length"`
+	Version     string `json:"version"` // 古いピアは返さない（空文字列）
+}
+
+// FetchInfo は client でピアの GET /info を取得する。ctx がキャンセルされたらリクエストを中断する
+func FetchInfo(ctx context.Context, client *http.Client, addr string) (*PeerInfo, error) {
+	var info PeerInfo
+	if err := getJSON(ctx, client, PeerURL(addr, "/info"), &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// NDJSONContentType は GET /chain のストリーミング応答（1行に1ブロックの JSON）の Content-Type
+const NDJSONContentType = "application/x-ndjson"
+
+// ChainBlocksTrailer はストリーミング応答を最後まで書き出したピアが付けるトレーラー（書き出したブロック数）
+// 宣言されているのに届かない・件数が合わない応答は途中で打ち切られたものとして扱う
+const ChainBlocksTrailer = "X-Chain-Blocks"
+
+// FetchChain は client でピアの GET /chain からチェーン全体を取得する
+// B には *server.Block を指定すること（BroadcastBlock と同様に server パッケージへは依存しない）
+func FetchChain[B any](ctx context.Context, client *http.Client, addr string) ([]B, error) {
+	return fetchBlocks[B](ctx, client, PeerURL(addr, "/chain"))
+}
+
+// FetchChainFrom はピアの GET /chain?from=N で Index が from 以上のブロックを取得する
+// 範囲指定に対応していないピアはチェーン全体を返すため、呼び出し側で先頭のインデックスを確認すること
+func FetchChainFrom[B any](ctx context.Context, client *http.Client, addr string, from int) ([]B, error) {
+	return fetchBlocks[B](ctx, client, PeerURL(addr, fmt.Sprintf("/chain?from=%d", from)))
+}
+
+// FetchChainRange はピアの GET /chain?from=N&to=M で Index が from 以上 to 未満のブロックを取得する
+// 範囲指定に対応していないピアはチェーン全体を返すため、呼び出し側で先頭のインデックスを確認すること
+func FetchChainRange[B any](ctx context.Context, client *http.Client, addr string, from, to int) ([]B, error) {
+	return fetchBlocks[B](ctx, client, PeerURL(addr, fmt.Sprintf("/chain?from=%d&to=%d", from, to)))
+}
+
+// fetchBlocks は url にストリーミング応答（NDJSON）を要求して GET し、ブロックを順にデコードする
+// Accept を無視して JSON 配列で返すピアにも対応する
+// ChainBlocksTrailer を宣言したピアの応答は、トレーラーの件数と一致しなければエラーにする（行の区切りで打ち切られた応答の検出）
+func fetchBlocks[B any](ctx context.Context, client *http.Client, url string) ([]B, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", NDJSONContentType)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &StatusError{Code: resp.StatusCode, Body: string(body)}
+	}
+
+	var blocks []B
+	dec := json.NewDecoder(resp.Body)
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), NDJSONContentType) {
+		if err := dec.Decode(&blocks); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		return blocks, nil
+	}
+	for dec.More() {
+		var b B
+		if err := dec.Decode(&b); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		blocks = append(blocks, b)
+	}
+
+	// トレーラーはボディを最後まで読んだ後に届く（接続が切れていればここでエラーになる）
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		return nil, fmt.Errorf("chain stream truncated after %d blocks: %w", len(blocks), err)
+	}
+	if _, declared := resp.Trailer[http.CanonicalHeaderKey(ChainBlocksTrailer)]; declared {
+		count, err := strconv.Atoi(resp.Trailer.Get(ChainBlocksTrailer))
+		if err != nil {
+			return nil, fmt.Errorf("chain stream truncated after %d blocks: missing %s trailer", len(blocks), ChainBlocksTrailer)
+		}
+		if count != len(blocks) {
+			return nil, fmt.Errorf("chain stream truncated: received %d of %d blocks", len(blocks), count)
+		}
+	}
+	return blocks, nil
+}
+
+// getJSON は url に GET し、200 ならレスポンスを out にデコードする
+func getJSON(ctx context.Context, client *http.Client, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return &StatusError{Code: resp.StatusCode, Body: string(body)}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
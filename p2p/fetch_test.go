@@ -0,0 +1,174 @@
+package p2p
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchInfoAndChainFrom(t *testing.T) {
+	var query, accept string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /info", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"node_name":"alice","chain_length":5,"pending_count":0}`))
+	})
+	mux.HandleFunc("GET /chain", func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(`[{"index":3},{"index":4}]`))
+	})
+	mux.HandleFunc("GET /stream", func(w http.ResponseWriter, r *http.Request) {
+		accept = r.Header.Get("Accept")
+		w.Header().Set("Content-Type", NDJSONContentType)
+		w.Write([]byte("{\"index\":0}\n{\"index\":1}\n{\"index\":2}\n"))
+	})
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	addr := strings.TrimPrefix(ts.URL, "http://")
+
+	info, err := FetchInfo(context.Background(), NewClient(DefaultTimeout), addr)
+	if err != nil {
+		t.Fatalf("FetchInfo() error = %v", err)
+	}
+	if info.NodeName != "alice" || info.ChainLength != 5 {
+		t.Errorf("FetchInfo() = %+v, want alice/5", info)
+	}
+
+	type block struct {
+		Index int `json:"index"`
+	}
+	blocks, err := FetchChainFrom[block](context.Background(), NewClient(DefaultTimeout), addr, 3)
+	if err != nil {
+		t.Fatalf("FetchChainFrom() error = %v", err)
+	}
+	if query != "from=3" || len(blocks) != 2 || blocks[0].Index != 3 {
+		t.Errorf("query = %q, blocks = %+v, want from=3 and 2 blocks", query, blocks)
+	}
+
+	// NDJSON の応答は1行ずつデコードする
+	streamed, err := fetchBlocks[block](context.Background(), NewClient(DefaultTimeout), ts.URL+"/stream")
+	if err != nil {
+		t.Fatalf("fetchBlocks() error = %v", err)
+	}
+	if accept != NDJSONContentType || len(streamed) != 3 || streamed[2].Index != 2 {
+		t.Errorf("Accept = %q, blocks = %+v, want NDJSON and 3 blocks", accept, streamed)
+	}
+
+	// 200 以外は StatusError（到達はできている）
+	ts.Config.Handler = http.NotFoundHandler()
+	_, err = FetchInfo(context.Background(), NewClient(DefaultTimeout), addr)
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.Code != http.StatusNotFound || !IsReachable(err) {
+		t.Errorf("FetchInfo() error = %v, want reachable StatusError 404", err)
+	}
+}
+
+func TestFetchBlocks_Truncated(t *testing.T) {
+	type block struct {
+		Index int `json:"index"`
+	}
+	lines := "{\"index\":0}\n{\"index\":1}\n"
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		wantErr bool
+	}{
+		{
+			name: "complete",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", NDJSONContentType)
+				w.Header().Set("Trailer", ChainBlocksTrailer)
+				w.Write([]byte(lines))
+				w.Header().Set(ChainBlocksTrailer, "2")
+			},
+		},
+		{
+			// トレーラーを付けない古いピアはそのまま受け入れる
+			name: "no trailer declared",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", NDJSONContentType)
+				w.Write([]byte(lines))
+			},
+		},
+		{
+			// 行の区切りで書き出しをやめた応答（トレーラーなし）
+			name: "stopped without trailer",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", NDJSONContentType)
+				w.Header().Set("Trailer", ChainBlocksTrailer)
+				w.Write([]byte(lines))
+			},
+			wantErr: true,
+		},
+		{
+			name: "count mismatch",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", NDJSONContentType)
+				w.Header().Set("Trailer", ChainBlocksTrailer)
+				w.Write([]byte(lines))
+				w.Header().Set(ChainBlocksTrailer, "3")
+			},
+			wantErr: true,
+		},
+		{
+			// 書き込み期限切れなどで接続が切れた応答
+			name: "connection closed",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", NDJSONContentType)
+				w.Header().Set("Trailer", ChainBlocksTrailer)
+				w.Write([]byte(lines))
+				w.(http.Flusher).Flush()
+				conn, _, err := http.NewResponseController(w).Hijack()
+				if err == nil {
+					conn.Close()
+				}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts := httptest.NewServer(tt.handler)
+			defer ts.Close()
+
+			blocks, err := fetchBlocks[block](context.Background(), NewClient(DefaultTimeout), ts.URL)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("fetchBlocks() = %d blocks, want truncation error", len(blocks))
+				}
+				return
+			}
+			if err != nil || len(blocks) != 2 {
+				t.Errorf("fetchBlocks() = %d blocks, %v, want 2 blocks", len(blocks), err)
+			}
+		})
+	}
+}
+
+func TestNewClient_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	// クライアントごとにタイムアウトを持ち、他のクライアントの設定に影響しない
+	short := NewClient(50 * time.Millisecond)
+	long := NewClient(DefaultTimeout)
+	if short.Timeout != 50*time.Millisecond || long.Timeout != DefaultTimeout {
+		t.Fatalf("Timeout = %v / %v, want 50ms / %v", short.Timeout, long.Timeout, DefaultTimeout)
+	}
+	start := time.Now()
+	if _, err := FetchInfo(context.Background(), short, strings.TrimPrefix(ts.URL, "http://")); err == nil {
+		t.Fatal("FetchInfo() should time out on a hanging peer")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("FetchInfo() returned after %v, want about 50ms", elapsed)
+	}
+}
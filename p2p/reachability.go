@@ -0,0 +1,78 @@
+package p2p
+
+import (
+	"sync"
+	"time"
+)
+
+// PeerStatus はピアの到達性を表す
+type PeerStatus struct {
+	Reachable   bool
+	LastSeen    time.Time // 最後に応答があった時刻
+	LastFailure time.Time // 最後に到達できなかった時刻
+	Failures    int       // 連続失敗回数
+}
+
+// Reachability はピアごとの到達性を追跡する
+type Reachability struct {
+	mu    sync.Mutex
+	peers map[string]*PeerStatus
+}
+
+// NewReachability は新しい Reachability を作成する
+func NewReachability() *Reachability {
+	return &Reachability{
+		peers: make(map[string]*PeerStatus),
+	}
+}
+
+// Record はピアへの通信結果を記録する
+// 直前まで到達不能だったピアに到達できた場合（復帰）は true を返す
+func (r *Reachability) Record(name string, reachable bool) (recovered bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	st, ok := r.peers[name]
+	if !ok {
+		st = &PeerStatus{Reachable: true}
+		r.peers[name] = st
+	}
+
+	if !reachable {
+		st.Reachable = false
+		st.Failures++
+		st.LastFailure = time.Now()
+		return false
+	}
+
+	recovered = !st.Reachable
+	st.Reachable = true
+	st.Failures = 0
+	st.LastSeen = time.Now()
+	return recovered
+}
+
+// Status はピアの到達性を返す。記録がなければ false を返す
+func (r *Reachability) Status(name string) (PeerStatus, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	st, ok := r.peers[name]
+	if !ok {
+		return PeerStatus{}, false
+	}
+	return *st, true
+}
+
+// ShouldSkip は到達不能なピアへの送信を見送るべきかを返す
+// 最後の失敗から window が経過するまで見送り、経過したら再試行させる。記録がないピアや到達可能なピアは見送らない
+func (r *Reachability) ShouldSkip(name string, window time.Duration) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	st, ok := r.peers[name]
+	if !ok || st.Reachable {
+		return false
+	}
+	return time.Since(st.LastFailure) < window
+}
@@ -0,0 +1,75 @@
+package p2p
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestReachability_Record(t *testing.T) {
+	r := NewReachability()
+
+	if _, ok := r.Status("bob"); ok {
+		t.Error("Status should return false for unknown peer")
+	}
+
+	// 初回成功は復帰ではない
+	if r.Record("bob", true) {
+		t.Error("first success should not be reported as recovery")
+	}
+
+	r.Record("bob", false)
+	r.Record("bob", false)
+	st, _ := r.Status("bob")
+	if st.Reachable || st.Failures != 2 {
+		t.Errorf("Status = %+v, want unreachable with 2 failures", st)
+	}
+
+	// 失敗後の最初の成功は復帰
+	if !r.Record("bob", true) {
+		t.Error("success after failures should be reported as recovery")
+	}
+	if r.Record("bob", true) {
+		t.Error("second success should not be reported as recovery")
+	}
+	st, _ = r.Status("bob")
+	if !st.Reachable || st.Failures != 0 || st.LastSeen.IsZero() {
+		t.Errorf("Status = %+v, want reachable with LastSeen set", st)
+	}
+}
+
+func TestReachability_ShouldSkip(t *testing.T) {
+	r := NewReachability()
+
+	if r.ShouldSkip("bob", time.Minute) {
+		t.Error("unknown peer should not be skipped")
+	}
+	r.Record("bob", true)
+	if r.ShouldSkip("bob", time.Minute) {
+		t.Error("reachable peer should not be skipped")
+	}
+
+	r.Record("bob", false)
+	if !r.ShouldSkip("bob", time.Minute) {
+		t.Error("peer that just failed should be skipped within the window")
+	}
+	if r.ShouldSkip("bob", 0) {
+		t.Error("zero window should never skip")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if r.ShouldSkip("bob", 10*time.Millisecond) {
+		t.Error("peer should be retried once the window has passed")
+	}
+}
+
+func TestIsReachable(t *testing.T) {
+	if !IsReachable(nil) {
+		t.Error("nil error should be reachable")
+	}
+	if !IsReachable(fmt.Errorf("wrapped: %w", &StatusError{Code: 400})) {
+		t.Error("status error should be reachable")
+	}
+	if IsReachable(fmt.Errorf("failed to send request: connection refused")) {
+		t.Error("transport error should be unreachable")
+	}
+}
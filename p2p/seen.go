@@ -0,0 +1,52 @@
+package p2p
+
+import "sync"
+
+// SeenSet は転送済みブロックのハッシュを記録する（容量を超えたら古いものから忘れる）
+// 同じブロックを何度も受け取った場合に再ブロードキャストしないために使う
+type SeenSet struct {
+	mu       sync.Mutex
+	capacity int
+	hashes   map[string]struct{}
+	order    []string // 記録した順（古い順）
+}
+
+// NewSeenSet は最大 capacity 件を記録する SeenSet を作成する
+func NewSeenSet(capacity int) *SeenSet {
+	return &SeenSet{
+		capacity: capacity,
+		hashes:   make(map[string]struct{}),
+	}
+}
+
+// Mark は hash を記録する。初めて記録した場合は true、既に記録済みなら false を返す
+func (s *SeenSet) Mark(hash string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.hashes[hash]; ok {
+		return false
+	}
+
+	s.hashes[hash] = struct{}{}
+	s.order = append(s.order, hash)
+	if len(s.order) > s.capacity {
+		delete(s.hashes, s.order[0])
+		s.order = s.order[1:]
+	}
+	return true
+}
+
+// Len は記録しているハッシュの数を返す
+func (s *SeenSet) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.order)
+}
+
+// Hashes は記録しているハッシュを記録した順（古い順）に返す
+func (s *SeenSet) Hashes() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.order...)
+}
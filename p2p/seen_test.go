@@ -0,0 +1,41 @@
+package p2p
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSeenSet_Mark(t *testing.T) {
+	s := NewSeenSet(2)
+
+	if !s.Mark("a") {
+		t.Error("first Mark(a) should return true")
+	}
+	if s.Mark("a") {
+		t.Error("second Mark(a) should return false")
+	}
+
+	// 容量を超えると古いものから忘れる
+	s.Mark("b")
+	s.Mark("c")
+	if s.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", s.Len())
+	}
+	if s.Mark("c") {
+		t.Error("Mark(c) should return false while c is remembered")
+	}
+	if !s.Mark("a") {
+		t.Error("Mark(a) should return true after a was evicted")
+	}
+}
+
+func TestSeenSet_Hashes(t *testing.T) {
+	s := NewSeenSet(2)
+	s.Mark("a")
+	s.Mark("b")
+	s.Mark("c")
+
+	if got, want := s.Hashes(), []string{"b", "c"}; !slices.Equal(got, want) {
+		t.Errorf("Hashes() = %v, want %v", got, want)
+	}
+}
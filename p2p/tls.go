@@ -0,0 +1,77 @@
+package p2p
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// scheme は P2P 通信の URL スキーム（UseTLS で https になる）
+var scheme = "http"
+
+// tlsTransport は UseTLS で設定した TLS 用のトランスポート（nil なら http.DefaultTransport）
+var tlsTransport http.RoundTripper
+
+// TLSConfigs は証明書ファイルから作成したサーバー用・クライアント用の TLS 設定
+type TLSConfigs struct {
+	Server *tls.Config
+	Client *tls.Config
+}
+
+// LoadTLS はノードの証明書・秘密鍵と CA 証明書から TLS 設定を作成する
+// caFile を指定した場合は相互 TLS とし、サーバーはその CA で署名されたクライアント証明書を検証し（提示は任意。要求は server 側で行う）、
+// クライアントはその CA でピアのサーバー証明書を検証する。caFile が空ならクライアント証明書を要求せず、システムのルート証明書で検証する
+func LoadTLS(certFile, keyFile, caFile string, minVersion uint16) (*TLSConfigs, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS certificate: %w", err)
+	}
+
+	serverCfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   minVersion,
+	}
+	clientCfg := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   minVersion,
+	}
+
+	if caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TLS CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in TLS CA file: %s", caFile)
+		}
+		serverCfg.ClientCAs = pool
+		serverCfg.ClientAuth = tls.VerifyClientCertIfGiven
+		clientCfg.RootCAs = pool
+	}
+
+	return &TLSConfigs{Server: serverCfg, Client: clientCfg}, nil
+}
+
+// UseTLS はピアとの通信を cfg の TLS（https）で行うよう設定する。nil なら平文（http）に戻す
+// ノードの起動時、NewClient でクライアントを作成する前に呼ぶこと
+func UseTLS(cfg *tls.Config) {
+	if cfg == nil {
+		scheme = "http"
+		tlsTransport = nil
+		updateTransport()
+		return
+	}
+	scheme = "https"
+	base := http.DefaultTransport.(*http.Transport).Clone()
+	base.TLSClientConfig = cfg
+	tlsTransport = base
+	updateTransport()
+}
+
+// PeerURL はピアの addr（host:port）と path から URL を作る
+func PeerURL(addr, path string) string {
+	return scheme + "://" + addr + path
+}
@@ -0,0 +1,240 @@
+package p2p
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCA はテスト用の認証局
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "signet test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate() error = %v", err)
+	}
+	return &testCA{cert: cert, key: key}
+}
+
+// writeFiles は CA 証明書と、CA が name に発行したノード証明書・秘密鍵を dir に書き出してパスを返す
+func (ca *testCA) writeFiles(t *testing.T, dir, name string) (certFile, keyFile, caFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("MarshalECPrivateKey() error = %v", err)
+	}
+
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	caFile = filepath.Join(dir, name+"-ca.crt")
+	files := map[string]*pem.Block{
+		certFile: {Type: "CERTIFICATE", Bytes: der},
+		keyFile:  {Type: "EC PRIVATE KEY", Bytes: keyDER},
+		caFile:   {Type: "CERTIFICATE", Bytes: ca.cert.Raw},
+	}
+	for path, block := range files {
+		if err := os.WriteFile(path, pem.EncodeToMemory(block), 0600); err != nil {
+			t.Fatalf("WriteFile() error = %v", err)
+		}
+	}
+	return certFile, keyFile, caFile
+}
+
+func TestLoadTLS_MutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := newTestCA(t)
+
+	bobCert, bobKey, caFile := ca.writeFiles(t, dir, "bob")
+	bob, err := LoadTLS(bobCert, bobKey, caFile, tls.VersionTLS12)
+	if err != nil {
+		t.Fatalf("LoadTLS(bob) error = %v", err)
+	}
+
+	// bob は検証済みのクライアント証明書の CN を記録し、証明書がなければ 401 を返す
+	var clientName string
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.TLS.VerifiedChains) == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		clientName = r.TLS.VerifiedChains[0][0].Subject.CommonName
+	}))
+	ts.TLS = bob.Server
+	ts.StartTLS()
+	defer ts.Close()
+	addr := strings.TrimPrefix(ts.URL, "https://")
+
+	t.Run("same CA", func(t *testing.T) {
+		aliceCert, aliceKey, _ := ca.writeFiles(t, dir, "alice")
+		alice, err := LoadTLS(aliceCert, aliceKey, caFile, tls.VersionTLS12)
+		if err != nil {
+			t.Fatalf("LoadTLS(alice) error = %v", err)
+		}
+		UseTLS(alice.Client)
+		t.Cleanup(func() { UseTLS(nil) })
+
+		if err := SendBlock(context.Background(), NewClient(DefaultTimeout), addr, "alice", map[string]string{}); err != nil {
+			t.Fatalf("SendBlock() error = %v", err)
+		}
+		if clientName != "alice" {
+			t.Errorf("verified client = %q, want alice", clientName)
+		}
+	})
+
+	t.Run("other CA", func(t *testing.T) {
+		// 別の CA が発行した証明書は受け付けられない
+		malloryCert, malloryKey, _ := newTestCA(t).writeFiles(t, t.TempDir(), "mallory")
+		mallory, err := LoadTLS(malloryCert, malloryKey, caFile, tls.VersionTLS12)
+		if err != nil {
+			t.Fatalf("LoadTLS(mallory) error = %v", err)
+		}
+		UseTLS(mallory.Client)
+		t.Cleanup(func() { UseTLS(nil) })
+
+		if err := SendBlock(context.Background(), NewClient(DefaultTimeout), addr, "mallory", map[string]string{}); err == nil {
+			t.Error("SendBlock() should fail with a certificate from another CA")
+		}
+	})
+}
+
+func TestUseTLS_FetchAndSend(t *testing.T) {
+	var gotBlock bool
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/info":
+			w.Write([]byte(`{"node_name":"bob","chain_length":2}`))
+		case "/chain":
+			w.Header().Set("Content-Type", NDJSONContentType)
+			w.Write([]byte("{\"index\":0}\n{\"index\":1}\n"))
+		case "/block":
+			gotBlock = true
+		}
+	}))
+	defer ts.Close()
+	addr := strings.TrimPrefix(ts.URL, "https://")
+
+	// 平文のままでは TLS のピアと通信できない
+	if _, err := FetchInfo(context.Background(), NewClient(DefaultTimeout), addr); err == nil {
+		t.Fatal("FetchInfo() over plain HTTP should fail against a TLS peer")
+	}
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ts.Certificate())
+	UseTLS(&tls.Config{RootCAs: pool})
+	defer UseTLS(nil)
+
+	if got := PeerURL(addr, "/chain"); got != "https://"+addr+"/chain" {
+		t.Errorf("PeerURL() = %q, want https scheme", got)
+	}
+	info, err := FetchInfo(context.Background(), NewClient(DefaultTimeout), addr)
+	if err != nil {
+		t.Fatalf("FetchInfo() error = %v", err)
+	}
+	if info.NodeName != "bob" || info.ChainLength != 2 {
+		t.Errorf("FetchInfo() = %+v", info)
+	}
+	blocks, err := FetchChain[map[string]int](context.Background(), NewClient(DefaultTimeout), addr)
+	if err != nil {
+		t.Fatalf("FetchChain() error = %v", err)
+	}
+	if len(blocks) != 2 || blocks[1]["index"] != 1 {
+		t.Errorf("FetchChain() = %v, want 2 blocks", blocks)
+	}
+	if err := SendBlock(context.Background(), NewClient(DefaultTimeout), addr, "alice", map[string]string{}); err != nil {
+		t.Fatalf("SendBlock() error = %v", err)
+	}
+	if !gotBlock {
+		t.Error("block was not delivered over TLS")
+	}
+}
+
+func TestLoadTLS_Options(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, caFile := newTestCA(t).writeFiles(t, dir, "bob")
+
+	configs, err := LoadTLS(certFile, keyFile, caFile, tls.VersionTLS13)
+	if err != nil {
+		t.Fatalf("LoadTLS() error = %v", err)
+	}
+	if configs.Server.MinVersion != tls.VersionTLS13 || configs.Client.MinVersion != tls.VersionTLS13 {
+		t.Errorf("MinVersion = %x/%x, want TLS 1.3", configs.Server.MinVersion, configs.Client.MinVersion)
+	}
+	if configs.Server.ClientAuth != tls.VerifyClientCertIfGiven || configs.Server.ClientCAs == nil || configs.Client.RootCAs == nil {
+		t.Error("CA file should enable client certificate verification and peer verification")
+	}
+
+	// CA を指定しなければクライアント証明書は要求しない
+	configs, err = LoadTLS(certFile, keyFile, "", tls.VersionTLS12)
+	if err != nil {
+		t.Fatalf("LoadTLS() error = %v", err)
+	}
+	if configs.Server.ClientAuth != tls.NoClientCert || configs.Server.ClientCAs != nil {
+		t.Error("client certificates should not be requested without a CA file")
+	}
+
+	if _, err := LoadTLS(filepath.Join(dir, "missing.crt"), keyFile, "", tls.VersionTLS12); err == nil {
+		t.Error("LoadTLS() should fail for a missing certificate")
+	}
+	empty := filepath.Join(dir, "empty.crt")
+	if err := os.WriteFile(empty, nil, 0600); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+	if _, err := LoadTLS(certFile, keyFile, empty, tls.VersionTLS12); err == nil {
+		t.Error("LoadTLS() should fail for a CA file without certificates")
+	}
+}
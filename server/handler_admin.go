@@ -0,0 +1,51 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// requireAdmin は管理用エンドポイントを保護するミドルウェア
+// SetAdminToken でトークンが設定されていなければ 404、Authorization: Bearer <token> が一致しなければ 401 を返す
+func (s *Server) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.adminToken == "" {
+			http.NotFound(w, r)
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.adminToken)) != 1 {
+			writeError(w, http.StatusUnauthorized, CodeUnauthorized, "invalid admin token")
+			return
+		}
+		next(w, r)
+	}
+}
+
+// handleAdminRollback はチェーンを指定インデックスのブロックまで巻き戻す
+// リクエスト: {"index": 5}（0 ならジェネシスのみ残す）。index の指定がない・範囲外なら 400
+func (s *Server) handleAdminRollback(w http.ResponseWriter, r *http.Request) {
+	var req rollbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+	if req.Index == nil {
+		writeError(w, http.StatusBadRequest, CodeValidationFailed, "index is required")
+		return
+	}
+
+	removed, err := s.node.RollbackChain(*req.Index)
+	if err != nil {
+		writeNodeError(w, err, http.StatusBadRequest, "Failed to roll back chain: "+err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, rollbackResponse{
+		Status:      "rolled_back",
+		Removed:     removed,
+		ChainLength: s.node.GetChainLen(),
+	})
+}
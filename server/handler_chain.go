@@ -1,31 +1,216 @@
 package server
 
 import (
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 )
 
+// blockHashLength はブロックハッシュ（SHA-256 の hex）の文字数
+const blockHashLength = 64
+
+// ndjsonContentType は GET /chain のストリーミング応答（1行に1ブロックの JSON）の Content-Type（p2p.NDJSONContentType と同じ値）
+const ndjsonContentType = "application/x-ndjson"
+
+// chainStreamPageSize は GET /chain のストリーミング応答でまとめて取得するブロック数
+const chainStreamPageSize = 500
+
+// chainStreamPageTimeout は GET /chain のストリーミング応答で1ページの書き出しに許す時間
+// サーバー全体の WriteTimeout では大きなチェーンの途中で応答が打ち切られるため、ページごとに書き込み期限を延ばす
+const chainStreamPageTimeout = 10 * time.Second
+
+// chainBlocksTrailer は NDJSON 応答を最後まで書き出したときに付けるトレーラー（書き出したブロック数、p2p.ChainBlocksTrailer と同じ値）
+// 途中で打ち切られた応答には付かないため、受信側は短いチェーンと区別できる
+const chainBlocksTrailer = "X-Chain-Blocks"
+
+// chainTooLargeResponse は GET /chain の JSON 配列が上限を超える場合のレスポンス
+// Next は上限内に収まる最初のページの URL
+type chainTooLargeResponse struct {
+	Error     string    `json:"error"`
+	Code      ErrorCode `json:"code"`
+	MaxBlocks int       `json:"max_blocks"`
+	Next      string    `json:"next"`
+}
+
 // handleGetChain はチェーン全体をJSON配列で返す
+// ?from=10&to=20 を指定すると from <= index < to のブロックだけを返す（省略時はそれぞれ先頭・末尾）
+// 範囲外の値はチェーンの範囲に丸め、整数でない場合や from > to の場合は 400
+// Accept に application/x-ndjson を含む場合は件数によらず NDJSON で逐次返す
+// JSON 配列で SetMaxChainBlocks の上限を超える場合は 413 と次に要求すべき範囲を返す
+// SetValidateChain が有効なら先に構造を検証し、不正なチェーンは配信せず 500 を返す
 func (s *Server) handleGetChain(w http.ResponseWriter, r *http.Request) {
-	chain := s.node.GetChain()
+	query := r.URL.Query()
+	ranged := query.Has("from") || query.Has("to")
+	from, to := 0, math.MaxInt
+	if v := query.Get("from"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, CodeValidationFailed, "from must be an integer")
+			return
+		}
+		from = n
+	}
+	if v := query.Get("to"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, CodeValidationFailed, "to must be an integer")
+			return
+		}
+		to = n
+	}
+	if from > to {
+		writeError(w, http.StatusBadRequest, CodeValidationFailed, "from must not be greater than to")
+		return
+	}
+
+	if s.validateChain {
+		if err := s.node.ValidateChainStructure(); err != nil {
+			writeError(w, http.StatusInternalServerError, CodeInternal, "local chain failed validation: "+err.Error())
+			return
+		}
+	}
+
+	if strings.Contains(r.Header.Get("Accept"), ndjsonContentType) {
+		s.streamChain(w, from, to)
+		return
+	}
+
+	// JSON 配列はまとめてエンコードするため、大きすぎる範囲はページ分割を求める
+	if s.maxChainBlocks > 0 {
+		lo, hi := max(from, 0), min(to, s.node.GetChainLen())
+		if hi-lo > s.maxChainBlocks {
+			writeJSON(w, http.StatusRequestEntityTooLarge, chainTooLargeResponse{
+				Error:     fmt.Sprintf("chain segment of %d blocks exceeds the limit of %d; request it in pages or as %s", hi-lo, s.maxChainBlocks, ndjsonContentType),
+				Code:      CodeBodyTooLarge,
+				MaxBlocks: s.maxChainBlocks,
+				Next:      fmt.Sprintf("/chain?from=%d&to=%d", lo, lo+s.maxChainBlocks),
+			})
+			return
+		}
+	}
+
+	if !ranged {
+		chain, err := s.node.GetChain()
+		if err != nil {
+			writeNodeError(w, err, http.StatusInternalServerError, "Failed to get chain: "+err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, chain)
+		return
+	}
+	chain, err := s.node.GetChainRange(from, to)
+	if err != nil {
+		writeNodeError(w, err, http.StatusBadRequest, "Failed to get chain: "+err.Error())
+		return
+	}
 	writeJSON(w, http.StatusOK, chain)
 }
 
-// handleReceiveBlock はブロックをJSONでデコードし、node.ReceiveBlock()で処理する
+// streamChain は from <= index < to のブロックを NDJSON（1行に1ブロック）で書き出す
+// チェーン全体を一度に変換・エンコードしないよう chainStreamPageSize ごとに取得し、そのたびに書き込み期限を延ばす
+// 最後まで書き出せた場合だけ chainBlocksTrailer にブロック数を入れる
+func (s *Server) streamChain(w http.ResponseWriter, from, to int) {
+	w.Header().Set("Content-Type", ndjsonContentType)
+	w.Header().Set("Trailer", chainBlocksTrailer)
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	enc := json.NewEncoder(w)
+	count := 0
+	for from = max(from, 0); from < to; {
+		end := to
+		if to-from > chainStreamPageSize {
+			end = from + chainStreamPageSize
+		}
+		page, err := s.node.GetChainRange(from, end)
+		if err != nil {
+			return
+		}
+		if len(page) == 0 {
+			break
+		}
+		// 書き込み期限を設定できない ResponseWriter（テスト用など）ではサーバーの WriteTimeout のまま
+		_ = rc.SetWriteDeadline(time.Now().Add(chainStreamPageTimeout))
+		for _, b := range page {
+			if err := enc.Encode(b); err != nil {
+				return
+			}
+		}
+		count += len(page)
+		from = page[len(page)-1].Header.Index + 1
+	}
+	w.Header().Set(chainBlocksTrailer, strconv.Itoa(count))
+}
+
+// handleVerifyChain はチェーン全体（署名含む）を検証し、その結果を返す
+// レスポンス: {"valid": true} または {"valid": false, "index": 3, "reason": "..."}
+func (s *Server) handleVerifyChain(w http.ResponseWriter, r *http.Request) {
+	result := s.node.VerifyChain()
+	writeJSON(w, http.StatusOK, result)
+}
+
+// handleGetBlock は指定インデックスのブロックを返す
+// インデックスが整数でなければ 400、範囲外なら 404
+func (s *Server) handleGetBlock(w http.ResponseWriter, r *http.Request) {
+	index, err := strconv.Atoi(r.PathValue("index"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, CodeValidationFailed, "index must be an integer")
+		return
+	}
+
+	block, err := s.node.GetBlockByIndex(index)
+	if err != nil {
+		writeNodeError(w, err, http.StatusInternalServerError, "Failed to get block: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, block)
+}
+
+// handleGetBlockByHash は指定ハッシュのブロックを返す
+// ハッシュが 64 文字の hex でなければ探索せずに 400、存在しなければ 404
+func (s *Server) handleGetBlockByHash(w http.ResponseWriter, r *http.Request) {
+	hash := strings.ToLower(r.PathValue("hash"))
+	if len(hash) != blockHashLength {
+		writeError(w, http.StatusBadRequest, CodeValidationFailed, "hash must be a 64-character hex string")
+		return
+	}
+	if _, err := hex.DecodeString(hash); err != nil {
+		writeError(w, http.StatusBadRequest, CodeValidationFailed, "hash must be a 64-character hex string")
+		return
+	}
+
+	block, err := s.node.GetBlockByHash(hash)
+	if err != nil {
+		writeNodeError(w, err, http.StatusInternalServerError, "Failed to get block: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, block)
+}
+
+// handleReceiveBlock はブロックをJSONでデコードし、node.ReceiveBlockFrom()で処理する
+// X-Signet-Origin ヘッダーがあれば送信元ノード名として渡す（未指定も許可）
+// レスポンスの status は added / duplicate / sync_queued、拒否した場合は rejected とエラーを返す
 func (s *Server) handleReceiveBlock(w http.ResponseWriter, r *http.Request) {
 	var block Block
 	if err := json.NewDecoder(r.Body).Decode(&block); err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid JSON: "+err.Error())
+		writeDecodeError(w, err)
 		return
 	}
 
-	if err := s.node.ReceiveBlock(&block); err != nil {
-		writeError(w, http.StatusBadRequest, "Failed to receive block: "+err.Error())
+	status, err := s.node.ReceiveBlockFrom(&block, r.Header.Get(originHeader))
+	if err != nil {
+		httpStatus := errorStatus(err, http.StatusBadRequest)
+		writeJSON(w, httpStatus, receiveBlockResponse{
+			Status: ReceiveRejected,
+			Error:  "Failed to receive block: " + err.Error(),
+			Code:   statusErrorCode(httpStatus),
+		})
 		return
 	}
-
-	type response struct {
-		Status string `json:"status"`
-	}
-	writeJSON(w, http.StatusOK, response{Status: "received"})
+	writeJSON(w, http.StatusOK, receiveBlockResponse{Status: status})
 }
@@ -1,12 +1,17 @@
 package server
 
-import "net/http"
+import (
+	"net/http"
+	"signet/version"
+)
 
+// handleGetInfo は自ノードの情報（ノード名・チェーン長・自ノード宛の承認待ち件数・バージョン）を返す
+// ヘルスチェック用ダッシュボードが各ピアをポーリングする用途を想定
 func (s *Server) handleGetInfo(w http.ResponseWriter, r *http.Request) {
-	type response struct {
-		NodeName string `json:"node_name"`
-	}
-	writeJSON(w, http.StatusOK, response{
-		NodeName: s.node.GetNodeName(),
+	writeJSON(w, http.StatusOK, infoResponse{
+		NodeName:     s.node.GetNodeName(),
+		ChainLength:  s.node.GetChainLen(),
+		PendingCount: len(s.node.ListPending()),
+		Version:      version.Version,
 	})
 }
@@ -0,0 +1,28 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// handleGetMetrics は監視用の統計値を Prometheus のテキスト形式（text/plain; version=0.0.4）で返す
+func (s *Server) handleGetMetrics(w http.ResponseWriter, r *http.Request) {
+	m := s.node.GetMetrics()
+
+	var b strings.Builder
+	writeMetric(&b, "signet_chain_length", "gauge", "Number of blocks in the local chain.", uint64(m.ChainLength))
+	writeMetric(&b, "signet_pending_transactions", "gauge", "Number of pending transactions.", uint64(m.PendingCount))
+	writeMetric(&b, "signet_peers", "gauge", "Number of known peers.", uint64(m.Peers))
+	writeMetric(&b, "signet_blocks_received_total", "counter", "Total blocks received from peers.", m.BlocksReceived)
+	writeMetric(&b, "signet_broadcasts_sent_total", "counter", "Total blocks broadcast to peers.", m.BroadcastsSent)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(b.String()))
+}
+
+// writeMetric は1つのメトリクスを HELP / TYPE 行とともに書き込む
+func writeMetric(b *strings.Builder, name, kind, help string, value uint64) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n%s %d\n", name, help, name, kind, name, value)
+}
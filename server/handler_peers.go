@@ -9,3 +9,10 @@ func (s *Server) handleGetPeers(w http.ResponseWriter, r *http.Request) {
 	peers := s.node.GetPeers()
 	writeJSON(w, http.StatusOK, peers)
 }
+
+// handleGetPeerHealth は各ピアに GET /info で問い合わせ、到達性と最後に応答があった時刻を返す
+// ブロードキャストが届かない原因の調査用
+func (s *Server) handleGetPeerHealth(w http.ResponseWriter, r *http.Request) {
+	health := s.node.CheckPeerHealth(r.Context())
+	writeJSON(w, http.StatusOK, health)
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"regexp"
+	"signet/crypto"
 )
 
 // handleRegister はノード登録を処理する
@@ -18,36 +19,41 @@ func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid JSON: "+err.Error())
+		writeDecodeError(w, err)
 		return
 	}
 
 	// 入力バリデーション
 	if req.NodeName == "" {
-		writeError(w, http.StatusBadRequest, "node_name is required")
+		writeError(w, http.StatusBadRequest, CodeValidationFailed, "node_name is required")
 		return
 	}
 	// ノード名は英数字・ハイフン・アンダースコアのみ許可（パストラバーサル防止）
 	if !regexp.MustCompile(`^[a-zA-Z0-9_-]+$`).MatchString(req.NodeName) {
-		writeError(w, http.StatusBadRequest, "node_name must contain only alphanumeric characters, hyphens, and underscores")
+		writeError(w, http.StatusBadRequest, CodeValidationFailed, "node_name must contain only alphanumeric characters, hyphens, and underscores")
 		return
 	}
 	if req.NickName == "" {
-		writeError(w, http.StatusBadRequest, "nick_name is required")
+		writeError(w, http.StatusBadRequest, CodeValidationFailed, "nick_name is required")
 		return
 	}
 	if req.Address == "" {
-		writeError(w, http.StatusBadRequest, "address is required")
+		writeError(w, http.StatusBadRequest, CodeValidationFailed, "address is required")
 		return
 	}
 	if req.PublicKey == "" {
-		writeError(w, http.StatusBadRequest, "public_key is required")
+		writeError(w, http.StatusBadRequest, CodeValidationFailed, "public_key is required")
+		return
+	}
+	// 不正な公開鍵を登録すると、そのノードの署名検証が後から分かりにくい形で失敗するため先に弾く
+	if _, err := crypto.HexToPublicKey(req.PublicKey); err != nil {
+		writeError(w, http.StatusBadRequest, CodeValidationFailed, err.Error())
 		return
 	}
 
 	block, err := s.node.RegisterNode(req.NodeName, req.NickName, req.Address, req.PublicKey)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "Failed to register node: "+err.Error())
+		writeNodeError(w, err, http.StatusBadRequest, "Failed to register node: "+err.Error())
 		return
 	}
 
@@ -63,3 +69,40 @@ func (s *Server) handleRegister(w http.ResponseWriter, r *http.Request) {
 		Block:  block,
 	})
 }
+
+// handleUpdateNickname は自ノードのニックネーム変更を処理する
+// リクエスト: {"nick_name": "アリス"}
+// レスポンス: {"status": "updated", "block": {...}}
+func (s *Server) handleUpdateNickname(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		NickName string `json:"nick_name"`
+	}
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	if req.NickName == "" {
+		writeError(w, http.StatusBadRequest, CodeValidationFailed, "nick_name is required")
+		return
+	}
+
+	block, err := s.node.UpdateNickname(req.NickName)
+	if err != nil {
+		writeNodeError(w, err, http.StatusBadRequest, "Failed to update nickname: "+err.Error())
+		return
+	}
+
+	// 成功したらブロックをブロードキャスト
+	s.node.BroadcastBlock(block)
+
+	type response struct {
+		Status string `json:"status"`
+		Block  *Block `json:"block"`
+	}
+	writeJSON(w, http.StatusOK, response{
+		Status: "updated",
+		Block:  block,
+	})
+}
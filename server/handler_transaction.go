@@ -7,7 +7,7 @@ import (
 
 // handlePropose はトランザクション提案を処理する
 // リクエスト: {"from": "alice", "to": "bob", "amount": 1000, "title": "飲み会代"}
-// レスポンス: {"status": "proposed", "message": "Transaction proposed to bob"}
+// レスポンス: {"status": "proposed", "message": "Transaction proposed to bob", "id": "..."}
 func (s *Server) handlePropose(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		From          string `json:"from"`
@@ -18,33 +18,29 @@ func (s *Server) handlePropose(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid JSON: "+err.Error())
+		writeDecodeError(w, err)
 		return
 	}
 
 	// 入力バリデーション
 	if req.From == "" {
-		writeError(w, http.StatusBadRequest, "from is required")
+		writeError(w, http.StatusBadRequest, CodeValidationFailed, "from is required")
 		return
 	}
 	if req.To == "" {
-		writeError(w, http.StatusBadRequest, "to is required")
+		writeError(w, http.StatusBadRequest, CodeValidationFailed, "to is required")
 		return
 	}
 	if req.From == req.To {
-		writeError(w, http.StatusBadRequest, "from and to must be different")
+		writeError(w, http.StatusBadRequest, CodeValidationFailed, "from and to must be different")
 		return
 	}
 	if req.Amount <= 0 {
-		writeError(w, http.StatusBadRequest, "amount must be positive")
+		writeError(w, http.StatusBadRequest, CodeValidationFailed, "amount must be positive")
 		return
 	}
 	if req.Title == "" {
-		writeError(w, http.StatusBadRequest, "title is required")
-		return
-	}
-	if len(req.Title) > 200 {
-		writeError(w, http.StatusBadRequest, "title must be 200 characters or less")
+		writeError(w, http.StatusBadRequest, CodeValidationFailed, "title is required")
 		return
 	}
 
@@ -55,18 +51,21 @@ func (s *Server) handlePropose(w http.ResponseWriter, r *http.Request) {
 		Title:  req.Title,
 	}
 
-	if err := s.node.ProposeTransaction(data, req.FromSignature); err != nil {
-		writeError(w, http.StatusBadRequest, "Failed to propose transaction: "+err.Error())
+	pending, err := s.node.ProposeTransactionDetailed(data, req.FromSignature)
+	if err != nil {
+		writeNodeError(w, err, http.StatusBadRequest, "Failed to propose transaction: "+err.Error())
 		return
 	}
 
 	type response struct {
 		Status  string `json:"status"`
 		Message string `json:"message"`
+		ID      string `json:"id"`
 	}
 	writeJSON(w, http.StatusOK, response{
 		Status:  "proposed",
 		Message: "Transaction proposed to " + req.To,
+		ID:      pending.ID,
 	})
 }
 
@@ -79,13 +78,13 @@ func (s *Server) handleApprove(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid JSON: "+err.Error())
+		writeDecodeError(w, err)
 		return
 	}
 
 	block, err := s.node.ApproveTransaction(req.ID)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "Failed to approve transaction: "+err.Error())
+		writeNodeError(w, err, http.StatusBadRequest, "Failed to approve transaction: "+err.Error())
 		return
 	}
 
@@ -111,12 +110,12 @@ func (s *Server) handleReject(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid JSON: "+err.Error())
+		writeDecodeError(w, err)
 		return
 	}
 
 	if err := s.node.RejectTransaction(req.ID); err != nil {
-		writeError(w, http.StatusBadRequest, "Failed to reject transaction: "+err.Error())
+		writeNodeError(w, err, http.StatusBadRequest, "Failed to reject transaction: "+err.Error())
 		return
 	}
 
@@ -130,14 +129,96 @@ func (s *Server) handleReject(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// handleExpired は To ノードからの期限切れ通知を処理する
+// to_signature は To ノードが from_signature に対して行った署名（crypto.SignExpiry）
+// リクエスト: {"from": "alice", "to": "bob", "amount": 1000, "title": "飲み会代", "from_signature": "...", "to_signature": "..."}
+// レスポンス: {"status": "expired", "message": "Transaction expired"}
+func (s *Server) handleExpired(w http.ResponseWriter, r *http.Request) {
+	var req expiredRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeDecodeError(w, err)
+		return
+	}
+
+	if req.FromSignature == "" {
+		writeError(w, http.StatusBadRequest, CodeValidationFailed, "from_signature is required")
+		return
+	}
+	if req.ToSignature == "" {
+		writeError(w, http.StatusBadRequest, CodeValidationFailed, "to_signature is required")
+		return
+	}
+
+	data := &TransactionData{
+		From:   req.From,
+		To:     req.To,
+		Amount: req.Amount,
+		Title:  req.Title,
+	}
+
+	if err := s.node.NotifyExpired(data, req.FromSignature, req.ToSignature); err != nil {
+		writeNodeError(w, err, http.StatusBadRequest, "Failed to expire transaction: "+err.Error())
+		return
+	}
+
+	type response struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	}
+	writeJSON(w, http.StatusOK, response{
+		Status:  "expired",
+		Message: "Transaction expired",
+	})
+}
+
 // handleGetPending は自ノード宛の承認待ちトランザクションの一覧を返す
 func (s *Server) handleGetPending(w http.ResponseWriter, r *http.Request) {
 	pending := s.node.ListPending()
 	writeJSON(w, http.StatusOK, pending)
 }
 
+// handleGetPendingByID は指定 ID の承認待ちトランザクションを返す（承認待ちにない ID は 404）
+func (s *Server) handleGetPendingByID(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	pending := s.node.GetPending(id)
+	if pending == nil {
+		writeError(w, http.StatusNotFound, CodeNotFound, "pending transaction not found: "+id)
+		return
+	}
+	writeJSON(w, http.StatusOK, pending)
+}
+
 // handleGetProposed は自ノードが提案した承認待ちトランザクションの一覧を返す
 func (s *Server) handleGetProposed(w http.ResponseWriter, r *http.Request) {
 	proposed := s.node.ListProposed()
 	writeJSON(w, http.StatusOK, proposed)
 }
+
+// handleGetTransactionStatus は指定 ID のトランザクションの状態を返す（不明な ID は 404）
+// レスポンス: {"id": "...", "status": "approved", "block_hash": "..."}
+func (s *Server) handleGetTransactionStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := s.node.GetTransactionStatus(r.PathValue("id"))
+	if err != nil {
+		writeNodeError(w, err, http.StatusInternalServerError, "Failed to get transaction status: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handleGetExpired は期限切れ・拒否された承認待ちトランザクションの記録を新しい順に返す
+func (s *Server) handleGetExpired(w http.ResponseWriter, r *http.Request) {
+	expired := s.node.ListExpired()
+	writeJSON(w, http.StatusOK, expired)
+}
+
+// handleGetTransactions はチェーンに確定した取引の履歴をチェーン順に返す
+// クエリ: ?node=alice（省略時は全ての取引）
+func (s *Server) handleGetTransactions(w http.ResponseWriter, r *http.Request) {
+	records, err := s.node.GetTransactionsFor(r.URL.Query().Get("node"))
+	if err != nil {
+		writeNodeError(w, err, http.StatusInternalServerError, "Failed to get transactions: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, records)
+}
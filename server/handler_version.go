@@ -0,0 +1,11 @@
+package server
+
+import (
+	"net/http"
+	"signet/version"
+)
+
+// handleGetVersion は実行中のバイナリのバージョン情報（ビルド時に -ldflags で設定）を返す
+func (s *Server) handleGetVersion(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, version.Get())
+}
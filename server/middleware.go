@@ -1,8 +1,14 @@
 package server
 
 import (
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
 	"net/http"
+	"strings"
+	"time"
 )
 
 // writeJSON はJSONレスポンスを書き込む
@@ -12,10 +18,179 @@ func writeJSON(w http.ResponseWriter, status int, v any) {
 	json.NewEncoder(w).Encode(v)
 }
 
-// writeError はエラーレスポンスを書き込む
-func writeError(w http.ResponseWriter, status int, message string) {
-	type errResponse struct {
-		Error string `json:"error"`
+// ErrorCode はエラーレスポンスの code（クライアントが判定に使う機械判読用の値）を表す
+type ErrorCode string
+
+const (
+	CodeInvalidJSON          ErrorCode = "invalid_json"           // ボディを JSON として解釈できない
+	CodeValidationFailed     ErrorCode = "validation_failed"      // 入力値・ブロック・取引の検証に失敗した
+	CodeNotFound             ErrorCode = "not_found"              // 指定されたブロック・取引がない
+	CodeUnauthorized         ErrorCode = "unauthorized"           // API キー・管理者トークン・クライアント証明書がない・一致しない
+	CodeConflict             ErrorCode = "conflict"               // 重複した提案・登録済みノードやチェーンとの競合
+	CodeBodyTooLarge         ErrorCode = "body_too_large"         // リクエストのボディ・応答するブロック数が上限を超える
+	CodeUnsupportedMediaType ErrorCode = "unsupported_media_type" // Content-Type が application/json でない
+	CodeRateLimited          ErrorCode = "rate_limited"           // 受信レートの上限を超えた
+	CodeUnavailable          ErrorCode = "unavailable"            // 同時実行数の上限を超えた
+	CodeInsufficientStorage  ErrorCode = "insufficient_storage"   // ディスク容量不足で書き込めない
+	CodeInternal             ErrorCode = "internal_error"         // ノード内部のエラー
+)
+
+// writeError はエラーレスポンス（人が読む error と機械判読用の code）を書き込む
+func writeError(w http.ResponseWriter, status int, code ErrorCode, message string) {
+	writeJSON(w, status, errorResponse{Error: message, Code: code})
+}
+
+// writeNodeError は NodeService が返したエラーのレスポンスを書き込む
+// ステータスは errorStatus（該当しなければ fallback）、code はステータスから決める
+func writeNodeError(w http.ResponseWriter, err error, fallback int, message string) {
+	status := errorStatus(err, fallback)
+	writeError(w, status, statusErrorCode(status), message)
+}
+
+// statusErrorCode は errorStatus が返すステータスに対応する code を返す
+func statusErrorCode(status int) ErrorCode {
+	switch status {
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusInsufficientStorage:
+		return CodeInsufficientStorage
+	}
+	if status >= http.StatusInternalServerError {
+		return CodeInternal
+	}
+	return CodeValidationFailed
+}
+
+// writeDecodeError はリクエストボディの JSON デコードに失敗したエラーレスポンスを書き込む
+// ボディが limitBody の上限を超えた場合は 413、それ以外は 400
+func writeDecodeError(w http.ResponseWriter, err error) {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		writeError(w, http.StatusRequestEntityTooLarge, CodeBodyTooLarge, fmt.Sprintf("request body exceeds %d bytes", maxErr.Limit))
+		return
+	}
+	writeError(w, http.StatusBadRequest, CodeInvalidJSON, "Invalid JSON: "+err.Error())
+}
+
+// errorStatus は NodeService が返したエラーに対応する HTTP ステータスを返す
+// 特定のエラーに該当しなければ fallback を返す
+func errorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, ErrInsufficientStorage):
+		return http.StatusInsufficientStorage
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrDuplicateProposal), errors.Is(err, ErrNodeConflict), errors.Is(err, ErrBlockBehind):
+		return http.StatusConflict
 	}
-	writeJSON(w, status, errResponse{Error: message})
+	return fallback
+}
+
+// requireJSON は POST リクエストのボディが application/json であることを検証するミドルウェア
+// Content-Type 未指定やボディなしのリクエストは既存クライアント互換のため通す
+func requireJSON(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.ContentLength != 0 {
+			if ct := r.Header.Get("Content-Type"); ct != "" {
+				mediaType, _, err := mime.ParseMediaType(ct)
+				if err != nil || mediaType != "application/json" {
+					writeError(w, http.StatusUnsupportedMediaType, CodeUnsupportedMediaType, "Content-Type must be application/json")
+					return
+				}
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// limitBody は POST リクエストのボディを SetMaxBodyBytes の上限までしか読まないようにするミドルウェア
+// Content-Length が上限を超えていればボディを読まずに 413 を返す。チャンク転送などで上限を超えた場合は読み込み時にエラーになり、writeDecodeError が 413 を返す
+func (s *Server) limitBody(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && s.maxBodyBytes > 0 {
+			if r.ContentLength > s.maxBodyBytes {
+				writeError(w, http.StatusRequestEntityTooLarge, CodeBodyTooLarge, fmt.Sprintf("request body exceeds %d bytes", s.maxBodyBytes))
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, s.maxBodyBytes)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireClientCert は相互 TLS が有効な場合に、更新系（GET / HEAD 以外）のリクエストへ検証済みのクライアント証明書を要求するミドルウェア
+// 参照系はブラウザ（UI）からも使えるよう証明書なしで通す。Unix ドメインソケット経由のローカルからのリクエストは TLS を使わないため対象外
+func (s *Server) requireClientCert(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.tlsConfig != nil && s.tlsConfig.ClientCAs != nil && r.TLS != nil &&
+			r.Method != http.MethodGet && r.Method != http.MethodHead && len(r.TLS.VerifiedChains) == 0 {
+			writeError(w, http.StatusUnauthorized, CodeUnauthorized, "client certificate required")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// requireAPIKey は SetAPIKey で共有鍵が設定されている場合に、更新系（GET / HEAD 以外）のリクエストへ Authorization: Bearer <key> を要求するミドルウェア
+// 参照系は誰でも読めるよう鍵なしで通す。/admin/ 以下は AdminToken で別に保護しているため対象外
+func (s *Server) requireAPIKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.apiKey != "" && r.Method != http.MethodGet && r.Method != http.MethodHead && !strings.HasPrefix(r.URL.Path, "/admin/") {
+			key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(key), []byte(s.apiKey)) != 1 {
+				writeError(w, http.StatusUnauthorized, CodeUnauthorized, "invalid API key")
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// statusRecorder は WriteHeader に渡されたステータスコードを記録する ResponseWriter
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Unwrap は http.ResponseController が元の ResponseWriter を辿れるようにする
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// logRequests はリクエストごとにメソッド・パス・ステータスコード・処理時間を1行ログに出すミドルウェア
+// ロガーはリクエスト時に参照するため、SetLogger は NewServer の後に呼んでもよい（nil なら出力しない）
+func (s *Server) logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		s.mu.Lock()
+		logger := s.logger
+		s.mu.Unlock()
+		if logger == nil {
+			return
+		}
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		logger.Printf("%s %s %d %s", r.Method, r.URL.Path, status, time.Since(start))
+	})
 }
@@ -0,0 +1,276 @@
+package server
+
+import (
+	"net/http"
+	"reflect"
+	"signet/version"
+	"strings"
+)
+
+// statusResponse は status/message を返すハンドラーのレスポンス
+type statusResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// blockResponse は生成したブロックを返すハンドラーのレスポンス
+type blockResponse struct {
+	Status string `json:"status"`
+	Block  *Block `json:"block"`
+}
+
+// errorResponse はエラー時のレスポンス
+type errorResponse struct {
+	Error string    `json:"error"`
+	Code  ErrorCode `json:"code"`
+}
+
+// receiveBlockResponse は POST /block のレスポンス（拒否した場合のみ error と code を含む）
+type receiveBlockResponse struct {
+	Status ReceiveStatus `json:"status"`
+	Error  string        `json:"error,omitempty"`
+	Code   ErrorCode     `json:"code,omitempty"`
+}
+
+// proposeResponse は /transaction/propose のレスポンス
+type proposeResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	ID      string `json:"id"`
+}
+
+// proposeRequest は /transaction/propose のリクエスト
+type proposeRequest struct {
+	From          string `json:"from"`
+	To            string `json:"to"`
+	Amount        int64  `json:"amount"`
+	Title         string `json:"title"`
+	FromSignature string `json:"from_signature"`
+}
+
+// expiredRequest は /transaction/expired のリクエスト。ToSignature は To ノードによる期限切れ通知の署名
+type expiredRequest struct {
+	From          string `json:"from"`
+	To            string `json:"to"`
+	Amount        int64  `json:"amount"`
+	Title         string `json:"title"`
+	FromSignature string `json:"from_signature"`
+	ToSignature   string `json:"to_signature"`
+}
+
+// infoResponse は /info のレスポンス
+type infoResponse struct {
+	NodeName     string `json:"node_name"`
+	ChainLength  int    `json:"chain_length"`
+	PendingCount int    `json:"pending_count"`
+	Version      string `json:"version"`
+}
+
+// rollbackRequest は /admin/rollback のリクエスト（Index のブロックまで残す）
+type rollbackRequest struct {
+	Index *int `json:"index"`
+}
+
+// rollbackResponse は /admin/rollback のレスポンス
+type rollbackResponse struct {
+	Status      string `json:"status"`
+	Removed     int    `json:"removed"`
+	ChainLength int    `json:"chain_length"`
+}
+
+// idRequest は ID を指定するリクエスト
+type idRequest struct {
+	ID string `json:"id"`
+}
+
+// apiRoute は OpenAPI ドキュメントに載せるルート定義
+// Request/Response にはハンドラーが扱う型のゼロ値を入れ、スキーマはリフレクションで生成する
+type apiRoute struct {
+	Method   string
+	Path     string
+	Summary  string
+	Request  any
+	Response any
+}
+
+// apiRoutes は HTTP API のルート一覧（NewServer のルーティングと揃えること）
+var apiRoutes = []apiRoute{
+	{Method: "GET", Path: "/chain", Summary: "チェーン全体を返す（?from=&to= で from <= index < to の範囲のみ。Accept: application/x-ndjson なら NDJSON で逐次返し、JSON 配列が MaxChainBlocks を超える場合は413）", Response: []*Block{}},
+	{Method: "GET", Path: "/chain/verify", Summary: "チェーン全体（署名含む）を検証する", Response: ChainVerification{}},
+	{Method: "POST", Path: "/block", Summary: "ブロックを受信する（status は added / duplicate / sync_queued / rejected）", Request: Block{}, Response: receiveBlockResponse{}},
+	{Method: "GET", Path: "/block/{index}", Summary: "指定インデックスのブロックを返す（範囲外は404）", Response: Block{}},
+	{Method: "GET", Path: "/block/hash/{hash}", Summary: "指定ハッシュのブロックを返す（不正な形式は400、存在しなければ404）", Response: Block{}},
+	{Method: "POST", Path: "/transaction/propose", Summary: "トランザクションを提案する", Request: proposeRequest{}, Response: proposeResponse{}},
+	{Method: "POST", Path: "/transaction/approve", Summary: "トランザクションを承認する", Request: idRequest{}, Response: blockResponse{}},
+	{Method: "POST", Path: "/transaction/reject", Summary: "トランザクションを拒否する", Request: idRequest{}, Response: statusResponse{}},
+	{Method: "POST", Path: "/transaction/expired", Summary: "期限切れ通知を受け取る（To ノードの署名が必要）", Request: expiredRequest{}, Response: statusResponse{}},
+	{Method: "GET", Path: "/transaction/pending", Summary: "自ノード宛の承認待ちトランザクション一覧", Response: []*PendingTransaction{}},
+	{Method: "GET", Path: "/transaction/pending/{id}", Summary: "指定 ID の承認待ちトランザクション（承認待ちにない ID は404）", Response: PendingTransaction{}},
+	{Method: "GET", Path: "/transaction/proposed", Summary: "自ノードが提案した承認待ちトランザクション一覧", Response: []*PendingTransaction{}},
+	{Method: "GET", Path: "/transaction/status/{id}", Summary: "トランザクションの状態（pending / approved / expired / rejected、不明な ID は404）", Response: TransactionStatus{}},
+	{Method: "GET", Path: "/transaction/expired", Summary: "期限切れ・拒否された承認待ちトランザクションの記録（新しい順）", Response: []*ArchivedTransaction{}},
+	{Method: "GET", Path: "/transactions", Summary: "チェーンに確定した取引の履歴（チェーン順。?node= で From / To が一致するものに絞り、direction に sent / received を付ける）", Response: []*TransactionRecord{}},
+	{Method: "POST", Path: "/register", Summary: "ノードを登録する", Request: struct {
+		NodeName  string `json:"node_name"`
+		NickName  string `json:"nick_name"`
+		Address   string `json:"address"`
+		PublicKey string `json:"public_key"`
+	}{}, Response: blockResponse{}},
+	{Method: "POST", Path: "/node/nickname", Summary: "自ノードのニックネームを変更する", Request: struct {
+		NickName string `json:"nick_name"`
+	}{}, Response: blockResponse{}},
+	{Method: "GET", Path: "/peers", Summary: "ピアノードの一覧", Response: map[string]*NodeInfo{}},
+	{Method: "GET", Path: "/peers/health", Summary: "ピアの到達性（各ピアの GET /info に問い合わせる）", Response: map[string]*PeerHealth{}},
+	{Method: "GET", Path: "/info", Summary: "自ノードの情報（ノード名・チェーン長・承認待ち件数・バージョン）", Response: infoResponse{}},
+	{Method: "GET", Path: "/version", Summary: "実行中のバイナリのバージョン・Go のバージョン・ビルド日時", Response: version.Info{}},
+	{Method: "POST", Path: "/admin/rollback", Summary: "チェーンを指定インデックスのブロックまで巻き戻す（AdminToken を Authorization: Bearer で指定。未設定なら404）", Request: rollbackRequest{}, Response: rollbackResponse{}},
+}
+
+// openAPISchema は OpenAPI の Schema Object（必要な項目のみ）
+type openAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty"`
+	Format               string                    `json:"format,omitempty"`
+	Properties           map[string]*openAPISchema `json:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty"`
+	Items                *openAPISchema            `json:"items,omitempty"`
+	AdditionalProperties *openAPISchema            `json:"additionalProperties,omitempty"`
+}
+
+type openAPIMediaType struct {
+	Schema *openAPISchema `json:"schema"`
+}
+
+type openAPIBody struct {
+	Description string                      `json:"description,omitempty"`
+	Content     map[string]openAPIMediaType `json:"content"`
+}
+
+type openAPIOperation struct {
+	Summary     string                 `json:"summary"`
+	RequestBody *openAPIBody           `json:"requestBody,omitempty"`
+	Responses   map[string]openAPIBody `json:"responses"`
+}
+
+// OpenAPIDocument は OpenAPI 3 ドキュメントを表す
+type OpenAPIDocument struct {
+	OpenAPI string `json:"openapi"`
+	Info    struct {
+		Title   string `json:"title"`
+		Version string `json:"version"`
+	} `json:"info"`
+	Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+	Components struct {
+		Schemas map[string]*openAPISchema `json:"schemas"`
+	} `json:"components"`
+}
+
+// BuildOpenAPIDocument は apiRoutes から OpenAPI ドキュメントを生成する
+func BuildOpenAPIDocument() *OpenAPIDocument {
+	doc := &OpenAPIDocument{
+		OpenAPI: "3.0.3",
+		Paths:   make(map[string]map[string]*openAPIOperation),
+	}
+	doc.Info.Title = "Signet Node API"
+	doc.Info.Version = "1.0"
+	doc.Components.Schemas = make(map[string]*openAPISchema)
+
+	jsonBody := func(description string, v any) openAPIBody {
+		return openAPIBody{
+			Description: description,
+			Content: map[string]openAPIMediaType{
+				"application/json": {Schema: schemaFor(reflect.TypeOf(v), doc.Components.Schemas)},
+			},
+		}
+	}
+
+	for _, route := range apiRoutes {
+		op := &openAPIOperation{
+			Summary: route.Summary,
+			Responses: map[string]openAPIBody{
+				"200": jsonBody("OK", route.Response),
+			},
+		}
+		if route.Request != nil {
+			body := jsonBody("", route.Request)
+			op.RequestBody = &body
+		}
+		if route.Method == "POST" {
+			op.Responses["400"] = jsonBody("Bad Request", errorResponse{})
+		}
+
+		if doc.Paths[route.Path] == nil {
+			doc.Paths[route.Path] = make(map[string]*openAPIOperation)
+		}
+		doc.Paths[route.Path][strings.ToLower(route.Method)] = op
+	}
+
+	return doc
+}
+
+// schemaFor は Go の型から JSON Schema を生成する
+// server パッケージの名前付き構造体は components に登録して $ref で参照する
+func schemaFor(t reflect.Type, components map[string]*openAPISchema) *openAPISchema {
+	switch t.Kind() {
+	case reflect.Pointer:
+		return schemaFor(t.Elem(), components)
+	case reflect.String:
+		return &openAPISchema{Type: "string"}
+	case reflect.Bool:
+		return &openAPISchema{Type: "boolean"}
+	case reflect.Int, reflect.Int32:
+		return &openAPISchema{Type: "integer", Format: "int32"}
+	case reflect.Int64:
+		return &openAPISchema{Type: "integer", Format: "int64"}
+	case reflect.Slice, reflect.Array:
+		return &openAPISchema{Type: "array", Items: schemaFor(t.Elem(), components)}
+	case reflect.Map:
+		return &openAPISchema{Type: "object", AdditionalProperties: schemaFor(t.Elem(), components)}
+	case reflect.Struct:
+		if t.Name() != "" && isExportedType(t) {
+			if _, ok := components[t.Name()]; !ok {
+				components[t.Name()] = structSchema(t, components)
+			}
+			return &openAPISchema{Ref: "#/components/schemas/" + t.Name()}
+		}
+		return structSchema(t, components)
+	default:
+		return &openAPISchema{}
+	}
+}
+
+// structSchema は構造体のフィールドを json タグに従ってスキーマ化する
+// omitempty のないフィールドは required とする
+func structSchema(t reflect.Type, components map[string]*openAPISchema) *openAPISchema {
+	schema := &openAPISchema{Type: "object", Properties: make(map[string]*openAPISchema)}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" || !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		schema.Properties[name] = schemaFor(f.Type, components)
+		if !strings.Contains(opts, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	return schema
+}
+
+// isExportedType は server パッケージの公開型かを返す
+func isExportedType(t reflect.Type) bool {
+	return t.PkgPath() == reflect.TypeOf(Block{}).PkgPath() && t.Name()[0] >= 'A' && t.Name()[0] <= 'Z'
+}
+
+// handleOpenAPI は HTTP API の OpenAPI ドキュメントを返す
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if !s.openAPI {
+		http.NotFound(w, r)
+		return
+	}
+	writeJSON(w, http.StatusOK, BuildOpenAPIDocument())
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimiter はキー（送信元アドレス）ごとにリクエストを受け付けてよいかを判定する
+type RateLimiter interface {
+	Allow(key string) bool
+}
+
+// maxIdleBuckets を超えてバケットを保持している場合、満杯に戻ったバケットを削除する
+const maxIdleBuckets = 1024
+
+// TokenBucketLimiter はキーごとのトークンバケットで毎秒 rate 件（最大 burst 件の連続）までリクエストを許可する
+type TokenBucketLimiter struct {
+	mu      sync.Mutex
+	rate    float64
+	burst   float64
+	buckets map[string]*tokenBucket
+	now     func() time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucketLimiter は毎秒 rate 件、最大 burst 件まで連続して許可する TokenBucketLimiter を作成する
+// burst が rate より小さい場合は rate（最低 1）に切り上げる
+func NewTokenBucketLimiter(rate float64, burst int) *TokenBucketLimiter {
+	b := float64(burst)
+	if b < rate {
+		b = rate
+	}
+	if b < 1 {
+		b = 1
+	}
+	return &TokenBucketLimiter{
+		rate:    rate,
+		burst:   b,
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// Allow は key のバケットからトークンを1つ消費できれば true を返す
+func (l *TokenBucketLimiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if len(l.buckets) > maxIdleBuckets {
+		l.prune(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+	l.refill(b, now)
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// refill は前回からの経過時間分のトークンを補充する（呼び出し側でロックを保持すること）
+func (l *TokenBucketLimiter) refill(b *tokenBucket, now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens += elapsed * l.rate
+		if b.tokens > l.burst {
+			b.tokens = l.burst
+		}
+		b.last = now
+	}
+}
+
+// prune は満杯に戻ったバケット（しばらくリクエストのない送信元）を削除する（呼び出し側でロックを保持すること）
+func (l *TokenBucketLimiter) prune(now time.Time) {
+	for key, b := range l.buckets {
+		l.refill(b, now)
+		if b.tokens >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// remoteHost は r.RemoteAddr からポートを除いた送信元アドレスを返す
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// rateLimited は SetBlockRateLimiter で設定したレートリミッターを送信元アドレスごとに適用するハンドラーを返す
+// 上限を超えたリクエストには 429 を返す
+func (s *Server) rateLimited(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		limiter := s.blockLimiter
+		s.mu.Unlock()
+
+		if limiter != nil && !limiter.Allow(remoteHost(r)) {
+			w.Header().Set("Retry-After", "1")
+			writeError(w, http.StatusTooManyRequests, CodeRateLimited, "too many requests")
+			return
+		}
+		next(w, r)
+	}
+}
@@ -2,10 +2,14 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io/fs"
+	"log"
 	"net"
 	"net/http"
+	"os"
 	"strings"
 	"sync"
 	"time"
@@ -13,34 +17,85 @@ import (
 	"signet/ui"
 )
 
+// originHeader は送信元ノード名を伝えるリクエストヘッダー（p2p.OriginHeader と同じ値）
+const originHeader = "X-Signet-Origin"
+
+// ErrInsufficientStorage はディスク容量不足で書き込めないことを表す
+// NodeService の実装がこのエラーを（%w で）返すと、更新系エンドポイントは 507 を返す
+var ErrInsufficientStorage = errors.New("insufficient storage")
+
+// ErrNotFound は要求されたリソース（ブロックなど）が存在しないことを表す（404 に対応）
+var ErrNotFound = errors.New("not found")
+
+// ErrDuplicateProposal は同じ内容（From/To/Amount/Title）の提案が既に承認待ちであることを表す（409 に対応）
+var ErrDuplicateProposal = errors.New("duplicate proposal")
+
+// ErrBlockBehind は受信したブロックがローカルのチェーンの末尾以前の位置にあり、かつ持っているブロックと異なる（フォーク）ことを表す（409 に対応）
+// 既に持っているブロック（ReceiveDuplicate）や先のブロック（ReceiveSyncQueued）はエラーにしない
+var ErrBlockBehind = errors.New("block conflicts with the local chain")
+
+// ErrNodeConflict は登録しようとしたノード名が別の公開鍵で既に登録されていることを表す（409 に対応）
+var ErrNodeConflict = errors.New("node already registered with a different public key")
+
+// ReceiveStatus は POST /block で受信したブロックをどう扱ったかを表す
+type ReceiveStatus string
+
+const (
+	ReceiveAdded      ReceiveStatus = "added"       // チェーンに追加した
+	ReceiveDuplicate  ReceiveStatus = "duplicate"   // 既に持っているブロックなので無視した
+	ReceiveSyncQueued ReceiveStatus = "sync_queued" // チェーンより先のブロックなので同期を予約した
+	ReceiveRejected   ReceiveStatus = "rejected"    // 検証に失敗した・チェーンと競合するので拒否した
+)
+
 // NodeService はノードサービスのインターフェース
 // nodeパッケージのNode構造体に依存するためにインターフェースを定義
 type NodeService interface {
 	// Chain operations
-	GetChain() []*Block
+	GetChain() ([]*Block, error)
 	GetChainLen() int
+	GetChainRange(from, to int) ([]*Block, error)
+	GetBlockByIndex(index int) (*Block, error)
+	GetBlockByHash(hash string) (*Block, error)
 	ReceiveBlock(b *Block) error
+	ReceiveBlockFrom(b *Block, origin string) (ReceiveStatus, error)
+	VerifyChain() *ChainVerification
+	ValidateChainStructure() error
+
+	// Admin operations（index のブロックまで巻き戻し、取り除いたブロック数を返す）
+	RollbackChain(index int) (int, error)
 
 	// Transaction operations
 	ProposeTransaction(data *TransactionData, fromSignature string) error
+	ProposeTransactionDetailed(data *TransactionData, fromSignature string) (*PendingTransaction, error)
 	ApproveTransaction(id string) (*Block, error)
 	ListPending() []*PendingTransaction
 	ListProposed() []*PendingTransaction
 	GetPending(id string) *PendingTransaction
+	ListExpired() []*ArchivedTransaction
+	GetTransactionStatus(id string) (*TransactionStatus, error)
+	// チェーン上の取引履歴（node が空なら全件）
+	GetTransactionsFor(node string) ([]*TransactionRecord, error)
 
 	// Transaction rejection
 	RejectTransaction(id string) error
 
+	// Transaction expiry (To ノードからの期限切れ通知。toSignature は To ノードによる通知の署名)
+	NotifyExpired(data *TransactionData, fromSignature, toSignature string) error
+
 	// Registration
 	RegisterNode(nodeName, nickName, address, publicKey string) (*Block, error)
+	UpdateNickname(nickName string) (*Block, error)
 
 	// Peer operations
 	GetPeers() map[string]*NodeInfo
+	// 各ピアに問い合わせて到達性を返す（自ノードは含まない）
+	CheckPeerHealth(ctx context.Context) map[string]*PeerHealth
 
 	// Node info
 	GetNodeName() string
+	GetMetrics() *Metrics
 
-	// Broadcast
+	// Broadcast（自ノードで生成したブロックはハンドラーが呼ぶ。同じブロックの2回目以降は何もしない）
 	BroadcastBlock(b *Block)
 }
 
@@ -51,10 +106,13 @@ type Block struct {
 }
 
 // BlockHeader はブロックのヘッダーを表す
+// Version と TxRoot はバージョン2以降のブロックのみが持つ
 type BlockHeader struct {
+	Version   int    `json:"version,omitempty"`
 	Index     int    `json:"index"`
 	CreatedAt int64  `json:"created_at"`
 	PrevHash  string `json:"prev_hash"`
+	TxRoot    string `json:"tx_root,omitempty"`
 	Hash      string `json:"hash"`
 }
 
@@ -90,6 +148,50 @@ type PendingTransaction struct {
 	ID          string           `json:"id"`
 }
 
+// ArchivedTransaction は期限切れ・拒否によりチェーンに入らずに取り除かれた承認待ちトランザクションの記録を表す
+// Reason は "expired" または "rejected"、ArchivedAt は取り除いた時刻（Unix 秒）
+type ArchivedTransaction struct {
+	PendingTransaction
+	Reason     string `json:"reason"`
+	ArchivedAt int64  `json:"archived_at"`
+}
+
+// TransactionStatus は承認待ちトランザクション（ID は受け付けたノードのもの）の現在の状態を表す
+// Status は "pending" / "approved" / "expired" / "rejected"。approved の場合は BlockHash にブロックのハッシュが入る
+type TransactionStatus struct {
+	ID        string `json:"id"`
+	Status    string `json:"status"`
+	BlockHash string `json:"block_hash,omitempty"`
+}
+
+// TransactionRecord はチェーンに確定した取引1件を表す
+// Index と CreatedAt は取引を含むブロックのもの。Direction は問い合わせたノードから見た向き（"sent" / "received"）で、ノード指定なしの場合は空
+type TransactionRecord struct {
+	Index       int              `json:"index"`
+	CreatedAt   int64            `json:"created_at"`
+	Transaction *TransactionData `json:"transaction"`
+	Direction   string           `json:"direction,omitempty"`
+}
+
+// ChainVerification はチェーン検証の結果を表す
+// 失敗時は最初に不正と判定されたブロックのインデックス、失敗した検査の種類（genesis / hash / payload / link / index / timestamp / signature）と理由を含む
+type ChainVerification struct {
+	Valid  bool   `json:"valid"`
+	Index  *int   `json:"index,omitempty"`
+	Kind   string `json:"kind,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Metrics は GET /metrics で公開する監視用の統計値を表す
+// BlocksReceived と BroadcastsSent は起動してからの累計
+type Metrics struct {
+	ChainLength    int
+	PendingCount   int
+	Peers          int
+	BlocksReceived uint64
+	BroadcastsSent uint64
+}
+
 // NodeInfo はピアノードの情報を表す
 type NodeInfo struct {
 	Name      string `json:"name"`
@@ -98,34 +200,96 @@ type NodeInfo struct {
 	PublicKey string `json:"public_key"`
 }
 
+// PeerHealth は GET /peers/health で返すピアの到達性を表す
+// LastSeen は最後に応答があった時刻（Unix 秒、起動してから一度も応答がなければ 0）、Failures は連続して到達できなかった回数
+type PeerHealth struct {
+	Reachable bool  `json:"reachable"`
+	LastSeen  int64 `json:"last_seen"`
+	Failures  int   `json:"failures"`
+}
+
 // Server はHTTPサーバーを表す
 type Server struct {
 	node       NodeService
 	httpServer *http.Server
 	addr       string
 	mu         sync.Mutex
+
+	// openAPI が true の場合のみ GET /openapi.json を返す
+	openAPI bool
+
+	// socketPath が空でなければ Unix ドメインソケットでも待ち受ける。socketOnly なら TCP では待ち受けない
+	socketPath string
+	socketOnly bool
+
+	// validateChain が true の場合は GET /chain の応答前にチェーンの構造を検証する
+	validateChain bool
+
+	// adminToken が空でなければ /admin/ 以下の管理用エンドポイントを有効にし、Bearer トークンとして要求する
+	adminToken string
+
+	// apiKey が空でなければ /admin/ 以下を除く更新系エンドポイントに Bearer トークンとして要求する
+	apiKey string
+
+	// tlsConfig が nil でなければ TCP の待ち受けを TLS にする。ClientCAs があれば更新系エンドポイントにクライアント証明書を要求する
+	tlsConfig *tls.Config
+
+	// maxChainBlocks は GET /chain が JSON 配列で返すブロック数の上限（0 以下で無制限）
+	maxChainBlocks int
+
+	// limits はルートパターンごとの同時実行数セマフォ（SetConcurrencyLimit で設定）
+	limits map[string]chan struct{}
+
+	// logger はリクエストログの出力先（nil なら出力しない）
+	logger *log.Logger
+
+	// blockLimiter は POST /block に送信元アドレスごとに適用するレートリミッター（nil なら制限しない）
+	blockLimiter RateLimiter
+
+	// maxBodyBytes は POST リクエストのボディの上限（バイト、0 以下で無制限）
+	maxBodyBytes int64
 }
 
+// DefaultMaxBodyBytes は POST リクエストのボディの上限のデフォルト値
+const DefaultMaxBodyBytes = 1 << 20
+
 // NewServer は新しいサーバーを作成する
 func NewServer(addr string, node NodeService) *Server {
 	s := &Server{
-		addr: addr,
-		node: node,
+		addr:         addr,
+		node:         node,
+		limits:       make(map[string]chan struct{}),
+		logger:       log.Default(),
+		maxBodyBytes: DefaultMaxBodyBytes,
 	}
 
 	mux := http.NewServeMux()
 
 	// Go 1.22+ のパターン構文を使用
-	mux.HandleFunc("GET /chain", s.handleGetChain)
-	mux.HandleFunc("POST /block", s.handleReceiveBlock)
+	mux.HandleFunc("GET /chain", s.limited("GET /chain", s.handleGetChain))
+	mux.HandleFunc("GET /chain/verify", s.limited("GET /chain/verify", s.handleVerifyChain))
+	mux.HandleFunc("POST /block", s.rateLimited(s.handleReceiveBlock))
+	mux.HandleFunc("GET /block/{index}", s.handleGetBlock)
+	mux.HandleFunc("GET /block/hash/{hash}", s.handleGetBlockByHash)
 	mux.HandleFunc("POST /transaction/propose", s.handlePropose)
 	mux.HandleFunc("POST /transaction/approve", s.handleApprove)
 	mux.HandleFunc("POST /transaction/reject", s.handleReject)
+	mux.HandleFunc("POST /transaction/expired", s.handleExpired)
 	mux.HandleFunc("GET /transaction/pending", s.handleGetPending)
+	mux.HandleFunc("GET /transaction/pending/{id}", s.handleGetPendingByID)
 	mux.HandleFunc("GET /transaction/proposed", s.handleGetProposed)
+	mux.HandleFunc("GET /transaction/expired", s.handleGetExpired)
+	mux.HandleFunc("GET /transaction/status/{id}", s.handleGetTransactionStatus)
+	mux.HandleFunc("GET /transactions", s.handleGetTransactions)
 	mux.HandleFunc("POST /register", s.handleRegister)
+	mux.HandleFunc("POST /node/nickname", s.handleUpdateNickname)
 	mux.HandleFunc("GET /peers", s.handleGetPeers)
+	mux.HandleFunc("GET /peers/health", s.handleGetPeerHealth)
 	mux.HandleFunc("GET /info", s.handleGetInfo)
+	mux.HandleFunc("GET /version", s.handleGetVersion)
+	mux.HandleFunc("GET /metrics", s.handleGetMetrics)
+	mux.HandleFunc("GET /openapi.json", s.handleOpenAPI)
+	mux.HandleFunc("POST /admin/rollback", s.requireAdmin(s.handleAdminRollback))
 
 	// UI 静的ファイル配信 + SPA フォールバック
 	distFS, _ := fs.Sub(ui.DistFS, "dist")
@@ -147,7 +311,7 @@ func NewServer(addr string, node NodeService) *Server {
 
 	s.httpServer = &http.Server{
 		Addr:         addr,
-		Handler:      mux,
+		Handler:      s.logRequests(s.limitBody(requireJSON(s.requireClientCert(s.requireAPIKey(mux))))),
 		ReadTimeout:  10 * time.Second,
 		WriteTimeout: 10 * time.Second,
 		IdleTimeout:  60 * time.Second,
@@ -156,17 +320,175 @@ func NewServer(addr string, node NodeService) *Server {
 	return s
 }
 
+// Handler はサーバーのHTTPハンドラーを返す（テストで httptest に載せる用途）
+func (s *Server) Handler() http.Handler {
+	return s.httpServer.Handler
+}
+
+// SetOpenAPI は GET /openapi.json の配信を有効・無効にする
+func (s *Server) SetOpenAPI(enabled bool) {
+	s.openAPI = enabled
+}
+
+// SetUnixSocket は path の Unix ドメインソケットで待ち受けるよう設定する（空文字列で無効）
+// only が true なら TCP では待ち受けない。Start 前に呼ぶこと
+func (s *Server) SetUnixSocket(path string, only bool) {
+	s.socketPath = path
+	s.socketOnly = only && path != ""
+}
+
+// SetValidateChain は GET /chain の応答前にチェーンの構造（ハッシュ・連結・インデックス）を検証するかを設定する
+// 破損したチェーンを同期中のピアに配信しないためのもので、署名は検証しない
+func (s *Server) SetValidateChain(enabled bool) {
+	s.validateChain = enabled
+}
+
+// SetAdminToken は管理用エンドポイント（POST /admin/rollback）の認証トークンを設定する（空文字列で無効）
+func (s *Server) SetAdminToken(token string) {
+	s.adminToken = token
+}
+
+// SetAPIKey は更新系エンドポイント（/admin/ 以下を除く GET / HEAD 以外）が要求する共有鍵を設定する（空文字列で無効）
+func (s *Server) SetAPIKey(key string) {
+	s.apiKey = key
+}
+
+// SetTLS は TCP の待ち受けを cfg の TLS にする（nil で平文）。Unix ドメインソケットは平文のまま。Start 前に呼ぶこと
+// cfg.ClientCAs が設定されていれば、更新系エンドポイントはその CA で検証できるクライアント証明書を提示したリクエストのみ受け付ける
+func (s *Server) SetTLS(cfg *tls.Config) {
+	s.tlsConfig = cfg
+}
+
+// SetMaxChainBlocks は GET /chain が JSON 配列で一度に返すブロック数の上限を設定する（0 以下で無制限）
+// 上限を超える範囲は 413 でページ分割を求める。NDJSON のストリーミング応答には適用しない
+func (s *Server) SetMaxChainBlocks(limit int) {
+	s.maxChainBlocks = limit
+}
+
+// SetMaxBodyBytes は POST リクエストのボディの上限（バイト）を設定する（0 以下で無制限。デフォルトは DefaultMaxBodyBytes）
+// 上限を超えるボディは 413 で拒否する。Start 前に呼ぶこと
+func (s *Server) SetMaxBodyBytes(limit int64) {
+	s.maxBodyBytes = limit
+}
+
+// SetLogger はリクエストログの出力先を設定する（nil で出力しない。デフォルトは log.Default()）
+func (s *Server) SetLogger(logger *log.Logger) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.logger = logger
+}
+
+// SetBlockRateLimiter は POST /block に送信元アドレスごとに適用するレートリミッターを設定する（nil で無制限）
+// 上限を超えたリクエストには 429 を返す
+func (s *Server) SetBlockRateLimiter(limiter RateLimiter) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.blockLimiter = limiter
+}
+
+// SetConcurrencyLimit は pattern のルートの同時実行数の上限を設定する（0 以下で無制限）
+// 上限に達している間のリクエストには 503 を返す。Start 前に呼ぶこと
+func (s *Server) SetConcurrencyLimit(pattern string, limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if limit <= 0 {
+		delete(s.limits, pattern)
+		return
+	}
+	s.limits[pattern] = make(chan struct{}, limit)
+}
+
+// limited は pattern に設定された同時実行数の上限を適用するハンドラーを返す
+func (s *Server) limited(pattern string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		sem := s.limits[pattern]
+		s.mu.Unlock()
+
+		if sem != nil {
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			default:
+				writeError(w, http.StatusServiceUnavailable, CodeUnavailable, "too many concurrent requests")
+				return
+			}
+		}
+		next(w, r)
+	}
+}
+
 // Start はサーバーを起動する
+// SetUnixSocket が設定されていれば Unix ドメインソケットでも待ち受け、いずれかの待ち受けが終了するまでブロックする
 func (s *Server) Start() error {
-	ln, err := net.Listen("tcp", s.httpServer.Addr)
+	var listeners []net.Listener
+	if s.socketPath != "" {
+		if err := removeStaleSocket(s.socketPath); err != nil {
+			return err
+		}
+		ln, err := net.Listen("unix", s.socketPath)
+		if err != nil {
+			return err
+		}
+		listeners = append(listeners, ln)
+	}
+	if !s.socketOnly {
+		ln, err := net.Listen("tcp", s.httpServer.Addr)
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return err
+		}
+		if s.tlsConfig != nil {
+			ln = tls.NewListener(ln, s.tlsConfig)
+		}
+		listeners = append(listeners, ln)
+	}
+
+	errCh := make(chan error, len(listeners))
+	for _, ln := range listeners {
+		fmt.Printf("Server starting on %s\n", ln.Addr().String())
+		go func(ln net.Listener) {
+			errCh <- s.httpServer.Serve(ln)
+		}(ln)
+	}
+	return <-errCh
+}
+
+// removeStaleSocket は前回の異常終了で残ったソケットファイルを削除する（ソケット以外のファイルは削除しない）
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
 	if err != nil {
-		return err
+		return fmt.Errorf("failed to stat socket path: %w", err)
 	}
-	fmt.Printf("Server starting on %s\n", ln.Addr().String())
-	return s.httpServer.Serve(ln)
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("socket path exists and is not a socket: %s", path)
+	}
+	// 接続できるなら他のプロセスが使用中
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return fmt.Errorf("socket is already in use: %s", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+	return nil
 }
 
-// Stop はサーバーを停止する
+// Stop はサーバーを停止する。Unix ドメインソケットのファイルも削除する
 func (s *Server) Stop(ctx context.Context) error {
-	return s.httpServer.Shutdown(ctx)
+	err := s.httpServer.Shutdown(ctx)
+	if s.socketPath != "" {
+		if rmErr := os.Remove(s.socketPath); rmErr != nil && !os.IsNotExist(rmErr) && err == nil {
+			err = fmt.Errorf("failed to remove socket: %w", rmErr)
+		}
+	}
+	return err
 }
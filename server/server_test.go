@@ -3,18 +3,40 @@ package server
 import (
 	"bytes"
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"signet/version"
+	"strings"
 	"testing"
 	"time"
 )
 
+var _ NodeService = (*mockNodeService)(nil)
+
 // mockNodeService はテスト用のモック実装
 type mockNodeService struct {
 	chain       []*Block
 	pending     []*PendingTransaction
+	expired     []*ArchivedTransaction
+	statuses    map[string]*TransactionStatus
 	peers       map[string]*NodeInfo
+	peerHealth  map[string]*PeerHealth
 	nodeName    string
 	proposeErr  error
 	approveErr  error
@@ -26,18 +48,88 @@ type mockNodeService struct {
 	rejectCalled   bool
 	registerCalled bool
 	receiveCalled  bool
+	receiveOrigin  string
 	rejectErr      error
 	broadcastBlock *Block
+
+	receiveStatus ReceiveStatus
+
+	expiredCalled bool
+	expiredErr    error
+
+	verification *ChainVerification
+	// verifyEntered/verifyRelease が設定されていれば VerifyChain は release されるまでブロックする
+	verifyEntered chan struct{}
+	verifyRelease chan struct{}
+
+	nicknameCalled bool
+
+	structureErr error
+	// chainErr が設定されていればチェーンを読む GetTransactionsFor はこのエラーを返す（ブロックの読み込み失敗の再現）
+	chainErr error
+
+	// rangeDelay が設定されていれば GetChainRange はその時間待ってから返す（遅いストレージの再現）
+	rangeDelay time.Duration
 }
 
-func (m *mockNodeService) GetChain() []*Block {
-	return m.chain
+func (m *mockNodeService) GetChain() ([]*Block, error) {
+	return m.chain, nil
 }
 
 func (m *mockNodeService) GetChainLen() int {
 	return len(m.chain)
 }
 
+func (m *mockNodeService) GetChainRange(from, to int) ([]*Block, error) {
+	time.Sleep(m.rangeDelay)
+	if from > to {
+		return nil, fmt.Errorf("invalid range: from %d is greater than to %d", from, to)
+	}
+	from = max(0, min(from, len(m.chain)))
+	to = max(0, min(to, len(m.chain)))
+	return m.chain[from:to], nil
+}
+
+func (m *mockNodeService) RollbackChain(index int) (int, error) {
+	if index < 0 || index >= len(m.chain) {
+		return 0, fmt.Errorf("invalid rollback index %d", index)
+	}
+	removed := len(m.chain) - index - 1
+	m.chain = m.chain[:index+1]
+	return removed, nil
+}
+
+func (m *mockNodeService) ValidateChainStructure() error {
+	return m.structureErr
+}
+
+func (m *mockNodeService) GetBlockByHash(hash string) (*Block, error) {
+	for _, b := range m.chain {
+		if b.Header.Hash == hash {
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("block not found: %s: %w", hash, ErrNotFound)
+}
+
+func (m *mockNodeService) GetBlockByIndex(index int) (*Block, error) {
+	if index < 0 || index >= len(m.chain) {
+		return nil, fmt.Errorf("index out of range: %d: %w", index, ErrNotFound)
+	}
+	return m.chain[index], nil
+}
+
+func (m *mockNodeService) ReceiveBlockFrom(b *Block, origin string) (ReceiveStatus, error) {
+	m.receiveOrigin = origin
+	if err := m.ReceiveBlock(b); err != nil {
+		return ReceiveRejected, err
+	}
+	if m.receiveStatus != "" {
+		return m.receiveStatus, nil
+	}
+	return ReceiveAdded, nil
+}
+
 func (m *mockNodeService) ReceiveBlock(b *Block) error {
 	m.receiveCalled = true
 	if m.receiveErr != nil {
@@ -47,9 +139,28 @@ func (m *mockNodeService) ReceiveBlock(b *Block) error {
 	return nil
 }
 
+func (m *mockNodeService) VerifyChain() *ChainVerification {
+	if m.verifyRelease != nil {
+		m.verifyEntered <- struct{}{}
+		<-m.verifyRelease
+	}
+	if m.verification == nil {
+		return &ChainVerification{Valid: true}
+	}
+	return m.verification
+}
+
 func (m *mockNodeService) ProposeTransaction(data *TransactionData, fromSignature string) error {
+	_, err := m.ProposeTransactionDetailed(data, fromSignature)
+	return err
+}
+
+func (m *mockNodeService) ProposeTransactionDetailed(data *TransactionData, fromSignature string) (*PendingTransaction, error) {
 	m.proposeCalled = true
-	return m.proposeErr
+	if m.proposeErr != nil {
+		return nil, m.proposeErr
+	}
+	return &PendingTransaction{Transaction: data, FromSig: fromSignature, ID: "tx-proposed"}, nil
 }
 
 func (m *mockNodeService) ApproveTransaction(id string) (*Block, error) {
@@ -85,6 +196,38 @@ func (m *mockNodeService) ListProposed() []*PendingTransaction {
 	return m.pending
 }
 
+func (m *mockNodeService) ListExpired() []*ArchivedTransaction {
+	return m.expired
+}
+
+func (m *mockNodeService) GetTransactionStatus(id string) (*TransactionStatus, error) {
+	if st, ok := m.statuses[id]; ok {
+		return st, nil
+	}
+	return nil, fmt.Errorf("transaction not found: %s: %w", id, ErrNotFound)
+}
+
+func (m *mockNodeService) GetTransactionsFor(node string) ([]*TransactionRecord, error) {
+	if m.chainErr != nil {
+		return nil, m.chainErr
+	}
+	records := []*TransactionRecord{}
+	for _, b := range m.chain {
+		tx := b.Payload.Transaction
+		if tx == nil || (node != "" && tx.From != node && tx.To != node) {
+			continue
+		}
+		record := &TransactionRecord{Index: b.Header.Index, CreatedAt: b.Header.CreatedAt, Transaction: tx}
+		if node == tx.From {
+			record.Direction = "sent"
+		} else if node != "" {
+			record.Direction = "received"
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
 func (m *mockNodeService) GetPending(id string) *PendingTransaction {
 	for _, p := range m.pending {
 		if p.ID == id {
@@ -99,6 +242,11 @@ func (m *mockNodeService) RejectTransaction(id string) error {
 	return m.rejectErr
 }
 
+func (m *mockNodeService) NotifyExpired(data *TransactionData, fromSignature, toSignature string) error {
+	m.expiredCalled = true
+	return m.expiredErr
+}
+
 func (m *mockNodeService) RegisterNode(nodeName, nickName, address, publicKey string) (*Block, error) {
 	m.registerCalled = true
 	if m.registerErr != nil {
@@ -124,14 +272,39 @@ func (m *mockNodeService) RegisterNode(nodeName, nickName, address, publicKey st
 	return block, nil
 }
 
+func (m *mockNodeService) UpdateNickname(nickName string) (*Block, error) {
+	m.nicknameCalled = true
+	return &Block{
+		Header: BlockHeader{Index: 1, Hash: "nickname-block-hash"},
+		Payload: BlockPayload{
+			Type:    "add_node",
+			AddNode: &AddNodeData{NodeName: m.nodeName, NickName: nickName},
+		},
+	}, nil
+}
+
 func (m *mockNodeService) GetPeers() map[string]*NodeInfo {
 	return m.peers
 }
 
+func (m *mockNodeService) CheckPeerHealth(ctx context.Context) map[string]*PeerHealth {
+	return m.peerHealth
+}
+
 func (m *mockNodeService) GetNodeName() string {
 	return m.nodeName
 }
 
+func (m *mockNodeService) GetMetrics() *Metrics {
+	return &Metrics{
+		ChainLength:    len(m.chain),
+		PendingCount:   len(m.pending),
+		Peers:          len(m.peers),
+		BlocksReceived: 7,
+		BroadcastsSent: 3,
+	}
+}
+
 func (m *mockNodeService) BroadcastBlock(b *Block) {
 	m.broadcastBlock = b
 }
@@ -208,6 +381,65 @@ func TestHandleGetChain(t *testing.T) {
 	}
 }
 
+func TestHandleVerifyChain(t *testing.T) {
+	t.Run("valid chain", func(t *testing.T) {
+		mock := &mockNodeService{
+			chain:    []*Block{},
+			peers:    make(map[string]*NodeInfo),
+			nodeName: "test-node",
+		}
+		server := NewServer(":8080", mock)
+
+		req := httptest.NewRequest("GET", "/chain/verify", nil)
+		w := httptest.NewRecorder()
+		server.handleVerifyChain(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("Expected status 200, got %d", w.Code)
+		}
+
+		var resp map[string]any
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if resp["valid"] != true {
+			t.Errorf("Expected valid true, got %v", resp["valid"])
+		}
+		if _, ok := resp["index"]; ok {
+			t.Error("Expected no index for valid chain")
+		}
+	})
+
+	t.Run("tampered chain", func(t *testing.T) {
+		index := 2
+		mock := &mockNodeService{
+			chain:        []*Block{},
+			peers:        make(map[string]*NodeInfo),
+			nodeName:     "test-node",
+			verification: &ChainVerification{Valid: false, Index: &index, Reason: "invalid block hash"},
+		}
+		server := NewServer(":8080", mock)
+
+		req := httptest.NewRequest("GET", "/chain/verify", nil)
+		w := httptest.NewRecorder()
+		server.handleVerifyChain(w, req)
+
+		var resp ChainVerification
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if resp.Valid {
+			t.Error("Expected valid false")
+		}
+		if resp.Index == nil || *resp.Index != 2 {
+			t.Errorf("Expected index 2, got %v", resp.Index)
+		}
+		if resp.Reason == "" {
+			t.Error("Expected reason")
+		}
+	})
+}
+
 func TestHandleReceiveBlock(t *testing.T) {
 	mock := &mockNodeService{
 		chain:    []*Block{},
@@ -254,6 +486,60 @@ func TestHandleReceiveBlock(t *testing.T) {
 	if len(mock.chain) != 1 {
 		t.Errorf("Expected 1 block in chain, got %d", len(mock.chain))
 	}
+
+	var resp map[string]string
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp["status"] != "added" {
+		t.Errorf("Expected status 'added', got '%s'", resp["status"])
+	}
+}
+
+func TestHandleReceiveBlockStatus(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     ReceiveStatus
+		err        error
+		wantCode   int
+		wantStatus string
+	}{
+		{name: "added", status: ReceiveAdded, wantCode: http.StatusOK, wantStatus: "added"},
+		{name: "duplicate", status: ReceiveDuplicate, wantCode: http.StatusOK, wantStatus: "duplicate"},
+		{name: "sync queued", status: ReceiveSyncQueued, wantCode: http.StatusOK, wantStatus: "sync_queued"},
+		{name: "rejected", err: errors.New("block validation failed"), wantCode: http.StatusBadRequest, wantStatus: "rejected"},
+		{name: "conflict", err: fmt.Errorf("%w: block index 1 is behind", ErrBlockBehind), wantCode: http.StatusConflict, wantStatus: "rejected"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &mockNodeService{
+				chain:         []*Block{},
+				peers:         make(map[string]*NodeInfo),
+				nodeName:      "test-node",
+				receiveStatus: tt.status,
+				receiveErr:    tt.err,
+			}
+			server := NewServer(":8080", mock)
+
+			blockJSON, _ := json.Marshal(Block{Payload: BlockPayload{Type: "add_node"}})
+			req := httptest.NewRequest("POST", "/block", bytes.NewBuffer(blockJSON))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			server.handleReceiveBlock(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("Expected status %d, got %d", tt.wantCode, w.Code)
+			}
+			var resp map[string]string
+			json.NewDecoder(w.Body).Decode(&resp)
+			if resp["status"] != tt.wantStatus {
+				t.Errorf("Expected status '%s', got '%s'", tt.wantStatus, resp["status"])
+			}
+			if (tt.err != nil) != (resp["error"] != "") {
+				t.Errorf("error = %q, want error only when rejected", resp["error"])
+			}
+		})
+	}
 }
 
 func TestHandleReceiveBlockInvalidJSON(t *testing.T) {
@@ -314,6 +600,7 @@ func TestHandlePropose(t *testing.T) {
 	var resp struct {
 		Status  string `json:"status"`
 		Message string `json:"message"`
+		ID      string `json:"id"`
 	}
 	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
 		t.Fatalf("Failed to decode response: %v", err)
@@ -322,6 +609,9 @@ func TestHandlePropose(t *testing.T) {
 	if resp.Status != "proposed" {
 		t.Errorf("Expected status 'proposed', got '%s'", resp.Status)
 	}
+	if resp.ID != "tx-proposed" {
+		t.Errorf("Expected id 'tx-proposed', got '%s'", resp.ID)
+	}
 }
 
 func TestHandleProposeInvalidJSON(t *testing.T) {
@@ -334,14 +624,23 @@ func TestHandleProposeInvalidJSON(t *testing.T) {
 
 	server := NewServer(":8080", mock)
 
-	req := httptest.NewRequest("POST", "/transaction/propose", nil)
-	req.Header.Set("Content-Type", "application/json")
+	for _, body := range []string{"", `{"from":"alice",`} {
+		req := httptest.NewRequest("POST", "/transaction/propose", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
 
-	w := httptest.NewRecorder()
-	server.handlePropose(w, req)
+		w := httptest.NewRecorder()
+		server.handlePropose(w, req)
 
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("Expected status 400, got %d", w.Code)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("body %q: Expected status 400, got %d", body, w.Code)
+		}
+		var resp errorResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if resp.Code != CodeInvalidJSON || resp.Error == "" {
+			t.Errorf("body %q: response = %+v, want code %s with an error message", body, resp, CodeInvalidJSON)
+		}
 	}
 }
 
@@ -396,6 +695,70 @@ func TestHandleApprove(t *testing.T) {
 	}
 }
 
+func TestHandleExpired(t *testing.T) {
+	mock := &mockNodeService{
+		chain:    []*Block{},
+		pending:  []*PendingTransaction{},
+		peers:    make(map[string]*NodeInfo),
+		nodeName: "test-node",
+	}
+
+	server := NewServer(":8080", mock)
+
+	reqBody := map[string]any{
+		"from":           "alice",
+		"to":             "bob",
+		"amount":         1000,
+		"title":          "飲み会代",
+		"from_signature": "sig",
+		"to_signature":   "expiry-sig",
+	}
+	body, _ := json.Marshal(reqBody)
+	req := httptest.NewRequest("POST", "/transaction/expired", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	server.handleExpired(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if !mock.expiredCalled {
+		t.Error("Expected NotifyExpired to be called")
+	}
+}
+
+func TestHandleExpiredMissingSignature(t *testing.T) {
+	mock := &mockNodeService{
+		chain:    []*Block{},
+		pending:  []*PendingTransaction{},
+		peers:    make(map[string]*NodeInfo),
+		nodeName: "test-node",
+	}
+
+	server := NewServer(":8080", mock)
+
+	bodies := []map[string]any{
+		{"from": "alice", "to": "bob", "amount": 1000, "title": "x", "to_signature": "expiry-sig"},
+		{"from": "alice", "to": "bob", "amount": 1000, "title": "x", "from_signature": "sig"},
+	}
+	for _, reqBody := range bodies {
+		body, _ := json.Marshal(reqBody)
+		req := httptest.NewRequest("POST", "/transaction/expired", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		server.handleExpired(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%v: Expected status 400, got %d", reqBody, w.Code)
+		}
+	}
+	if mock.expiredCalled {
+		t.Error("NotifyExpired should not be called without from_signature and to_signature")
+	}
+}
+
 func TestHandleGetPending(t *testing.T) {
 	pending := []*PendingTransaction{
 		{
@@ -441,6 +804,177 @@ func TestHandleGetPending(t *testing.T) {
 	}
 }
 
+func TestHandleGetPendingByID(t *testing.T) {
+	mock := &mockNodeService{
+		pending: []*PendingTransaction{
+			{ID: "uuid-1", Transaction: &TransactionData{From: "alice", To: "bob", Amount: 1000, Title: "Test"}, FromSig: "sig123"},
+		},
+		peers:    make(map[string]*NodeInfo),
+		nodeName: "test-node",
+	}
+	server := NewServer(":8080", mock)
+
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/transaction/pending/uuid-1", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	var result PendingTransaction
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if result.ID != "uuid-1" || result.FromSig != "sig123" || result.Transaction == nil || result.Transaction.Amount != 1000 {
+		t.Errorf("response = %+v, want uuid-1", result)
+	}
+
+	w = httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/transaction/pending/unknown", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("unknown id status = %d, want 404", w.Code)
+	}
+}
+
+func TestHandleGetExpired(t *testing.T) {
+	mock := &mockNodeService{
+		expired: []*ArchivedTransaction{
+			{
+				PendingTransaction: PendingTransaction{
+					ID:          "uuid-1",
+					Transaction: &TransactionData{From: "alice", To: "bob", Amount: 1000, Title: "Test"},
+					FromSig:     "sig123",
+				},
+				Reason:     "expired",
+				ArchivedAt: 1700000000,
+			},
+		},
+		peers:    make(map[string]*NodeInfo),
+		nodeName: "test-node",
+	}
+
+	server := NewServer(":8080", mock)
+
+	req := httptest.NewRequest("GET", "/transaction/expired", nil)
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var result []map[string]any
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(result) != 1 {
+		t.Fatalf("Expected 1 archived transaction, got %d", len(result))
+	}
+	if result[0]["id"] != "uuid-1" || result[0]["reason"] != "expired" || result[0]["archived_at"] != float64(1700000000) {
+		t.Errorf("Unexpected archived transaction: %v", result[0])
+	}
+}
+
+func TestHandleGetTransactions(t *testing.T) {
+	txBlock := func(index int, from, to string) *Block {
+		return &Block{
+			Header:  BlockHeader{Index: index, CreatedAt: 1700000000 + int64(index)},
+			Payload: BlockPayload{Type: "transaction", Transaction: &TransactionData{From: from, To: to, Amount: 100, Title: "Test"}},
+		}
+	}
+	mock := &mockNodeService{
+		chain: []*Block{
+			{Header: BlockHeader{Index: 0}, Payload: BlockPayload{Type: "add_node", AddNode: &AddNodeData{NodeName: "genesis"}}},
+			txBlock(1, "alice", "bob"),
+			txBlock(2, "bob", "carol"),
+			txBlock(3, "carol", "alice"),
+		},
+		peers:    make(map[string]*NodeInfo),
+		nodeName: "test-node",
+	}
+
+	server := NewServer(":8080", mock)
+
+	tests := []struct {
+		query      string
+		indexes    []int
+		directions []string
+	}{
+		{query: "?node=alice", indexes: []int{1, 3}, directions: []string{"sent", "received"}},
+		{query: "", indexes: []int{1, 2, 3}, directions: []string{"", "", ""}},
+		{query: "?node=dave", indexes: []int{}, directions: []string{}},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/transactions"+tt.query, nil)
+		w := httptest.NewRecorder()
+		server.Handler().ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET /transactions%s status = %d, want 200", tt.query, w.Code)
+		}
+		var result []*TransactionRecord
+		if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if result == nil {
+			t.Errorf("GET /transactions%s returned null, want an array", tt.query)
+		}
+		if len(result) != len(tt.indexes) {
+			t.Fatalf("GET /transactions%s returned %d records, want %d", tt.query, len(result), len(tt.indexes))
+		}
+		for i, record := range result {
+			if record.Index != tt.indexes[i] || record.Direction != tt.directions[i] {
+				t.Errorf("GET /transactions%s [%d] = index %d direction %q, want %d %q",
+					tt.query, i, record.Index, record.Direction, tt.indexes[i], tt.directions[i])
+			}
+			if record.CreatedAt != 1700000000+int64(record.Index) {
+				t.Errorf("GET /transactions%s [%d].created_at = %d", tt.query, i, record.CreatedAt)
+			}
+		}
+	}
+
+	// チェーンのブロックが読めなければ一部だけの履歴を返さず 500
+	mock.chainErr = fmt.Errorf("read error")
+	req := httptest.NewRequest("GET", "/transactions?node=alice", nil)
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("GET /transactions with read error status = %d, want 500", w.Code)
+	}
+}
+
+func TestHandleGetTransactionStatus(t *testing.T) {
+	mock := &mockNodeService{
+		statuses: map[string]*TransactionStatus{
+			"uuid-1": {ID: "uuid-1", Status: "approved", BlockHash: "hash-1"},
+		},
+		peers:    make(map[string]*NodeInfo),
+		nodeName: "test-node",
+	}
+
+	server := NewServer(":8080", mock)
+
+	req := httptest.NewRequest("GET", "/transaction/status/uuid-1", nil)
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	var status TransactionStatus
+	if err := json.NewDecoder(w.Body).Decode(&status); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if status.Status != "approved" || status.BlockHash != "hash-1" {
+		t.Errorf("Unexpected status: %+v", status)
+	}
+
+	req = httptest.NewRequest("GET", "/transaction/status/unknown", nil)
+	w = httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for unknown ID, got %d", w.Code)
+	}
+}
+
 func TestHandleRegister(t *testing.T) {
 	mock := &mockNodeService{
 		chain:    []*Block{},
@@ -455,7 +989,7 @@ func TestHandleRegister(t *testing.T) {
 		"node_name":  "alice",
 		"nick_name":  "アリス",
 		"address":    "10.0.0.1",
-		"public_key": "pub-key-123",
+		"public_key": strings.Repeat("ab", 32),
 	}
 	body, _ := json.Marshal(reqBody)
 	req := httptest.NewRequest("POST", "/register", nil)
@@ -491,48 +1025,860 @@ func TestHandleRegister(t *testing.T) {
 	}
 }
 
-func TestHandleRegisterInvalidJSON(t *testing.T) {
+func TestHandleRegister_InvalidPublicKey(t *testing.T) {
+	tests := []struct {
+		name      string
+		publicKey string
+		mock      *mockNodeService
+		wantCode  int
+	}{
+		{name: "malformed hex", publicKey: strings.Repeat("zz", 32), mock: &mockNodeService{}, wantCode: http.StatusBadRequest},
+		{name: "wrong length", publicKey: strings.Repeat("ab", 16), mock: &mockNodeService{}, wantCode: http.StatusBadRequest},
+		{
+			name:      "conflicting key",
+			publicKey: strings.Repeat("ab", 32),
+			mock:      &mockNodeService{registerErr: fmt.Errorf("%w: alice", ErrNodeConflict)},
+			wantCode:  http.StatusConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := `{"node_name":"alice","nick_name":"アリス","address":"10.0.0.1","public_key":"` + tt.publicKey + `"}`
+			req := httptest.NewRequest("POST", "/register", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			NewServer(":8080", tt.mock).Handler().ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d, want %d (body: %s)", w.Code, tt.wantCode, w.Body.String())
+			}
+			if tt.mock.broadcastBlock != nil {
+				t.Error("rejected registration should not be broadcast")
+			}
+			if tt.wantCode == http.StatusBadRequest {
+				if tt.mock.registerCalled {
+					t.Error("RegisterNode should not be called for an invalid public key")
+				}
+				if !strings.Contains(w.Body.String(), "invalid public key") {
+					t.Errorf("body = %s, want invalid public key", w.Body.String())
+				}
+			}
+		})
+	}
+}
+
+func TestRequestLogging(t *testing.T) {
 	mock := &mockNodeService{
-		chain:    []*Block{},
-		pending:  []*PendingTransaction{},
 		peers:    make(map[string]*NodeInfo),
 		nodeName: "test-node",
 	}
+	s := NewServer(":8080", mock)
+	var buf bytes.Buffer
+	s.SetLogger(log.New(&buf, "", 0))
+	handler := s.Handler()
+
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{"GET", "/peers", "GET /peers 200 "},
+		{"GET", "/block/abc", "GET /block/abc 400 "},
+		{"DELETE", "/peers", "DELETE /peers 405 "},
+	}
+	for _, tt := range tests {
+		buf.Reset()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+
+		line := buf.String()
+		if !strings.HasPrefix(line, tt.want) || strings.Count(line, "\n") != 1 {
+			t.Errorf("log = %q, want one line starting with %q", line, tt.want)
+		}
+	}
 
-	server := NewServer(":8080", mock)
+	// nil ならログを出さない
+	s.SetLogger(nil)
+	buf.Reset()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/peers", nil))
+	if buf.Len() != 0 {
+		t.Errorf("log = %q, want empty with nil logger", buf.String())
+	}
+}
 
-	req := httptest.NewRequest("POST", "/register", nil)
-	req.Header.Set("Content-Type", "application/json")
+func TestTokenBucketLimiter(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	l := NewTokenBucketLimiter(2, 3)
+	l.now = func() time.Time { return now }
 
-	w := httptest.NewRecorder()
-	server.handleRegister(w, req)
+	for i := 0; i < 3; i++ {
+		if !l.Allow("10.0.0.1") {
+			t.Fatalf("request %d denied within burst", i+1)
+		}
+	}
+	if l.Allow("10.0.0.1") {
+		t.Error("request past burst allowed")
+	}
+	// 送信元ごとに独立している
+	if !l.Allow("10.0.0.2") {
+		t.Error("request from another address denied")
+	}
 
-	if w.Code != http.StatusBadRequest {
-		t.Errorf("Expected status 400, got %d", w.Code)
+	// 0.5 秒で 1 トークン補充される
+	now = now.Add(500 * time.Millisecond)
+	if !l.Allow("10.0.0.1") {
+		t.Error("request after refill denied")
+	}
+	if l.Allow("10.0.0.1") {
+		t.Error("second request after partial refill allowed")
 	}
 }
 
-func TestHandleGetPeers(t *testing.T) {
-	peers := map[string]*NodeInfo{
-		"alice": {
-			Name:      "alice",
-			NickName:  "アリス",
-			Address:   "10.0.0.1",
-			PublicKey: "pub-key-alice",
-		},
-		"bob": {
-			Name:      "bob",
-			NickName:  "ボブ",
-			Address:   "10.0.0.2",
-			PublicKey: "pub-key-bob",
-		},
+func TestHandleReceiveBlock_RateLimited(t *testing.T) {
+	mock := &mockNodeService{peers: make(map[string]*NodeInfo), nodeName: "test-node"}
+	srv := NewServer(":8080", mock)
+	srv.SetBlockRateLimiter(NewTokenBucketLimiter(1, 3))
+	handler := srv.Handler()
+
+	post := func(remoteAddr string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/block", strings.NewReader(`{"header":{"index":1},"payload":{"type":"transaction"}}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
 	}
 
-	mock := &mockNodeService{
-		chain:    []*Block{},
-		pending:  []*PendingTransaction{},
-		peers:    peers,
-		nodeName: "test-node",
+	for i := 0; i < 3; i++ {
+		if w := post("192.0.2.1:40000"); w.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200 (body: %s)", i+1, w.Code, w.Body.String())
+		}
+	}
+	// ポートが異なっても同じ送信元として数える
+	w := post("192.0.2.1:40001")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("request past limit: status = %d, want 429", w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("429 response has no Retry-After header")
+	}
+	if w := post("192.0.2.2:40000"); w.Code != http.StatusOK {
+		t.Errorf("other address: status = %d, want 200", w.Code)
+	}
+
+	// 他のエンドポイントは制限しない
+	for i := 0; i < 5; i++ {
+		req := httptest.NewRequest("GET", "/peers", nil)
+		req.RemoteAddr = "192.0.2.1:40000"
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET /peers status = %d, want 200", w.Code)
+		}
+	}
+}
+
+func TestInsufficientStorage(t *testing.T) {
+	diskFull := fmt.Errorf("failed to persist block: %w", ErrInsufficientStorage)
+
+	tests := []struct {
+		name string
+		mock *mockNodeService
+		path string
+		body string
+	}{
+		{"propose", &mockNodeService{proposeErr: diskFull}, "/transaction/propose", `{"from":"alice","to":"bob","amount":100,"title":"test"}`},
+		{"approve", &mockNodeService{approveErr: diskFull}, "/transaction/approve", `{"id":"tx1"}`},
+		{"block", &mockNodeService{receiveErr: diskFull}, "/block", `{"header":{"index":1},"payload":{"type":"transaction"}}`},
+		{"register", &mockNodeService{registerErr: diskFull}, "/register", `{"node_name":"bob","nick_name":"bob","address":"10.0.0.2","public_key":"` + strings.Repeat("aa", 32) + `"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewServer(":8080", tt.mock).Handler()
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != http.StatusInsufficientStorage {
+				t.Errorf("status = %d, want %d (body: %s)", w.Code, http.StatusInsufficientStorage, w.Body.String())
+			}
+		})
+	}
+
+	// ディスク容量不足以外のエラーは従来通り 400
+	mock := &mockNodeService{proposeErr: errors.New("boom")}
+	req := httptest.NewRequest("POST", "/transaction/propose", strings.NewReader(`{"from":"alice","to":"bob","amount":100,"title":"test"}`))
+	w := httptest.NewRecorder()
+	NewServer(":8080", mock).Handler().ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHandleProposeDuplicate(t *testing.T) {
+	dup := fmt.Errorf("proposal already pending: %w", ErrDuplicateProposal)
+	mock := &mockNodeService{proposeErr: dup}
+	req := httptest.NewRequest("POST", "/transaction/propose", strings.NewReader(`{"from":"alice","to":"bob","amount":100,"title":"test"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	NewServer(":8080", mock).Handler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("status = %d, want %d (body: %s)", w.Code, http.StatusConflict, w.Body.String())
+	}
+	var resp errorResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Code != CodeConflict {
+		t.Errorf("code = %s, want %s", resp.Code, CodeConflict)
+	}
+}
+
+func TestHandleGetChainRange(t *testing.T) {
+	mock := &mockNodeService{
+		peers:    make(map[string]*NodeInfo),
+		nodeName: "test-node",
+	}
+	for i := 0; i < 5; i++ {
+		mock.chain = append(mock.chain, &Block{Header: BlockHeader{Index: i, Hash: fmt.Sprintf("hash-%d", i)}})
+	}
+
+	server := NewServer(":8080", mock)
+
+	tests := []struct {
+		query      string
+		wantStatus int
+		wantIndex  []int
+	}{
+		{"", http.StatusOK, []int{0, 1, 2, 3, 4}},
+		{"?from=1&to=3", http.StatusOK, []int{1, 2}},
+		{"?from=3", http.StatusOK, []int{3, 4}},
+		{"?to=2", http.StatusOK, []int{0, 1}},
+		{"?from=-10&to=100", http.StatusOK, []int{0, 1, 2, 3, 4}},
+		{"?from=10&to=20", http.StatusOK, []int{}},
+		{"?from=3&to=1", http.StatusBadRequest, nil},
+		{"?from=abc", http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/chain"+tt.query, nil)
+			w := httptest.NewRecorder()
+			server.handleGetChain(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var chain []*Block
+			if err := json.NewDecoder(w.Body).Decode(&chain); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(chain) != len(tt.wantIndex) {
+				t.Fatalf("Expected %d blocks, got %d", len(tt.wantIndex), len(chain))
+			}
+			for i, b := range chain {
+				if b.Header.Index != tt.wantIndex[i] {
+					t.Errorf("block %d index = %d, want %d", i, b.Header.Index, tt.wantIndex[i])
+				}
+			}
+		})
+	}
+}
+
+func TestHandleGetChainLarge(t *testing.T) {
+	mock := &mockNodeService{
+		peers:    make(map[string]*NodeInfo),
+		nodeName: "test-node",
+	}
+	// ストリーミング応答のページ（chainStreamPageSize）をまたぐ長さのチェーン
+	for i := 0; i < 1200; i++ {
+		mock.chain = append(mock.chain, &Block{Header: BlockHeader{Index: i, Hash: fmt.Sprintf("hash-%d", i)}})
+	}
+
+	server := NewServer(":8080", mock)
+	server.SetMaxChainBlocks(100)
+
+	// 上限を超える JSON 配列は 413 と次のページを返す
+	for query, wantNext := range map[string]string{
+		"":                "/chain?from=0&to=100",
+		"?from=50&to=151": "/chain?from=50&to=150",
+		"?from=1000":      "/chain?from=1000&to=1100",
+	} {
+		req := httptest.NewRequest("GET", "/chain"+query, nil)
+		w := httptest.NewRecorder()
+		server.handleGetChain(w, req)
+
+		if w.Code != http.StatusRequestEntityTooLarge {
+			t.Fatalf("%q: Expected status 413, got %d", query, w.Code)
+		}
+		var resp chainTooLargeResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		if resp.MaxBlocks != 100 || resp.Next != wantNext {
+			t.Errorf("%q: response = %+v, want max_blocks 100 and next %s", query, resp, wantNext)
+		}
+	}
+
+	// 上限内の範囲は JSON 配列で返す
+	req := httptest.NewRequest("GET", "/chain?from=100&to=200", nil)
+	w := httptest.NewRecorder()
+	server.handleGetChain(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// NDJSON を要求すると上限を超えても1行に1ブロックで全件返す
+	req = httptest.NewRequest("GET", "/chain", nil)
+	req.Header.Set("Accept", ndjsonContentType)
+	w = httptest.NewRecorder()
+	server.handleGetChain(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != ndjsonContentType {
+		t.Errorf("Content-Type = %q, want %q", ct, ndjsonContentType)
+	}
+	dec := json.NewDecoder(w.Body)
+	count := 0
+	for dec.More() {
+		var b Block
+		if err := dec.Decode(&b); err != nil {
+			t.Fatalf("Failed to decode line %d: %v", count, err)
+		}
+		if b.Header.Index != count {
+			t.Fatalf("line %d index = %d, want %d", count, b.Header.Index, count)
+		}
+		count++
+	}
+	if count != len(mock.chain) {
+		t.Errorf("streamed %d blocks, want %d", count, len(mock.chain))
+	}
+	// 最後まで書き出した応答にはブロック数のトレーラーが付く
+	if got, want := w.Result().Trailer.Get(chainBlocksTrailer), fmt.Sprint(len(mock.chain)); got != want {
+		t.Errorf("%s trailer = %q, want %q", chainBlocksTrailer, got, want)
+	}
+}
+
+func TestStreamChain_WriteTimeout(t *testing.T) {
+	mock := &mockNodeService{
+		peers:      make(map[string]*NodeInfo),
+		nodeName:   "test-node",
+		rangeDelay: 150 * time.Millisecond,
+	}
+	for i := 0; i < 3*chainStreamPageSize; i++ {
+		mock.chain = append(mock.chain, &Block{Header: BlockHeader{Index: i, Hash: fmt.Sprintf("hash-%d", i)}})
+	}
+
+	// 全ページの書き出しにサーバーの WriteTimeout より長くかかっても打ち切られない
+	ts := httptest.NewUnstartedServer(NewServer(":8080", mock).Handler())
+	ts.Config.WriteTimeout = 200 * time.Millisecond
+	ts.Start()
+	defer ts.Close()
+
+	req, _ := http.NewRequest("GET", ts.URL+"/chain", nil)
+	req.Header.Set("Accept", ndjsonContentType)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET /chain error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading stream error = %v", err)
+	}
+	if got := strings.Count(string(body), "\n"); got != len(mock.chain) {
+		t.Errorf("streamed %d blocks, want %d", got, len(mock.chain))
+	}
+	if got, want := resp.Trailer.Get(chainBlocksTrailer), fmt.Sprint(len(mock.chain)); got != want {
+		t.Errorf("%s trailer = %q, want %q", chainBlocksTrailer, got, want)
+	}
+}
+
+func TestRequireAPIKey(t *testing.T) {
+	newServer := func(key string) (*Server, *mockNodeService) {
+		mock := &mockNodeService{peers: make(map[string]*NodeInfo), nodeName: "test-node"}
+		srv := NewServer(":8080", mock)
+		srv.SetAPIKey(key)
+		return srv, mock
+	}
+	propose := func(srv *Server, auth string) int {
+		req := httptest.NewRequest("POST", "/transaction/propose", strings.NewReader(`{"from":"alice","to":"bob","amount":100,"title":"test"}`))
+		req.Header.Set("Content-Type", "application/json")
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(w, req)
+		return w.Code
+	}
+
+	t.Run("authorized", func(t *testing.T) {
+		srv, mock := newServer("shared-key")
+		if code := propose(srv, "Bearer shared-key"); code != http.StatusOK {
+			t.Errorf("status = %d, want 200", code)
+		}
+		if !mock.proposeCalled {
+			t.Error("ProposeTransaction was not called")
+		}
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		srv, mock := newServer("shared-key")
+		for _, auth := range []string{"", "Bearer wrong", "shared-key", "Basic shared-key"} {
+			if code := propose(srv, auth); code != http.StatusUnauthorized {
+				t.Errorf("auth %q: status = %d, want 401", auth, code)
+			}
+		}
+		if mock.proposeCalled {
+			t.Error("ProposeTransaction was called without a valid API key")
+		}
+
+		// 参照系は鍵なしで読める
+		w := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/peers", nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("GET /peers status = %d, want 200", w.Code)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		srv, mock := newServer("")
+		if code := propose(srv, ""); code != http.StatusOK {
+			t.Errorf("status = %d, want 200", code)
+		}
+		if !mock.proposeCalled {
+			t.Error("ProposeTransaction was not called")
+		}
+	})
+}
+
+func TestHandleAdminRollback(t *testing.T) {
+	newMock := func() *mockNodeService {
+		mock := &mockNodeService{peers: make(map[string]*NodeInfo), nodeName: "test-node"}
+		for i := 0; i < 5; i++ {
+			mock.chain = append(mock.chain, &Block{Header: BlockHeader{Index: i, Hash: fmt.Sprintf("hash-%d", i)}})
+		}
+		return mock
+	}
+	post := func(srv *Server, auth, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/admin/rollback", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		w := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(w, req)
+		return w
+	}
+
+	// トークン未設定なら無効
+	mock := newMock()
+	if w := post(NewServer(":8080", mock), "Bearer secret", `{"index":2}`); w.Code != http.StatusNotFound {
+		t.Errorf("without admin token: status = %d, want 404", w.Code)
+	}
+
+	srv := NewServer(":8080", mock)
+	srv.SetAdminToken("secret")
+	tests := []struct {
+		name       string
+		auth       string
+		body       string
+		wantStatus int
+	}{
+		{"missing token", "", `{"index":2}`, http.StatusUnauthorized},
+		{"wrong token", "Bearer wrong", `{"index":2}`, http.StatusUnauthorized},
+		{"missing index", "Bearer secret", `{}`, http.StatusBadRequest},
+		{"negative index", "Bearer secret", `{"index":-1}`, http.StatusBadRequest},
+		{"beyond tip", "Bearer secret", `{"index":5}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := post(srv, tt.auth, tt.body); w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if len(mock.chain) != 5 {
+				t.Errorf("chain length = %d, want unchanged 5", len(mock.chain))
+			}
+		})
+	}
+
+	w := post(srv, "Bearer secret", `{"index":2}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200: %s", w.Code, w.Body.String())
+	}
+	var resp rollbackResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Removed != 2 || resp.ChainLength != 3 {
+		t.Errorf("response = %+v, want removed 2 and chain_length 3", resp)
+	}
+}
+
+func TestHandleGetChainValidation(t *testing.T) {
+	mock := &mockNodeService{
+		chain:        []*Block{{Header: BlockHeader{Index: 0, Hash: "genesis-hash"}}},
+		structureErr: errors.New("block at index 1 validation failed"),
+	}
+
+	// 無効時は検証せずそのまま返す
+	srv := NewServer(":8080", mock)
+	w := httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/chain", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d when validation is disabled", w.Code, http.StatusOK)
+	}
+
+	srv.SetValidateChain(true)
+	w = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/chain", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	if strings.Contains(w.Body.String(), "genesis-hash") {
+		t.Error("corrupt chain should not be served")
+	}
+
+	mock.structureErr = nil
+	w = httptest.NewRecorder()
+	srv.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/chain", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d for a valid chain", w.Code, http.StatusOK)
+	}
+}
+
+func TestHandleGetBlock(t *testing.T) {
+	mock := &mockNodeService{
+		chain: []*Block{
+			{Header: BlockHeader{Index: 0, Hash: "genesis-hash"}, Payload: BlockPayload{Type: "add_node"}},
+			{Header: BlockHeader{Index: 1, PrevHash: "genesis-hash", Hash: "hash-1"}, Payload: BlockPayload{Type: "transaction"}},
+		},
+	}
+	handler := NewServer(":8080", mock).Handler()
+
+	tests := []struct {
+		path     string
+		wantCode int
+		wantHash string
+	}{
+		{"/block/1", http.StatusOK, "hash-1"},
+		{"/block/0", http.StatusOK, "genesis-hash"},
+		{"/block/2", http.StatusNotFound, ""},
+		{"/block/-1", http.StatusNotFound, ""},
+		{"/block/abc", http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d (body: %s)", w.Code, tt.wantCode, w.Body.String())
+			}
+			if tt.wantHash == "" {
+				return
+			}
+			var block Block
+			if err := json.NewDecoder(w.Body).Decode(&block); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if block.Header.Hash != tt.wantHash {
+				t.Errorf("hash = %s, want %s", block.Header.Hash, tt.wantHash)
+			}
+		})
+	}
+}
+
+func TestHandleGetBlockByHash(t *testing.T) {
+	known := strings.Repeat("ab", 32)
+	mock := &mockNodeService{
+		chain: []*Block{
+			{Header: BlockHeader{Index: 0, Hash: strings.Repeat("00", 32)}, Payload: BlockPayload{Type: "add_node"}},
+			{Header: BlockHeader{Index: 1, Hash: known}, Payload: BlockPayload{Type: "transaction"}},
+		},
+	}
+	handler := NewServer(":8080", mock).Handler()
+
+	tests := []struct {
+		name      string
+		hash      string
+		wantCode  int
+		wantIndex int
+	}{
+		{"found", known, http.StatusOK, 1},
+		{"found uppercase", strings.ToUpper(known), http.StatusOK, 1},
+		{"not found", strings.Repeat("cd", 32), http.StatusNotFound, 0},
+		{"too short", "abcd", http.StatusBadRequest, 0},
+		{"not hex", strings.Repeat("zz", 32), http.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("GET", "/block/hash/"+tt.hash, nil))
+
+			if w.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d (body: %s)", w.Code, tt.wantCode, w.Body.String())
+			}
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+			var block Block
+			if err := json.NewDecoder(w.Body).Decode(&block); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if block.Header.Index != tt.wantIndex {
+				t.Errorf("index = %d, want %d", block.Header.Index, tt.wantIndex)
+			}
+		})
+	}
+}
+
+func TestUnixSocket(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "signet.sock")
+	mock := &mockNodeService{nodeName: "test-node"}
+
+	srv := NewServer("127.0.0.1:0", mock)
+	srv.SetUnixSocket(socketPath, true)
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- srv.Start()
+	}()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return (&net.Dialer{}).DialContext(ctx, "unix", socketPath)
+			},
+		},
+	}
+
+	var resp *http.Response
+	var err error
+	for i := 0; i < 50; i++ {
+		if resp, err = client.Get("http://unix/info"); err == nil {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err != nil {
+		t.Fatalf("GET /info over unix socket error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	var info struct {
+		NodeName string `json:"node_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if info.NodeName != "test-node" {
+		t.Errorf("node_name = %s, want test-node", info.NodeName)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := srv.Stop(ctx); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	if err := <-serverErr; err != http.ErrServerClosed {
+		t.Errorf("Start() error = %v, want ErrServerClosed", err)
+	}
+	if _, err := os.Stat(socketPath); !os.IsNotExist(err) {
+		t.Errorf("socket file should be removed after Stop: %v", err)
+	}
+}
+
+func TestHandleGetInfo(t *testing.T) {
+	mock := &mockNodeService{
+		nodeName: "test-node",
+		chain:    []*Block{{Header: BlockHeader{Index: 0}}, {Header: BlockHeader{Index: 1}}, {Header: BlockHeader{Index: 2}}},
+		pending:  []*PendingTransaction{{ID: "tx1"}},
+	}
+
+	w := httptest.NewRecorder()
+	NewServer(":8080", mock).Handler().ServeHTTP(w, httptest.NewRequest("GET", "/info", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	var resp infoResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.NodeName != "test-node" {
+		t.Errorf("node_name = %s, want test-node", resp.NodeName)
+	}
+	if resp.ChainLength != 3 {
+		t.Errorf("chain_length = %d, want 3", resp.ChainLength)
+	}
+	if resp.PendingCount != 1 {
+		t.Errorf("pending_count = %d, want 1", resp.PendingCount)
+	}
+}
+
+func TestHandleGetMetrics(t *testing.T) {
+	mock := &mockNodeService{
+		nodeName: "test-node",
+		chain:    []*Block{{Header: BlockHeader{Index: 0}}, {Header: BlockHeader{Index: 1}}},
+		pending:  []*PendingTransaction{{ID: "tx1"}},
+		peers:    map[string]*NodeInfo{"alice": {Name: "alice"}, "bob": {Name: "bob"}},
+	}
+
+	w := httptest.NewRecorder()
+	NewServer(":8080", mock).Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Content-Type = %q, want text/plain", ct)
+	}
+
+	body := w.Body.String()
+	for _, line := range []string{
+		"# TYPE signet_chain_length gauge\nsignet_chain_length 2\n",
+		"signet_pending_transactions 1\n",
+		"signet_peers 2\n",
+		"# TYPE signet_blocks_received_total counter\nsignet_blocks_received_total 7\n",
+		"signet_broadcasts_sent_total 3\n",
+	} {
+		if !strings.Contains(body, line) {
+			t.Errorf("metrics output missing %q:\n%s", line, body)
+		}
+	}
+}
+
+func TestHandleGetVersion(t *testing.T) {
+	orig, origDate := version.Version, version.BuildDate
+	version.Version, version.BuildDate = "v1.2.3", "2024-01-02T03:04:05Z"
+	t.Cleanup(func() { version.Version, version.BuildDate = orig, origDate })
+
+	mock := &mockNodeService{nodeName: "test-node"}
+	handler := NewServer(":8080", mock).Handler()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/version", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var resp version.Info
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if resp.Version != "v1.2.3" || resp.BuildDate != "2024-01-02T03:04:05Z" {
+		t.Errorf("version/build_date = %s/%s, want v1.2.3/2024-01-02T03:04:05Z", resp.Version, resp.BuildDate)
+	}
+	if resp.GoVersion != runtime.Version() {
+		t.Errorf("go_version = %s, want %s", resp.GoVersion, runtime.Version())
+	}
+
+	// /info でもピアが比較できるようバージョンを返す
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/info", nil))
+	var info infoResponse
+	if err := json.NewDecoder(w.Body).Decode(&info); err != nil {
+		t.Fatalf("Failed to decode info: %v", err)
+	}
+	if info.Version != "v1.2.3" {
+		t.Errorf("info version = %s, want v1.2.3", info.Version)
+	}
+}
+
+func TestHandleRegisterInvalidJSON(t *testing.T) {
+	mock := &mockNodeService{
+		chain:    []*Block{},
+		pending:  []*PendingTransaction{},
+		peers:    make(map[string]*NodeInfo),
+		nodeName: "test-node",
+	}
+
+	server := NewServer(":8080", mock)
+
+	req := httptest.NewRequest("POST", "/register", nil)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	server.handleRegister(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
+
+func TestHandleUpdateNickname(t *testing.T) {
+	mock := &mockNodeService{
+		chain:    []*Block{},
+		peers:    make(map[string]*NodeInfo),
+		nodeName: "test-node",
+	}
+	server := NewServer(":8080", mock)
+
+	body, _ := json.Marshal(map[string]string{"nick_name": "新しい名前"})
+	req := httptest.NewRequest("POST", "/node/nickname", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	server.handleUpdateNickname(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if !mock.nicknameCalled {
+		t.Error("Expected UpdateNickname to be called")
+	}
+	if mock.broadcastBlock == nil {
+		t.Error("Expected block to be broadcasted")
+	}
+}
+
+func TestHandleUpdateNicknameEmpty(t *testing.T) {
+	mock := &mockNodeService{
+		chain:    []*Block{},
+		peers:    make(map[string]*NodeInfo),
+		nodeName: "test-node",
+	}
+	server := NewServer(":8080", mock)
+
+	body, _ := json.Marshal(map[string]string{"nick_name": ""})
+	req := httptest.NewRequest("POST", "/node/nickname", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	server.handleUpdateNickname(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+	if mock.nicknameCalled {
+		t.Error("UpdateNickname should not be called for empty nick_name")
+	}
+}
+
+func TestHandleGetPeers(t *testing.T) {
+	peers := map[string]*NodeInfo{
+		"alice": {
+			Name:      "alice",
+			NickName:  "アリス",
+			Address:   "10.0.0.1",
+			PublicKey: "pub-key-alice",
+		},
+		"bob": {
+			Name:      "bob",
+			NickName:  "ボブ",
+			Address:   "10.0.0.2",
+			PublicKey: "pub-key-bob",
+		},
+	}
+
+	mock := &mockNodeService{
+		chain:    []*Block{},
+		pending:  []*PendingTransaction{},
+		peers:    peers,
+		nodeName: "test-node",
 	}
 
 	server := NewServer(":8080", mock)
@@ -559,6 +1905,37 @@ func TestHandleGetPeers(t *testing.T) {
 	}
 }
 
+func TestHandleGetPeerHealth(t *testing.T) {
+	mock := &mockNodeService{
+		peerHealth: map[string]*PeerHealth{
+			"alice": {Reachable: true, LastSeen: 1700000000},
+			"bob":   {Reachable: false, Failures: 2},
+		},
+		nodeName: "test-node",
+	}
+
+	server := NewServer(":8080", mock)
+
+	req := httptest.NewRequest("GET", "/peers/health", nil)
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+
+	var result map[string]*PeerHealth
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !result["alice"].Reachable || result["alice"].LastSeen != 1700000000 {
+		t.Errorf("alice = %+v, want reachable with last_seen", result["alice"])
+	}
+	if result["bob"].Reachable || result["bob"].Failures != 2 {
+		t.Errorf("bob = %+v, want unreachable with 2 failures", result["bob"])
+	}
+}
+
 func TestServerStartAndStop(t *testing.T) {
 	mock := &mockNodeService{
 		chain:    []*Block{},
@@ -585,3 +1962,340 @@ func TestServerStartAndStop(t *testing.T) {
 		t.Errorf("Failed to stop server: %v", err)
 	}
 }
+
+func TestHandleOpenAPI(t *testing.T) {
+	mock := &mockNodeService{
+		chain:    []*Block{},
+		peers:    make(map[string]*NodeInfo),
+		nodeName: "test-node",
+	}
+
+	t.Run("disabled by default", func(t *testing.T) {
+		server := NewServer(":8080", mock)
+
+		req := httptest.NewRequest("GET", "/openapi.json", nil)
+		w := httptest.NewRecorder()
+		server.Handler().ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("Expected status 404, got %d", w.Code)
+		}
+	})
+
+	t.Run("serves document listing known paths", func(t *testing.T) {
+		server := NewServer(":8080", mock)
+		server.SetOpenAPI(true)
+
+		req := httptest.NewRequest("GET", "/openapi.json", nil)
+		w := httptest.NewRecorder()
+		server.Handler().ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+
+		var doc struct {
+			OpenAPI string                    `json:"openapi"`
+			Paths   map[string]map[string]any `json:"paths"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&doc); err != nil {
+			t.Fatalf("Failed to decode document: %v", err)
+		}
+		if !strings.HasPrefix(doc.OpenAPI, "3.") {
+			t.Errorf("Expected OpenAPI 3 document, got %q", doc.OpenAPI)
+		}
+
+		want := map[string]string{
+			"/chain":                "get",
+			"/block":                "post",
+			"/transaction/propose":  "post",
+			"/transaction/approve":  "post",
+			"/transaction/reject":   "post",
+			"/transaction/pending":  "get",
+			"/transaction/proposed": "get",
+			"/register":             "post",
+			"/peers":                "get",
+			"/info":                 "get",
+			"/version":              "get",
+		}
+		for path, method := range want {
+			if _, ok := doc.Paths[path][method]; !ok {
+				t.Errorf("Document does not list %s %s", method, path)
+			}
+		}
+	})
+}
+
+func TestRequireJSON(t *testing.T) {
+	mock := &mockNodeService{
+		chain:    []*Block{},
+		peers:    make(map[string]*NodeInfo),
+		nodeName: "test-node",
+	}
+	server := NewServer(":8080", mock)
+	body := `{"id": "tx-1"}`
+
+	tests := []struct {
+		name        string
+		contentType string
+		wantStatus  int
+	}{
+		{"json", "application/json", http.StatusOK},
+		{"json with charset", "application/json; charset=utf-8", http.StatusOK},
+		{"form data", "application/x-www-form-urlencoded", http.StatusUnsupportedMediaType},
+		{"plain text", "text/plain", http.StatusUnsupportedMediaType},
+		{"missing content type", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/transaction/reject", strings.NewReader(body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+			server.Handler().ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+
+	// ブロック受信・登録・取引の各 POST にも適用される
+	for _, path := range []string{"/block", "/register", "/transaction/propose", "/transaction/approve"} {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "text/plain")
+		w := httptest.NewRecorder()
+		server.Handler().ServeHTTP(w, req)
+		if w.Code != http.StatusUnsupportedMediaType {
+			t.Errorf("POST %s with text/plain status = %d, want 415", path, w.Code)
+		}
+	}
+}
+
+func TestMaxBodyBytes(t *testing.T) {
+	mock := &mockNodeService{
+		chain:    []*Block{},
+		peers:    make(map[string]*NodeInfo),
+		nodeName: "test-node",
+	}
+	server := NewServer(":8080", mock)
+	server.SetMaxBodyBytes(1024)
+
+	post := func(body io.Reader, contentLength int64) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/block", body)
+		req.Header.Set("Content-Type", "application/json")
+		req.ContentLength = contentLength
+		w := httptest.NewRecorder()
+		server.Handler().ServeHTTP(w, req)
+		return w
+	}
+
+	// Content-Length が上限を超えていれば読まずに拒否する
+	oversized := `{"header":{"hash":"` + strings.Repeat("a", 2048) + `"}}`
+	if w := post(strings.NewReader(oversized), int64(len(oversized))); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("oversized body status = %d, want 413", w.Code)
+	}
+	// Content-Length が不明（チャンク転送）でも読み込み時に上限で止める
+	if w := post(strings.NewReader(oversized), -1); w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("oversized chunked body status = %d, want 413 (body: %s)", w.Code, w.Body.String())
+	}
+	if mock.receiveCalled {
+		t.Error("ReceiveBlock should not be called for an oversized body")
+	}
+
+	blockJSON, _ := json.Marshal(Block{Payload: BlockPayload{Type: "add_node"}})
+	if w := post(bytes.NewReader(blockJSON), int64(len(blockJSON))); w.Code != http.StatusOK {
+		t.Errorf("small body status = %d, want 200", w.Code)
+	}
+
+	// 0 以下なら制限しない
+	server.SetMaxBodyBytes(0)
+	if w := post(strings.NewReader(oversized), -1); w.Code == http.StatusRequestEntityTooLarge {
+		t.Error("body should not be limited when the limit is 0")
+	}
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	mock := &mockNodeService{
+		chain:         []*Block{},
+		peers:         make(map[string]*NodeInfo),
+		nodeName:      "test-node",
+		verifyEntered: make(chan struct{}),
+		verifyRelease: make(chan struct{}),
+	}
+	server := NewServer(":8080", mock)
+	server.SetConcurrencyLimit("GET /chain/verify", 1)
+
+	verify := func() int {
+		req := httptest.NewRequest("GET", "/chain/verify", nil)
+		w := httptest.NewRecorder()
+		server.Handler().ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// 1件目がスロットを占有している間は 503
+	first := make(chan int, 1)
+	go func() { first <- verify() }()
+	<-mock.verifyEntered
+
+	if code := verify(); code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 while limit reached, got %d", code)
+	}
+
+	// 制限のないルートは影響を受けない
+	req := httptest.NewRequest("GET", "/info", nil)
+	w := httptest.NewRecorder()
+	server.Handler().ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200 for unlimited route, got %d", w.Code)
+	}
+
+	mock.verifyRelease <- struct{}{}
+	if code := <-first; code != http.StatusOK {
+		t.Errorf("Expected first request status 200, got %d", code)
+	}
+
+	// スロットが空けば再び成功する
+	go func() {
+		<-mock.verifyEntered
+		mock.verifyRelease <- struct{}{}
+	}()
+	if code := verify(); code != http.StatusOK {
+		t.Errorf("Expected status 200 after slot freed, got %d", code)
+	}
+}
+
+func TestHandleReceiveBlockOrigin(t *testing.T) {
+	mock := &mockNodeService{
+		chain:    []*Block{},
+		peers:    make(map[string]*NodeInfo),
+		nodeName: "test-node",
+	}
+	server := NewServer(":8080", mock)
+
+	blockJSON, _ := json.Marshal(Block{Payload: BlockPayload{Type: "add_node"}})
+	req := httptest.NewRequest("POST", "/block", bytes.NewBuffer(blockJSON))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Signet-Origin", "alice")
+
+	w := httptest.NewRecorder()
+	server.handleReceiveBlock(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", w.Code)
+	}
+	if mock.receiveOrigin != "alice" {
+		t.Errorf("Expected origin 'alice', got '%s'", mock.receiveOrigin)
+	}
+}
+
+// issueTestCert は parent（nil なら自己署名の CA）が署名した証明書を作る
+func issueTestCert(t *testing.T, name string, parent *tls.Certificate) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	signer, signerKey := tmpl, any(key)
+	if parent == nil {
+		tmpl.IsCA, tmpl.BasicConstraintsValid = true, true
+		tmpl.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		signer, signerKey = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("CreateCertificate() error = %v", err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("ParseCertificate() error = %v", err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func TestMutualTLS(t *testing.T) {
+	ca := issueTestCert(t, "signet test CA", nil)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Leaf)
+
+	mock := &mockNodeService{
+		chain:    []*Block{},
+		peers:    make(map[string]*NodeInfo),
+		nodeName: "test-node",
+	}
+	srv := NewServer(":8080", mock)
+	srv.SetTLS(&tls.Config{
+		Certificates: []tls.Certificate{issueTestCert(t, "bob", &ca)},
+		ClientCAs:    pool,
+		ClientAuth:   tls.VerifyClientCertIfGiven,
+		MinVersion:   tls.VersionTLS12,
+	})
+	ts := httptest.NewUnstartedServer(srv.Handler())
+	ts.TLS = srv.tlsConfig
+	ts.StartTLS()
+	defer ts.Close()
+
+	client := func(certs ...tls.Certificate) *http.Client {
+		return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, Certificates: certs}}}
+	}
+	reject := func(c *http.Client) (*http.Response, error) {
+		return c.Post(ts.URL+"/transaction/reject", "application/json", strings.NewReader(`{"id":"tx-1"}`))
+	}
+
+	t.Run("valid client certificate", func(t *testing.T) {
+		resp, err := reject(client(issueTestCert(t, "alice", &ca)))
+		if err != nil {
+			t.Fatalf("POST error = %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+		}
+	})
+
+	t.Run("no client certificate", func(t *testing.T) {
+		c := client()
+		resp, err := reject(c)
+		if err != nil {
+			t.Fatalf("POST error = %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("POST status = %d, want %d", resp.StatusCode, http.StatusUnauthorized)
+		}
+
+		// 参照系は証明書なしでも使える
+		resp, err = c.Get(ts.URL + "/info")
+		if err != nil {
+			t.Fatalf("GET error = %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("GET status = %d, want %d", resp.StatusCode, http.StatusOK)
+		}
+	})
+
+	t.Run("certificate from another CA", func(t *testing.T) {
+		// 提示すればハンドシェイクで拒否され、サーバーの CA に合わず提示されなければ 401 になる
+		other := issueTestCert(t, "other CA", nil)
+		if resp, err := reject(client(issueTestCert(t, "mallory", &other))); err == nil {
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusUnauthorized {
+				t.Errorf("POST status = %d, want handshake failure or %d", resp.StatusCode, http.StatusUnauthorized)
+			}
+		}
+	})
+}
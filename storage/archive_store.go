@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"signet/core"
+)
+
+// ArchiveStore は期限切れ・拒否された承認待ちトランザクションの記録の永続化を担当する
+type ArchiveStore struct {
+	path string
+}
+
+// NewArchiveStore は新しいArchiveStoreを作成する
+func NewArchiveStore(path string) *ArchiveStore {
+	return &ArchiveStore{path: path}
+}
+
+// Load は記録を読み込む（古い順）
+// ファイルが存在しない場合は空スライスを返す
+func (s *ArchiveStore) Load() ([]*core.ArchivedTransaction, error) {
+	data, err := readFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []*core.ArchivedTransaction{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	if len(data) == 0 {
+		return []*core.ArchivedTransaction{}, nil
+	}
+
+	var items []*core.ArchivedTransaction
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal archived transactions: %w", err)
+	}
+
+	return items, nil
+}
+
+// Save は記録をJSON配列として書き出す
+func (s *ArchiveStore) Save(items []*core.ArchivedTransaction) error {
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal archived transactions: %w", err)
+	}
+
+	// 改行で終わるようにする
+	data = append(data, '\n')
+
+	if err := writeFile(s.path, string(data)); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
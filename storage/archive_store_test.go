@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"path/filepath"
+	"signet/core"
+	"testing"
+	"time"
+)
+
+func TestArchiveStoreSaveLoad(t *testing.T) {
+	store := NewArchiveStore(filepath.Join(t.TempDir(), "expired_transaction.json"))
+
+	items, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(items) != 0 {
+		t.Errorf("Load() returned %d items for missing file, want 0", len(items))
+	}
+
+	archivedAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	want := []*core.ArchivedTransaction{
+		{PendingTransaction: core.PendingTransaction{ID: "tx1"}, Reason: core.ArchiveReasonExpired, ArchivedAt: archivedAt},
+		{PendingTransaction: core.PendingTransaction{ID: "tx2"}, Reason: core.ArchiveReasonRejected, ArchivedAt: archivedAt},
+	}
+	if err := store.Save(want); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	got, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Load() returned %d items, want 2", len(got))
+	}
+	for i := range want {
+		if got[i].ID != want[i].ID || got[i].Reason != want[i].Reason || !got[i].ArchivedAt.Equal(archivedAt) {
+			t.Errorf("item %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"signet/core"
+)
+
+// ErrStaleIndex はインデックスファイルが存在しない、または block.jsonl と一致しないことを表す（RebuildIndex で作り直す）
+var ErrStaleIndex = errors.New("block index is missing or stale")
+
+// IndexEntry は block.jsonl 内の1ブロックの位置（インデックスファイルの1行）
+// Offset は行頭のバイト位置、Size は改行を除いた行のバイト数
+type IndexEntry struct {
+	Index  int    `json:"index"`
+	Offset int64  `json:"offset"`
+	Size   int    `json:"size"`
+	Hash   string `json:"hash"`
+}
+
+// IndexPath はインデックスファイルのパスを返す（block.jsonl と同じディレクトリの block.jsonl.idx）
+func (s *BlockStore) IndexPath() string {
+	return s.path + ".idx"
+}
+
+// LoadIndex はインデックスファイルを読み込み、ブロックの位置を古い順に返す
+// 以降の Append・ReplaceAll ではインデックスファイルも更新し、ReadBlock で位置を指定して読めるようになる
+// ファイルがない、または block.jsonl と一致しない（行数・末尾のブロックが違う）場合は ErrStaleIndex を返す
+func (s *BlockStore) LoadIndex() ([]IndexEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.flushLocked(); err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(s.IndexPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s not found", ErrStaleIndex, s.IndexPath())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read index file: %w", err)
+	}
+
+	entries := []IndexEntry{}
+	for i, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var e IndexEntry
+		if err := json.Unmarshal(line, &e); err != nil {
+			return nil, fmt.Errorf("%w: line %d: %v", ErrStaleIndex, i+1, err)
+		}
+		entries = append(entries, e)
+	}
+
+	size, err := s.validateIndexLocked(entries)
+	if err != nil {
+		return nil, err
+	}
+
+	s.indexed, s.entries, s.size = true, entries, size
+	return append([]IndexEntry(nil), entries...), nil
+}
+
+// validateIndexLocked は entries が block.jsonl の現在の内容と一致するかを確認し、block.jsonl のサイズを返す
+// 位置が重ならず昇順に並び、末尾のブロックの後ろに改行以外がなく、末尾のブロックのハッシュが一致すれば一致とみなす
+func (s *BlockStore) validateIndexLocked(entries []IndexEntry) (int64, error) {
+	info, err := os.Stat(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		if len(entries) == 0 {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("%w: block file not found", ErrStaleIndex)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat block file: %w", err)
+	}
+	size := info.Size()
+
+	var end int64
+	for i, e := range entries {
+		if e.Offset < end || e.Size <= 0 {
+			return 0, fmt.Errorf("%w: entry %d overlaps the previous block", ErrStaleIndex, i)
+		}
+		end = e.Offset + int64(e.Size)
+	}
+	if end > size {
+		return 0, fmt.Errorf("%w: index covers %d bytes but block file has %d", ErrStaleIndex, end, size)
+	}
+
+	f, err := os.Open(s.path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	// 末尾のブロックより後ろに追記されたブロックがないこと
+	tail := make([]byte, size-end)
+	if _, err := f.ReadAt(tail, end); err != nil {
+		return 0, fmt.Errorf("failed to read file: %w", err)
+	}
+	if len(bytes.Trim(tail, "\r\n")) > 0 {
+		return 0, fmt.Errorf("%w: block file has data after the last indexed block", ErrStaleIndex)
+	}
+
+	if len(entries) > 0 {
+		if _, err := s.readBlockLocked(entries[len(entries)-1]); err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrStaleIndex, err)
+		}
+	}
+	return size, nil
+}
+
+// RebuildIndex は block.jsonl を全て読んでインデックスファイルを作り直し、ブロックの位置を古い順に返す
+// LoadIndex と同様に以降の追記でインデックスファイルを更新する。不正な行が1つでもあればエラーを返す
+func (s *BlockStore) RebuildIndex() ([]IndexEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := []IndexEntry{}
+	var size int64
+	err := s.scanFileLocked(func(lineNo int, offset int64, line []byte, lineErr error) error {
+		if lineErr != nil {
+			return fmt.Errorf("block at line %d: %w", lineNo, lineErr)
+		}
+		var block core.Block
+		if err := json.Unmarshal(line, &block); err != nil {
+			return fmt.Errorf("failed to unmarshal block at line %d: %w", lineNo, err)
+		}
+		entries = append(entries, IndexEntry{Index: block.Header.Index, Offset: offset, Size: len(line), Hash: block.Header.Hash})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(s.path); err == nil {
+		size = info.Size()
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("failed to stat block file: %w", err)
+	}
+
+	s.indexed, s.entries, s.size = true, entries, size
+	if err := writeFileAtomic(s.IndexPath(), encodeIndex(entries)); err != nil {
+		return nil, fmt.Errorf("failed to write index file: %w", err)
+	}
+	return append([]IndexEntry(nil), entries...), nil
+}
+
+// ReadBlock は position 番目（0 始まり）のブロックをインデックスの位置から読み込む（LoadIndex・RebuildIndex の後で使う）
+func (s *BlockStore) ReadBlock(position int) (*core.Block, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.indexed {
+		return nil, fmt.Errorf("block index is not loaded")
+	}
+	if position < 0 || position >= len(s.entries) {
+		return nil, fmt.Errorf("block position out of range: %d", position)
+	}
+	// バッファ済みの追記も読めるよう先に書き出す
+	if err := s.flushLocked(); err != nil {
+		return nil, err
+	}
+	return s.readBlockLocked(s.entries[position])
+}
+
+// readBlockLocked は e の位置の行を読んでブロックとして解釈し、ハッシュがインデックスと一致するかを確認する
+func (s *BlockStore) readBlockLocked(e IndexEntry) (*core.Block, error) {
+	if e.Size > s.maxBlockSize {
+		return nil, fmt.Errorf("block %d: %w (%d bytes, limit %d)", e.Index, ErrBlockTooLarge, e.Size, s.maxBlockSize)
+	}
+
+	f, err := os.Open(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer f.Close()
+
+	line := make([]byte, e.Size)
+	if _, err := f.ReadAt(line, e.Offset); err != nil {
+		return nil, fmt.Errorf("failed to read block %d: %w", e.Index, err)
+	}
+	var block core.Block
+	if err := json.Unmarshal(line, &block); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal block %d: %w", e.Index, err)
+	}
+	if block.Header.Hash != e.Hash || block.Header.Index != e.Index {
+		return nil, fmt.Errorf("block at offset %d does not match index entry %d", e.Offset, e.Index)
+	}
+	return &block, nil
+}
+
+// indexAppendedLocked は Append で書き込んだブロックの位置をインデックスに追加する
+// prefix は行の前に補った改行のバイト数、written は書き込んだバイト数（改行を含む）
+// インデックスファイルは作り直せるため、書き込みに失敗しても警告のみとする（次回起動時に ErrStaleIndex になる）
+func (s *BlockStore) indexAppendedLocked(b *core.Block, prefix, written int) {
+	if !s.indexed {
+		return
+	}
+	e := IndexEntry{
+		Index:  b.Header.Index,
+		Offset: s.size + int64(prefix),
+		Size:   written - prefix - 1,
+		Hash:   b.Header.Hash,
+	}
+	s.entries = append(s.entries, e)
+	s.size += int64(written)
+
+	if err := appendFile(s.IndexPath(), encodeIndex([]IndexEntry{e})); err != nil {
+		log.Printf("Warning: failed to append to block index: %v", err)
+	}
+}
+
+// writeIndexLocked はインデックスファイルを entries で書き直す（失敗すれば次回起動時に作り直すよう削除する）
+func (s *BlockStore) writeIndexLocked() {
+	if err := writeFileAtomic(s.IndexPath(), encodeIndex(s.entries)); err != nil {
+		log.Printf("Warning: failed to write block index: %v", err)
+		os.Remove(s.IndexPath())
+	}
+}
+
+// encodeIndex は entries をインデックスファイルの形式（1行1エントリの JSON）にする
+func encodeIndex(entries []IndexEntry) []byte {
+	var buf bytes.Buffer
+	for _, e := range entries {
+		data, _ := json.Marshal(e)
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
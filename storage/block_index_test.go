@@ -0,0 +1,151 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"signet/core"
+	"testing"
+	"time"
+)
+
+// newIndexTestBlocks はジェネシスから始まる count 個のブロックを作る
+func newIndexTestBlocks(count int) []*core.Block {
+	blocks := []*core.Block{core.NewGenesisBlock()}
+	for i := 1; i < count; i++ {
+		prev := blocks[len(blocks)-1]
+		blocks = append(blocks, core.NewBlock(i, prev.Header.Hash, core.BlockPayload{Type: "add_node"}))
+	}
+	return blocks
+}
+
+// assertIndexMatches は store の全ブロックを ReadBlock で読み、want と同じハッシュであることを確認する
+func assertIndexMatches(t *testing.T, store *BlockStore, entries []IndexEntry, want []*core.Block) {
+	t.Helper()
+
+	if len(entries) != len(want) {
+		t.Fatalf("index has %d entries, want %d", len(entries), len(want))
+	}
+	for i, b := range want {
+		if entries[i].Hash != b.Header.Hash || entries[i].Index != b.Header.Index {
+			t.Errorf("entry %d = %+v, want hash %s", i, entries[i], b.Header.Hash)
+		}
+		got, err := store.ReadBlock(i)
+		if err != nil {
+			t.Fatalf("ReadBlock(%d) error = %v", i, err)
+		}
+		if got.Header.Hash != b.Header.Hash {
+			t.Errorf("ReadBlock(%d) hash = %s, want %s", i, got.Header.Hash, b.Header.Hash)
+		}
+	}
+}
+
+func TestBlockStoreIndex(t *testing.T) {
+	for _, policy := range []SyncPolicy{SyncAlways, SyncInterval} {
+		t.Run(string(policy), func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "block.jsonl")
+			blocks := newIndexTestBlocks(5)
+
+			store := NewBlockStore(filePath)
+			if err := store.SetSyncPolicy(policy, time.Hour); err != nil {
+				t.Fatalf("SetSyncPolicy() error = %v", err)
+			}
+			defer store.Close()
+
+			if _, err := store.LoadIndex(); !errors.Is(err, ErrStaleIndex) {
+				t.Fatalf("LoadIndex() without index file error = %v, want ErrStaleIndex", err)
+			}
+			if _, err := store.RebuildIndex(); err != nil {
+				t.Fatalf("RebuildIndex() error = %v", err)
+			}
+			for _, b := range blocks[:3] {
+				if err := store.Append(b); err != nil {
+					t.Fatalf("Append() error = %v", err)
+				}
+			}
+			if err := store.ReplaceAll(blocks[:2]); err != nil {
+				t.Fatalf("ReplaceAll() error = %v", err)
+			}
+			for _, b := range blocks[2:] {
+				if err := store.Append(b); err != nil {
+					t.Fatalf("Append() error = %v", err)
+				}
+			}
+			if err := store.Close(); err != nil {
+				t.Fatalf("Close() error = %v", err)
+			}
+
+			// 追記・置換で更新したインデックスファイルがそのまま使える
+			reopened := NewBlockStore(filePath)
+			entries, err := reopened.LoadIndex()
+			if err != nil {
+				t.Fatalf("LoadIndex() error = %v", err)
+			}
+			assertIndexMatches(t, reopened, entries, blocks)
+		})
+	}
+}
+
+func TestBlockStoreIndex_Stale(t *testing.T) {
+	blocks := newIndexTestBlocks(4)
+
+	tests := []struct {
+		name   string
+		modify func(t *testing.T, filePath string, store *BlockStore)
+	}{
+		{"missing", func(t *testing.T, filePath string, store *BlockStore) {
+			os.Remove(store.IndexPath())
+		}},
+		{"block appended without index", func(t *testing.T, filePath string, store *BlockStore) {
+			data, _ := encodeJSON(blocks[3])
+			if err := appendFile(filePath, append(data, '\n')); err != nil {
+				t.Fatalf("appendFile() error = %v", err)
+			}
+		}},
+		{"block file replaced", func(t *testing.T, filePath string, store *BlockStore) {
+			if err := NewBlockStore(filePath).ReplaceAll(blocks[:2]); err != nil {
+				t.Fatalf("ReplaceAll() error = %v", err)
+			}
+		}},
+		{"corrupted index", func(t *testing.T, filePath string, store *BlockStore) {
+			if err := writeFile(store.IndexPath(), "not json\n"); err != nil {
+				t.Fatalf("writeFile() error = %v", err)
+			}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "block.jsonl")
+			store := NewBlockStore(filePath)
+			if _, err := store.RebuildIndex(); err != nil {
+				t.Fatalf("RebuildIndex() error = %v", err)
+			}
+			for _, b := range blocks[:3] {
+				if err := store.Append(b); err != nil {
+					t.Fatalf("Append() error = %v", err)
+				}
+			}
+			tt.modify(t, filePath, store)
+
+			reopened := NewBlockStore(filePath)
+			if _, err := reopened.LoadIndex(); !errors.Is(err, ErrStaleIndex) {
+				t.Fatalf("LoadIndex() error = %v, want ErrStaleIndex", err)
+			}
+
+			// 作り直したインデックスは block.jsonl を全て読んだ結果と一致する
+			entries, err := reopened.RebuildIndex()
+			if err != nil {
+				t.Fatalf("RebuildIndex() error = %v", err)
+			}
+			want, err := reopened.LoadAll()
+			if err != nil {
+				t.Fatalf("LoadAll() error = %v", err)
+			}
+			assertIndexMatches(t, reopened, entries, want)
+			if _, err := NewBlockStore(filePath).LoadIndex(); err != nil {
+				t.Errorf("LoadIndex() after rebuild error = %v", err)
+			}
+		})
+	}
+}
@@ -1,79 +1,423 @@
 package storage
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"log"
 	"os"
 	"signet/core"
+	"sync"
+	"time"
 )
 
+// SyncPolicy はブロック追記時の永続化方針を表す
+type SyncPolicy string
+
+const (
+	// SyncAlways は追記ごとに fsync する（デフォルト）
+	SyncAlways SyncPolicy = "sync_always"
+	// SyncInterval は追記をバッファし、一定間隔でまとめて fsync する
+	// 間隔内の追記はクラッシュ時に失われうる
+	SyncInterval SyncPolicy = "sync_interval"
+)
+
+// ParseSyncPolicy は文字列から SyncPolicy をパースする
+func ParseSyncPolicy(s string) (SyncPolicy, error) {
+	switch SyncPolicy(s) {
+	case SyncAlways, SyncInterval:
+		return SyncPolicy(s), nil
+	default:
+		return "", fmt.Errorf("unknown sync policy: %s", s)
+	}
+}
+
+// DefaultMaxBlockSize は block.jsonl の1行（1ブロック）として読み書きするサイズの上限（バイト）のデフォルト値
+const DefaultMaxBlockSize = 1 << 20
+
+// ErrBlockTooLarge はブロックの JSON が上限（SetMaxBlockSize）を超えていることを表す
+var ErrBlockTooLarge = errors.New("block exceeds the maximum block size")
+
 // BlockStore はブロックチェーンの永続化を担当する
 type BlockStore struct {
 	path string
+
+	// maxBlockSize は1行（1ブロック）のサイズの上限（バイト）
+	maxBlockSize int
+
+	mu     sync.Mutex
+	policy SyncPolicy
+	file   *os.File      // SyncInterval で開いたままにする追記先
+	buf    *bufio.Writer // file へのバッファ
+	stop   chan struct{} // 定期 fsync の停止
+	done   chan struct{}
+
+	// indexed は LoadIndex・RebuildIndex でインデックスファイルを使い始めたかを表す
+	// entries は各ブロックの位置、size は block.jsonl のサイズ（バッファ済みの追記を含む）
+	indexed bool
+	entries []IndexEntry
+	size    int64
 }
 
-// NewBlockStore は新しいBlockStoreを作成する
+// NewBlockStore は新しいBlockStoreを作成する（SyncAlways）
 func NewBlockStore(path string) *BlockStore {
-	return &BlockStore{path: path}
+	return &BlockStore{path: path, policy: SyncAlways, maxBlockSize: DefaultMaxBlockSize}
+}
+
+// SetMaxBlockSize は1行（1ブロック）のサイズの上限を設定する（0 以下で DefaultMaxBlockSize）
+// 読み込み時に上限を超える行があればエラーとし、上限を超えるブロックは追記しない
+func (s *BlockStore) SetMaxBlockSize(size int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if size <= 0 {
+		size = DefaultMaxBlockSize
+	}
+	s.maxBlockSize = size
+}
+
+// SetSyncPolicy は永続化方針を設定する
+// SyncInterval の場合は interval ごとにバッファを書き出して fsync する goroutine を起動する
+func (s *BlockStore) SetSyncPolicy(policy SyncPolicy, interval time.Duration) error {
+	if policy == SyncInterval && interval <= 0 {
+		return fmt.Errorf("sync interval must be positive")
+	}
+	if err := s.Close(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.policy = policy
+	if policy == SyncInterval {
+		s.stop = make(chan struct{})
+		s.done = make(chan struct{})
+		go s.flushLoop(interval, s.stop, s.done)
+	}
+	return nil
+}
+
+// flushLoop は interval ごとにバッファを書き出す
+func (s *BlockStore) flushLoop(interval time.Duration, stop, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if err := s.Flush(); err != nil {
+				log.Printf("Warning: failed to flush block file: %v", err)
+			}
+		}
+	}
+}
+
+// Flush はバッファ済みの追記をファイルに書き出して fsync する
+func (s *BlockStore) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.flushLocked()
+}
+
+func (s *BlockStore) flushLocked() error {
+	if s.buf == nil || s.buf.Buffered() == 0 {
+		return nil
+	}
+	if err := s.buf.Flush(); err != nil {
+		return fmt.Errorf("failed to flush buffer: %w", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync file: %w", err)
+	}
+	return nil
+}
+
+// closeFileLocked はバッファを書き出して追記先ファイルを閉じる
+func (s *BlockStore) closeFileLocked() error {
+	if s.file == nil {
+		return nil
+	}
+	err := s.flushLocked()
+	if cerr := s.file.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("failed to close file: %w", cerr)
+	}
+	s.file, s.buf = nil, nil
+	return err
+}
+
+// Close は定期 fsync を停止し、バッファ済みの追記をすべて書き出す
+func (s *BlockStore) Close() error {
+	s.mu.Lock()
+	stop, done := s.stop, s.done
+	s.stop, s.done = nil, nil
+	s.mu.Unlock()
+
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.closeFileLocked()
+}
+
+// LoadError は LoadAllLenient で読み飛ばした block.jsonl の行を表す
+type LoadError struct {
+	Line int   // 1始まりの行番号
+	Err  error // JSON として解釈できない、またはサイズの上限を超えている
+}
+
+func (e LoadError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e LoadError) Unwrap() error {
+	return e.Err
 }
 
 // LoadAll は全ブロックを読み込む
-// ファイルが存在しない場合は空スライスを返す
+// ファイルが存在しない場合は空スライスを返す。不正な行が1つでもあればエラーを返す
 func (s *BlockStore) LoadAll() ([]*core.Block, error) {
-	// ファイルが存在しない場合は空スライスを返す
-	_, err := os.Stat(s.path)
-	if errors.Is(err, os.ErrNotExist) {
-		return []*core.Block{}, nil
+	blocks := []*core.Block{}
+	err := s.scanFile(func(lineNo int, _ int64, line []byte, lineErr error) error {
+		if lineErr != nil {
+			return fmt.Errorf("block at line %d: %w", lineNo, lineErr)
+		}
+		var block core.Block
+		if err := json.Unmarshal(line, &block); err != nil {
+			return fmt.Errorf("failed to unmarshal block at line %d: %w", lineNo, err)
+		}
+		blocks = append(blocks, &block)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return blocks, nil
+}
+
+// LoadAllLenient は LoadAll と同様に全ブロックを読み込むが、不正な行（JSON として解釈できない・サイズの上限を超える）は
+// 読み飛ばして行番号とともに返す（破損したファイルから読めるブロックを回収するため）
+// ブロック同士の連結は検証しないため、呼び出し側でチェーンとして検証すること。error はファイルを読めない場合のみ返す
+func (s *BlockStore) LoadAllLenient() ([]*core.Block, []LoadError, error) {
+	blocks := []*core.Block{}
+	var loadErrs []LoadError
+	err := s.scanFile(func(lineNo int, _ int64, line []byte, lineErr error) error {
+		if lineErr != nil {
+			loadErrs = append(loadErrs, LoadError{Line: lineNo, Err: lineErr})
+			return nil
+		}
+		var block core.Block
+		if err := json.Unmarshal(line, &block); err != nil {
+			loadErrs = append(loadErrs, LoadError{Line: lineNo, Err: fmt.Errorf("failed to unmarshal block: %w", err)})
+			return nil
+		}
+		blocks = append(blocks, &block)
+		return nil
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to stat file: %w", err)
+		return nil, nil, err
+	}
+	return blocks, loadErrs, nil
+}
+
+// scanFile はバッファ済みの追記を書き出してから block.jsonl の各行を scanBlockLines で fn に渡す
+// ファイルが存在しない場合は何もしない
+func (s *BlockStore) scanFile(fn func(lineNo int, offset int64, line []byte, lineErr error) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.scanFileLocked(fn)
+}
+
+func (s *BlockStore) scanFileLocked(fn func(lineNo int, offset int64, line []byte, lineErr error) error) error {
+	// バッファ済みの追記も読めるよう先に書き出す
+	if err := s.flushLocked(); err != nil {
+		return err
 	}
 
-	data, err := readFile(s.path)
+	f, err := os.Open(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to read file: %w", err)
+		return fmt.Errorf("failed to open file: %w", err)
 	}
+	defer f.Close()
+
+	return scanBlockLines(f, s.maxBlockSize, fn)
+}
+
+// scanBlockLines は r を1行ずつ読み、空行以外を行番号（1始まり）と行頭のオフセット（バイト）とともに fn に渡す
+// CRLF の改行にも対応する。maxSize バイトを超える行はメモリに保持せずに読み飛ばし、ErrBlockTooLarge を lineErr として渡す
+func scanBlockLines(r io.Reader, maxSize int, fn func(lineNo int, offset int64, line []byte, lineErr error) error) error {
+	br := bufio.NewReaderSize(r, min(maxSize+2, 64*1024))
+	var offset int64
+	for lineNo := 1; ; lineNo++ {
+		line, size, consumed, err := readBlockLine(br, maxSize)
+		if err != nil && err != io.EOF {
+			return fmt.Errorf("failed to read file: %w", err)
+		}
+
+		switch {
+		case size > maxSize:
+			lineErr := fmt.Errorf("%w (%d bytes, limit %d)", ErrBlockTooLarge, size, maxSize)
+			if err := fn(lineNo, offset, nil, lineErr); err != nil {
+				return err
+			}
+		case size > 0:
+			if err := fn(lineNo, offset, line, nil); err != nil {
+				return err
+			}
+		}
+		offset += int64(consumed)
 
-	if len(data) == 0 {
-		return []*core.Block{}, nil
+		if err == io.EOF {
+			return nil
+		}
 	}
+}
 
-	var blocks []*core.Block
-	lines := splitLines(data)
-	for i, line := range lines {
-		if len(line) == 0 {
+// readBlockLine は改行までの1行を読み、行末の改行（\n・\r\n）を除いた内容とそのバイト数、改行を含めて読んだバイト数を返す
+// maxSize バイトを超える行は内容を返さずに読み飛ばす。最後の行を読み終えると io.EOF を返す
+func readBlockLine(br *bufio.Reader, maxSize int) ([]byte, int, int, error) {
+	var line []byte
+	size := 0
+	for {
+		chunk, err := br.ReadSlice('\n')
+		size += len(chunk)
+		if size <= maxSize+2 {
+			line = append(line, chunk...)
+		} else {
+			line = nil
+		}
+		if err == bufio.ErrBufferFull {
 			continue
 		}
-		var block core.Block
-		if err := json.Unmarshal(line, &block); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal block at line %d: %w", i+1, err)
+		consumed := size
+
+		switch {
+		case bytes.HasSuffix(chunk, []byte("\r\n")):
+			size -= 2
+		case bytes.HasSuffix(chunk, []byte("\n")):
+			size--
 		}
-		blocks = append(blocks, &block)
+		if size <= maxSize {
+			line = bytes.TrimSuffix(bytes.TrimSuffix(line, []byte("\n")), []byte("\r"))
+		}
+		return line, size, consumed, err
 	}
-
-	return blocks, nil
 }
 
 // Append はブロックを1行追記する
 func (s *BlockStore) Append(b *core.Block) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	data, err := json.Marshal(b)
 	if err != nil {
 		return fmt.Errorf("failed to marshal block: %w", err)
 	}
+	// 読み込めなくなるブロックは書き込まない
+	if len(data) > s.maxBlockSize {
+		return fmt.Errorf("failed to append block %d: %w (%d bytes, limit %d)", b.Header.Index, ErrBlockTooLarge, len(data), s.maxBlockSize)
+	}
 
 	// 改行を追加して追記
 	data = append(data, '\n')
-	if err := appendFile(s.path, data); err != nil {
+
+	if s.policy == SyncInterval {
+		if s.file == nil {
+			if err := s.openForAppendLocked(); err != nil {
+				return err
+			}
+		}
+		if _, err := s.buf.Write(data); err != nil {
+			return fmt.Errorf("failed to append to file: %w", err)
+		}
+		s.indexAppendedLocked(b, 0, len(data))
+		return nil
+	}
+
+	// 末尾改行のないファイル（外部ツールで編集された等）に追記すると前の行と連結されるため補う
+	terminated, err := endsWithNewline(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to check file ending: %w", err)
+	}
+	prefix := 0
+	if !terminated {
+		data = append([]byte{'\n'}, data...)
+		prefix = 1
+	}
+
+	if err := appendFileSync(s.path, data); err != nil {
 		return fmt.Errorf("failed to append to file: %w", err)
 	}
+	s.indexAppendedLocked(b, prefix, len(data))
+
+	return nil
+}
+
+// openForAppendLocked は SyncInterval 用に追記先ファイルを開いたままにする
+func (s *BlockStore) openForAppendLocked() error {
+	terminated, err := endsWithNewline(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to check file ending: %w", err)
+	}
 
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open file: %w", err)
+	}
+	s.file = f
+	s.buf = bufio.NewWriter(f)
+
+	if !terminated {
+		s.buf.WriteByte('\n')
+		s.size++
+	}
 	return nil
 }
 
+// TruncateAfter はインデックスが index より大きいブロックをファイルから取り除く
+// ReplaceAll と同じく一時ファイルへの書き込みとリネームで置き換える
+func (s *BlockStore) TruncateAfter(index int) error {
+	if index < 0 {
+		return fmt.Errorf("cannot truncate past the genesis block: index %d", index)
+	}
+
+	blocks, err := s.LoadAll()
+	if err != nil {
+		return err
+	}
+	if index+1 >= len(blocks) {
+		return nil
+	}
+	return s.ReplaceAll(blocks[:index+1])
+}
+
 // ReplaceAll は全ブロックを書き直す（最長チェーンルール用）
 // 一時ファイルに書いてrenameすることでアトミック性を確保
 func (s *BlockStore) ReplaceAll(blocks []*core.Block) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 開いたままの追記先は置換後のファイルを指さなくなるため閉じる
+	if err := s.closeFileLocked(); err != nil {
+		return err
+	}
+
 	// 一時ファイルパス
 	tmpPath := s.path + ".tmp"
 
@@ -84,12 +428,16 @@ func (s *BlockStore) ReplaceAll(blocks []*core.Block) error {
 	}
 	defer f.Close()
 
-	// 全ブロックを書き込み
+	// 全ブロックを書き込み（インデックスを使っていれば各ブロックの位置も記録する）
+	var entries []IndexEntry
+	var offset int64
 	for _, b := range blocks {
 		data, err := json.Marshal(b)
 		if err != nil {
 			return fmt.Errorf("failed to marshal block: %w", err)
 		}
+		entries = append(entries, IndexEntry{Index: b.Header.Index, Offset: offset, Size: len(data), Hash: b.Header.Hash})
+		offset += int64(len(data)) + 1
 		if _, err := f.Write(data); err != nil {
 			return fmt.Errorf("failed to write block: %w", err)
 		}
@@ -108,5 +456,10 @@ func (s *BlockStore) ReplaceAll(blocks []*core.Block) error {
 		return fmt.Errorf("failed to rename file: %w", err)
 	}
 
+	if s.indexed {
+		s.entries, s.size = entries, offset
+		s.writeIndexLocked()
+	}
+
 	return nil
 }
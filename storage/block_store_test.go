@@ -1,9 +1,12 @@
 package storage
 
 import (
+	"encoding/json"
+	"errors"
 	"os"
 	"path/filepath"
 	"signet/core"
+	"strings"
 	"testing"
 	"time"
 )
@@ -82,7 +85,145 @@ func TestBlockStoreLoadAll(t *testing.T) {
 	})
 }
 
+func TestBlockStoreLoadAll_LineEndings(t *testing.T) {
+	block1 := core.NewBlock(0, "0", core.BlockPayload{Type: "add_node"})
+	block2 := core.NewBlock(1, block1.Header.Hash, core.BlockPayload{Type: "add_node"})
+	data1, _ := encodeJSON(block1)
+	data2, _ := encodeJSON(block2)
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"CRLF terminated", string(data1) + "\r\n" + string(data2) + "\r\n"},
+		{"CRLF with blank line", string(data1) + "\r\n\r\n" + string(data2) + "\r\n"},
+		{"no trailing newline", string(data1) + "\n" + string(data2)},
+		{"CRLF without trailing newline", string(data1) + "\r\n" + string(data2)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "blocks.jsonl")
+			if err := writeFile(filePath, tt.content); err != nil {
+				t.Fatalf("failed to write test file: %v", err)
+			}
+
+			blocks, err := NewBlockStore(filePath).LoadAll()
+			if err != nil {
+				t.Fatalf("LoadAll() error = %v", err)
+			}
+			if len(blocks) != 2 {
+				t.Fatalf("LoadAll() returned %d blocks, want 2", len(blocks))
+			}
+			if blocks[1].Header.Hash != block2.Header.Hash {
+				t.Errorf("blocks[1].Header.Hash = %s, want %s", blocks[1].Header.Hash, block2.Header.Hash)
+			}
+		})
+	}
+}
+
+func TestBlockStoreLoadAll_MaxBlockSize(t *testing.T) {
+	block1 := core.NewBlock(0, "0", core.BlockPayload{Type: "add_node"})
+	block2 := core.NewBlock(1, block1.Header.Hash, core.BlockPayload{Type: "add_node", Data: json.RawMessage(`"` + strings.Repeat("x", 2048) + `"`)})
+	data1, _ := encodeJSON(block1)
+	data2, _ := encodeJSON(block2)
+
+	filePath := filepath.Join(t.TempDir(), "blocks.jsonl")
+	if err := writeFile(filePath, string(data1)+"\n\n"+string(data2)+"\n"); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	store := NewBlockStore(filePath)
+
+	// 上限内なら読める
+	if blocks, err := store.LoadAll(); err != nil || len(blocks) != 2 {
+		t.Fatalf("LoadAll() = %d blocks, %v, want 2 blocks", len(blocks), err)
+	}
+
+	// 上限を超える行は行番号を示して拒否する（空行も行番号に数える）
+	store.SetMaxBlockSize(1024)
+	_, err := store.LoadAll()
+	if !errors.Is(err, ErrBlockTooLarge) || !strings.Contains(err.Error(), "line 3") {
+		t.Errorf("LoadAll() error = %v, want ErrBlockTooLarge at line 3", err)
+	}
+
+	// 上限を超えるブロックは追記しない
+	if err := store.Append(block2); !errors.Is(err, ErrBlockTooLarge) {
+		t.Errorf("Append() error = %v, want ErrBlockTooLarge", err)
+	}
+}
+
+func TestBlockStoreLoadAllLenient(t *testing.T) {
+	block1 := core.NewBlock(0, "0", core.BlockPayload{Type: "add_node"})
+	block2 := core.NewBlock(1, block1.Header.Hash, core.BlockPayload{Type: "add_node"})
+	block3 := core.NewBlock(2, block2.Header.Hash, core.BlockPayload{Type: "add_node"})
+	data1, _ := encodeJSON(block1)
+	data2, _ := encodeJSON(block2)
+	data3, _ := encodeJSON(block3)
+
+	// 2行目が壊れた JSON、4行目がサイズの上限を超える行
+	filePath := filepath.Join(t.TempDir(), "blocks.jsonl")
+	content := string(data1) + "\n" + `{"header":{"index":1,` + "\n" + string(data2) + "\n" + strings.Repeat("x", 4096) + "\r\n" + string(data3) + "\n"
+	if err := writeFile(filePath, content); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	store := NewBlockStore(filePath)
+	store.SetMaxBlockSize(1024)
+
+	// 厳密な読み込みは失敗する
+	if _, err := store.LoadAll(); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("LoadAll() error = %v, want failure at line 2", err)
+	}
+
+	blocks, loadErrs, err := store.LoadAllLenient()
+	if err != nil {
+		t.Fatalf("LoadAllLenient() error = %v", err)
+	}
+	if len(blocks) != 3 {
+		t.Fatalf("LoadAllLenient() returned %d blocks, want 3", len(blocks))
+	}
+	for i, want := range []*core.Block{block1, block2, block3} {
+		if blocks[i].Header.Hash != want.Header.Hash {
+			t.Errorf("blocks[%d].Header.Hash = %s, want %s", i, blocks[i].Header.Hash, want.Header.Hash)
+		}
+	}
+	if len(loadErrs) != 2 || loadErrs[0].Line != 2 || loadErrs[1].Line != 4 {
+		t.Fatalf("LoadAllLenient() errors = %v, want lines 2 and 4", loadErrs)
+	}
+	if !errors.Is(loadErrs[1], ErrBlockTooLarge) {
+		t.Errorf("loadErrs[1] = %v, want ErrBlockTooLarge", loadErrs[1])
+	}
+
+	// ファイルが存在しない場合は空
+	blocks, loadErrs, err = NewBlockStore(filepath.Join(t.TempDir(), "missing.jsonl")).LoadAllLenient()
+	if err != nil || len(blocks) != 0 || len(loadErrs) != 0 {
+		t.Errorf("LoadAllLenient() on missing file = %d blocks, %v, %v, want empty", len(blocks), loadErrs, err)
+	}
+}
+
 func TestBlockStoreAppend(t *testing.T) {
+	t.Run("append to file without trailing newline", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "blocks.jsonl")
+		block1 := core.NewBlock(0, "0", core.BlockPayload{Type: "add_node"})
+		data1, _ := encodeJSON(block1)
+		if err := writeFile(filePath, string(data1)); err != nil {
+			t.Fatalf("failed to write test file: %v", err)
+		}
+
+		store := NewBlockStore(filePath)
+		block2 := core.NewBlock(1, block1.Header.Hash, core.BlockPayload{Type: "add_node"})
+		if err := store.Append(block2); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+
+		blocks, err := store.LoadAll()
+		if err != nil {
+			t.Fatalf("LoadAll() error = %v", err)
+		}
+		if len(blocks) != 2 {
+			t.Errorf("LoadAll() returned %d blocks, want 2", len(blocks))
+		}
+	})
+
 	t.Run("append block to new file", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		filePath := filepath.Join(tmpDir, "blocks.jsonl")
@@ -225,3 +366,166 @@ func TestBlockStoreReplaceAll(t *testing.T) {
 		}
 	})
 }
+
+func TestBlockStoreTruncateAfter(t *testing.T) {
+	store := NewBlockStore(filepath.Join(t.TempDir(), "blocks.jsonl"))
+	prev := "0"
+	for i := 0; i < 4; i++ {
+		b := core.NewBlock(i, prev, core.BlockPayload{Type: "add_node"})
+		if err := store.Append(b); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+		prev = b.Header.Hash
+	}
+
+	if err := store.TruncateAfter(1); err != nil {
+		t.Fatalf("TruncateAfter(1) error = %v", err)
+	}
+	blocks, err := store.LoadAll()
+	if err != nil {
+		t.Fatalf("LoadAll() error = %v", err)
+	}
+	if len(blocks) != 2 || blocks[1].Header.Index != 1 {
+		t.Errorf("LoadAll() returned %d blocks, want indexes 0 and 1", len(blocks))
+	}
+
+	// 切り詰めた後も追記できる
+	if err := store.Append(core.NewBlock(2, blocks[1].Header.Hash, core.BlockPayload{Type: "add_node"})); err != nil {
+		t.Fatalf("Append() after truncate error = %v", err)
+	}
+	if blocks, _ := store.LoadAll(); len(blocks) != 3 {
+		t.Errorf("LoadAll() returned %d blocks after append, want 3", len(blocks))
+	}
+
+	if err := store.TruncateAfter(-1); err == nil {
+		t.Error("TruncateAfter(-1) error = nil, want error")
+	}
+}
+
+func TestParseSyncPolicy(t *testing.T) {
+	for _, s := range []string{"sync_always", "sync_interval"} {
+		if p, err := ParseSyncPolicy(s); err != nil || string(p) != s {
+			t.Errorf("ParseSyncPolicy(%q) = %v, %v", s, p, err)
+		}
+	}
+	if _, err := ParseSyncPolicy("sometimes"); err == nil {
+		t.Error("ParseSyncPolicy() should fail for unknown policy")
+	}
+}
+
+func TestBlockStoreSyncInterval(t *testing.T) {
+	t.Run("appends are eventually flushed", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "blocks.jsonl")
+		store := NewBlockStore(filePath)
+		if err := store.SetSyncPolicy(SyncInterval, 20*time.Millisecond); err != nil {
+			t.Fatalf("SetSyncPolicy() error = %v", err)
+		}
+		defer store.Close()
+
+		block := core.NewBlock(0, "0", core.BlockPayload{Type: "add_node"})
+		if err := store.Append(block); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+
+		// 別の BlockStore（=ディスク上の内容）から見えるようになるまで待つ
+		deadline := time.Now().Add(2 * time.Second)
+		for {
+			data, _ := os.ReadFile(filePath)
+			if len(data) > 0 {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("appended block was not flushed to disk")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		blocks, err := NewBlockStore(filePath).LoadAll()
+		if err != nil {
+			t.Fatalf("LoadAll() error = %v", err)
+		}
+		if len(blocks) != 1 || blocks[0].Header.Hash != block.Header.Hash {
+			t.Errorf("LoadAll() = %d blocks, want the appended block", len(blocks))
+		}
+	})
+
+	t.Run("buffered appends are visible and flushed on close", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "blocks.jsonl")
+		store := NewBlockStore(filePath)
+		if err := store.SetSyncPolicy(SyncInterval, time.Hour); err != nil {
+			t.Fatalf("SetSyncPolicy() error = %v", err)
+		}
+
+		for i := 0; i < 3; i++ {
+			if err := store.Append(core.NewBlock(i, "prev", core.BlockPayload{Type: "add_node"})); err != nil {
+				t.Fatalf("Append() error = %v", err)
+			}
+		}
+
+		// 同じ BlockStore からは書き出し前でも読める
+		blocks, err := store.LoadAll()
+		if err != nil || len(blocks) != 3 {
+			t.Fatalf("LoadAll() = %d blocks, %v; want 3", len(blocks), err)
+		}
+
+		if err := store.Append(core.NewBlock(3, "prev", core.BlockPayload{Type: "add_node"})); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+		if err := store.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+
+		blocks, err = NewBlockStore(filePath).LoadAll()
+		if err != nil || len(blocks) != 4 {
+			t.Errorf("LoadAll() after Close = %d blocks, %v; want 4", len(blocks), err)
+		}
+	})
+
+	t.Run("replace all closes buffered file", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "blocks.jsonl")
+		store := NewBlockStore(filePath)
+		if err := store.SetSyncPolicy(SyncInterval, time.Hour); err != nil {
+			t.Fatalf("SetSyncPolicy() error = %v", err)
+		}
+		defer store.Close()
+
+		store.Append(core.NewBlock(0, "0", core.BlockPayload{Type: "add_node"}))
+		replacement := []*core.Block{
+			core.NewBlock(0, "0", core.BlockPayload{Type: "add_node"}),
+			core.NewBlock(1, "prev", core.BlockPayload{Type: "add_node"}),
+		}
+		if err := store.ReplaceAll(replacement); err != nil {
+			t.Fatalf("ReplaceAll() error = %v", err)
+		}
+		store.Append(core.NewBlock(2, "prev", core.BlockPayload{Type: "add_node"}))
+
+		blocks, err := store.LoadAll()
+		if err != nil || len(blocks) != 3 {
+			t.Errorf("LoadAll() = %d blocks, %v; want 3", len(blocks), err)
+		}
+	})
+}
+
+func benchmarkBlockStoreAppend(b *testing.B, policy SyncPolicy) {
+	store := NewBlockStore(filepath.Join(b.TempDir(), "blocks.jsonl"))
+	if err := store.SetSyncPolicy(policy, 100*time.Millisecond); err != nil {
+		b.Fatalf("SetSyncPolicy() error = %v", err)
+	}
+	defer store.Close()
+
+	block := core.NewBlock(1, "prev", core.BlockPayload{Type: "add_node"})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := store.Append(block); err != nil {
+			b.Fatalf("Append() error = %v", err)
+		}
+	}
+}
+
+func BenchmarkBlockStoreAppend_SyncAlways(b *testing.B) {
+	benchmarkBlockStoreAppend(b, SyncAlways)
+}
+
+func BenchmarkBlockStoreAppend_SyncInterval(b *testing.B) {
+	benchmarkBlockStoreAppend(b, SyncInterval)
+}
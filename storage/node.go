@@ -1,5 +1,10 @@
 package storage
 
+import (
+	"fmt"
+	"signet/crypto"
+)
+
 // NodeInfo はピアノードの情報を表す
 type NodeInfo struct {
 	Name      string `json:"name"`
@@ -7,3 +12,17 @@ type NodeInfo struct {
 	Address   string `json:"address"`
 	PublicKey string `json:"public_key"`
 }
+
+// Validate はノード情報の必須項目と公開鍵の形式を検証する
+func (n *NodeInfo) Validate() error {
+	if n.Name == "" {
+		return fmt.Errorf("node name is empty")
+	}
+	if n.Address == "" {
+		return fmt.Errorf("address is empty")
+	}
+	if _, err := crypto.HexToPublicKey(n.PublicKey); err != nil {
+		return fmt.Errorf("invalid public key: %w", err)
+	}
+	return nil
+}
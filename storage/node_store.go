@@ -2,6 +2,7 @@ package storage
 
 import (
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 	"signet/config"
@@ -9,6 +10,10 @@ import (
 )
 
 // NodeStore はノード情報の永続化を担当する
+//
+// ノード名は大文字・小文字を保持したまま保存するが、大文字・小文字の違いだけの
+// 名前（例: Alice と alice）は同一ノードとみなし、別ノードとしての登録を拒否する。
+// 大文字・小文字を区別しないファイルシステムでもノードファイルが衝突しないようにするため
 type NodeStore struct {
 	dir string // nodesディレクトリパス
 }
@@ -39,6 +44,11 @@ func (s *NodeStore) Save(nodeName string, info *NodeInfo) error {
 		return fmt.Errorf("invalid node name: %w", err)
 	}
 
+	// 内容の検証（不正なノード情報を保存しない）
+	if err := info.Validate(); err != nil {
+		return fmt.Errorf("invalid node info: %w", err)
+	}
+
 	// ディレクトリが存在しない場合は作成
 	if err := os.MkdirAll(s.dir, 0755); err != nil {
 		return fmt.Errorf("failed to create nodes directory: %w", err)
@@ -49,6 +59,11 @@ func (s *NodeStore) Save(nodeName string, info *NodeInfo) error {
 	content += fmt.Sprintf("Address = \"%s\"\n", info.Address)
 	content += fmt.Sprintf("Ed25519PublicKey = \"%s\"\n", info.PublicKey)
 
+	// 大文字・小文字違いの既存ノードがあれば別ノードとして保存しない
+	if existing := s.CaseConflict(nodeName); existing != "" {
+		return fmt.Errorf("node name conflicts with existing node %s (names are case-insensitive)", existing)
+	}
+
 	filePath := filepath.Join(s.dir, nodeName)
 	if err := writeFile(filePath, content); err != nil {
 		return fmt.Errorf("failed to write node file: %w", err)
@@ -76,6 +91,10 @@ func (s *NodeStore) Load(nodeName string) (*NodeInfo, error) {
 		PublicKey: values["Ed25519PublicKey"],
 	}
 
+	if err := info.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid node info: %w", err)
+	}
+
 	return info, nil
 }
 
@@ -92,16 +111,25 @@ func (s *NodeStore) LoadAll() (map[string]*NodeInfo, error) {
 	}
 
 	result := make(map[string]*NodeInfo)
+	seen := make(map[string]string) // 小文字化した名前 -> 採用したノード名
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
 		}
 		nodeName := entry.Name()
+		// 大文字・小文字違いの重複ファイルは名前順で最初のものだけを採用する
+		if first, ok := seen[strings.ToLower(nodeName)]; ok {
+			log.Printf("Warning: skipping node file %s: conflicts with %s (names are case-insensitive)", nodeName, first)
+			continue
+		}
 		info, err := s.Load(nodeName)
 		if err != nil {
-			return nil, fmt.Errorf("failed to load node %s: %w", nodeName, err)
+			// 不正なノードファイルはスキップして他のノードの読み込みを続ける
+			log.Printf("Warning: skipping invalid node file %s: %v", nodeName, err)
+			continue
 		}
 		result[nodeName] = info
+		seen[strings.ToLower(nodeName)] = nodeName
 	}
 
 	return result, nil
@@ -128,3 +156,19 @@ func (s *NodeStore) Exists(nodeName string) bool {
 	_, err := os.Stat(filePath)
 	return err == nil
 }
+
+// CaseConflict は nodeName と大文字・小文字だけが異なる既存ノード名を返す
+// 衝突がなければ空文字列を返す（同名のノードは衝突とみなさない）
+func (s *NodeStore) CaseConflict(nodeName string) string {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() && name != nodeName && strings.EqualFold(name, nodeName) {
+			return name
+		}
+	}
+	return ""
+}
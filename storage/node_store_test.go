@@ -3,9 +3,13 @@ package storage
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
+// testPubKey はテスト用の正しい形式（hex 32バイト）の公開鍵
+var testPubKey = strings.Repeat("ab", 32)
+
 func TestNewNodeStore(t *testing.T) {
 	store := NewNodeStore("/test/nodes")
 	if store == nil {
@@ -25,7 +29,7 @@ func TestNodeStoreSave(t *testing.T) {
 			Name:      "node1",
 			NickName:  "田中",
 			Address:   "10.0.0.1",
-			PublicKey: "test_public_key",
+			PublicKey: testPubKey,
 		}
 
 		if err := store.Save("node1", info); err != nil {
@@ -40,7 +44,7 @@ func TestNodeStoreSave(t *testing.T) {
 
 		// 内容を確認
 		content, _ := readFile(filePath)
-		expectedContent := "NickName = \"田中\"\nAddress = \"10.0.0.1\"\nEd25519PublicKey = \"test_public_key\"\n"
+		expectedContent := "NickName = \"田中\"\nAddress = \"10.0.0.1\"\nEd25519PublicKey = \"" + testPubKey + "\"\n"
 		if string(content) != expectedContent {
 			t.Errorf("File content = %q, want %q", string(content), expectedContent)
 		}
@@ -55,7 +59,7 @@ func TestNodeStoreSave(t *testing.T) {
 			Name:      "node1",
 			NickName:  "Test",
 			Address:   "10.0.0.1",
-			PublicKey: "key",
+			PublicKey: testPubKey,
 		}
 
 		if err := store.Save("node1", info); err != nil {
@@ -73,7 +77,7 @@ func TestNodeStorePathTraversal(t *testing.T) {
 	tmpDir := t.TempDir()
 	store := NewNodeStore(tmpDir)
 
-	info := &NodeInfo{Name: "evil", NickName: "Evil", Address: "1.2.3.4", PublicKey: "key"}
+	info := &NodeInfo{Name: "evil", NickName: "Evil", Address: "1.2.3.4", PublicKey: testPubKey}
 
 	tests := []struct {
 		name     string
@@ -105,7 +109,7 @@ func TestNodeStoreLoad(t *testing.T) {
 			Name:      "node1",
 			NickName:  "田中",
 			Address:   "10.0.0.1",
-			PublicKey: "test_public_key",
+			PublicKey: testPubKey,
 		}
 		store.Save("node1", info)
 
@@ -121,8 +125,8 @@ func TestNodeStoreLoad(t *testing.T) {
 		if loaded.Address != "10.0.0.1" {
 			t.Errorf("Address = %v, want 10.0.0.1", loaded.Address)
 		}
-		if loaded.PublicKey != "test_public_key" {
-			t.Errorf("PublicKey = %v, want test_public_key", loaded.PublicKey)
+		if loaded.PublicKey != testPubKey {
+			t.Errorf("PublicKey = %v, want %v", loaded.PublicKey, testPubKey)
 		}
 	})
 
@@ -143,9 +147,9 @@ func TestNodeStoreLoadAll(t *testing.T) {
 		store := NewNodeStore(tmpDir)
 
 		// 複数のノードを保存
-		node1 := &NodeInfo{Name: "node1", NickName: "田中", Address: "10.0.0.1", PublicKey: "key1"}
-		node2 := &NodeInfo{Name: "node2", NickName: "佐藤", Address: "10.0.0.2", PublicKey: "key2"}
-		node3 := &NodeInfo{Name: "node3", NickName: "鈴木", Address: "10.0.0.3", PublicKey: "key3"}
+		node1 := &NodeInfo{Name: "node1", NickName: "田中", Address: "10.0.0.1", PublicKey: testPubKey}
+		node2 := &NodeInfo{Name: "node2", NickName: "佐藤", Address: "10.0.0.2", PublicKey: testPubKey}
+		node3 := &NodeInfo{Name: "node3", NickName: "鈴木", Address: "10.0.0.3", PublicKey: testPubKey}
 
 		store.Save("node1", node1)
 		store.Save("node2", node2)
@@ -173,6 +177,33 @@ func TestNodeStoreLoadAll(t *testing.T) {
 		}
 	})
 
+	t.Run("invalid node files are skipped", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		store := NewNodeStore(tmpDir)
+
+		valid := &NodeInfo{Name: "node1", NickName: "田中", Address: "10.0.0.1", PublicKey: testPubKey}
+		if err := store.Save("node1", valid); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+
+		// 公開鍵が不正なファイルを直接書き込む
+		content := "NickName = \"壊れた\"\nAddress = \"10.0.0.2\"\nEd25519PublicKey = \"not-hex\"\n"
+		if err := writeFile(filepath.Join(tmpDir, "broken"), content); err != nil {
+			t.Fatalf("writeFile() error = %v", err)
+		}
+
+		all, err := store.LoadAll()
+		if err != nil {
+			t.Fatalf("LoadAll() error = %v", err)
+		}
+		if len(all) != 1 {
+			t.Errorf("LoadAll() returned %d nodes, want 1", len(all))
+		}
+		if _, ok := all["broken"]; ok {
+			t.Error("LoadAll() should skip invalid node file")
+		}
+	})
+
 	t.Run("load from nonexistent directory returns empty map", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		store := NewNodeStore(filepath.Join(tmpDir, "nonexistent"))
@@ -202,13 +233,106 @@ func TestNodeStoreLoadAll(t *testing.T) {
 	})
 }
 
+func TestNodeStoreCaseInsensitiveNames(t *testing.T) {
+	t.Run("save rejects name differing only by case", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		store := NewNodeStore(tmpDir)
+
+		if err := store.Save("Alice", &NodeInfo{Name: "Alice", NickName: "アリス", Address: "10.0.0.1", PublicKey: testPubKey}); err != nil {
+			t.Fatalf("Save(Alice) error = %v", err)
+		}
+		if err := store.Save("alice", &NodeInfo{Name: "alice", NickName: "偽物", Address: "10.0.0.2", PublicKey: testPubKey}); err == nil {
+			t.Error("Save(alice) should fail when Alice exists")
+		}
+		// 同名での上書きは許可する
+		if err := store.Save("Alice", &NodeInfo{Name: "Alice", NickName: "アリス2", Address: "10.0.0.1", PublicKey: testPubKey}); err != nil {
+			t.Errorf("Save(Alice) overwrite error = %v", err)
+		}
+
+		all, err := store.LoadAll()
+		if err != nil {
+			t.Fatalf("LoadAll() error = %v", err)
+		}
+		if len(all) != 1 || all["Alice"] == nil {
+			t.Errorf("LoadAll() = %v, want only Alice", all)
+		}
+		if got := store.CaseConflict("ALICE"); got != "Alice" {
+			t.Errorf("CaseConflict(ALICE) = %q, want Alice", got)
+		}
+		if got := store.CaseConflict("Alice"); got != "" {
+			t.Errorf("CaseConflict(Alice) = %q, want empty", got)
+		}
+	})
+
+	t.Run("load all keeps only the first of case-duplicate files", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		store := NewNodeStore(tmpDir)
+
+		content := "NickName = \"x\"\nAddress = \"10.0.0.1\"\nEd25519PublicKey = \"" + testPubKey + "\"\n"
+		for _, name := range []string{"Alice", "alice"} {
+			if err := writeFile(filepath.Join(tmpDir, name), content); err != nil {
+				t.Fatalf("writeFile() error = %v", err)
+			}
+		}
+		entries, _ := os.ReadDir(tmpDir)
+		if len(entries) < 2 {
+			t.Skip("filesystem is case-insensitive")
+		}
+
+		all, err := store.LoadAll()
+		if err != nil {
+			t.Fatalf("LoadAll() error = %v", err)
+		}
+		if len(all) != 1 || all["Alice"] == nil {
+			t.Errorf("LoadAll() = %v, want only Alice", all)
+		}
+	})
+}
+
+func TestNodeInfoValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		info    NodeInfo
+		wantErr bool
+	}{
+		{"valid", NodeInfo{Name: "node1", Address: "10.0.0.1", PublicKey: testPubKey}, false},
+		{"empty name", NodeInfo{Name: "", Address: "10.0.0.1", PublicKey: testPubKey}, true},
+		{"empty address", NodeInfo{Name: "node1", Address: "", PublicKey: testPubKey}, true},
+		{"empty public key", NodeInfo{Name: "node1", Address: "10.0.0.1", PublicKey: ""}, true},
+		{"non-hex public key", NodeInfo{Name: "node1", Address: "10.0.0.1", PublicKey: "zz" + testPubKey[2:]}, true},
+		{"short public key", NodeInfo{Name: "node1", Address: "10.0.0.1", PublicKey: "abcd"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.info.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNodeStoreSaveInvalid(t *testing.T) {
+	tmpDir := t.TempDir()
+	store := NewNodeStore(tmpDir)
+
+	info := &NodeInfo{Name: "node1", NickName: "Test", Address: "", PublicKey: testPubKey}
+	if err := store.Save("node1", info); err == nil {
+		t.Error("Save() should return error for invalid node info")
+	}
+	if store.Exists("node1") {
+		t.Error("Save() should not create file for invalid node info")
+	}
+}
+
 func TestNodeStoreDelete(t *testing.T) {
 	t.Run("delete existing node", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		store := NewNodeStore(tmpDir)
 
 		// ノードを保存
-		info := &NodeInfo{Name: "node1", NickName: "Test", Address: "10.0.0.1", PublicKey: "key"}
+		info := &NodeInfo{Name: "node1", NickName: "Test", Address: "10.0.0.1", PublicKey: testPubKey}
 		store.Save("node1", info)
 
 		// 削除
@@ -238,7 +362,7 @@ func TestNodeStoreExists(t *testing.T) {
 		tmpDir := t.TempDir()
 		store := NewNodeStore(tmpDir)
 
-		info := &NodeInfo{Name: "node1", NickName: "Test", Address: "10.0.0.1", PublicKey: "key"}
+		info := &NodeInfo{Name: "node1", NickName: "Test", Address: "10.0.0.1", PublicKey: testPubKey}
 		store.Save("node1", info)
 
 		if !store.Exists("node1") {
@@ -48,6 +48,7 @@ func (s *PendingStore) Load() ([]*core.PendingTransaction, error) {
 }
 
 // Save は承認待ちトランザクションをJSON配列として書き出す
+// 一時ファイルに書いて rename するため、書き込み途中でクラッシュしても既存のファイルは壊れない
 func (s *PendingStore) Save(items []*core.PendingTransaction) error {
 	data, err := json.MarshalIndent(items, "", "  ")
 	if err != nil {
@@ -57,7 +58,7 @@ func (s *PendingStore) Save(items []*core.PendingTransaction) error {
 	// 改行で終わるようにする
 	data = append(data, '\n')
 
-	if err := writeFile(s.path, string(data)); err != nil {
+	if err := writeFileAtomic(s.path, data); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
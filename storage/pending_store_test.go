@@ -2,6 +2,8 @@ package storage
 
 import (
 	"encoding/json"
+	"fmt"
+	"os"
 	"path/filepath"
 	"signet/core"
 	"testing"
@@ -145,6 +147,70 @@ func TestPendingStoreSave(t *testing.T) {
 	})
 }
 
+func TestPendingStoreSave_Atomic(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "pending.json")
+	store := NewPendingStore(filePath)
+	makeItems := func(n int) []*core.PendingTransaction {
+		items := make([]*core.PendingTransaction, n)
+		for i := range items {
+			items[i] = core.NewPendingTransaction(fmt.Sprintf("id%d", i), core.BlockPayload{Type: "transaction"})
+		}
+		return items
+	}
+	if err := store.Save(makeItems(1)); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	// 上書き保存中に読んでも、空や書きかけのファイルは見えない
+	done := make(chan struct{})
+	readErr := make(chan error, 1)
+	go func() {
+		defer close(readErr)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			loaded, err := store.Load()
+			if err == nil && len(loaded) == 0 {
+				err = fmt.Errorf("loaded empty pending list")
+			}
+			if err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+	for i := 1; i <= 50; i++ {
+		if err := store.Save(makeItems(i)); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	close(done)
+	if err := <-readErr; err != nil {
+		t.Errorf("concurrent Load() error = %v", err)
+	}
+	if _, err := os.Stat(filePath + ".tmp"); !os.IsNotExist(err) {
+		t.Errorf("temp file left behind: %v", err)
+	}
+
+	// 一時ファイルに書けない場合は既存のファイルをそのまま残す
+	if err := os.Mkdir(filePath+".tmp", 0755); err != nil {
+		t.Fatalf("Mkdir() error = %v", err)
+	}
+	if err := store.Save(makeItems(3)); err == nil {
+		t.Fatal("Save() error = nil, want error when the temp file cannot be created")
+	}
+	loaded, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(loaded) != 50 {
+		t.Errorf("Load() returned %d items after failed save, want 50", len(loaded))
+	}
+}
+
 func TestPendingStoreAdd(t *testing.T) {
 	t.Run("add pending transaction", func(t *testing.T) {
 		tmpDir := t.TempDir()
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// SeenStore は転送済みブロックのハッシュ（直近 capacity 件）の永続化を担当する
+// 再起動後も同じブロックを再ブロードキャストしないために使う
+type SeenStore struct {
+	path     string
+	capacity int
+}
+
+// NewSeenStore は最大 capacity 件を保存する SeenStore を作成する
+func NewSeenStore(path string, capacity int) *SeenStore {
+	return &SeenStore{path: path, capacity: capacity}
+}
+
+// Load は保存されたハッシュを古い順に読み込む（capacity を超える分は古いものから捨てる）
+// ファイルが存在しない場合は空スライスを返す
+func (s *SeenStore) Load() ([]string, error) {
+	data, err := readFile(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	if len(data) == 0 {
+		return []string{}, nil
+	}
+
+	var hashes []string
+	if err := json.Unmarshal(data, &hashes); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal seen blocks: %w", err)
+	}
+
+	return s.newest(hashes), nil
+}
+
+// Save はハッシュ（古い順）のうち新しい capacity 件を JSON 配列として書き出す
+func (s *SeenStore) Save(hashes []string) error {
+	data, err := json.Marshal(s.newest(hashes))
+	if err != nil {
+		return fmt.Errorf("failed to marshal seen blocks: %w", err)
+	}
+
+	if err := writeFileAtomic(s.path, append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
+
+// newest は hashes の末尾 capacity 件を返す（capacity が 0 以下なら制限しない）
+func (s *SeenStore) newest(hashes []string) []string {
+	if s.capacity > 0 && len(hashes) > s.capacity {
+		return hashes[len(hashes)-s.capacity:]
+	}
+	return hashes
+}
@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestSeenStoreSaveLoad(t *testing.T) {
+	store := NewSeenStore(filepath.Join(t.TempDir(), "seen_blocks.json"), 10)
+
+	hashes, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(hashes) != 0 {
+		t.Errorf("Load() returned %d hashes for missing file, want 0", len(hashes))
+	}
+
+	want := []string{"hash1", "hash2", "hash3"}
+	if err := store.Save(want); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Load() = %v, want %v", got, want)
+	}
+}
+
+func TestSeenStoreEviction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "seen_blocks.json")
+	store := NewSeenStore(path, 3)
+
+	// 容量を超えた分は古いものから捨てる
+	if err := store.Save([]string{"h1", "h2", "h3", "h4", "h5"}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	got, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if want := []string{"h3", "h4", "h5"}; !slices.Equal(got, want) {
+		t.Errorf("Load() = %v, want %v", got, want)
+	}
+
+	// 容量を小さくして読み込んだ場合も新しいものだけ残す
+	got, err = NewSeenStore(path, 2).Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if want := []string{"h4", "h5"}; !slices.Equal(got, want) {
+		t.Errorf("Load() with smaller capacity = %v, want %v", got, want)
+	}
+}
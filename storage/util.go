@@ -2,9 +2,17 @@ package storage
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"syscall"
 )
 
+// IsDiskFull はエラーがディスク容量不足（ENOSPC）によるものかを返す
+func IsDiskFull(err error) bool {
+	return errors.Is(err, syscall.ENOSPC)
+}
+
 // readFile はファイルを読み込むヘルパー関数
 func readFile(path string) ([]byte, error) {
 	return os.ReadFile(path)
@@ -15,6 +23,35 @@ func writeFile(path string, content string) error {
 	return os.WriteFile(path, []byte(content), 0644)
 }
 
+// writeFileAtomic は path + ".tmp" に書き込んで fsync してから path にリネームするヘルパー関数
+// 書き込み途中でクラッシュしても path が空や書きかけの状態で残らない
+func writeFileAtomic(path string, data []byte) error {
+	tmpPath := path + ".tmp"
+	f, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to create temp file: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to sync temp file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to close temp file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("failed to rename file: %w", err)
+	}
+	return nil
+}
+
 // appendFile はファイルに追記するヘルパー関数
 func appendFile(path string, data []byte) error {
 	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
@@ -26,20 +63,43 @@ func appendFile(path string, data []byte) error {
 	return err
 }
 
-// splitLines はバイト列を行ごとに分割するヘルパー関数
-func splitLines(data []byte) [][]byte {
-	var lines [][]byte
-	start := 0
-	for i, b := range data {
-		if b == '\n' {
-			lines = append(lines, data[start:i])
-			start = i + 1
-		}
+// appendFileSync はファイルに追記し、fsync してから閉じるヘルパー関数
+func appendFileSync(path string, data []byte) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
 	}
-	if start < len(data) {
-		lines = append(lines, data[start:])
+	defer f.Close()
+	if _, err := f.Write(data); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// endsWithNewline はファイルが空か、改行で終わっているかを返す（存在しない場合も true）
+func endsWithNewline(path string) (bool, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+	if info.Size() == 0 {
+		return true, nil
+	}
+
+	last := make([]byte, 1)
+	if _, err := f.ReadAt(last, info.Size()-1); err != nil {
+		return false, err
 	}
-	return lines
+	return last[0] == '\n', nil
 }
 
 // openFile はファイルを開くヘルパー関数
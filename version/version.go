@@ -0,0 +1,28 @@
+// Package version はビルド時に埋め込むバージョン情報を提供する
+package version
+
+import "runtime"
+
+// Version / BuildDate はビルド時に -ldflags で設定する
+//
+//	go build -ldflags "-X signet/version.Version=v1.2.0 -X signet/version.BuildDate=2024-01-01T00:00:00Z"
+var (
+	Version   = "dev"
+	BuildDate = "unknown"
+)
+
+// Info はビルドのバージョン情報を表す
+type Info struct {
+	Version   string `json:"version"`
+	GoVersion string `json:"go_version"`
+	BuildDate string `json:"build_date"`
+}
+
+// Get は実行中のバイナリのバージョン情報を返す
+func Get() Info {
+	return Info{
+		Version:   Version,
+		GoVersion: runtime.Version(),
+		BuildDate: BuildDate,
+	}
+}